@@ -0,0 +1,97 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+// TestStatistics checks the statistics over values far from zero, where a
+// naive variance loses its precision, inserted in no particular order and
+// mixed with nulls, which are skipped.
+func TestStatistics(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (g INT32, x INT64)")
+	values := []string{"(1, NULL)", "(2, NULL)", "(3, 7)"}
+	for _, i := range rand.New(rand.NewSource(1)).Perm(1000) {
+		values = append(values, fmt.Sprintf("(1, %d)", 1000000000+i+1))
+	}
+	mustExec(t, e, ctx, "INSERT INTO t (g, x) VALUES "+strings.Join(values, ", "))
+
+	tests := []struct {
+		sql       string
+		want      float64
+		tolerance float64
+	}{
+		{"SELECT VARIANCE(x) AS s FROM t WHERE g = 1", 1000 * 1001 / 12.0, 1e-3},
+		{"SELECT STDDEV(x) AS s FROM t WHERE g = 1", math.Sqrt(1000 * 1001 / 12.0), 1e-6},
+		{"SELECT MEDIAN(x) AS s FROM t WHERE g = 1", 1000000500.5, 0},
+		{"SELECT PERCENTILE(x, 0.9) AS s FROM t WHERE g = 1", 1000000900.1, 1e-6},
+		{"SELECT PERCENTILE(x, 0) AS s FROM t WHERE g = 1", 1000000001, 0},
+		{"SELECT PERCENTILE(x, 1) AS s FROM t WHERE g = 1", 1000001000, 0},
+		{"SELECT APPROX_PERCENTILE(x, 0.9) AS s FROM t WHERE g = 1", 1000000900.1, 10},
+		{"SELECT MEDIAN(x) AS s FROM t WHERE g = 3", 7, 0},
+		{"SELECT APPROX_PERCENTILE(x, 0.5) AS s FROM t WHERE g = 3", 7, 0},
+	}
+	for _, tt := range tests {
+		rows := selectRows(t, e, tt.sql)
+		got, ok := rows[0]["s"].(float64)
+		if !ok || math.Abs(got-tt.want) > tt.tolerance {
+			t.Errorf("%s = %v, want %v", tt.sql, rows, tt.want)
+		}
+	}
+
+	// There is no statistic of no values, and no sample variance of one.
+	for _, sql := range []string{
+		"SELECT VARIANCE(x) AS s FROM t WHERE g = 3",
+		"SELECT STDDEV(x) AS s FROM t WHERE g = 3",
+		"SELECT VARIANCE(x) AS s FROM t WHERE g = 2",
+		"SELECT MEDIAN(x) AS s FROM t WHERE g = 2",
+		"SELECT PERCENTILE(x, 0.5) AS s FROM t WHERE g = 2",
+		"SELECT APPROX_PERCENTILE(x, 0.5) AS s FROM t WHERE g = 2",
+		"SELECT MEDIAN(x) AS s FROM t WHERE g = 4",
+	} {
+		if got := fmt.Sprint(selectRows(t, e, sql)); got != "[map[s:<nil>]]" {
+			t.Errorf("%s = %s, want [map[s:<nil>]]", sql, got)
+		}
+	}
+}
+
+func TestApproxCountDistinct(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (g INT32, s STRING(10))")
+	values := []string{"(2, NULL)", "(3, 'a')", "(3, 'a')", "(3, NULL)"}
+	for i := range 3000 {
+		values = append(values, fmt.Sprintf("(1, 'v%d')", i%2000))
+	}
+	mustExec(t, e, ctx, "INSERT INTO t (g, s) VALUES "+strings.Join(values, ", "))
+
+	rows := selectRows(t, e, "SELECT APPROX_COUNT_DISTINCT(s) AS n, COUNT(DISTINCT s) AS d FROM t WHERE g = 1")
+	if d := fmt.Sprint(rows[0]["d"]); d != "2000" {
+		t.Errorf("COUNT(DISTINCT s) = %s, want 2000", d)
+	}
+	if n, ok := rows[0]["n"].(int64); !ok || n < 1900 || n > 2100 {
+		t.Errorf("APPROX_COUNT_DISTINCT(s) = %v, want about 2000", rows[0]["n"])
+	}
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT APPROX_COUNT_DISTINCT(s) AS n FROM t WHERE g = 3", "[map[n:1]]"},
+		{"SELECT APPROX_COUNT_DISTINCT(s) AS n FROM t WHERE g = 2", "[map[n:0]]"},
+		{"SELECT APPROX_COUNT_DISTINCT(s) AS n FROM t WHERE g = 4", "[map[n:0]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+}
@@ -1 +1,88 @@
 package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+func (e *DefaultExecutor) executeAlterTable(ctx context.Context, stmt *statement.AlterTableStatement) response.Response {
+	table, err := e.catalog.GetTable(stmt.Database, stmt.Schema, stmt.TableName)
+	if err != nil {
+		return response.NewAlterTableResponse(false, err.Error())
+	}
+
+	table.Lock()
+	defer table.Unlock()
+
+	for _, change := range stmt.Changes {
+		select {
+		case <-ctx.Done():
+			return response.NewAlterTableResponse(false, "context canceled")
+		default:
+		}
+
+		if err := applyAlterChange(table, change); err != nil {
+			return response.NewAlterTableResponse(false, err.Error())
+		}
+	}
+
+	if err := saveTableConfig(table); err != nil {
+		return response.NewAlterTableResponse(false, err.Error())
+	}
+
+	return response.NewAlterTableResponse(true, "table altered successfully")
+}
+
+func applyAlterChange(table *catalog.Table, change statement.AlterTableChange) error {
+	meta, err := table.GetFieldMeta(change.Column)
+	if err != nil {
+		return err
+	}
+
+	switch change.Action {
+	case statement.AddEnumValues:
+		if meta.Type != fields.Enum {
+			return fmt.Errorf("column %s is not an enum", change.Column)
+		}
+		meta.Values = append(append([]string(nil), meta.Values...), newEnumValues(meta.Values, change.Values)...)
+		return table.UpdateField(change.Column, meta)
+	}
+
+	return fmt.Errorf("unsupported alter action %s", change.Action)
+}
+
+// newEnumValues returns the values not yet in current, in order and without
+// duplicates.
+func newEnumValues(current, values []string) []string {
+	seen := make(map[string]struct{}, len(current)+len(values))
+	for _, v := range current {
+		seen[v] = struct{}{}
+	}
+
+	var result []string
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
+
+// saveTableConfig persists the current field definitions to config.json.
+func saveTableConfig(table *catalog.Table) error {
+	metas, err := table.ListFields()
+	if err != nil {
+		return err
+	}
+	table.StorageConfig.Fields = metas
+
+	return storage.NewConfigManager(table.Path).SaveTableConfig(table.Name, table.StorageConfig)
+}
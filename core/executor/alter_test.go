@@ -0,0 +1,55 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestEnums(t *testing.T) {
+	path := t.TempDir()
+	e, cat := openExecutor(t, path, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE DATABASE db")
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, e ENUM('low', 'high'))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, e) VALUES (1, 'high'), (2, 'low'), (3, NULL)")
+	if resp := run(e, ctx, "INSERT INTO t (a, e) VALUES (4, 'mid')"); resp.IsSuccess() {
+		t.Error("INSERT accepted a value not in the enum")
+	}
+
+	mustExec(t, e, ctx, "ALTER TABLE t ALTER COLUMN e ADD VALUES ('mid', 'low')")
+	mustExec(t, e, ctx, "INSERT INTO t (a, e) VALUES (4, 'mid')")
+	mustExec(t, e, ctx, "UPDATE t SET e = 'mid' WHERE a = 2")
+	if resp := run(e, ctx, "ALTER TABLE t ALTER a ADD VALUE 'x'"); resp.IsSuccess() {
+		t.Error("ALTER TABLE added a value to a column that is not an enum")
+	}
+	cat.Close()
+
+	// The values added are kept, and the rows written before read the
+	// same.
+	e, _ = openExecutor(t, path, executor.Config{})
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT a, e FROM t ORDER BY a", "[map[a:1 e:high] map[a:2 e:mid] map[a:3 e:<nil>] map[a:4 e:mid]]"},
+		{"SELECT a FROM t WHERE e = 'mid' ORDER BY a", "[map[a:2] map[a:4]]"},
+		{"SELECT a FROM t WHERE e <> 'mid'", "[map[a:1]]"},
+		{"SELECT a FROM t WHERE e IN ('high', 'low')", "[map[a:1]]"},
+		{"SELECT a FROM t WHERE e IS NULL", "[map[a:3]]"},
+		{"SELECT e, COUNT(*) AS n FROM t WHERE e IS NOT NULL GROUP BY e ORDER BY e", "[map[e:high n:1] map[e:mid n:2]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	mustExec(t, e, ctx, "ALTER TABLE t ALTER e ADD VALUE 'top'")
+	mustExec(t, e, ctx, "INSERT INTO t (a, e) VALUES (5, 'top')")
+	if got := fmt.Sprint(selectRows(t, e, "SELECT a FROM t WHERE e = 'top'")); got != "[map[a:5]]" {
+		t.Errorf("rows with the value added = %s", got)
+	}
+}
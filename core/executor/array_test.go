@@ -0,0 +1,60 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestArrays(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, tags ARRAY<STRING(10)>, n INT32[])")
+	mustExec(t, e, ctx, `INSERT INTO t (a, tags, n) VALUES
+		(1, ['x', 'y'], [1, 2, 3]),
+		(2, ['y'], []),
+		(3, [], [2]),
+		(4, NULL, NULL)`)
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT a, tags, n FROM t ORDER BY a", "[map[a:1 n:[1 2 3] tags:[x y]] map[a:2 n:[] tags:[y]] map[a:3 n:[2] tags:[]] map[a:4 n:<nil> tags:<nil>]]"},
+		{"SELECT a FROM t WHERE tags CONTAINS 'y' ORDER BY a", "[map[a:1] map[a:2]]"},
+		{"SELECT a FROM t WHERE tags CONTAINS ('x', 'y')", "[map[a:1]]"},
+		{"SELECT a FROM t WHERE tags CONTAINS ('x', 'z')", "[]"},
+		{"SELECT a FROM t WHERE n CONTAINS 2 ORDER BY a", "[map[a:1] map[a:3]]"},
+		{"SELECT a FROM t WHERE n ANY (3, 4)", "[map[a:1]]"},
+		{"SELECT a FROM t WHERE n ALL (1, 2) ORDER BY a", "[map[a:2] map[a:3]]"},
+		{"SELECT a FROM t WHERE tags = ['y']", "[map[a:2]]"},
+		{"SELECT a FROM t WHERE tags IS NULL", "[map[a:4]]"},
+		// Each element gives a row, and the empty and null arrays none.
+		{"SELECT a, UNNEST(tags) FROM t ORDER BY a", "[map[a:1 tags:x] map[a:1 tags:y] map[a:2 tags:y]]"},
+		{"SELECT UNNEST(n) AS n FROM t WHERE a = 1", "[map[n:1] map[n:2] map[n:3]]"},
+		{"SELECT a, UNNEST(n) FROM t WHERE n CONTAINS 2 ORDER BY a", "[map[a:1 n:1] map[a:1 n:2] map[a:1 n:3] map[a:3 n:2]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	mustExec(t, e, ctx, "UPDATE t SET tags = ['z'] WHERE a = 1")
+	if got := fmt.Sprint(selectRows(t, e, "SELECT a, tags FROM t WHERE a = 1")); got != "[map[a:1 tags:[z]]]" {
+		t.Errorf("rows after update = %s", got)
+	}
+
+	for _, sql := range []string{
+		"INSERT INTO t (a, tags) VALUES (5, [1])",
+		"INSERT INTO t (a, n) VALUES (5, ['x'])",
+		"SELECT UNNEST(a) FROM t",
+		"SELECT UNNEST(tags) AS other FROM t",
+	} {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+func (e *DefaultExecutor) executeCreateIndex(ctx context.Context, stmt *statement.CreateIndexStatement) response.Response {
+	select {
+	case <-ctx.Done():
+		return response.NewCreateIndexResponse(false, "context canceled")
+	default:
+	}
+
+	table, err := e.catalog.GetTable(stmt.Database, stmt.Schema, stmt.TableName)
+	if err != nil {
+		return response.NewCreateIndexResponse(false, err.Error())
+	}
+	if len(stmt.Columns) != 1 {
+		return response.NewCreateIndexResponse(false, "indexes on several columns are not supported")
+	}
+
+	table.LockInsert()
+	defer table.UnlockInsert()
+
+	err = table.CreateIndex(storage.IndexConfig{
+		Name:    stmt.IndexName,
+		Column:  stmt.Columns[0],
+		Kind:    stmt.Using,
+		Unique:  stmt.Unique,
+		Options: stmt.Options,
+	})
+	if err != nil {
+		return response.NewCreateIndexResponse(false, err.Error())
+	}
+
+	return response.NewCreateIndexResponse(true, fmt.Sprintf("index %s created", stmt.IndexName))
+}
@@ -0,0 +1,175 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+func TestIndexesSurviveReopen(t *testing.T) {
+	path := t.TempDir()
+	e, cat := openExecutor(t, path, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE DATABASE db")
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10), v VECTOR(3))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b, v) VALUES (1, 'x', [1, 0, 0]), (2, 'y', [0, 1, 0]), (3, 'x', [0, 0, 1])")
+	mustExec(t, e, ctx, "CREATE INDEX t_a ON t (a) USING zonemap WITH (block_size = 2)")
+	mustExec(t, e, ctx, "CREATE INDEX t_b ON t (b)")
+	mustExec(t, e, ctx, "CREATE INDEX t_v ON t (v) USING hnsw WITH (metric = 'l2', m = 8, seed = 7)")
+	if resp := run(e, ctx, "CREATE INDEX t_b ON t (a)"); resp.IsSuccess() {
+		t.Error("CREATE INDEX reused the name of an index")
+	}
+	if resp := run(e, ctx, "CREATE INDEX t_c ON t (b) USING hnsw"); resp.IsSuccess() {
+		t.Error("CREATE INDEX built an HNSW index on a string")
+	}
+	cat.Close()
+
+	e, cat = openExecutor(t, path, executor.Config{})
+	table, err := cat.GetTable("db", "public", "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[{t_a a zonemap false map[block_size:2]} {t_b b hash false map[]} {t_v v hnsw false map[m:8 metric:l2 seed:7]}]"
+	if got := fmt.Sprint(table.StorageConfig.Indexes); got != want {
+		t.Errorf("indexes in config = %s, want %s", got, want)
+	}
+
+	indexes := table.Indexes("")
+	if len(indexes) != 3 {
+		t.Fatalf("got %d indexes after reopening, want 3", len(indexes))
+	}
+	if _, ok := indexes[2].Index.(storage.VectorIndex); !ok {
+		t.Errorf("index t_v is a %T, not a vector index", indexes[2].Index)
+	}
+	for _, idx := range indexes {
+		if size := idx.Stats().Size; size == 0 {
+			t.Errorf("index %s is empty after reopening", idx.Name)
+		}
+	}
+
+	mustExec(t, e, ctx, "INSERT INTO t (a, b, v) VALUES (4, 'x', [1, 1, 0])")
+	rows := fmt.Sprint(selectRows(t, e, "SELECT a FROM t WHERE b = 'x' ORDER BY a"))
+	if rows != "[map[a:1] map[a:3] map[a:4]]" {
+		t.Errorf("rows = %s", rows)
+	}
+}
+
+func TestDropIndex(t *testing.T) {
+	path := t.TempDir()
+	e, cat := openExecutor(t, path, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE DATABASE db")
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y')")
+	mustExec(t, e, ctx, "CREATE INDEX t_a ON t (a)")
+	mustExec(t, e, ctx, "CREATE INDEX t_b ON t (b)")
+	mustExec(t, e, ctx, "DROP INDEX t_a ON t")
+	if resp := run(e, ctx, "DROP INDEX t_a ON t"); resp.IsSuccess() {
+		t.Error("DROP INDEX dropped a missing index")
+	}
+	cat.Close()
+
+	e, cat = openExecutor(t, path, executor.Config{})
+	table, err := cat.GetTable("db", "public", "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexes := table.Indexes(""); len(indexes) != 1 || indexes[0].Name != "t_b" {
+		t.Errorf("indexes after reopening = %v, want t_b alone", indexes)
+	}
+	mustExec(t, e, ctx, "CREATE INDEX t_a ON t (b)")
+	if rows := fmt.Sprint(selectRows(t, e, "SELECT a FROM t WHERE b = 'y'")); rows != "[map[a:2]]" {
+		t.Errorf("rows = %s", rows)
+	}
+}
+
+func TestUniqueIndex(t *testing.T) {
+	path := t.TempDir()
+	e, cat := openExecutor(t, path, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE DATABASE db")
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'x')")
+
+	if resp := run(e, ctx, "CREATE UNIQUE INDEX t_b ON t (b)"); resp.IsSuccess() {
+		t.Error("CREATE UNIQUE INDEX accepted a column holding duplicates")
+	}
+	if resp := run(e, ctx, "CREATE UNIQUE INDEX t_a ON t (a) USING zonemap"); resp.IsSuccess() {
+		t.Error("CREATE UNIQUE INDEX built a zone map")
+	}
+	mustExec(t, e, ctx, "CREATE UNIQUE INDEX t_a ON t (a)")
+	cat.Close()
+
+	e, _ = openExecutor(t, path, executor.Config{})
+	rejected := []string{
+		"INSERT INTO t (a, b) VALUES (2, 'z')",
+		"INSERT INTO t (a, b) VALUES (4, 'z'), (4, 'w')",
+		"UPDATE t SET a = 1 WHERE a = 3",
+		"UPDATE t SET a = 5",
+	}
+	for _, sql := range rejected {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: duplicate accepted", sql)
+		}
+	}
+	imported, err := statement.NewImportStatement("db", "public", "t", []map[string]interface{}{{"a": 3, "b": "z"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := e.Execute(ctx, imported); resp.IsSuccess() {
+		t.Error("IMPORT: duplicate accepted")
+	}
+
+	mustExec(t, e, ctx, "UPDATE t SET a = 4 - a WHERE a <> 2")
+	mustExec(t, e, ctx, "INSERT INTO t (b) VALUES ('n'), ('m')")
+	mustExec(t, e, ctx, "DELETE FROM t WHERE a = 1")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'z')")
+
+	rows := fmt.Sprint(selectRows(t, e, "SELECT a, b FROM t WHERE a IS NOT NULL ORDER BY a"))
+	if want := "[map[a:1 b:z] map[a:2 b:y] map[a:3 b:x]]"; rows != want {
+		t.Errorf("rows = %s, want %s", rows, want)
+	}
+}
+
+func TestIndexMatchesScan(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (k INT32, a INT32)")
+	mustExec(t, e, ctx, "INSERT INTO t (k, a) VALUES (1, 0), (2, 1), (3, -1)")
+	mustExec(t, e, ctx, "INSERT INTO t (k) VALUES (4)")
+
+	queries := []string{
+		"SELECT k FROM t WHERE a = 0 ORDER BY k",
+		"SELECT k FROM t WHERE a < 1 ORDER BY k",
+		"SELECT k FROM t WHERE a IN (0, 1) ORDER BY k",
+		"SELECT k FROM t WHERE a BETWEEN -1 AND 0 ORDER BY k",
+		"SELECT k FROM t WHERE a IS NULL ORDER BY k",
+	}
+	scanned := make([]string, len(queries))
+	for i, sql := range queries {
+		scanned[i] = fmt.Sprint(selectRows(t, e, sql))
+	}
+
+	for _, using := range []string{"hash", "zonemap"} {
+		mustExec(t, e, ctx, "CREATE INDEX ia ON t (a) USING "+using)
+		for i, sql := range queries {
+			if got := fmt.Sprint(selectRows(t, e, sql)); got != scanned[i] {
+				t.Errorf("%s with a %s index = %s, want %s as scanned", sql, using, got, scanned[i])
+			}
+		}
+		mustExec(t, e, ctx, "DROP INDEX ia ON t")
+	}
+
+	// The comparison holds only if the index is used.
+	mustExec(t, e, ctx, "CREATE INDEX ia ON t (a)")
+	plan := mustExec(t, e, ctx, "EXPLAIN "+queries[0]).(*response.SelectResponse).Plan.String()
+	if !strings.Contains(plan, "ia") {
+		t.Errorf("%s does not use the index:\n%s", queries[0], plan)
+	}
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/onnasoft/ZenithSQL/core/storage"
@@ -33,3 +34,26 @@ func (e *DefaultExecutor) executeCreateTable(ctx context.Context, stmt *statemen
 
 	return response.NewCreateTableResponse(true, "table created")
 }
+
+// executeCreateDatabase creates the database with a public schema, which
+// tables are created in: there is no CREATE SCHEMA.
+func (e *DefaultExecutor) executeCreateDatabase(ctx context.Context, stmt *statement.CreateDatabaseStatement) response.Response {
+	select {
+	case <-ctx.Done():
+		return response.NewCreateDatabaseResponse(false, "context canceled")
+	default:
+	}
+
+	if e.catalog.ExistsDatabase(stmt.DatabaseName) {
+		return response.NewCreateDatabaseResponse(false, fmt.Sprintf("database %s already exists", stmt.DatabaseName))
+	}
+	db, err := e.catalog.CreateDatabase(stmt.DatabaseName)
+	if err != nil {
+		return response.NewCreateDatabaseResponse(false, err.Error())
+	}
+	if _, err := db.CreateSchema("public"); err != nil {
+		return response.NewCreateDatabaseResponse(false, err.Error())
+	}
+
+	return response.NewCreateDatabaseResponse(true, "database created")
+}
@@ -1 +1,141 @@
 package executor
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+)
+
+// executeDelete sets the deleted_at of the rows matching the condition,
+// which hides them from selects, and removes them from the indexes. With
+// PURGE it removes the deleted rows matching the condition from the table
+// files instead.
+func (e *DefaultExecutor) executeDelete(ctx context.Context, stmt *statement.DeleteStatement) response.Response {
+	startTime := time.Now()
+	fail := func(err error) response.Response {
+		return response.NewDeleteResponse(false, err.Error(), 0, time.Since(startTime).Milliseconds())
+	}
+
+	table, err := e.catalog.GetTable(stmt.Database, stmt.Schema, stmt.TableName)
+	if err != nil {
+		return fail(err)
+	}
+
+	table.LockInsert()
+	defer table.UnlockInsert()
+
+	rows, err := e.deletedRows(ctx, table, stmt)
+	if err != nil {
+		return fail(err)
+	}
+
+	if stmt.Purge {
+		ids := make([]int64, len(rows))
+		for i, row := range rows {
+			ids[i] = row.values["id"].(int64)
+		}
+		slices.Sort(ids)
+		if err := table.Purge(ctx, ids); err != nil {
+			return fail(err)
+		}
+		return response.NewDeleteResponse(true, "purged successfully", int64(len(ids)), time.Since(startTime).Milliseconds())
+	}
+
+	writer, err := table.Writer()
+	if err != nil {
+		return fail(err)
+	}
+	defer writer.Close()
+
+	now := time.Now()
+	for _, row := range rows {
+		select {
+		case <-ctx.Done():
+			writer.Rollback()
+			return fail(ctx.Err())
+		default:
+		}
+
+		if err := writer.Update(map[string]interface{}{"id": row.values["id"], "deleted_at": now}); err != nil {
+			writer.Rollback()
+			return fail(err)
+		}
+	}
+	if err := writer.Commit(); err != nil {
+		writer.Rollback()
+		return fail(err)
+	}
+
+	if err := table.UpdateDeletedRowCount(table.DeletedRowCount() + int64(len(rows))); err != nil {
+		return fail(err)
+	}
+	// The rows keep no value in the indexes.
+	if err := reindexRows(table, rows); err != nil {
+		return fail(err)
+	}
+
+	return response.NewDeleteResponse(true, "deleted successfully", int64(len(rows)), time.Since(startTime).Milliseconds())
+}
+
+// deletedRows selects the ids of the rows stmt deletes, and the values of
+// their indexed columns. The rows a purge removes are those already
+// deleted.
+func (e *DefaultExecutor) deletedRows(ctx context.Context, table *catalog.Table, stmt *statement.DeleteStatement) ([]updatedRow, error) {
+	sel := &statement.SelectStatement{
+		Database:  stmt.Database,
+		Schema:    stmt.Schema,
+		TableName: stmt.TableName,
+		Columns:   []string{"id"},
+		Where:     stmt.Where,
+	}
+	if stmt.Purge {
+		sel.IncludeDeleted = true
+		sel.Where = filters.NewCondition("deleted_at", filters.IsNotNull, nil)
+		if stmt.Where != nil {
+			sel.Where = filters.NewGroup(filters.And).Add(stmt.Where).Add(sel.Where)
+		}
+	} else {
+		for _, idx := range table.Indexes("") {
+			if !slices.Contains(sel.Columns, idx.Column) {
+				sel.Columns = append(sel.Columns, idx.Column)
+			}
+		}
+	}
+	indexed := sel.Columns[1:]
+
+	opened, err := e.openSelect(ctx, sel, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer opened.cursor.Close()
+
+	var rows []updatedRow
+	for opened.cursor.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		id, err := opened.cursor.ScanField("id")
+		if err != nil {
+			return nil, err
+		}
+		row := updatedRow{values: map[string]interface{}{"id": id}}
+		if len(indexed) > 0 {
+			row.previous = make(map[string]interface{}, len(indexed))
+			for _, column := range indexed {
+				if row.previous[column], err = opened.cursor.ScanField(column); err != nil {
+					return nil, err
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, opened.cursor.Err()
+}
@@ -0,0 +1,205 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestDeleteHidesRows(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'x')")
+	mustExec(t, e, ctx, "CREATE INDEX t_b ON t (b)")
+	mustExec(t, e, ctx, "DELETE FROM t WHERE a = 1")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT a FROM t ORDER BY a", "[map[a:2] map[a:3]]"},
+		{"SELECT a FROM t WHERE b = 'x'", "[map[a:3]]"},
+		{"SELECT COUNT(*) AS n FROM t", "[map[n:2]]"},
+		{"SELECT a FROM t WITH DELETED ORDER BY a", "[map[a:1] map[a:2] map[a:3]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestDeleteSkipsNulls(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (k INT32, a INT32)")
+	mustExec(t, e, ctx, "INSERT INTO t (k, a) VALUES (1, 0), (2, 5)")
+	mustExec(t, e, ctx, "INSERT INTO t (k) VALUES (3)")
+
+	// A null compares as neither equal nor unequal to any value, so the row
+	// holding it is neither deleted nor purged.
+	mustExec(t, e, ctx, "DELETE FROM t WHERE a = 0")
+	mustExec(t, e, ctx, "DELETE FROM t WHERE a <> 5")
+	mustExec(t, e, ctx, "PURGE FROM t WHERE a NOT IN (1, 2)")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT k, a FROM t ORDER BY k", "[map[a:5 k:2] map[a:<nil> k:3]]"},
+		{"SELECT k FROM t WITH DELETED ORDER BY k", "[map[k:2] map[k:3]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestPurgeKeepsIDs(t *testing.T) {
+	path := t.TempDir()
+	e, cat := openExecutor(t, path, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE DATABASE db")
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'x'), (4, 'y'), (5, 'x')")
+	mustExec(t, e, ctx, "CREATE INDEX t_b ON t (b)")
+	mustExec(t, e, ctx, "CREATE UNIQUE INDEX t_a ON t (a)")
+	mustExec(t, e, ctx, "DELETE FROM t WHERE a IN (1, 3, 4)")
+
+	if resp := mustExec(t, e, ctx, "PURGE FROM t WHERE a = 1"); resp.GetMessage() != "purged successfully" {
+		t.Errorf("PURGE = %v", resp)
+	}
+	mustExec(t, e, ctx, "PURGE FROM t WHERE b = 'y'")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT id, a FROM t ORDER BY id", "[map[a:2 id:2] map[a:5 id:5]]"},
+		{"SELECT id, a FROM t WITH DELETED ORDER BY id", "[map[a:2 id:2] map[a:3 id:3] map[a:5 id:5]]"},
+		{"SELECT id FROM t WHERE b = 'x'", "[map[id:5]]"},
+		{"SELECT id FROM t WHERE b = 'y'", "[map[id:2]]"},
+		{"SELECT id FROM t WHERE a = 5", "[map[id:5]]"},
+		{"SELECT id FROM t WITH DELETED WHERE id = 4", "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	mustExec(t, e, ctx, "PURGE FROM t")
+	if got := fmt.Sprint(selectRows(t, e, "SELECT id FROM t WITH DELETED ORDER BY id")); got != "[map[id:2] map[id:5]]" {
+		t.Errorf("after purging every deleted row: %s", got)
+	}
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'x')")
+	cat.Close()
+
+	e, _ = openExecutor(t, path, executor.Config{})
+	tests = []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT id, a, b FROM t ORDER BY id", "[map[a:2 b:y id:2] map[a:5 b:x id:5] map[a:1 b:x id:6]]"},
+		{"SELECT id FROM t WHERE b = 'x' ORDER BY id", "[map[id:5] map[id:6]]"},
+		{"SELECT COUNT(*) AS n FROM t", "[map[n:3]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("after reopening: %s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+	if resp := run(e, ctx, "INSERT INTO t (a, b) VALUES (5, 'z')"); resp.IsSuccess() {
+		t.Error("the unique index lost a row kept by the purge")
+	}
+}
+
+func TestPurgeInterrupted(t *testing.T) {
+	path := t.TempDir()
+	e, cat := openExecutor(t, path, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE DATABASE db")
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y')")
+	mustExec(t, e, ctx, "DELETE FROM t WHERE a = 1")
+	mustExec(t, e, ctx, "PURGE FROM t")
+	table, err := cat.GetTable("db", "public", "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cat.Close()
+
+	// A purge that stopped before committing leaves its column files.
+	stale := filepath.Join(table.Path, table.Name, "columns-stale")
+	if err := os.Mkdir(stale, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(stale, "a.data"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e, _ = openExecutor(t, path, executor.Config{})
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("the files of the interrupted purge are still there: %v", err)
+	}
+	if got := fmt.Sprint(selectRows(t, e, "SELECT id, b FROM t WITH DELETED")); got != "[map[b:y id:2]]" {
+		t.Errorf("rows = %s", got)
+	}
+}
+
+func TestPurgeWhileReading(t *testing.T) {
+	path := t.TempDir()
+	e, cat := openExecutor(t, path, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE DATABASE db")
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, tags ARRAY<STRING(10)>)")
+	mustExec(t, e, ctx, "INSERT INTO t (a, tags) VALUES (1, ['x']), (2, ['y']), (3, ['z'])")
+	mustExec(t, e, ctx, "DELETE FROM t WHERE a = 2")
+	table, err := cat.GetTable("db", "public", "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A cursor opened before the purge reads the rows as they were.
+	cursor, err := table.Cursor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []string
+	read := func() {
+		a, err := cursor.ScanField("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tags, err := cursor.ScanField("tags")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, fmt.Sprint(a, tags))
+	}
+	if !cursor.Next() {
+		t.Fatal("no rows")
+	}
+	read()
+	mustExec(t, e, ctx, "PURGE FROM t")
+	for cursor.Next() {
+		read()
+	}
+	if err := cursor.Err(); err != nil {
+		t.Fatal(err)
+	}
+	cursor.Close()
+	if got := fmt.Sprint(rows); got != "[1 [x] 3 [z]]" {
+		t.Errorf("rows read across the purge = %s", got)
+	}
+
+	if got := fmt.Sprint(selectRows(t, e, "SELECT a, tags FROM t WITH DELETED ORDER BY a")); got != "[map[a:1 tags:[x]] map[a:3 tags:[z]]]" {
+		t.Errorf("rows after the purge = %s", got)
+	}
+}
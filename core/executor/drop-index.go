@@ -0,0 +1,30 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+func (e *DefaultExecutor) executeDropIndex(ctx context.Context, stmt *statement.DropIndexStatement) response.Response {
+	select {
+	case <-ctx.Done():
+		return response.NewDropIndexResponse(false, "context canceled")
+	default:
+	}
+
+	table, err := e.catalog.GetTable(stmt.Database, stmt.Schema, stmt.TableName)
+	if err != nil {
+		return response.NewDropIndexResponse(false, err.Error())
+	}
+
+	table.LockInsert()
+	defer table.UnlockInsert()
+
+	if err := table.DropIndex(stmt.IndexName); err != nil {
+		return response.NewDropIndexResponse(false, err.Error())
+	}
+	return response.NewDropIndexResponse(true, fmt.Sprintf("index %s dropped", stmt.IndexName))
+}
@@ -23,3 +23,16 @@ func (e *DefaultExecutor) executeDropTable(ctx context.Context, stmt *statement.
 	}
 	return response.NewDropTableResponse(true, "table dropped")
 }
+
+func (e *DefaultExecutor) executeDropDatabase(ctx context.Context, stmt *statement.DropDatabaseStatement) response.Response {
+	select {
+	case <-ctx.Done():
+		return response.NewDropDatabaseResponse(false, "context canceled")
+	default:
+	}
+
+	if err := e.catalog.DropDatabase(stmt.DatabaseName); err != nil {
+		return response.NewDropDatabaseResponse(false, err.Error())
+	}
+	return response.NewDropDatabaseResponse(true, "database dropped")
+}
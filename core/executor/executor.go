@@ -11,14 +11,35 @@ import (
 
 var (
 	ErrUnsupportedStatement = errors.New("unsupported statement")
+	// ErrNoTransactions rejects the transaction statements: every statement
+	// commits on its own.
+	ErrNoTransactions = errors.New("transactions are not supported: every statement commits on its own")
 )
 
 type Executor interface {
 	Execute(ctx context.Context, stmt statement.Statement) response.Response
 }
 
+// Config tunes the resources a query may use. SortMemoryLimit is the number
+// of bytes of rows an ORDER BY, a set operation or the work table of a
+// recursive common table expression holds in memory before spilling to
+// files in TempDir, and GroupMemoryLimit the bytes of groups a GROUP BY,
+// or of values a DISTINCT, holds before spilling rows of new ones.
+// JoinMemoryLimit is the bytes of rows a hash join holds from its right
+// input before partitioning both inputs to files. RecursionLimit is the
+// number of steps a recursive common table expression may take. Zero
+// values use the storage defaults.
+type Config struct {
+	SortMemoryLimit  int64
+	GroupMemoryLimit int64
+	JoinMemoryLimit  int64
+	RecursionLimit   int
+	TempDir          string
+}
+
 type DefaultExecutor struct {
 	catalog *catalog.Catalog
+	config  Config
 }
 
 func New(catalog *catalog.Catalog) *DefaultExecutor {
@@ -27,22 +48,61 @@ func New(catalog *catalog.Catalog) *DefaultExecutor {
 	}
 }
 
+func NewWithConfig(catalog *catalog.Catalog, config Config) *DefaultExecutor {
+	return &DefaultExecutor{
+		catalog: catalog,
+		config:  config,
+	}
+}
+
 func (e *DefaultExecutor) Execute(ctx context.Context, stmt statement.Statement) response.Response {
 	switch s := stmt.(type) {
+	case *statement.CreateDatabaseStatement:
+		return e.executeCreateDatabase(ctx, s)
+	case *statement.DropDatabaseStatement:
+		return e.executeDropDatabase(ctx, s)
 	case *statement.CreateTableStatement:
 		return e.executeCreateTable(ctx, s)
 	case *statement.DropTableStatement:
 		return e.executeDropTable(ctx, s)
+	case *statement.AlterTableStatement:
+		return e.executeAlterTable(ctx, s)
+	case *statement.CreateIndexStatement:
+		return e.executeCreateIndex(ctx, s)
+	case *statement.DropIndexStatement:
+		return e.executeDropIndex(ctx, s)
 	case *statement.TruncateTableStatement:
 		return e.executeTruncateTable(ctx, s)
 	case *statement.ImportStatement:
 		return e.executeImport(ctx, s)
 	case *statement.InsertStatement:
 		return e.executeInsert(ctx, s)
+	case *statement.UpsertStatement:
+		return e.executeUpsert(ctx, s)
 	case *statement.UpdateStatement:
 		return e.executeUpdate(ctx, s)
+	case *statement.DeleteStatement:
+		return e.executeDelete(ctx, s)
 	case *statement.SelectStatement:
 		return e.executeSelect(ctx, s)
+	case *statement.QueryStatement:
+		return e.executeQuery(ctx, s)
+	case *statement.PrepareStatement:
+		return e.executePrepare(ctx, s)
+	case *statement.ExecuteStatement:
+		return e.executePrepared(ctx, s)
+	case *statement.DeallocateStatement:
+		return e.executeDeallocate(ctx, s)
+	case *statement.BeginTransactionStatement:
+		return response.NewBeginTransactionResponse(false, ErrNoTransactions.Error())
+	case *statement.CommitStatement:
+		return response.NewCommitResponse(false, ErrNoTransactions.Error())
+	case *statement.RollbackStatement:
+		return response.NewRollbackResponse(false, ErrNoTransactions.Error())
+	case *statement.SavepointStatement:
+		return response.NewSavepointResponse(false, ErrNoTransactions.Error())
+	case *statement.ReleaseSavepointStatement:
+		return response.NewReleaseSavepointResponse(false, ErrNoTransactions.Error())
 	}
 
 	return response.NewErrorResponse("unsupported statement")
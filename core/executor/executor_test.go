@@ -0,0 +1,80 @@
+package executor_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+	"github.com/sirupsen/logrus"
+)
+
+// newExecutor returns an executor on a new catalog holding the database db
+// and its schema public.
+func newExecutor(t *testing.T, config executor.Config) *executor.DefaultExecutor {
+	t.Helper()
+	e, _ := openExecutor(t, t.TempDir(), config)
+	mustExec(t, e, context.Background(), "CREATE DATABASE db")
+	return e
+}
+
+// openExecutor returns an executor on the catalog stored in path, and the
+// catalog, which the test closes on cleanup.
+func openExecutor(t *testing.T, path string, config executor.Config) (*executor.DefaultExecutor, *catalog.Catalog) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cat, err := catalog.OpenCatalog(&catalog.CatalogConfig{Path: path, Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cat.Close() })
+	return executor.NewWithConfig(cat, config), cat
+}
+
+// run executes the SQL text in the database db and returns the response of
+// its last statement, or the failure of the query.
+func run(e *executor.DefaultExecutor, ctx context.Context, sql string) response.Response {
+	resp := e.Execute(ctx, &statement.QueryStatement{Database: "db", Schema: "public", Query: sql})
+	query := resp.(*response.QueryResponse)
+	if !query.Success || len(query.Results) == 0 {
+		return resp
+	}
+	return query.Results[len(query.Results)-1]
+}
+
+func mustExec(t *testing.T, e *executor.DefaultExecutor, ctx context.Context, sql string) response.Response {
+	t.Helper()
+	resp := run(e, ctx, sql)
+	if !resp.IsSuccess() {
+		t.Fatalf("%s: %s", sql, resp.GetMessage())
+	}
+	return resp
+}
+
+// selectRows returns the rows a SELECT returns.
+func selectRows(t *testing.T, e *executor.DefaultExecutor, sql string) []map[string]interface{} {
+	t.Helper()
+	resp, ok := mustExec(t, e, context.Background(), sql).(*response.SelectResponse)
+	if !ok {
+		t.Fatalf("%s: not a select", sql)
+	}
+	return resp.Rows
+}
+
+func TestTransactionsRejected(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	for _, sql := range []string{"BEGIN", "BEGIN t1; COMMIT", "BEGIN; SAVEPOINT s", "BEGIN; RELEASE SAVEPOINT s", "BEGIN; ROLLBACK"} {
+		resp := e.Execute(ctx, &statement.QueryStatement{Database: "db", Schema: "public", Query: sql})
+		if resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		} else if !strings.Contains(resp.GetMessage(), "transactions are not supported") {
+			t.Errorf("%s: %s", sql, resp.GetMessage())
+		}
+	}
+}
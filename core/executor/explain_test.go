@@ -0,0 +1,125 @@
+package executor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+	"github.com/onnasoft/ZenithSQL/io/response"
+)
+
+// explain returns the plan EXPLAIN describes for sql.
+func explain(t *testing.T, e *executor.DefaultExecutor, sql string) string {
+	t.Helper()
+	return mustExec(t, e, context.Background(), "EXPLAIN "+sql).(*response.SelectResponse).Plan.String()
+}
+
+func TestExplain(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10))")
+	mustExec(t, e, ctx, "CREATE TABLE u (a INT32)")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'z')")
+	mustExec(t, e, ctx, "INSERT INTO u (a) VALUES (1), (2)")
+	mustExec(t, e, ctx, "CREATE INDEX t_b ON t (b)")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT a FROM t", `
+Select a
+-> Seq Scan on t (estimated rows=3)`},
+		{"SELECT a FROM t WHERE b = 'y'", `
+Select a
+-> Filter b = 'y' (estimated rows=1)
+  -> ID Scan on t using t_b (hash on b) (estimated rows=1)`},
+		{"SELECT b FROM t WHERE a > 1 ORDER BY b LIMIT 2", `
+Select b
+-> Limit 2
+  -> Sort b (top 2)
+    -> Filter a > 1 (estimated rows=1)
+      -> Seq Scan on t (estimated rows=3)`},
+		{"SELECT b, COUNT(*) AS n FROM t GROUP BY b", `
+Select b, n
+-> Hash Aggregate by b: n
+  -> Seq Scan on t (estimated rows=3)`},
+		{"SELECT t.b FROM t JOIN u ON t.a = u.a", `
+Select t.b
+-> Hash Join INNER on t.a = u.a
+  -> Seq Scan on t (estimated rows=3)
+  -> Seq Scan on u (estimated rows=2)`},
+		{"SELECT b FROM t WHERE EXISTS (SELECT a FROM u WHERE u.a = t.a)", `
+Select b
+-> Hash Semi Join on a = u.a
+  -> Seq Scan on t (estimated rows=3)
+  -> Seq Scan on u (estimated rows=2)`},
+		{"SELECT b FROM t WHERE a IN (SELECT a FROM u)", `
+Select b
+-> Filter a IN (SELECT a FROM db.public.u) (estimated rows=0)
+  -> Seq Scan on t (estimated rows=3)
+-> Subquery
+  -> Select a
+    -> Seq Scan on u (estimated rows=2)`},
+		{"SELECT b FROM t WHERE a IN (SELECT a FROM u WHERE a IN (SELECT a FROM t WHERE b IS NOT NULL)) UNION SELECT b FROM t WHERE NOT EXISTS (SELECT a FROM u)", `
+Select b
+-> Union
+  -> Filter a IN (SELECT a FROM db.public.u WHERE a IN (SELECT a FROM db.public.t WHERE b IS NOT NULL)) (estimated rows=0)
+    -> Seq Scan on t (estimated rows=3)
+  -> Filter NOT EXISTS (SELECT a FROM db.public.u) (estimated rows=3)
+    -> Seq Scan on t (estimated rows=3)
+-> Subquery
+  -> Select a
+    -> Filter a IN (SELECT a FROM db.public.t WHERE b IS NOT NULL) (estimated rows=0)
+      -> Seq Scan on u (estimated rows=2)
+    -> Subquery
+      -> Select a
+        -> Filter b IS NOT NULL (estimated rows=3)
+          -> Seq Scan on t (estimated rows=3)
+-> Subquery
+  -> Select a
+    -> Seq Scan on u (estimated rows=2)`},
+	}
+	for _, tt := range tests {
+		if got := explain(t, e, tt.sql); got != strings.TrimPrefix(tt.want, "\n") {
+			t.Errorf("EXPLAIN %s =\n%s\nwant%s", tt.sql, got, tt.want)
+		}
+	}
+
+	for _, sql := range []string{
+		"EXPLAIN SELECT b FROM t WHERE a IN (SELECT a, a FROM u)",
+		"EXPLAIN SELECT b FROM t WHERE a IN (SELECT a FROM u WHERE u.a = t.a)",
+	} {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+}
+
+// TestExplainDoesNotRunSubqueries checks that EXPLAIN plans the subqueries
+// of conditions without running them, through a subquery that fails when
+// it runs, while EXPLAIN ANALYZE runs it as the query does.
+func TestExplainDoesNotRunSubqueries(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32)")
+	mustExec(t, e, ctx, "INSERT INTO t (a) VALUES (1), (2)")
+
+	for _, sql := range []string{
+		"SELECT a FROM t WHERE a = (SELECT a FROM t)",
+		"SELECT a FROM t WHERE a IN (SELECT a FROM t WHERE a = (SELECT a FROM t))",
+		"SELECT a FROM t WHERE EXISTS (SELECT a FROM t WHERE a > (SELECT a FROM t))",
+		"SELECT a FROM t WHERE a = 1 UNION SELECT a FROM t WHERE a = (SELECT a FROM t)",
+	} {
+		plan := explain(t, e, sql)
+		if !strings.Contains(plan, "-> Subquery") {
+			t.Errorf("EXPLAIN %s does not describe its subquery:\n%s", sql, plan)
+		}
+		for _, query := range []string{sql, "EXPLAIN ANALYZE " + sql} {
+			if resp := run(e, ctx, query); resp.IsSuccess() || !strings.Contains(resp.GetMessage(), "more than one row") {
+				t.Errorf("%s: %s, want the subquery to return more than one row", query, resp.GetMessage())
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestFunctions(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (s STRING(20), n INT32, u UINT64, f FLOAT64, ts TIMESTAMP, tags ARRAY<STRING(5)>)")
+	mustExec(t, e, ctx, `INSERT INTO t (s, n, u, f, ts, tags) VALUES
+		('  Héllo World ', -1234, 18446744073709551615, -2.5, TIMESTAMP '2024-05-17 13:45:30.25', ['a', 'b']),
+		(NULL, NULL, NULL, NULL, NULL, NULL)`)
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT LOWER(s) AS v FROM t WHERE n < 0", "[map[v:  héllo world ]]"},
+		{"SELECT UPPER(TRIM(s)) AS v FROM t WHERE n < 0", "[map[v:HÉLLO WORLD]]"},
+		{"SELECT TRIM('xxhixx', 'x') AS v", "[map[v:hi]]"},
+		{"SELECT LENGTH(s) AS v, LENGTH(tags) AS w FROM t WHERE n < 0", "[map[v:14 w:2]]"},
+		{"SELECT SUBSTR(TRIM(s), 2, 4) AS v FROM t WHERE n < 0", "[map[v:éllo]]"},
+		{"SELECT SUBSTR('abcdef', 4) AS v, SUBSTR('abcdef', 0, 3) AS w, SUBSTR('abc', 5, 2) AS x", "[map[v:def w:ab x:]]"},
+		{"SELECT CONCAT(TRIM(s), '-', n, NULL) AS v FROM t WHERE n < 0", "[map[v:Héllo World--1234]]"},
+		{"SELECT ABS(n) AS a, ABS(f) AS b, ABS(u) AS c FROM t WHERE n < 0", "[map[a:1234 b:2.5 c:18446744073709551615]]"},
+		{"SELECT ROUND(f) AS a, ROUND(n, -2) AS b, ROUND(3.14159, 2) AS c, ROUND(-1250, -2) AS d FROM t WHERE n < 0", "[map[a:-3 b:-1200 c:3.14 d:-1300]]"},
+		{"SELECT FLOOR(f) AS a, FLOOR(n) AS b FROM t WHERE n < 0", "[map[a:-3 b:-1234]]"},
+		{"SELECT DATE_TRUNC('month', ts) AS a, DATE_TRUNC('hour', ts) AS b FROM t WHERE n < 0",
+			"[map[a:2024-05-01 00:00:00 +0000 UTC b:2024-05-17 13:00:00 +0000 UTC]]"},
+		{"SELECT EXTRACT(YEAR FROM ts) AS y, EXTRACT(QUARTER FROM ts) AS q, EXTRACT(DOW FROM ts) AS d, EXTRACT(SECOND FROM ts) AS s FROM t WHERE n < 0",
+			"[map[d:5 q:2 s:30.25 y:2024]]"},
+		{"SELECT COALESCE(s, 'none') AS a, COALESCE(n, 0) AS b, NULLIF(1, 1) AS c, NULLIF(1, 2) AS d FROM t WHERE n IS NULL", "[map[a:none b:0 c:<nil> d:1]]"},
+		// Functions return NULL for NULL arguments, except COALESCE and
+		// CONCAT.
+		{"SELECT LOWER(s) AS a, LENGTH(s) AS b, ABS(n) AS c, ROUND(f) AS d, EXTRACT(YEAR FROM ts) AS e, CONCAT(s, 'x') AS f FROM t WHERE n IS NULL",
+			"[map[a:<nil> b:<nil> c:<nil> d:<nil> e:<nil> f:x]]"},
+		// Functions may be used in conditions and sorted by, and are called
+		// in any case.
+		{"SELECT n FROM t WHERE lower(trim(s)) = 'héllo world'", "[map[n:-1234]]"},
+		{"SELECT n FROM t WHERE EXTRACT(MONTH FROM ts) = 5", "[map[n:-1234]]"},
+		{"SELECT n, COALESCE(n, 0) AS k FROM t ORDER BY k DESC", "[map[k:0 n:<nil>] map[k:-1234 n:-1234]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	for _, sql := range []string{
+		"SELECT UNKNOWN_FN(s) AS v FROM t",
+		"SELECT LOWER(n) AS v FROM t",
+		"SELECT LOWER(s, s) AS v FROM t",
+		"SELECT SUBSTR(s) AS v FROM t",
+		"SELECT SUBSTR(s, 1, -1) AS v FROM t WHERE n < 0",
+		"SELECT ABS(s) AS v FROM t",
+		"SELECT DATE_TRUNC('fortnight', ts) AS v FROM t",
+		"SELECT DATE_TRUNC(s, ts) AS v FROM t",
+		"SELECT EXTRACT(CENTURY FROM ts) AS v FROM t",
+		"SELECT NOW(1) AS v",
+	} {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+
+	before := time.Now()
+	rows := selectRows(t, e, "SELECT NOW() AS v")
+	if now, ok := rows[0]["v"].(time.Time); !ok || now.Before(before) || now.After(time.Now()) {
+		t.Errorf("NOW() = %v, want a time after %v", rows[0]["v"], before)
+	}
+}
@@ -0,0 +1,82 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+	"github.com/onnasoft/ZenithSQL/io/response"
+)
+
+// TestGeoFilters checks the geo filters on a scan and on a grid index,
+// across the antimeridian too.
+func TestGeoFilters(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (name STRING(10), p GEOPOINT)")
+	mustExec(t, e, ctx, `INSERT INTO t (name, p) VALUES
+		('paris', [48.8566, 2.3522]),
+		('london', [51.5074, -0.1278]),
+		('new york', [40.7128, -74.006]),
+		('suva', [-18.1416, 178.4419]),
+		('taveuni', [-16.85, -179.95]),
+		('nowhere', NULL)`)
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT name FROM t WHERE p WITHIN BOX (48, -1, 52, 3) ORDER BY name", "[map[name:london] map[name:paris]]"},
+		{"SELECT name FROM t WHERE p WITHIN BOX ([48, -1], [52, 3]) ORDER BY name", "[map[name:london] map[name:paris]]"},
+		{"SELECT name FROM t WHERE p WITHIN BOX (-20, 178, -15, -179) ORDER BY name", "[map[name:suva] map[name:taveuni]]"},
+		{"SELECT name FROM t WHERE p WITHIN BOX (0, 0, 1, 1)", "[]"},
+		{"SELECT name FROM t WHERE p WITHIN DISTANCE ([48.8566, 2.3522], 400000) ORDER BY name", "[map[name:london] map[name:paris]]"},
+		{"SELECT name FROM t WHERE p WITHIN DISTANCE ([48.8566, 2.3522], 300000)", "[map[name:paris]]"},
+		{"SELECT name FROM t WHERE p WITHIN DISTANCE ([-17.5, 179.9], 200000) ORDER BY name", "[map[name:suva] map[name:taveuni]]"},
+		{"SELECT name FROM t WHERE p = [48.8566, 2.3522]", "[map[name:paris]]"},
+		{"SELECT name FROM t WHERE p IS NULL", "[map[name:nowhere]]"},
+	}
+	check := func(index string) {
+		for _, tt := range tests {
+			if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+				t.Errorf("%s: %s = %s, want %s", index, tt.sql, got, tt.want)
+			}
+		}
+	}
+	check("scan")
+	mustExec(t, e, ctx, "CREATE INDEX t_p ON t (p) USING grid WITH (cell_degrees = 1)")
+	check("grid")
+
+	plan := mustExec(t, e, ctx, "EXPLAIN SELECT name FROM t WHERE p WITHIN BOX (48, -1, 52, 3)").(*response.SelectResponse).Plan.String()
+	if !strings.Contains(plan, "t_p") {
+		t.Errorf("plan does not use the grid index:\n%s", plan)
+	}
+
+	// The index follows the rows written after it.
+	mustExec(t, e, ctx, "INSERT INTO t (name, p) VALUES ('lille', [50.6292, 3.0573])")
+	mustExec(t, e, ctx, "UPDATE t SET p = [0.5, 0.5] WHERE name = 'london'")
+	mustExec(t, e, ctx, "DELETE FROM t WHERE name = 'paris'")
+	for sql, want := range map[string]string{
+		"SELECT name FROM t WHERE p WITHIN BOX (48, -1, 52, 4)": "[map[name:lille]]",
+		"SELECT name FROM t WHERE p WITHIN BOX (0, 0, 1, 1)":    "[map[name:london]]",
+	} {
+		if got := fmt.Sprint(selectRows(t, e, sql)); got != want {
+			t.Errorf("%s = %s, want %s", sql, got, want)
+		}
+	}
+
+	for _, sql := range []string{
+		"SELECT name FROM t WHERE p WITHIN BOX (52, -1, 48, 3)",
+		"SELECT name FROM t WHERE p WITHIN BOX (48, -1, 52)",
+		"SELECT name FROM t WHERE p WITHIN DISTANCE ([48.8566, 2.3522], -1)",
+		"SELECT name FROM t WHERE p WITHIN DISTANCE ([91, 0], 10)",
+		"CREATE INDEX t_q ON t (p) USING grid WITH (cell_degrees = 100)",
+		"INSERT INTO t (name, p) VALUES ('bad', [91, 0])",
+	} {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+}
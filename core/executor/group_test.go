@@ -0,0 +1,55 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestHaving(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (g STRING(10), x INT32)")
+	mustExec(t, e, ctx, `INSERT INTO t (g, x) VALUES
+		('a', 1), ('a', 2), ('a', 3),
+		('b', 10), ('b', NULL),
+		('c', 5),
+		(NULL, 7)`)
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT g, COUNT(*) AS n FROM t GROUP BY g HAVING COUNT(*) > 1 ORDER BY g", "[map[g:a n:3] map[g:b n:2]]"},
+		// HAVING refers to the aggregates of the select list by their alias.
+		{"SELECT g, COUNT(*) AS n FROM t GROUP BY g HAVING n > 1 ORDER BY g", "[map[g:a n:3] map[g:b n:2]]"},
+		{"SELECT g, SUM(x) AS s FROM t GROUP BY g HAVING s >= 6 ORDER BY s", "[map[g:a s:6] map[g:<nil> s:7] map[g:b s:10]]"},
+		// Aggregates HAVING computes are not returned.
+		{"SELECT g FROM t GROUP BY g HAVING MAX(x) < 5 ORDER BY g", "[map[g:a]]"},
+		{"SELECT g FROM t GROUP BY g HAVING COUNT(x) = 1 AND MIN(x) > 4 ORDER BY g", "[map[g:b] map[g:c] map[g:<nil>]]"},
+		{"SELECT g FROM t GROUP BY g HAVING COUNT(x) < COUNT(*)", "[map[g:b]]"},
+		{"SELECT g, AVG(x) AS m FROM t GROUP BY g HAVING m > 2 OR g = 'a' ORDER BY g", "[map[g:a m:2] map[g:b m:10] map[g:c m:5] map[g:<nil> m:7]]"},
+		{"SELECT g FROM t GROUP BY g HAVING g IS NULL", "[map[g:<nil>]]"},
+		{"SELECT g, COUNT(*) AS n FROM t GROUP BY g HAVING n > 5", "[]"},
+		// Without GROUP BY, the whole table is the group.
+		{"SELECT COUNT(*) AS n FROM t HAVING n > 5", "[map[n:7]]"},
+		{"SELECT COUNT(*) AS n FROM t HAVING n > 7", "[]"},
+		{"SELECT g, COUNT(*) AS n FROM t WHERE x > 1 GROUP BY g HAVING n > 1", "[map[g:a n:2]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	for _, sql := range []string{
+		"SELECT g FROM t GROUP BY g HAVING x > 1",
+		"SELECT g FROM t GROUP BY g HAVING unknown > 1",
+	} {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+}
@@ -19,7 +19,26 @@ func (e *DefaultExecutor) executeImport(ctx context.Context, stmt *statement.Imp
 	table.LockInsert()
 	defer table.UnlockInsert()
 
-	writer, _, err := insert(ctx, table, stmt.Values...)
+	if stmt.OnConflict != nil {
+		results, err := e.upsert(ctx, table, stmt.Database, stmt.Schema, stmt.Values, *stmt.OnConflict)
+		if err != nil {
+			return response.NewImportResponse(false, err.Error(), 0, time.Since(startTime).Milliseconds())
+		}
+		var inserted, updated int64
+		for _, result := range results {
+			switch result.Action {
+			case response.Inserted:
+				inserted++
+			case response.Updated:
+				updated++
+			}
+		}
+		res := response.NewImportResponse(true, "imported successfully", inserted, time.Since(startTime).Milliseconds())
+		res.RowsUpdated = updated
+		return res
+	}
+
+	writer, ids, rows, err := insert(ctx, table, stmt.Values...)
 	if err != nil {
 		return response.NewImportResponse(false, err.Error(), 0, time.Since(startTime).Milliseconds())
 	}
@@ -35,6 +54,10 @@ func (e *DefaultExecutor) executeImport(ctx context.Context, stmt *statement.Imp
 		return response.NewImportResponse(false, err.Error(), 0, time.Since(startTime).Milliseconds())
 	}
 
+	if err := indexRows(table, ids, rows); err != nil {
+		return response.NewImportResponse(false, err.Error(), int64(len(stmt.Values)), time.Since(startTime).Milliseconds())
+	}
+
 	return response.NewImportResponse(
 		true,
 		"imported successfully",
@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
 	"github.com/onnasoft/ZenithSQL/io/response"
 	"github.com/onnasoft/ZenithSQL/io/statement"
 	"github.com/onnasoft/ZenithSQL/model/catalog"
+	"github.com/onnasoft/ZenithSQL/model/fields"
 )
 
 func (e *DefaultExecutor) executeInsert(ctx context.Context, stmt *statement.InsertStatement) response.Response {
@@ -20,7 +22,7 @@ func (e *DefaultExecutor) executeInsert(ctx context.Context, stmt *statement.Ins
 	table.LockInsert()
 	defer table.UnlockInsert()
 
-	writer, ids, err := insert(ctx, table, stmt.Values...)
+	writer, ids, rows, err := insert(ctx, table, stmt.Values...)
 	if err != nil {
 		return response.NewInsertResponse(false, err.Error(), nil, 0, time.Since(startTime).Milliseconds())
 	}
@@ -36,6 +38,10 @@ func (e *DefaultExecutor) executeInsert(ctx context.Context, stmt *statement.Ins
 		return response.NewInsertResponse(false, err.Error(), nil, 0, time.Since(startTime).Milliseconds())
 	}
 
+	if err := indexRows(table, ids, rows); err != nil {
+		return response.NewInsertResponse(false, err.Error(), ids, int64(len(stmt.Values)), time.Since(startTime).Milliseconds())
+	}
+
 	return response.NewInsertResponse(
 		true,
 		"inserted successfully",
@@ -45,34 +51,89 @@ func (e *DefaultExecutor) executeInsert(ctx context.Context, stmt *statement.Ins
 	)
 }
 
-func insert(ctx context.Context, table *catalog.Table, values ...map[string]interface{}) (storage.Writer, []interface{}, error) {
+// insert writes values as new rows, returning their ids and the rows as
+// written, which the indexes take once the writer commits. Nothing is
+// written when a row holds a value a unique index already has.
+func insert(ctx context.Context, table *catalog.Table, values ...map[string]interface{}) (storage.Writer, []interface{}, []map[string]interface{}, error) {
 	now := time.Now()
 
+	ids := make([]interface{}, 0, len(values))
+	rows := make([]map[string]interface{}, 0, len(values))
+	id := table.GetNextID()
+	for _, given := range values {
+		ids = append(ids, id)
+		rows = append(rows, newRow(table, given, id, now))
+		id++
+	}
+	if err := table.CheckUnique(rows); err != nil {
+		return nil, nil, nil, err
+	}
+
 	writer, err := table.Writer()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	ids := make([]interface{}, 0, len(values))
-	id := table.GetNextID()
-	for _, row := range values {
+	for _, row := range rows {
 		select {
 		case <-ctx.Done():
-			return nil, nil, ctx.Err()
+			return nil, nil, nil, ctx.Err()
 		default:
 		}
 
-		row["deleted_at"] = nil
-		row["created_at"] = now
-		row["updated_at"] = now
-		row["id"] = id
-		ids = append(ids, id)
-
 		if err := writer.Write(row); err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
+		}
+	}
+
+	return writer, ids, rows, nil
+}
+
+// newRow returns the row values is inserted as, with id at now: its values
+// resolved and coerced, and its system columns set.
+func newRow(table *catalog.Table, values map[string]interface{}, id int64, now time.Time) map[string]interface{} {
+	row := make(map[string]interface{}, len(values)+4)
+	for column, value := range values {
+		row[column] = resolveValue(cloneValue(value), now)
+	}
+	coerceRow(table, row)
+	row["deleted_at"] = nil
+	row["created_at"] = now
+	row["updated_at"] = now
+	row["id"] = id
+	return row
+}
+
+// coerceRow narrows the values of row to the types of their columns, as
+// the writer stores them, so that the indexes get the values the rows
+// hold. Unknown columns are left for the writer to reject.
+func coerceRow(table *catalog.Table, row map[string]interface{}) {
+	for column, value := range row {
+		if meta, err := table.GetFieldMeta(column); err == nil {
+			row[column] = fields.Coerce(meta.DataType(), value)
 		}
+	}
+}
 
-		id++
+// resolveValue turns time expressions such as now() - interval '1 day' into
+// the instant they denote at now, so that every row of a statement stores
+// the same time.
+func resolveValue(value interface{}, now time.Time) interface{} {
+	switch v := value.(type) {
+	case filters.TimeExpr:
+		return v.Resolve(now)
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = resolveValue(elem, now)
+		}
 	}
+	return value
+}
 
-	return writer, ids, nil
+func indexRows(table *catalog.Table, ids []interface{}, values []map[string]interface{}) error {
+	for i, row := range values {
+		if err := table.IndexRow(ids[i].(int64), row); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,32 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestInsertNulls(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (k INT32 NOT NULL, a INT32, f FLOAT64, s STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (k, a, f, s) VALUES (1, NULL, NULL, NULL)")
+	mustExec(t, e, ctx, "INSERT INTO t (k) VALUES (2)")
+
+	rejected := []string{
+		"INSERT INTO t (k, a) VALUES (NULL, 1)",
+		"INSERT INTO t (a) VALUES (1)",
+	}
+	for _, sql := range rejected {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+
+	want := "[map[a:<nil> f:<nil> k:1 s:<nil>] map[a:<nil> f:<nil> k:2 s:<nil>]]"
+	if got := fmt.Sprint(selectRows(t, e, "SELECT k, a, f, s FROM t ORDER BY k")); got != want {
+		t.Errorf("rows = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,499 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// joinScope holds the tables of a select with joins, in the order they are
+// joined, and resolves the column names of the statement to the qualified
+// names of the joined rows.
+type joinScope struct {
+	tables []scopeTable
+	// aliases are the names of the aggregations and computed columns, which
+	// ORDER BY and HAVING may use and are not columns of any table.
+	aliases map[string]bool
+}
+
+type scopeTable struct {
+	name    string
+	rel     relation
+	columns []string
+	// optional tells that the table is on the side of an outer join that
+	// is filled with nulls when it has no match.
+	optional bool
+}
+
+func (e *DefaultExecutor) newJoinScope(ctx context.Context, stmt *statement.SelectStatement, rel relation, ctes *cteScope) (*joinScope, error) {
+	s := &joinScope{aliases: make(map[string]bool)}
+	for _, agg := range stmt.Aggregations {
+		s.aliases[agg.Name()] = true
+	}
+	for _, projection := range stmt.Projections {
+		s.aliases[projection.Name()] = true
+	}
+	for _, w := range stmt.Windows {
+		s.aliases[w.Name()] = true
+	}
+	if err := s.add(stmt.TableAlias(), rel); err != nil {
+		return nil, err
+	}
+	for _, join := range stmt.Joins {
+		rel, err := e.relation(ctx, ctes, join.Database, join.Schema, join.TableName, join.IncludeDeleted)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.add(join.Name(), rel); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, join := range stmt.Joins {
+		switch join.Type {
+		case statement.LeftJoin:
+			s.tables[i+1].optional = true
+		case statement.RightJoin:
+			for j := 0; j <= i; j++ {
+				s.tables[j].optional = true
+			}
+		case statement.FullJoin:
+			for j := 0; j <= i+1; j++ {
+				s.tables[j].optional = true
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *joinScope) add(name string, rel relation) error {
+	columns, err := rel.columns()
+	if err != nil {
+		return err
+	}
+	s.tables = append(s.tables, scopeTable{name: name, rel: rel, columns: columns})
+	return nil
+}
+
+// tableIndex returns the position of the table named by a qualified
+// column, or -1.
+func (s *joinScope) tableIndex(column string) int {
+	name, _, ok := strings.Cut(column, ".")
+	if !ok {
+		return -1
+	}
+	return slices.IndexFunc(s.tables, func(t scopeTable) bool { return t.name == name })
+}
+
+// resolveColumn qualifies a bare column with the only table having it.
+// Qualified columns are checked, and the names of aggregations are left
+// alone.
+func (s *joinScope) resolveColumn(name string) (string, error) {
+	if table, column, ok := strings.Cut(name, "."); ok {
+		i := s.tableIndex(name)
+		if i < 0 {
+			return "", fmt.Errorf("unknown table %s in column %s", table, name)
+		}
+		if !slices.Contains(s.tables[i].columns, column) {
+			return "", fmt.Errorf("column %s not found", name)
+		}
+		return name, nil
+	}
+	if s.aliases[name] {
+		return name, nil
+	}
+
+	var resolved string
+	for _, t := range s.tables {
+		if !slices.Contains(t.columns, name) {
+			continue
+		}
+		if resolved != "" {
+			return "", fmt.Errorf("column %s is ambiguous", name)
+		}
+		resolved = t.name + "." + name
+	}
+	if resolved == "" {
+		return "", fmt.Errorf("column %s not found", name)
+	}
+	return resolved, nil
+}
+
+// owns reports whether column names a column of a table of the scope.
+func (s *joinScope) owns(column string) bool {
+	if _, name, ok := strings.Cut(column, "."); ok {
+		i := s.tableIndex(column)
+		return i >= 0 && slices.Contains(s.tables[i].columns, name)
+	}
+	return slices.ContainsFunc(s.tables, func(t scopeTable) bool { return slices.Contains(t.columns, column) })
+}
+
+func (s *joinScope) resolveColumns(columns []string) ([]string, error) {
+	resolved := make([]string, len(columns))
+	for i, column := range columns {
+		var err error
+		if resolved[i], err = s.resolveColumn(column); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// resolveStatement returns a copy of stmt naming every column by its
+// qualified name, with * and table.* expanded and the ON conditions of the
+// joins turned so that Left is a column of a table joined before and Right
+// one of the joined table. Aggregations, computed columns and window
+// functions keep the names they were written with.
+func (s *joinScope) resolveStatement(stmt *statement.SelectStatement) (*statement.SelectStatement, error) {
+	resolved := *stmt
+	var err error
+
+	resolved.Columns = nil
+	for _, column := range stmt.Columns {
+		switch {
+		case column == "*":
+			for _, t := range s.tables {
+				for _, name := range t.columns {
+					resolved.Columns = append(resolved.Columns, t.name+"."+name)
+				}
+			}
+		case strings.HasSuffix(column, ".*"):
+			i := s.tableIndex(column)
+			if i < 0 {
+				return nil, fmt.Errorf("unknown table %s", strings.TrimSuffix(column, ".*"))
+			}
+			for _, name := range s.tables[i].columns {
+				resolved.Columns = append(resolved.Columns, s.tables[i].name+"."+name)
+			}
+		default:
+			name, err := s.resolveColumn(column)
+			if err != nil {
+				return nil, err
+			}
+			resolved.Columns = append(resolved.Columns, name)
+		}
+	}
+
+	if resolved.GroupBy, err = s.resolveColumns(stmt.GroupBy); err != nil {
+		return nil, err
+	}
+	if resolved.Unnest, err = s.resolveColumns(stmt.Unnest); err != nil {
+		return nil, err
+	}
+
+	resolved.Aggregations = make([]statement.Aggregation, len(stmt.Aggregations))
+	for i, agg := range stmt.Aggregations {
+		agg.Alias = agg.Name()
+		if agg.Column != "*" {
+			if agg.Column, err = s.resolveColumn(agg.Column); err != nil {
+				return nil, err
+			}
+		}
+		resolved.Aggregations[i] = agg
+	}
+
+	resolved.Projections = make([]statement.Projection, len(stmt.Projections))
+	for i, projection := range stmt.Projections {
+		projection.Alias = projection.Name()
+		if projection.Expression, err = projection.Expression.Rename(s.resolveHavingField); err != nil {
+			return nil, err
+		}
+		resolved.Projections[i] = projection
+	}
+
+	resolved.Windows = make([]statement.WindowFunction, len(stmt.Windows))
+	for i, w := range stmt.Windows {
+		if resolved.Windows[i], err = s.resolveWindow(w); err != nil {
+			return nil, err
+		}
+	}
+
+	if resolved.OrderBy, err = s.resolveOrderBy(stmt.OrderBy, s.resolveColumn); err != nil {
+		return nil, err
+	}
+
+	if stmt.Where != nil {
+		if resolved.Where, err = cloneFilter(stmt.Where, s.resolveColumn); err != nil {
+			return nil, err
+		}
+	}
+	if stmt.Having != nil {
+		if resolved.Having, err = cloneFilter(stmt.Having, s.resolveHavingField); err != nil {
+			return nil, err
+		}
+	}
+
+	resolved.Joins = make([]statement.Join, len(stmt.Joins))
+	for i, join := range stmt.Joins {
+		on := make([]statement.JoinCondition, len(join.On))
+		for j, cond := range join.On {
+			if on[j], err = s.resolveCondition(i+1, cond); err != nil {
+				return nil, err
+			}
+		}
+		join.On = on
+		resolved.Joins[i] = join
+	}
+	return &resolved, nil
+}
+
+// resolveWindow resolves the columns a window function and its window
+// read, which like computed columns may be aggregate calls.
+func (s *joinScope) resolveWindow(w statement.WindowFunction) (statement.WindowFunction, error) {
+	var err error
+	w.Alias = w.Name()
+	if w.Column != "" && w.Column != "*" {
+		if w.Column, err = s.resolveHavingField(w.Column); err != nil {
+			return w, err
+		}
+	}
+	partitionBy := make([]string, len(w.Over.PartitionBy))
+	for i, column := range w.Over.PartitionBy {
+		if partitionBy[i], err = s.resolveHavingField(column); err != nil {
+			return w, err
+		}
+	}
+	w.Over.PartitionBy = partitionBy
+	w.Over.OrderBy, err = s.resolveOrderBy(w.Over.OrderBy, s.resolveHavingField)
+	return w, err
+}
+
+// resolveOrderBy resolves the column of every ORDER BY key with resolve.
+func (s *joinScope) resolveOrderBy(keys []string, resolve func(string) (string, error)) ([]string, error) {
+	resolved := make([]string, len(keys))
+	for i, key := range keys {
+		column, rest, _ := strings.Cut(key, " ")
+		column, err := resolve(column)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = strings.TrimSpace(column + " " + rest)
+	}
+	return resolved, nil
+}
+
+// resolveHavingField resolves the columns HAVING and computed columns use,
+// and the column of the aggregate calls they make without selecting them.
+func (s *joinScope) resolveHavingField(field string) (string, error) {
+	if s.aliases[field] {
+		return field, nil
+	}
+	if agg, ok := statement.ParseAggregation(field); ok {
+		if agg.Column != "*" {
+			column, err := s.resolveColumn(agg.Column)
+			if err != nil {
+				return "", err
+			}
+			agg.Column = column
+		}
+		return agg.Name(), nil
+	}
+	return s.resolveColumn(field)
+}
+
+// resolveCondition resolves an ON condition of the table at position k.
+func (s *joinScope) resolveCondition(k int, cond statement.JoinCondition) (statement.JoinCondition, error) {
+	left, err := s.resolveColumn(cond.Left)
+	if err != nil {
+		return cond, err
+	}
+	right, err := s.resolveColumn(cond.Right)
+	if err != nil {
+		return cond, err
+	}
+	l, r := s.tableIndex(left), s.tableIndex(right)
+	if l == k {
+		left, right, l, r = right, left, r, l
+	}
+	if r != k || l < 0 || l >= k {
+		return cond, fmt.Errorf("join condition %s = %s must compare a column of %s with a column of a table before it",
+			cond.Left, cond.Right, s.tables[k].name)
+	}
+	return statement.JoinCondition{Left: left, Right: right}, nil
+}
+
+// splitWhere hands each table the conjuncts of WHERE that only use its
+// columns, unqualified so that its plan can use its indexes, and returns
+// the others to filter the joined rows with. Conjuncts on an optional table
+// are not handed to it, since they must also reject the rows the join
+// fills with nulls.
+func (s *joinScope) splitWhere(where *filters.Filter) ([]*filters.Filter, *filters.Filter) {
+	pushed := make([][]*filters.Filter, len(s.tables))
+	if where == nil {
+		return make([]*filters.Filter, len(s.tables)), nil
+	}
+
+	var residual []*filters.Filter
+	for _, f := range conjuncts(where) {
+		i := s.filterTable(f)
+		if i < 0 || s.tables[i].optional {
+			residual = append(residual, f)
+			continue
+		}
+		prefix := s.tables[i].name + "."
+		unqualified, _ := cloneFilter(f, func(field string) (string, error) {
+			return strings.TrimPrefix(field, prefix), nil
+		})
+		pushed[i] = append(pushed[i], unqualified)
+	}
+
+	result := make([]*filters.Filter, len(s.tables))
+	for i := range pushed {
+		result[i] = conjunction(pushed[i])
+	}
+	return result, conjunction(residual)
+}
+
+// filterTable returns the position of the only table a filter uses, or -1.
+func (s *joinScope) filterTable(f *filters.Filter) int {
+	table := -1
+	mixed := false
+	f.Walk(func(f *filters.Filter) {
+		if len(f.Children) > 0 {
+			return
+		}
+		columns := []string{f.Field}
+		if f.Expression != nil {
+			columns = f.Expression.Columns()
+		}
+		for _, column := range columns {
+			i := s.tableIndex(column)
+			if i < 0 || (table >= 0 && table != i) {
+				mixed = true
+			}
+			table = i
+		}
+	})
+	if mixed {
+		return -1
+	}
+	return table
+}
+
+// openJoin plans every table of the scope on its own and joins their
+// cursors in order, each with the rows joined so far.
+func (e *DefaultExecutor) openJoin(s *joinScope, stmt *statement.SelectStatement) (storage.Cursor, []storage.QueryPlan, error) {
+	pushed, residual := s.splitWhere(stmt.Where)
+
+	var cursor storage.Cursor
+	plans := make([]storage.QueryPlan, len(s.tables))
+	for i, t := range s.tables {
+		var input storage.Cursor
+		plan, err := t.rel.plan(storage.Query{Filter: pushed[i], Fields: t.columns})
+		if err == nil {
+			plans[i] = plan
+		}
+		if err == nil {
+			input, err = plans[i].Execute()
+		}
+		if err != nil {
+			if cursor != nil {
+				cursor.Close()
+			}
+			return nil, nil, err
+		}
+		if i == 0 {
+			cursor = input
+			continue
+		}
+
+		join := stmt.Joins[i-1]
+		config := storage.JoinConfig{
+			Type:        join.Type,
+			Strategy:    join.Strategy,
+			RightAlias:  t.name,
+			MemoryLimit: e.config.JoinMemoryLimit,
+			TempDir:     e.config.TempDir,
+		}
+		if i == 1 {
+			config.LeftAlias = s.tables[0].name
+		}
+		for _, cond := range join.On {
+			config.Keys = append(config.Keys, storage.JoinKey{Left: cond.Left, Right: cond.Right})
+		}
+		joined, err := cursor.WithJoin(input, config)
+		if err != nil {
+			cursor.Close()
+			input.Close()
+			return nil, nil, err
+		}
+		cursor = joined
+	}
+
+	if residual != nil {
+		filtered, err := cursor.WithFilter(residual)
+		if err != nil {
+			cursor.Close()
+			return nil, nil, fmt.Errorf("invalid WHERE: %w", err)
+		}
+		cursor = filtered
+	}
+	return cursor, plans, nil
+}
+
+// annotatePlan adds the estimates of the plans of the joined tables to the
+// plan tree of the cursor openJoin returned. The last plan describes the
+// right input of the outermost join, and the others its left input.
+func annotatePlan(node *storage.PlanNode, plans []storage.QueryPlan) {
+	if len(plans) == 1 {
+		plans[0].Annotate(node)
+		return
+	}
+	var join *storage.PlanNode
+	node.Walk(func(n *storage.PlanNode) {
+		if join == nil && (n.Operation == "Hash Join" || n.Operation == "Merge Join") {
+			join = n
+		}
+	})
+	if join == nil {
+		return
+	}
+	plans[len(plans)-1].Annotate(join.Children[1])
+	annotatePlan(join.Children[0], plans[:len(plans)-1])
+}
+
+// cloneFilter copies a filter tree, renaming the fields of its conditions.
+func cloneFilter(f *filters.Filter, rename func(string) (string, error)) (*filters.Filter, error) {
+	if f.Expression != nil {
+		e, err := f.Expression.Rename(rename)
+		if err != nil {
+			return nil, err
+		}
+		return filters.NewExpressionCondition(e), nil
+	}
+	if len(f.Children) == 0 {
+		field, err := rename(f.Field)
+		if err != nil {
+			return nil, err
+		}
+		return filters.NewCondition(field, f.Operator, f.Value), nil
+	}
+	group := filters.NewGroup(f.JoinWith)
+	for _, child := range f.Children {
+		clone, err := cloneFilter(child, rename)
+		if err != nil {
+			return nil, err
+		}
+		group.Add(clone)
+	}
+	return group, nil
+}
+
+func conjunction(conjuncts []*filters.Filter) *filters.Filter {
+	switch len(conjuncts) {
+	case 0:
+		return nil
+	case 1:
+		return conjuncts[0]
+	}
+	group := filters.NewGroup(filters.And)
+	for _, f := range conjuncts {
+		group.Add(f)
+	}
+	return group
+}
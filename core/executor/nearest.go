@@ -0,0 +1,242 @@
+package executor
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// approximateOverfetch is how many candidates per requested row are pulled
+// from a vector index when a WHERE clause may discard some of them, and how
+// many times more each further search pulls when too few of them match.
+const approximateOverfetch = 4
+
+func (e *DefaultExecutor) executeNearest(ctx context.Context, stmt *statement.SelectStatement, table *catalog.Table) response.Response {
+	neighbors, err := findNeighbors(ctx, stmt, table)
+	if err != nil {
+		return response.NewSelectResponse(false, err.Error(), nil)
+	}
+
+	ids := make([]int64, len(neighbors))
+	distances := make(map[int64]float32, len(neighbors))
+	for i, n := range neighbors {
+		ids[i] = n.ID
+		distances[n.ID] = n.Distance
+	}
+
+	columns, err := selectColumns(tableRelation{table: table}, stmt.Columns)
+	if err != nil {
+		return response.NewSelectResponse(false, err.Error(), nil)
+	}
+
+	cursor, err := tableCursor(table, stmt)
+	if err != nil {
+		return response.NewSelectResponse(false, err.Error(), nil)
+	}
+	defer cursor.Close()
+
+	if cursor, err = cursor.WithIDs(ids); err != nil {
+		return response.NewSelectResponse(false, err.Error(), nil)
+	}
+
+	if stmt.Offset > 0 {
+		if cursor, err = cursor.WithSkip(int64(stmt.Offset)); err != nil {
+			return response.NewSelectResponse(false, err.Error(), nil)
+		}
+	}
+
+	if stmt.Limit != nil {
+		if cursor, err = cursor.WithLimit(int64(*stmt.Limit)); err != nil {
+			return response.NewSelectResponse(false, err.Error(), nil)
+		}
+	}
+
+	rows := []map[string]interface{}{}
+	for cursor.Next() {
+		select {
+		case <-ctx.Done():
+			return response.NewSelectResponse(false, "context done", nil)
+		default:
+		}
+
+		record := make(map[string]interface{})
+		for _, column := range columns {
+			value, err := cursor.ScanField(column)
+			if err != nil {
+				return response.NewSelectResponse(false, err.Error(), nil)
+			}
+			record[column] = value
+		}
+		if stmt.Nearest.Alias != "" {
+			record[stmt.Nearest.Alias] = distances[cursor.Reader().CurrentID()]
+		}
+
+		rows = append(rows, record)
+	}
+
+	return response.NewSelectResponse(true, "Select executed successfully", rows)
+}
+
+// findNeighbors returns the K rows closest to the query vector, closest
+// first. Approximate queries use a vector index on the column when there is
+// one and fall back to an exact scan otherwise, as do those reading deleted
+// rows, which indexes do not hold. With a WHERE clause the index is searched
+// for more candidates until K of them match; when the index runs out of
+// candidates first, the rows matching are found by an exact scan.
+func findNeighbors(ctx context.Context, stmt *statement.SelectStatement, table *catalog.Table) ([]storage.Neighbor, error) {
+	nearest := stmt.Nearest
+	k := int(nearest.K)
+
+	if nearest.Approximate && !stmt.IncludeDeleted {
+		for _, idx := range table.Indexes(nearest.Column) {
+			vectorIndex, ok := idx.Index.(storage.VectorIndex)
+			if !ok {
+				continue
+			}
+			if stmt.Where == nil {
+				return vectorIndex.Search(nearest.Vector, k)
+			}
+			for fetch := k * approximateOverfetch; ; fetch *= approximateOverfetch {
+				candidates, err := vectorIndex.Search(nearest.Vector, fetch)
+				if err != nil {
+					return nil, err
+				}
+				neighbors, err := filterNeighbors(table, stmt, candidates, k)
+				if err != nil || len(neighbors) == k {
+					return neighbors, err
+				}
+				if len(candidates) < fetch {
+					break
+				}
+			}
+			break
+		}
+	}
+
+	return scanNeighbors(ctx, stmt, table, k)
+}
+
+// filterNeighbors keeps the candidates matching the WHERE clause, in order.
+func filterNeighbors(table *catalog.Table, stmt *statement.SelectStatement, candidates []storage.Neighbor, k int) ([]storage.Neighbor, error) {
+	ids := make([]int64, len(candidates))
+	byID := make(map[int64]storage.Neighbor, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.ID
+		byID[c.ID] = c
+	}
+
+	cursor, err := tableCursor(table, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	if cursor, err = cursor.WithIDs(ids); err != nil {
+		return nil, err
+	}
+	if cursor, err = cursor.WithFilter(stmt.Where); err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.Neighbor, 0, k)
+	for len(result) < k && cursor.Next() {
+		result = append(result, byID[cursor.Reader().CurrentID()])
+	}
+	return result, nil
+}
+
+// scanNeighbors computes the exact top-K with a single pass over the
+// column, reading vectors in place from the mapped file.
+func scanNeighbors(ctx context.Context, stmt *statement.SelectStatement, table *catalog.Table, k int) ([]storage.Neighbor, error) {
+	nearest := stmt.Nearest
+
+	cursor, err := tableCursor(table, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	if stmt.Where != nil {
+		if cursor, err = cursor.WithFilter(stmt.Where); err != nil {
+			return nil, err
+		}
+	}
+
+	scanner, ok := cursor.ScanMap()[nearest.Column]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found", nearest.Column)
+	}
+	vectorType, ok := scanner.Type.(fields.VectorType)
+	if !ok {
+		return nil, fmt.Errorf("column %s is not a vector", nearest.Column)
+	}
+	if len(nearest.Vector) != vectorType.Dimensions {
+		return nil, fmt.Errorf("query vector has %d dimensions, column %s has %d", len(nearest.Vector), nearest.Column, vectorType.Dimensions)
+	}
+	distance, err := nearest.Metric.DistanceFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	top := &neighborHeap{}
+	var vector []float32
+	for cursor.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		ok, err := scanner.Scan(&vector)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		d := distance(nearest.Vector, vector)
+		if top.Len() < k {
+			heap.Push(top, storage.Neighbor{ID: cursor.Reader().CurrentID(), Distance: d})
+		} else if d < (*top)[0].Distance {
+			(*top)[0] = storage.Neighbor{ID: cursor.Reader().CurrentID(), Distance: d}
+			heap.Fix(top, 0)
+		}
+	}
+
+	result := []storage.Neighbor(*top)
+	sort.Slice(result, func(i, j int) bool { return result[i].Distance < result[j].Distance })
+	return result, nil
+}
+
+// neighborHeap is a max-heap on distance holding the current top-K.
+type neighborHeap []storage.Neighbor
+
+func (h neighborHeap) Len() int           { return len(h) }
+func (h neighborHeap) Less(i, j int) bool { return h[i].Distance > h[j].Distance }
+func (h neighborHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *neighborHeap) Push(x any) { *h = append(*h, x.(storage.Neighbor)) }
+
+func (h *neighborHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// tableCursor reads the rows of table, and its deleted rows when stmt
+// includes them.
+func tableCursor(table *catalog.Table, stmt *statement.SelectStatement) (storage.Cursor, error) {
+	if stmt.IncludeDeleted {
+		return table.CursorWithDeleted()
+	}
+	return table.Cursor()
+}
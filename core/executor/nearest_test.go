@@ -0,0 +1,57 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// TestNearestFiltered checks that an approximate search whose WHERE clause
+// matches none of the closest rows still returns the K closest rows that
+// match, as the exact search does.
+func TestNearestFiltered(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (i INT32, tag STRING(1), v VECTOR(2))")
+	var values []string
+	for i := range 400 {
+		tag := "x"
+		if i >= 390 {
+			tag = "y"
+		}
+		values = append(values, fmt.Sprintf("(%d, '%s', [%d, 0])", i, tag, i))
+	}
+	mustExec(t, e, ctx, "INSERT INTO t (i, tag, v) VALUES "+strings.Join(values, ", "))
+	mustExec(t, e, ctx, "CREATE INDEX t_v ON t (v) USING hnsw WITH (metric = 'l2', seed = 1)")
+
+	for _, approximate := range []bool{false, true} {
+		resp := e.Execute(ctx, &statement.SelectStatement{
+			Database:  "db",
+			Schema:    "public",
+			TableName: "t",
+			Columns:   []string{"i"},
+			Where:     filters.NewCondition("tag", filters.Equal, "y"),
+			Nearest: &statement.NearestNeighbors{
+				Column:      "v",
+				Vector:      []float32{0, 0},
+				K:           5,
+				Metric:      fields.L2,
+				Approximate: approximate,
+			},
+		})
+		if !resp.IsSuccess() {
+			t.Fatalf("approximate %v: %s", approximate, resp.GetMessage())
+		}
+		rows := fmt.Sprint(resp.(*response.SelectResponse).Rows)
+		if want := "[map[i:390] map[i:391] map[i:392] map[i:393] map[i:394]]"; rows != want {
+			t.Errorf("approximate %v: rows = %s, want %s", approximate, rows, want)
+		}
+	}
+}
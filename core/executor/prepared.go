@@ -0,0 +1,263 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/parser"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+var errNoSession = errors.New("prepared statements require a session")
+
+// preparedStatement is a parsed statement kept by a session. Conditions
+// referencing parameters are listed so that an execution only replaces
+// their values; the filter tree, and the comparisons compiled for values
+// that did not change, are reused. Executions of the same prepared
+// statement are serialized because they share that tree.
+type preparedStatement struct {
+	mu         sync.Mutex
+	stmt       statement.Statement
+	paramCount int
+	conditions []*boundCondition
+}
+
+// boundCondition is a filter condition, or a literal of an expression,
+// whose value holds parameters.
+type boundCondition struct {
+	filter   *filters.Filter
+	literal  *expression.Expression
+	template interface{}
+	value    interface{}
+	bound    bool
+}
+
+func (e *DefaultExecutor) executePrepare(ctx context.Context, stmt *statement.PrepareStatement) response.Response {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return response.NewPrepareResponse(false, errNoSession.Error(), stmt.Name, 0)
+	}
+
+	p := parser.NewParserWithConfig(parser.ParserConfig{
+		Database: stmt.Database,
+		Schema:   stmt.Schema,
+	})
+	parsed, err := p.Parse(stmt.Query)
+	if err != nil {
+		return response.NewPrepareResponse(false, err.Error(), stmt.Name, 0)
+	}
+
+	ps, err := newPreparedStatement(parsed, p.NumParams())
+	if err != nil {
+		return response.NewPrepareResponse(false, err.Error(), stmt.Name, 0)
+	}
+	session.setPrepared(stmt.Name, ps)
+
+	return response.NewPrepareResponse(true, "statement prepared", stmt.Name, ps.paramCount)
+}
+
+func (e *DefaultExecutor) executePrepared(ctx context.Context, stmt *statement.ExecuteStatement) response.Response {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return response.NewExecuteResponse(false, errNoSession.Error(), nil)
+	}
+	ps, ok := session.getPrepared(stmt.Name)
+	if !ok {
+		return response.NewExecuteResponse(false, fmt.Sprintf("prepared statement %s not found", stmt.Name), nil)
+	}
+	if len(stmt.Params) != ps.paramCount {
+		return response.NewExecuteResponse(false, fmt.Sprintf("expected %d parameters, got %d", ps.paramCount, len(stmt.Params)), nil)
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	result := e.Execute(ctx, ps.bind(stmt.Params))
+	return response.NewExecuteResponse(result.IsSuccess(), result.GetMessage(), result)
+}
+
+func (e *DefaultExecutor) executeDeallocate(ctx context.Context, stmt *statement.DeallocateStatement) response.Response {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return response.NewDeallocateResponse(false, errNoSession.Error())
+	}
+	if !session.deletePrepared(stmt.Name) {
+		return response.NewDeallocateResponse(false, fmt.Sprintf("prepared statement %s not found", stmt.Name))
+	}
+	return response.NewDeallocateResponse(true, "statement deallocated")
+}
+
+func newPreparedStatement(stmt statement.Statement, paramCount int) (*preparedStatement, error) {
+	ps := &preparedStatement{stmt: stmt, paramCount: paramCount}
+	used := make(map[int]bool)
+
+	switch s := stmt.(type) {
+	case *statement.SelectStatement:
+		ps.collectSelect(s, used)
+	case *statement.InsertStatement:
+		collectRows(s.Values, used)
+	case *statement.UpsertStatement:
+		collectRows(s.Values, used)
+	case *statement.UpdateStatement:
+		for _, value := range s.Updates {
+			collectParams(value, used)
+		}
+		for _, e := range s.Expressions {
+			ps.collectLiterals(e, used)
+		}
+		ps.collectConditions(s.Where, used)
+	case *statement.DeleteStatement:
+		ps.collectConditions(s.Where, used)
+	}
+
+	for i := 0; i < paramCount; i++ {
+		if !used[i] {
+			return nil, fmt.Errorf("parameter %s cannot be bound in this position", statement.Param{Index: i})
+		}
+	}
+	return ps, nil
+}
+
+// collectSelect collects the parameters of a select, and of the
+// subqueries of its conditions, its common table expressions and the
+// selects it is combined with.
+func (ps *preparedStatement) collectSelect(s *statement.SelectStatement, used map[int]bool) {
+	for _, cte := range s.With {
+		ps.collectSelect(cte.Select, used)
+	}
+	ps.collectConditions(s.Where, used)
+	ps.collectConditions(s.Having, used)
+	for _, projection := range s.Projections {
+		ps.collectLiterals(projection.Expression, used)
+	}
+	for _, op := range s.SetOperations {
+		ps.collectSelect(op.Select, used)
+	}
+}
+
+func (ps *preparedStatement) collectConditions(f *filters.Filter, used map[int]bool) {
+	if f == nil {
+		return
+	}
+	for _, child := range f.Children {
+		ps.collectConditions(child, used)
+	}
+	if f.Expression != nil {
+		ps.collectLiterals(f.Expression, used)
+		return
+	}
+	if sub, ok := f.Value.(statement.Subquery); ok {
+		ps.collectSelect(sub.Select, used)
+		return
+	}
+	if len(f.Children) == 0 && collectParams(f.Value, used) {
+		ps.conditions = append(ps.conditions, &boundCondition{filter: f, template: f.Value})
+	}
+}
+
+func (ps *preparedStatement) collectLiterals(e *expression.Expression, used map[int]bool) {
+	e.Walk(func(node *expression.Expression) {
+		if node.Kind == expression.Literal && collectParams(node.Value, used) {
+			ps.conditions = append(ps.conditions, &boundCondition{literal: node, template: node.Value})
+		}
+	})
+}
+
+// bind returns the statement to run for params. Conditions are updated in
+// place; rows and assignments are copied since executing them changes them.
+func (ps *preparedStatement) bind(params []interface{}) statement.Statement {
+	for _, cond := range ps.conditions {
+		value := bindValue(cond.template, params)
+		if cond.bound && reflect.DeepEqual(value, cond.value) {
+			continue
+		}
+		if cond.literal != nil {
+			cond.literal.SetValue(value)
+		} else {
+			cond.filter.SetValue(value)
+		}
+		cond.value, cond.bound = value, true
+	}
+
+	switch s := ps.stmt.(type) {
+	case *statement.InsertStatement:
+		bound := *s
+		bound.Values = bindRows(s.Values, params)
+		return &bound
+	case *statement.UpsertStatement:
+		bound := *s
+		bound.Values = bindRows(s.Values, params)
+		return &bound
+	case *statement.UpdateStatement:
+		bound := *s
+		bound.Updates = bindRow(s.Updates, params)
+		return &bound
+	}
+	return ps.stmt
+}
+
+func bindRows(rows []map[string]interface{}, params []interface{}) []map[string]interface{} {
+	bound := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		bound[i] = bindRow(row, params)
+	}
+	return bound
+}
+
+func bindRow(row map[string]interface{}, params []interface{}) map[string]interface{} {
+	bound := maps.Clone(row)
+	for column, value := range bound {
+		bound[column] = bindValue(value, params)
+	}
+	return bound
+}
+
+// bindValue replaces the parameters in value, copying lists so that the
+// template is left untouched.
+func bindValue(value interface{}, params []interface{}) interface{} {
+	switch v := value.(type) {
+	case statement.Param:
+		return params[v.Index]
+	case []interface{}:
+		bound := make([]interface{}, len(v))
+		for i, elem := range v {
+			bound[i] = bindValue(elem, params)
+		}
+		return bound
+	}
+	return value
+}
+
+func collectRows(rows []map[string]interface{}, used map[int]bool) {
+	for _, row := range rows {
+		for _, value := range row {
+			collectParams(value, used)
+		}
+	}
+}
+
+// collectParams records the parameters found in value and reports whether
+// there was any.
+func collectParams(value interface{}, used map[int]bool) bool {
+	switch v := value.(type) {
+	case statement.Param:
+		used[v.Index] = true
+		return true
+	case []interface{}:
+		found := false
+		for _, elem := range v {
+			if collectParams(elem, used) {
+				found = true
+			}
+		}
+		return found
+	}
+	return false
+}
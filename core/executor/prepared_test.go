@@ -0,0 +1,134 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+func prepare(t *testing.T, e *executor.DefaultExecutor, ctx context.Context, name, query string) *response.PrepareResponse {
+	t.Helper()
+	stmt, err := statement.NewPrepareStatement(name, "db", "public", query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e.Execute(ctx, stmt).(*response.PrepareResponse)
+}
+
+func executePrepared(t *testing.T, e *executor.DefaultExecutor, ctx context.Context, name string, params ...interface{}) *response.ExecuteResponse {
+	t.Helper()
+	stmt, err := statement.NewExecuteStatement(name, params...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e.Execute(ctx, stmt).(*response.ExecuteResponse)
+}
+
+func preparedRows(t *testing.T, e *executor.DefaultExecutor, ctx context.Context, name string, params ...interface{}) string {
+	t.Helper()
+	resp := executePrepared(t, e, ctx, name, params...)
+	if !resp.Success {
+		t.Fatalf("EXECUTE %s%v: %s", name, params, resp.Message)
+	}
+	return fmt.Sprint(resp.Result.(*response.SelectResponse).Rows)
+}
+
+func TestPreparedRebinding(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := executor.WithSession(context.Background(), executor.NewSession())
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'x'), (4, 'y')")
+
+	if resp := prepare(t, e, ctx, "q", "SELECT a FROM t WHERE b = $2 AND a > $1 ORDER BY a"); !resp.Success || resp.ParamCount != 2 {
+		t.Fatalf("PREPARE = %v", resp)
+	}
+
+	tests := []struct {
+		params []interface{}
+		want   string
+	}{
+		{[]interface{}{0, "x"}, "[map[a:1] map[a:3]]"},
+		{[]interface{}{1, "x"}, "[map[a:3]]"},
+		{[]interface{}{1, "y"}, "[map[a:2] map[a:4]]"},
+		{[]interface{}{4, "y"}, "[]"},
+		{[]interface{}{0, "x"}, "[map[a:1] map[a:3]]"},
+	}
+	for _, tt := range tests {
+		if got := preparedRows(t, e, ctx, "q", tt.params...); got != tt.want {
+			t.Errorf("EXECUTE q%v = %s, want %s", tt.params, got, tt.want)
+		}
+	}
+
+	if resp := executePrepared(t, e, ctx, "q", 1); resp.Success {
+		t.Error("EXECUTE accepted too few parameters")
+	}
+}
+
+func TestPreparedWrites(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := executor.WithSession(context.Background(), executor.NewSession())
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, b STRING(10))")
+
+	prepare(t, e, ctx, "ins", "INSERT INTO t (a, b) VALUES ($1, $2), ($1, 'c')")
+	prepare(t, e, ctx, "upd", "UPDATE t SET a = a + $2 WHERE b = $1")
+	prepare(t, e, ctx, "del", "DELETE FROM t WHERE a = $1")
+	for _, params := range [][]interface{}{{1, "a"}, {2, "b"}} {
+		if resp := executePrepared(t, e, ctx, "ins", params...); !resp.Success {
+			t.Fatalf("EXECUTE ins%v: %s", params, resp.Message)
+		}
+	}
+	if resp := executePrepared(t, e, ctx, "upd", "c", 10); !resp.Success {
+		t.Fatalf("EXECUTE upd: %s", resp.Message)
+	}
+	if resp := executePrepared(t, e, ctx, "del", 11); !resp.Success {
+		t.Fatalf("EXECUTE del: %s", resp.Message)
+	}
+
+	rows := fmt.Sprint(selectRows(t, e, "SELECT a, b FROM t ORDER BY a"))
+	if want := "[map[a:1 b:a] map[a:2 b:b] map[a:12 b:c]]"; rows != want {
+		t.Errorf("rows = %s, want %s", rows, want)
+	}
+}
+
+func TestPreparedSession(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	session := executor.NewSession()
+	ctx := executor.WithSession(context.Background(), session)
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32)")
+	mustExec(t, e, ctx, "INSERT INTO t (a) VALUES (1)")
+
+	if resp := prepare(t, e, context.Background(), "q", "SELECT a FROM t"); resp.Success {
+		t.Error("PREPARE succeeded without a session")
+	}
+	if resp := prepare(t, e, ctx, "q", "SELECT a FROM t WHERE a = ? AND a = $1"); resp.Success {
+		t.Error("PREPARE accepted mixed parameter styles")
+	}
+
+	prepare(t, e, ctx, "q", "SELECT a FROM t WHERE a = $1")
+	other := executor.WithSession(context.Background(), executor.NewSession())
+	if resp := executePrepared(t, e, other, "q", 1); resp.Success {
+		t.Error("a statement prepared by one session ran in another")
+	}
+
+	prepare(t, e, ctx, "q", "SELECT a + $1 AS b FROM t")
+	if got := preparedRows(t, e, ctx, "q", 1); got != "[map[b:2]]" {
+		t.Errorf("re-prepared EXECUTE q = %s", got)
+	}
+
+	if resp := e.Execute(ctx, &statement.DeallocateStatement{Name: "q"}); !resp.IsSuccess() {
+		t.Fatalf("DEALLOCATE: %s", resp.GetMessage())
+	}
+	if resp := executePrepared(t, e, ctx, "q", 1); resp.Success {
+		t.Error("EXECUTE ran a deallocated statement")
+	}
+
+	prepare(t, e, ctx, "q", "SELECT a FROM t")
+	session.Close()
+	if resp := executePrepared(t, e, ctx, "q"); resp.Success {
+		t.Error("EXECUTE ran a statement of a closed session")
+	}
+}
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onnasoft/ZenithSQL/io/parser"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// executeQuery parses the SQL text of stmt and executes its statements in
+// order. Execution stops at the first failing statement; the responses of
+// the statements that ran are returned in QueryResponse.Results.
+func (e *DefaultExecutor) executeQuery(ctx context.Context, stmt *statement.QueryStatement) response.Response {
+	p := parser.NewParserWithConfig(parser.ParserConfig{
+		Database: stmt.Database,
+		Schema:   stmt.Schema,
+	})
+
+	stmts, err := p.ParseAll(stmt.Query)
+	if err != nil {
+		return response.NewQueryResponse(false, err.Error(), nil)
+	}
+	if len(stmts) == 0 {
+		return response.NewQueryResponse(false, "empty query", nil)
+	}
+	if p.NumParams() > 0 {
+		return response.NewQueryResponse(false, "query has parameters, prepare it and bind them on execute", nil)
+	}
+
+	resp := response.NewQueryResponse(true, "", nil)
+	for i, s := range stmts {
+		select {
+		case <-ctx.Done():
+			resp.Success = false
+			resp.Message = "context canceled"
+			return resp
+		default:
+		}
+
+		result := e.Execute(ctx, s)
+		resp.Results = append(resp.Results, result)
+		if !result.IsSuccess() {
+			resp.Success = false
+			resp.Message = fmt.Sprintf("statement %d failed: %s", i+1, result.GetMessage())
+			return resp
+		}
+	}
+
+	resp.Message = fmt.Sprintf("%d statements executed", len(stmts))
+	if len(stmts) == 1 {
+		resp.Message = "1 statement executed"
+	}
+	return resp
+}
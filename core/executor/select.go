@@ -2,48 +2,519 @@ package executor
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
 	"github.com/onnasoft/ZenithSQL/io/response"
 	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
 func (e *DefaultExecutor) executeSelect(ctx context.Context, stmt *statement.SelectStatement) response.Response {
-	table, err := e.catalog.GetTable(stmt.Database, stmt.Schema, stmt.TableName)
+	if stmt.Nearest != nil {
+		table, err := e.catalog.GetTable(stmt.Database, stmt.Schema, stmt.TableName)
+		if err != nil {
+			return response.NewSelectResponse(false, err.Error(), nil)
+		}
+		if stmt.Explain {
+			return response.NewSelectResponse(false, "EXPLAIN is not supported for NEAREST queries", nil)
+		}
+		return e.executeNearest(ctx, stmt, table)
+	}
+
+	sel, err := e.openSelect(ctx, stmt, nil)
 	if err != nil {
 		return response.NewSelectResponse(false, err.Error(), nil)
 	}
-	cursor, err := table.Cursor()
+	defer sel.cursor.Close()
+
+	if stmt.Explain {
+		return e.explainSelect(ctx, stmt.Analyze, sel)
+	}
+	return e.processSimpleSelect(ctx, sel.columns, sel.cursor)
+}
+
+// selection is an opened select: the cursor of its rows, the columns it
+// returns, how to add what the planner knows to the plan tree of the
+// cursor and, for an EXPLAIN, the plans of the subqueries it did not run.
+type selection struct {
+	cursor   storage.Cursor
+	columns  []string
+	annotate func(*storage.PlanNode)
+	subplans []*storage.PlanNode
+}
+
+// describe returns the plan tree of the cursor of sel under a Select node
+// for its columns, annotated by the plans of the tables it reads and
+// followed by the plans of the subqueries it did not run.
+func (sel *selection) describe() *storage.PlanNode {
+	input := sel.cursor.Plan()
+	sel.annotate(input)
+	children := append([]*storage.PlanNode{input}, sel.subplans...)
+	return storage.NewPlanNode("Select", strings.Join(sel.columns, ", "), children...)
+}
+
+// openSelect plans stmt and stacks the cursors producing its rows. The
+// subqueries of its conditions are run first, or turned into joins. It
+// reads the common table expressions of ctes, and those of its own WITH
+// clause.
+func (e *DefaultExecutor) openSelect(ctx context.Context, stmt *statement.SelectStatement, ctes *cteScope) (*selection, error) {
+	if stmt.Nearest != nil {
+		return nil, fmt.Errorf("nearest neighbors cannot be used in a subquery")
+	}
+	if len(stmt.With) > 0 {
+		ctes = newCTEScope(ctes, stmt.With, stmt.Recursive)
+	}
+	if len(stmt.SetOperations) > 0 {
+		return e.openSetOperation(ctx, stmt, ctes)
+	}
+	rel, err := e.relation(ctx, ctes, stmt.Database, stmt.Schema, stmt.TableName, stmt.IncludeDeleted)
 	if err != nil {
-		return response.NewSelectResponse(false, err.Error(), nil)
+		return nil, err
+	}
+
+	stmt, semiJoins, err := e.resolveSubqueries(ctx, stmt, rel, ctes)
+	if err != nil {
+		return nil, err
+	}
+	var subplans []*storage.PlanNode
+	if describing(stmt) {
+		if subplans, err = e.describeSubqueries(ctx, stmt, ctes); err != nil {
+			return nil, err
+		}
+	}
+
+	var scope *joinScope
+	if len(stmt.Joins) > 0 {
+		if scope, err = e.newJoinScope(ctx, stmt, rel, ctes); err != nil {
+			return nil, err
+		}
+		if stmt, err = scope.resolveStatement(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	columns, err := selectColumns(rel, stmt.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := stmt.Grouped()
+	aggregations := stmt.Aggregations
+	var keys []statement.Projection
+	projections := stmt.Projections
+	if grouped {
+		if columns, err = groupedColumns(stmt, columns); err != nil {
+			return nil, err
+		}
+		if keys, projections, err = groupKeys(stmt); err != nil {
+			return nil, err
+		}
+		if aggregations, err = groupAggregations(stmt, projections); err != nil {
+			return nil, err
+		}
+	}
+	for _, projection := range stmt.Projections {
+		columns = append(columns, projection.Name())
+	}
+	for _, w := range stmt.Windows {
+		columns = append(columns, w.Name())
+	}
+	windows, err := windowConfigs(stmt.Windows, e.config.SortMemoryLimit, e.config.TempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	sortKeys, err := orderByKeys(stmt.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Distinct {
+		for _, key := range sortKeys {
+			if !slices.Contains(columns, key.Column) {
+				return nil, fmt.Errorf("ORDER BY %s must be a selected column with DISTINCT", key.Column)
+			}
+		}
 	}
-	defer cursor.Close()
 
-	if stmt.Where != nil {
-		cursor, err = cursor.WithFilter(stmt.Where)
+	// Rows multiply through joins and UNNEST, are dropped by the joins of
+	// EXISTS, merged by GROUP BY and DISTINCT, reordered by ORDER BY and
+	// all read by window functions, so OFFSET and LIMIT can only be handed
+	// to the planner without them.
+	// The storage reads a zero limit as no limit, so a LIMIT 0 is applied
+	// here.
+	pushLimit := scope == nil && len(semiJoins) == 0 && len(stmt.Unnest) == 0 && !grouped && len(sortKeys) == 0 && len(windows) == 0 && !stmt.Distinct &&
+		(stmt.Limit == nil || *stmt.Limit > 0)
+	var cursor storage.Cursor
+	var plans []storage.QueryPlan
+	if scope != nil {
+		if cursor, plans, err = e.openJoin(scope, stmt); err != nil {
+			return nil, err
+		}
+	} else {
+		query := storage.Query{Filter: stmt.Where, Fields: columns}
+		if pushLimit {
+			query.Offset = int64(stmt.Offset)
+			if stmt.Limit != nil {
+				query.Limit = int64(*stmt.Limit)
+			}
+		}
+		plan, err := rel.plan(query)
 		if err != nil {
-			return response.NewSelectResponse(false, err.Error(), nil)
+			return nil, err
+		}
+		if cursor, err = plan.Execute(); err != nil {
+			return nil, err
 		}
+		plans = []storage.QueryPlan{plan}
 	}
+	// The wrapping cursors close the ones they wrap, so only the
+	// outermost is closed. Failed wrappers leave cursor unchanged.
+	done := false
+	defer func() {
+		if !done {
+			cursor.Close()
+		}
+	}()
 
-	if stmt.Offset > 0 {
-		cursor, err = cursor.WithSkip(int64(stmt.Offset))
+	annotate := func(node *storage.PlanNode) { annotatePlan(node, plans) }
+	for _, semi := range semiJoins {
+		if cursor, annotate, err = e.openSemiJoin(ctx, cursor, semi, annotate, ctes); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, column := range stmt.Unnest {
+		unnested, err := cursor.WithUnnest(column)
 		if err != nil {
-			return response.NewSelectResponse(false, err.Error(), nil)
+			return nil, err
+		}
+		cursor = unnested
+	}
+
+	if grouped {
+		if len(keys) > 0 {
+			computed, err := cursor.WithProjection(storageProjections(keys))
+			if err != nil {
+				return nil, err
+			}
+			cursor = computed
+		}
+
+		groups, err := cursor.WithGroupBy(storage.GroupConfig{
+			Columns:      stmt.GroupBy,
+			Aggregations: aggregations,
+			MemoryLimit:  e.config.GroupMemoryLimit,
+			TempDir:      e.config.TempDir,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cursor = groups
+
+		if stmt.Having != nil {
+			having, err := cursor.WithFilter(stmt.Having)
+			if err != nil {
+				return nil, fmt.Errorf("invalid HAVING: %s", err)
+			}
+			cursor = having
+		}
+	}
+
+	if len(projections) > 0 {
+		projected, err := cursor.WithProjection(storageProjections(projections))
+		if err != nil {
+			return nil, err
+		}
+		cursor = projected
+	}
+
+	for _, config := range windows {
+		windowed, err := cursor.WithWindow(config)
+		if err != nil {
+			return nil, err
+		}
+		cursor = windowed
+	}
+
+	if stmt.Distinct {
+		distinct, err := cursor.WithDistinct(storage.DistinctConfig{
+			Columns:     columns,
+			MemoryLimit: e.config.GroupMemoryLimit,
+			TempDir:     e.config.TempDir,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cursor = distinct
+	}
+
+	var offset uint64
+	var limit *uint64
+	if !pushLimit {
+		offset, limit = stmt.Offset, stmt.Limit
+	}
+	if cursor, err = e.sortAndLimit(cursor, sortKeys, offset, limit); err != nil {
+		return nil, err
+	}
+
+	done = true
+	return &selection{cursor: cursor, columns: columns, annotate: annotate, subplans: subplans}, nil
+}
+
+// sortAndLimit stacks on cursor the cursors sorting its rows by keys and
+// keeping limit of them from offset on, nil meaning all of them. It
+// returns the outermost cursor opened, for the caller to close even when
+// it fails.
+func (e *DefaultExecutor) sortAndLimit(cursor storage.Cursor, keys []storage.SortKey, offset uint64, limit *uint64) (storage.Cursor, error) {
+	if len(keys) > 0 {
+		// Only the rows up to the end of the LIMIT have to be kept sorted.
+		config := storage.SortConfig{
+			Keys:        keys,
+			MemoryLimit: e.config.SortMemoryLimit,
+			TempDir:     e.config.TempDir,
+		}
+		if limit != nil && *limit > 0 {
+			config.Limit = int64(offset + *limit)
+		}
+		sorted, err := cursor.WithSort(config)
+		if err != nil {
+			return cursor, err
 		}
+		cursor = sorted
 	}
 
-	if stmt.Limit > 0 {
-		cursor, err = cursor.WithLimit(int64(stmt.Limit))
+	if offset > 0 {
+		skipped, err := cursor.WithSkip(int64(offset))
 		if err != nil {
+			return cursor, err
+		}
+		cursor = skipped
+	}
+
+	if limit != nil {
+		limited, err := cursor.WithLimit(int64(*limit))
+		if err != nil {
+			return cursor, err
+		}
+		cursor = limited
+	}
+	return cursor, nil
+}
+
+// explainSelect returns the plan tree sel describes. With ANALYZE the rows
+// are read as processSimpleSelect reads them, then discarded, and the
+// Select node carries the total time.
+func (e *DefaultExecutor) explainSelect(ctx context.Context, analyze bool, sel *selection) response.Response {
+	cursor, columns := sel.cursor, sel.columns
+	var rows int64
+	var elapsed time.Duration
+	if analyze {
+		cursor.Analyze()
+		start := time.Now()
+		for cursor.Next() {
+			select {
+			case <-ctx.Done():
+				return response.NewSelectResponse(false, "context done", nil)
+			default:
+			}
+
+			for _, column := range columns {
+				if _, err := cursor.ScanField(column); err != nil {
+					return response.NewSelectResponse(false, err.Error(), nil)
+				}
+			}
+			rows++
+		}
+		elapsed = time.Since(start)
+		if err := cursor.Err(); err != nil {
 			return response.NewSelectResponse(false, err.Error(), nil)
 		}
 	}
 
-	return e.processSimpleSelect(ctx, stmt, cursor)
+	node := sel.describe()
+	if analyze {
+		node.Analyzed = true
+		node.ActualRows, node.RowsScanned = rows, rows
+		node.Duration = elapsed
+	}
+	return response.NewExplainResponse(node)
+}
+
+// groupedColumns returns the columns of a grouped select: the selected
+// columns, which must be grouped by, followed by the aggregations.
+func groupedColumns(stmt *statement.SelectStatement, columns []string) ([]string, error) {
+	result := make([]string, 0, len(columns)+len(stmt.Aggregations))
+	for _, column := range columns {
+		if !slices.Contains(stmt.GroupBy, column) {
+			return nil, fmt.Errorf("column %s must appear in GROUP BY or be used in an aggregate", column)
+		}
+		result = append(result, column)
+	}
+	for _, agg := range stmt.Aggregations {
+		result = append(result, agg.Name())
+	}
+	return result, nil
+}
+
+// groupKeys splits the computed columns of a grouped select into those it
+// groups by, which are computed for every row before grouping, and the
+// others, computed for every group.
+func groupKeys(stmt *statement.SelectStatement) (keys, others []statement.Projection, err error) {
+	for _, projection := range stmt.Projections {
+		if !slices.Contains(stmt.GroupBy, projection.Name()) {
+			others = append(others, projection)
+			continue
+		}
+		if projection.Aggregates() {
+			return nil, nil, fmt.Errorf("cannot group by %s, which uses an aggregate", projection.Name())
+		}
+		keys = append(keys, projection)
+	}
+	return keys, others, nil
+}
+
+func storageProjections(projections []statement.Projection) []storage.Projection {
+	result := make([]storage.Projection, len(projections))
+	for i, projection := range projections {
+		result[i] = storage.Projection{Name: projection.Name(), Expression: projection.Expression}
+	}
+	return result
+}
+
+// groupAggregations returns the aggregations a grouped select computes: the
+// selected ones, followed by those HAVING, the computed columns and the
+// window functions of the groups call without selecting them, which are
+// named by their call so the filter and the expressions find them. Computed
+// columns and windows may otherwise only use the grouped columns.
+func groupAggregations(stmt *statement.SelectStatement, projections []statement.Projection) ([]statement.Aggregation, error) {
+	aggregations := stmt.Aggregations
+
+	names := make(map[string]bool)
+	for _, column := range stmt.GroupBy {
+		names[column] = true
+	}
+	for _, agg := range aggregations {
+		names[agg.Name()] = true
+	}
+
+	add := func(field string) bool {
+		if names[field] {
+			return true
+		}
+		agg, ok := statement.ParseAggregation(field)
+		if !ok {
+			return false
+		}
+		agg.Alias = field
+		aggregations = append(slices.Clip(aggregations), agg)
+		names[field] = true
+		return true
+	}
+
+	for _, projection := range projections {
+		for _, column := range projection.Expression.Columns() {
+			if !add(column) {
+				return nil, fmt.Errorf("column %s must appear in GROUP BY or be used in an aggregate", column)
+			}
+		}
+	}
+	for _, w := range stmt.Windows {
+		for _, column := range w.Columns() {
+			if !add(column) {
+				return nil, fmt.Errorf("column %s must appear in GROUP BY or be used in an aggregate", column)
+			}
+		}
+	}
+
+	if stmt.Having == nil {
+		return aggregations, nil
+	}
+	var err error
+	stmt.Having.Walk(func(f *filters.Filter) {
+		if err != nil || len(f.Children) > 0 {
+			return
+		}
+		columns := []string{f.Field}
+		if f.Expression != nil {
+			columns = f.Expression.Columns()
+		}
+		for _, column := range columns {
+			if !add(column) {
+				err = fmt.Errorf("column %s in HAVING must appear in GROUP BY or be an aggregate", column)
+				return
+			}
+		}
+	})
+	return aggregations, err
+}
+
+// windowConfigs groups window functions by their window, so that the
+// functions sharing one are computed over a single sort of the rows.
+func windowConfigs(windows []statement.WindowFunction, memoryLimit int64, tempDir string) ([]storage.WindowConfig, error) {
+	var configs []storage.WindowConfig
+	index := make(map[string]int)
+	for _, w := range windows {
+		key := strings.Join(w.Over.PartitionBy, ",") + "|" + strings.Join(w.Over.OrderBy, ",")
+		i, ok := index[key]
+		if !ok {
+			orderBy, err := orderByKeys(w.Over.OrderBy)
+			if err != nil {
+				return nil, err
+			}
+			configs = append(configs, storage.WindowConfig{
+				PartitionBy: w.Over.PartitionBy,
+				OrderBy:     orderBy,
+				MemoryLimit: memoryLimit,
+				TempDir:     tempDir,
+			})
+			i = len(configs) - 1
+			index[key] = i
+		}
+		configs[i].Functions = append(configs[i].Functions, w)
+	}
+	return configs, nil
+}
+
+func orderByKeys(orderBy []string) ([]storage.SortKey, error) {
+	keys := make([]storage.SortKey, len(orderBy))
+	for i, item := range orderBy {
+		key, err := storage.ParseSortKey(item)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// selectColumns expands * into every column of the relation, in
+// definition order.
+func selectColumns(rel relation, columns []string) ([]string, error) {
+	if !slices.Contains(columns, "*") {
+		return columns, nil
+	}
+
+	names, err := rel.columns()
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make([]string, 0, len(columns)+len(names))
+	for _, column := range columns {
+		if column != "*" {
+			expanded = append(expanded, column)
+			continue
+		}
+		expanded = append(expanded, names...)
+	}
+	return expanded, nil
 }
 
-func (e *DefaultExecutor) processSimpleSelect(ctx context.Context, stmt *statement.SelectStatement, cursor storage.Cursor) response.Response {
+func (e *DefaultExecutor) processSimpleSelect(ctx context.Context, columns []string, cursor storage.Cursor) response.Response {
 	rows := []map[string]interface{}{}
 
 	for cursor.Next() {
@@ -55,7 +526,7 @@ func (e *DefaultExecutor) processSimpleSelect(ctx context.Context, stmt *stateme
 
 		record := make(map[string]interface{})
 
-		for _, column := range stmt.Columns {
+		for _, column := range columns {
 			value, err := cursor.ScanField(column)
 			if err != nil {
 				return response.NewSelectResponse(false, err.Error(), nil)
@@ -65,6 +536,11 @@ func (e *DefaultExecutor) processSimpleSelect(ctx context.Context, stmt *stateme
 
 		rows = append(rows, record)
 	}
+	if err := cursor.Err(); err != nil {
+		return response.NewSelectResponse(false, err.Error(), nil)
+	}
 
-	return response.NewSelectResponse(true, "Select executed successfully", rows)
+	resp := response.NewSelectResponse(true, "Select executed successfully", rows)
+	resp.Columns = columns
+	return resp
 }
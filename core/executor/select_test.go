@@ -0,0 +1,89 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestLimitZero(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32)")
+	mustExec(t, e, ctx, "INSERT INTO t (a) VALUES (1), (2), (3)")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT a FROM t LIMIT 0", "[]"},
+		{"SELECT a FROM t ORDER BY a LIMIT 0", "[]"},
+		{"SELECT a FROM t LIMIT 0 OFFSET 1", "[]"},
+		{"SELECT a FROM t UNION SELECT a FROM t LIMIT 0", "[]"},
+		{"SELECT COUNT(*) AS n FROM t LIMIT 0", "[]"},
+		{"SELECT a FROM t WHERE a IN (SELECT a FROM t LIMIT 0)", "[]"},
+		{"SELECT a FROM t ORDER BY a LIMIT 2", "[map[a:1] map[a:2]]"},
+		{"SELECT a FROM t ORDER BY a OFFSET 2", "[map[a:3]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestUnsignedMax(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (u UINT64, v UINT16)")
+	mustExec(t, e, ctx, "INSERT INTO t (u, v) VALUES (18446744073709551615, 65535), (1, 1)")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT u, v FROM t ORDER BY u", "[map[u:1 v:1] map[u:18446744073709551615 v:65535]]"},
+		{"SELECT u FROM t ORDER BY u DESC LIMIT 1", "[map[u:18446744073709551615]]"},
+		{"SELECT v FROM t ORDER BY v DESC", "[map[v:65535] map[v:1]]"},
+		{"SELECT MAX(u) AS m FROM t", "[map[m:18446744073709551615]]"},
+		{"SELECT v FROM t WHERE u > 9223372036854775807", "[map[v:65535]]"},
+		{"SELECT v FROM t WHERE v > 32767", "[map[v:65535]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestTemporalNulls(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (k INT32, d DATE, iv INTERVAL, tod TIME, ts TIMESTAMP)")
+	mustExec(t, e, ctx, "INSERT INTO t (k, d, iv, tod, ts) VALUES (1, DATE '1990-05-01', INTERVAL '2 hours', TIME '08:00', TIMESTAMP '1990-05-01T10:00:00Z')")
+	mustExec(t, e, ctx, "INSERT INTO t (k) VALUES (2)")
+
+	// The null row holds the zero of every type, which each filter would
+	// match if it read it.
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT k FROM t WHERE d < '2000-01-01'", "[map[k:1]]"},
+		{"SELECT k FROM t WHERE d <> '1990-05-01'", "[]"},
+		{"SELECT k FROM t WHERE iv < '1 day'", "[map[k:1]]"},
+		{"SELECT k FROM t WHERE iv NOT IN ('1 hour')", "[map[k:1]]"},
+		{"SELECT k FROM t WHERE tod < '12:00'", "[map[k:1]]"},
+		{"SELECT k FROM t WHERE tod BETWEEN '00:00' AND '09:00'", "[map[k:1]]"},
+		{"SELECT k FROM t WHERE ts < '2000-01-01T00:00:00Z'", "[map[k:1]]"},
+		{"SELECT k FROM t WHERE ts < now() - interval '1 day'", "[map[k:1]]"},
+		{"SELECT k FROM t WHERE d IS NULL AND iv IS NULL AND tod IS NULL AND ts IS NULL", "[map[k:2]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+}
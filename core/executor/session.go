@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// Session is the server side state of a client connection, currently the
+// statements it prepared. Attach it to the context given to Execute with
+// WithSession; the message server gives every connection one, in the
+// context of the connection, and closes it on disconnect. A Session is safe
+// for concurrent use.
+type Session struct {
+	mu       sync.Mutex
+	prepared map[string]*preparedStatement
+}
+
+func NewSession() *Session {
+	return &Session{
+		prepared: make(map[string]*preparedStatement),
+	}
+}
+
+// Close discards every prepared statement of the session.
+func (s *Session) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.prepared)
+}
+
+func (s *Session) getPrepared(name string) (*preparedStatement, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ps, ok := s.prepared[name]
+	return ps, ok
+}
+
+func (s *Session) setPrepared(name string, ps *preparedStatement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prepared[name] = ps
+}
+
+func (s *Session) deletePrepared(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.prepared[name]
+	delete(s.prepared, name)
+	return ok
+}
+
+type sessionKey struct{}
+
+func WithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(*Session)
+	return session, ok && session != nil
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"context"
+	"slices"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// openSetOperation opens the selects stmt combines and stacks the cursors
+// of their set operations, INTERSECT before UNION and EXCEPT, and then
+// those of the ORDER BY, OFFSET and LIMIT of stmt. The rows are named as
+// the columns of the first select.
+func (e *DefaultExecutor) openSetOperation(ctx context.Context, stmt *statement.SelectStatement, ctes *cteScope) (*selection, error) {
+	first := *stmt
+	first.With, first.Recursive, first.SetOperations = nil, false, nil
+	first.OrderBy, first.Offset, first.Limit = nil, 0, nil
+
+	result, err := e.openSelect(ctx, &first, ctes)
+	if err != nil {
+		return nil, err
+	}
+	// term is the intersection on the right of the last UNION or EXCEPT,
+	// pending, which is combined with result once complete.
+	var term *selection
+	var pending statement.SetOperation
+	closeAll := func() {
+		result.cursor.Close()
+		if term != nil {
+			term.cursor.Close()
+		}
+	}
+
+	for _, op := range stmt.SetOperations {
+		sel := op.Select
+		if describing(stmt) {
+			sel = described(sel)
+		}
+		right, err := e.openSelect(ctx, sel, ctes)
+		if err == nil {
+			switch {
+			case op.Operator == statement.Intersect && term == nil:
+				result, err = e.combine(result, right, op)
+			case op.Operator == statement.Intersect:
+				term, err = e.combine(term, right, op)
+			default:
+				if term != nil {
+					result, err = e.combine(result, term, pending)
+				}
+				if err == nil {
+					term, pending = right, op
+				}
+			}
+			if err != nil {
+				right.cursor.Close()
+			}
+		}
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+	}
+	if term != nil {
+		if result, err = e.combine(result, term, pending); err != nil {
+			closeAll()
+			return nil, err
+		}
+	}
+
+	sortKeys, err := orderByKeys(stmt.OrderBy)
+	if err == nil {
+		result.cursor, err = e.sortAndLimit(result.cursor, sortKeys, stmt.Offset, stmt.Limit)
+	}
+	if err != nil {
+		result.cursor.Close()
+		return nil, err
+	}
+	return result, nil
+}
+
+// combine stacks a set operation on the cursors of two selects. On
+// failure both are left open and left is returned.
+func (e *DefaultExecutor) combine(left, right *selection, op statement.SetOperation) (*selection, error) {
+	cursor, err := left.cursor.WithSetOperation(right.cursor, storage.SetOperationConfig{
+		Operator:     op.Operator,
+		All:          op.All,
+		LeftColumns:  left.columns,
+		RightColumns: right.columns,
+		MemoryLimit:  e.config.SortMemoryLimit,
+		TempDir:      e.config.TempDir,
+	})
+	if err != nil {
+		return left, err
+	}
+
+	// The set operation is the outermost node of the tree of the cursor.
+	subplans := append(slices.Clip(left.subplans), right.subplans...)
+	return &selection{cursor: cursor, columns: left.columns, subplans: subplans, annotate: func(node *storage.PlanNode) {
+		var combined *storage.PlanNode
+		node.Walk(func(n *storage.PlanNode) {
+			if combined == nil && len(n.Children) == 2 && isSetOperation(n.Operation) {
+				combined = n
+			}
+		})
+		if combined == nil {
+			return
+		}
+		left.annotate(combined.Children[0])
+		right.annotate(combined.Children[1])
+	}}, nil
+}
+
+func isSetOperation(operation string) bool {
+	switch operation {
+	case "Union", "Union All", "Intersect", "Intersect All", "Except", "Except All":
+		return true
+	}
+	return false
+}
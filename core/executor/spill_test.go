@@ -0,0 +1,101 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+	"github.com/onnasoft/ZenithSQL/io/response"
+)
+
+// spillExecutors returns an executor with the default memory limits and
+// one whose operators spill to disk past 1 KiB, over the same tables: t, of
+// 300 rows with repeated values, and u, of 100 rows matching some of them.
+func spillExecutors(t *testing.T) (memory, spill *executor.DefaultExecutor) {
+	t.Helper()
+	memory, cat := openExecutor(t, t.TempDir(), executor.Config{})
+	ctx := context.Background()
+	mustExec(t, memory, ctx, "CREATE DATABASE db")
+	mustExec(t, memory, ctx, "CREATE TABLE t (a INT32, g STRING(10), f FLOAT64)")
+	mustExec(t, memory, ctx, "CREATE TABLE u (a INT32, x STRING(10))")
+
+	var rows []string
+	for i := range 300 {
+		rows = append(rows, fmt.Sprintf("(%d, 'g%d', %d.5)", (i*37)%101, i%13, i%7))
+	}
+	mustExec(t, memory, ctx, "INSERT INTO t (a, g, f) VALUES "+strings.Join(rows, ", "))
+	rows = rows[:0]
+	for i := range 100 {
+		rows = append(rows, fmt.Sprintf("(%d, 'x%d')", i*3, i%4))
+	}
+	mustExec(t, memory, ctx, "INSERT INTO u (a, x) VALUES "+strings.Join(rows, ", "))
+
+	spill = executor.NewWithConfig(cat, executor.Config{
+		SortMemoryLimit:  1024,
+		GroupMemoryLimit: 1024,
+		JoinMemoryLimit:  1024,
+		TempDir:          t.TempDir(),
+	})
+	return memory, spill
+}
+
+// checkSpillParity checks that sql returns the same rows whether it runs in
+// memory or spills, and that it does spill.
+func checkSpillParity(t *testing.T, memory, spill *executor.DefaultExecutor, sql string) {
+	t.Helper()
+	want := fmt.Sprint(selectRows(t, memory, sql))
+	if got := fmt.Sprint(selectRows(t, spill, sql)); got != want {
+		t.Errorf("%s spilled returned\n%s\nwant\n%s", sql, got, want)
+	}
+	plan := mustExec(t, spill, context.Background(), "EXPLAIN ANALYZE "+sql).(*response.SelectResponse).Plan
+	if !strings.Contains(plan.String(), "spilled") {
+		t.Errorf("%s did not spill:\n%s", sql, plan)
+	}
+}
+
+func TestSortSpillParity(t *testing.T) {
+	memory, spill := spillExecutors(t)
+	for _, sql := range []string{
+		"SELECT id, a, g FROM t ORDER BY a, id",
+		"SELECT id, g, f FROM t ORDER BY g DESC, f, id LIMIT 25",
+		"SELECT a, f FROM t ORDER BY f DESC, a DESC, id OFFSET 290",
+	} {
+		checkSpillParity(t, memory, spill, sql)
+	}
+}
+
+func TestGroupSpillParity(t *testing.T) {
+	memory, spill := spillExecutors(t)
+	for _, sql := range []string{
+		"SELECT g, COUNT(*) AS n, SUM(a) AS s, MIN(f) AS lo, MAX(f) AS hi FROM t GROUP BY g ORDER BY g",
+		"SELECT a, AVG(f) AS m FROM t GROUP BY a HAVING COUNT(*) > 2 ORDER BY a",
+		"SELECT g, f, COUNT(*) AS n FROM t GROUP BY g, f ORDER BY g, f",
+	} {
+		checkSpillParity(t, memory, spill, sql)
+	}
+}
+
+func TestJoinSpillParity(t *testing.T) {
+	memory, spill := spillExecutors(t)
+	for _, sql := range []string{
+		"SELECT t.id, t.a, u.x FROM t HASH JOIN u ON t.a = u.a ORDER BY t.id, u.x",
+		"SELECT t.id, u.x FROM t LEFT HASH JOIN u ON t.a = u.a ORDER BY t.id, u.x",
+		"SELECT t.id, u.id, u.x FROM t FULL HASH JOIN u ON t.a = u.a ORDER BY t.id, u.id",
+	} {
+		checkSpillParity(t, memory, spill, sql)
+	}
+}
+
+func TestDistinctSpillParity(t *testing.T) {
+	memory, spill := spillExecutors(t)
+	for _, sql := range []string{
+		"SELECT DISTINCT g FROM t ORDER BY g",
+		"SELECT DISTINCT a, f FROM t ORDER BY a, f",
+		"SELECT DISTINCT g, f FROM t ORDER BY f DESC, g LIMIT 10",
+		"SELECT g, COUNT(DISTINCT a) AS n FROM t GROUP BY g ORDER BY g",
+	} {
+		checkSpillParity(t, memory, spill, sql)
+	}
+}
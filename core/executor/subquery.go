@@ -0,0 +1,505 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// semiJoin is a correlated EXISTS or NOT EXISTS condition of WHERE, run as
+// a join keeping the rows that have, or do not have, a match among the rows
+// of sub, which selects the right columns of keys.
+type semiJoin struct {
+	anti       bool
+	sub        *statement.SelectStatement
+	keys       []storage.JoinKey
+	rightAlias string
+}
+
+// resolveSubqueries returns a copy of stmt without subqueries in its
+// conditions and select list. Uncorrelated subqueries are run once and
+// replaced by their results: the values of IN and NOT IN, the value
+// compared with or of a scalar subquery, or whether EXISTS holds. Correlated EXISTS and NOT EXISTS conditions joined
+// by AND to the rest of WHERE are removed and returned as semi joins; other
+// correlated subqueries are not supported. The conditions of stmt are left
+// untouched, since prepared statements run them again. An EXPLAIN without
+// ANALYZE keeps the conditions on subqueries as they are, without running
+// them, but still runs the scalar subqueries of expressions, whose type
+// comes from their value.
+func (e *DefaultExecutor) resolveSubqueries(ctx context.Context, stmt *statement.SelectStatement, rel relation, ctes *cteScope) (*statement.SelectStatement, []semiJoin, error) {
+	scalars := slices.ContainsFunc(stmt.Projections, func(p statement.Projection) bool {
+		return len(statement.ScalarSubqueries(p.Expression)) > 0
+	})
+	if !hasSubquery(stmt.Where) && !hasSubquery(stmt.Having) && !scalars {
+		return stmt, nil, nil
+	}
+	outer, err := e.newJoinScope(ctx, stmt, rel, ctes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	explain := describing(stmt)
+	resolved := *stmt
+	var semiJoins []semiJoin
+	if stmt.Where != nil {
+		var kept []*filters.Filter
+		for _, f := range conjuncts(stmt.Where) {
+			semi, ok, err := e.semiJoinOf(ctx, f, outer, ctes, len(stmt.Joins) > 0)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				if explain {
+					semi.sub.Explain = true
+				}
+				semiJoins = append(semiJoins, semi)
+				continue
+			}
+			materialized, err := e.materialize(ctx, f, outer, ctes, explain)
+			if err != nil {
+				return nil, nil, err
+			}
+			kept = append(kept, materialized)
+		}
+		resolved.Where = conjunction(kept)
+	}
+	if stmt.Having != nil {
+		if resolved.Having, err = e.materialize(ctx, stmt.Having, outer, ctes, explain); err != nil {
+			return nil, nil, err
+		}
+	}
+	if scalars {
+		resolved.Projections = make([]statement.Projection, len(stmt.Projections))
+		for i, p := range stmt.Projections {
+			// The column keeps the name of the subquery it computes.
+			resolved.Projections[i] = statement.Projection{Alias: p.Name()}
+			if resolved.Projections[i].Expression, err = e.resolveScalars(ctx, p.Expression, outer, ctes); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return &resolved, semiJoins, nil
+}
+
+func hasSubquery(f *filters.Filter) bool {
+	found := false
+	if f != nil {
+		f.Walk(func(f *filters.Filter) {
+			if _, ok := f.Value.(statement.Subquery); ok {
+				found = true
+			}
+			if f.Expression != nil && len(statement.ScalarSubqueries(f.Expression)) > 0 {
+				found = true
+			}
+		})
+	}
+	return found
+}
+
+// conjuncts returns the conditions joined by AND at the top of f.
+func conjuncts(f *filters.Filter) []*filters.Filter {
+	if strings.EqualFold(f.JoinWith, filters.And) && len(f.Children) > 0 {
+		return f.Children
+	}
+	return []*filters.Filter{f}
+}
+
+// materialize returns f with its subqueries replaced by their results, or
+// with explain, checked but not run. Groups are copied and the other
+// conditions are shared with f.
+func (e *DefaultExecutor) materialize(ctx context.Context, f *filters.Filter, outer *joinScope, ctes *cteScope, explain bool) (*filters.Filter, error) {
+	if len(f.Children) > 0 {
+		group := filters.NewGroup(f.JoinWith)
+		for _, child := range f.Children {
+			materialized, err := e.materialize(ctx, child, outer, ctes, explain)
+			if err != nil {
+				return nil, err
+			}
+			group.Add(materialized)
+		}
+		return group, nil
+	}
+	if f.Expression != nil {
+		expr, err := e.resolveScalars(ctx, f.Expression, outer, ctes)
+		if err != nil || expr == f.Expression {
+			return f, err
+		}
+		return filters.NewExpressionCondition(expr), nil
+	}
+	sub, ok := f.Value.(statement.Subquery)
+	if !ok {
+		return f, nil
+	}
+
+	_, keys, err := e.correlation(ctx, sub.Select, outer, ctes)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		return nil, fmt.Errorf("correlated subqueries are only supported in EXISTS and NOT EXISTS conditions of WHERE joined by AND")
+	}
+	if explain {
+		return f, nil
+	}
+
+	switch f.Operator {
+	case filters.Exists, filters.NotExists:
+		found, err := e.subqueryExists(ctx, sub.Select, ctes)
+		if err != nil {
+			return nil, err
+		}
+		return constantFilter(found == (f.Operator == filters.Exists)), nil
+	case filters.In, filters.NotIn:
+		values, err := e.subqueryValues(ctx, sub.Select, ctes, 0)
+		if err != nil {
+			return nil, err
+		}
+		// A null in the list leaves NOT IN unknown for every row.
+		list := distinctValues(values)
+		switch {
+		case f.Operator == filters.NotIn && slices.Contains(values, nil):
+			return constantFilter(false), nil
+		case len(list) == 0:
+			return constantFilter(f.Operator == filters.NotIn), nil
+		}
+		return filters.NewCondition(f.Field, f.Operator, list), nil
+	}
+
+	value, err := e.scalarValue(ctx, sub, ctes)
+	if err != nil {
+		return nil, err
+	}
+	// Comparisons with null, or with no row, are unknown.
+	if value == nil {
+		return constantFilter(false), nil
+	}
+	return filters.NewCondition(f.Field, f.Operator, value), nil
+}
+
+// resolveScalars returns a copy of expr whose scalar subqueries are
+// replaced by the values they return, or expr itself without any.
+func (e *DefaultExecutor) resolveScalars(ctx context.Context, expr *expression.Expression, outer *joinScope, ctes *cteScope) (*expression.Expression, error) {
+	if len(statement.ScalarSubqueries(expr)) == 0 {
+		return expr, nil
+	}
+	resolved, err := expr.Rename(func(name string) (string, error) { return name, nil })
+	if err != nil {
+		return nil, err
+	}
+	resolved.Walk(func(node *expression.Expression) {
+		sub, ok := node.Value.(statement.Subquery)
+		if err != nil || !ok || node.Kind != expression.Literal {
+			return
+		}
+		var keys []storage.JoinKey
+		if _, keys, err = e.correlation(ctx, sub.Select, outer, ctes); err == nil && len(keys) > 0 {
+			err = fmt.Errorf("correlated subqueries are only supported in EXISTS and NOT EXISTS conditions of WHERE joined by AND")
+		}
+		if err != nil {
+			return
+		}
+		var value interface{}
+		value, err = e.scalarValue(ctx, sub, ctes)
+		node.SetValue(value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// scalarValue returns the only value sub returns, or nil when it returns
+// no row.
+func (e *DefaultExecutor) scalarValue(ctx context.Context, sub statement.Subquery, ctes *cteScope) (interface{}, error) {
+	values, err := e.subqueryValues(ctx, sub.Select, ctes, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > 1 {
+		return nil, fmt.Errorf("subquery %s returned more than one row", sub)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values[0], nil
+}
+
+// distinctValues returns values without nulls and repeated values.
+func distinctValues(values []interface{}) []interface{} {
+	seen := make(map[interface{}]bool)
+	var result []interface{}
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		if reflect.TypeOf(value).Comparable() {
+			if seen[value] {
+				continue
+			}
+			seen[value] = true
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+// describing reports whether stmt is only planned, by an EXPLAIN without
+// ANALYZE, which runs none of its rows.
+func describing(stmt *statement.SelectStatement) bool {
+	return stmt.Explain && !stmt.Analyze
+}
+
+// described returns a copy of stmt that is only planned.
+func described(stmt *statement.SelectStatement) *statement.SelectStatement {
+	copied := *stmt
+	copied.Explain, copied.Analyze = true, false
+	return &copied
+}
+
+// describeSubqueries returns the plans of the subqueries left in the
+// conditions of stmt by an EXPLAIN, which are planned without being run.
+func (e *DefaultExecutor) describeSubqueries(ctx context.Context, stmt *statement.SelectStatement, ctes *cteScope) ([]*storage.PlanNode, error) {
+	var conditions []*filters.Filter
+	for _, f := range []*filters.Filter{stmt.Where, stmt.Having} {
+		if f == nil {
+			continue
+		}
+		f.Walk(func(f *filters.Filter) {
+			if _, ok := f.Value.(statement.Subquery); ok {
+				conditions = append(conditions, f)
+			}
+		})
+	}
+
+	var nodes []*storage.PlanNode
+	for _, f := range conditions {
+		sel, err := e.openSelect(ctx, described(f.Value.(statement.Subquery).Select), ctes)
+		if err != nil {
+			return nil, err
+		}
+		node := sel.describe()
+		sel.cursor.Close()
+		if f.Operator != filters.Exists && f.Operator != filters.NotExists && len(sel.columns) != 1 {
+			return nil, fmt.Errorf("subquery must return a single column, not %d", len(sel.columns))
+		}
+		nodes = append(nodes, storage.NewPlanNode("Subquery", "", node))
+	}
+	return nodes, nil
+}
+
+func constantFilter(value bool) *filters.Filter {
+	return filters.NewExpressionCondition(expression.NewLiteral(value))
+}
+
+// subqueryExists reports whether stmt returns any row.
+func (e *DefaultExecutor) subqueryExists(ctx context.Context, stmt *statement.SelectStatement, ctes *cteScope) (bool, error) {
+	sel, err := e.openSelect(ctx, stmt, ctes)
+	if err != nil {
+		return false, err
+	}
+	defer sel.cursor.Close()
+	found := sel.cursor.Next()
+	return found, sel.cursor.Err()
+}
+
+// subqueryValues returns the values of the only column stmt returns, up to
+// limit of them unless limit is zero.
+func (e *DefaultExecutor) subqueryValues(ctx context.Context, stmt *statement.SelectStatement, ctes *cteScope, limit int) ([]interface{}, error) {
+	sel, err := e.openSelect(ctx, stmt, ctes)
+	if err != nil {
+		return nil, err
+	}
+	defer sel.cursor.Close()
+	if len(sel.columns) != 1 {
+		return nil, fmt.Errorf("subquery must return a single column, not %d", len(sel.columns))
+	}
+
+	values := []interface{}{}
+	for (limit == 0 || len(values) < limit) && sel.cursor.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		value, err := sel.cursor.ScanField(sel.columns[0])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, sel.cursor.Err()
+}
+
+// correlation splits the WHERE of a subquery into the conditions on its
+// own columns and the equalities of one of its columns with one of the
+// outer select, which must be joined by AND to the others. The keys name
+// the outer column on the left and the column of the subquery on the
+// right, both qualified.
+func (e *DefaultExecutor) correlation(ctx context.Context, sub *statement.SelectStatement, outer *joinScope, ctes *cteScope) (*filters.Filter, []storage.JoinKey, error) {
+	if sub.Where == nil {
+		return nil, nil, nil
+	}
+	ctes = newCTEScope(ctes, sub.With, sub.Recursive)
+	rel, err := e.relation(ctx, ctes, sub.Database, sub.Schema, sub.TableName, sub.IncludeDeleted)
+	if err != nil {
+		return nil, nil, err
+	}
+	inner, err := e.newJoinScope(ctx, sub, rel, ctes)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Columns of the subquery hide those of the outer select.
+	isOuter := func(column string) bool {
+		return !inner.owns(column) && outer.owns(column)
+	}
+
+	var local []*filters.Filter
+	var keys []storage.JoinKey
+	for _, f := range conjuncts(sub.Where) {
+		correlated := false
+		f.Walk(func(f *filters.Filter) {
+			if len(f.Children) > 0 {
+				return
+			}
+			columns := []string{f.Field}
+			if f.Expression != nil {
+				columns = f.Expression.Columns()
+			}
+			if slices.ContainsFunc(columns, isOuter) {
+				correlated = true
+			}
+		})
+		if !correlated {
+			local = append(local, f)
+			continue
+		}
+
+		left, right, ok := correlationColumns(f, isOuter)
+		if !ok {
+			return nil, nil, fmt.Errorf("condition %s of a subquery must compare a column of the subquery with one of the outer select by equality", f)
+		}
+		var key storage.JoinKey
+		if key.Left, err = outer.resolveColumn(left); err != nil {
+			return nil, nil, err
+		}
+		if key.Right, err = inner.resolveColumn(right); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+	}
+	return conjunction(local), keys, nil
+}
+
+// correlationColumns returns the outer and the inner column a condition
+// such as o.customer_id = c.id compares.
+func correlationColumns(f *filters.Filter, isOuter func(string) bool) (string, string, bool) {
+	e := f.Expression
+	if e == nil || e.Kind != expression.Binary || e.Operator != expression.Equal ||
+		e.Args[0].Kind != expression.Column || e.Args[1].Kind != expression.Column {
+		return "", "", false
+	}
+	l, r := e.Args[0].Name, e.Args[1].Name
+	switch {
+	case isOuter(l) && !isOuter(r):
+		return l, r, true
+	case isOuter(r) && !isOuter(l):
+		return r, l, true
+	}
+	return "", "", false
+}
+
+// semiJoinOf turns a correlated EXISTS or NOT EXISTS condition into a semi
+// join, and reports false for any other condition. Joined tells whether
+// the outer select has joins, whose rows name their columns qualified.
+func (e *DefaultExecutor) semiJoinOf(ctx context.Context, f *filters.Filter, outer *joinScope, ctes *cteScope, joined bool) (semiJoin, bool, error) {
+	sub, ok := f.Value.(statement.Subquery)
+	if !ok || (f.Operator != filters.Exists && f.Operator != filters.NotExists) {
+		return semiJoin{}, false, nil
+	}
+	where, keys, err := e.correlation(ctx, sub.Select, outer, ctes)
+	if err != nil || len(keys) == 0 {
+		return semiJoin{}, false, err
+	}
+	s := sub.Select
+	if s.Grouped() || len(s.Windows) > 0 || len(s.Unnest) > 0 || s.Limit != nil || s.Offset > 0 || len(s.SetOperations) > 0 {
+		return semiJoin{}, false, fmt.Errorf("correlated subqueries cannot use aggregates, window functions, UNNEST, LIMIT, OFFSET or set operations")
+	}
+
+	// The subquery only selects its keys. Without joins, its rows and those
+	// of the outer select name their columns bare.
+	right := *s
+	right.Where = where
+	right.Columns, right.Aggregations, right.Projections, right.OrderBy = nil, nil, nil, nil
+	semi := semiJoin{anti: f.Operator == filters.NotExists, sub: &right}
+	if len(s.Joins) == 0 {
+		semi.rightAlias = s.TableAlias()
+		if where != nil {
+			prefix := semi.rightAlias + "."
+			right.Where, _ = cloneFilter(where, func(field string) (string, error) {
+				return strings.TrimPrefix(field, prefix), nil
+			})
+		}
+	}
+	for _, key := range keys {
+		if !joined {
+			_, key.Left, _ = strings.Cut(key.Left, ".")
+		}
+		if len(s.Joins) == 0 {
+			_, key.Right, _ = strings.Cut(key.Right, ".")
+		}
+		if !slices.Contains(right.Columns, key.Right) {
+			right.Columns = append(right.Columns, key.Right)
+		}
+		semi.keys = append(semi.keys, key)
+	}
+	return semi, true, nil
+}
+
+// openSemiJoin joins cursor with the rows of the subquery of a semi join,
+// and extends annotate to the plan of the subquery. On failure cursor and
+// annotate are returned unchanged.
+func (e *DefaultExecutor) openSemiJoin(ctx context.Context, cursor storage.Cursor, semi semiJoin, annotate func(*storage.PlanNode), ctes *cteScope) (storage.Cursor, func(*storage.PlanNode), error) {
+	sub, err := e.openSelect(ctx, semi.sub, ctes)
+	if err != nil {
+		return cursor, annotate, err
+	}
+	config := storage.JoinConfig{
+		Type:        statement.SemiJoin,
+		Strategy:    statement.HashJoin,
+		RightAlias:  semi.rightAlias,
+		Keys:        semi.keys,
+		MemoryLimit: e.config.JoinMemoryLimit,
+		TempDir:     e.config.TempDir,
+	}
+	operation := "Hash Semi Join"
+	if semi.anti {
+		config.Type, operation = statement.AntiJoin, "Hash Anti Join"
+	}
+	joined, err := cursor.WithJoin(sub.cursor, config)
+	if err != nil {
+		sub.cursor.Close()
+		return cursor, annotate, err
+	}
+
+	// The outermost semi join of the tree is the last one opened.
+	return joined, func(node *storage.PlanNode) {
+		var join *storage.PlanNode
+		node.Walk(func(n *storage.PlanNode) {
+			if join == nil && n.Operation == operation {
+				join = n
+			}
+		})
+		if join == nil {
+			return
+		}
+		sub.annotate(join.Children[1])
+		annotate(join.Children[0])
+	}, nil
+}
@@ -0,0 +1,50 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestScalarSubqueries(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32)")
+	mustExec(t, e, ctx, "CREATE TABLE t2 (b INT32, c STRING(5))")
+	mustExec(t, e, ctx, "INSERT INTO t (a) VALUES (1), (2)")
+	mustExec(t, e, ctx, "INSERT INTO t2 (b, c) VALUES (5, 'x'), (7, 'y')")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT a, (SELECT MAX(b) FROM t2) AS m FROM t ORDER BY a", "[map[a:1 m:7] map[a:2 m:7]]"},
+		{"SELECT a, a + (SELECT MIN(b) FROM t2) AS s FROM t ORDER BY a", "[map[a:1 s:6] map[a:2 s:7]]"},
+		{"SELECT a, (SELECT b FROM t2 WHERE b > 10) AS m FROM t ORDER BY a", "[map[a:1 m:<nil>] map[a:2 m:<nil>]]"},
+		{"SELECT (SELECT c FROM t2 WHERE b = 7) AS c", "[map[c:y]]"},
+		{"SELECT a FROM t WHERE a + 5 < (SELECT MAX(b) FROM t2)", "[map[a:1]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	rejected := []string{
+		"SELECT a, (SELECT b FROM t2) AS m FROM t",
+		"SELECT a, (SELECT b, c FROM t2 WHERE b = 5) AS m FROM t",
+		"SELECT a, (SELECT b FROM t2 WHERE t2.b = t.a) AS m FROM t",
+	}
+	for _, sql := range rejected {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+
+	mustExec(t, e, ctx, "UPDATE t SET a = (SELECT MAX(b) FROM t2) + a WHERE a = 1")
+	if got := fmt.Sprint(selectRows(t, e, "SELECT a FROM t ORDER BY a")); got != "[map[a:2] map[a:8]]" {
+		t.Errorf("rows after UPDATE = %s", got)
+	}
+}
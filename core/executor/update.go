@@ -2,11 +2,202 @@ package executor
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/onnasoft/ZenithSQL/io/response"
 	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
 )
 
+// updatedRow is a row to overwrite: its new values, id included, and the
+// previous values of its indexed columns.
+type updatedRow struct {
+	values   map[string]interface{}
+	previous map[string]interface{}
+}
+
+// executeUpdate overwrites in place the assigned columns of the rows
+// matching the condition, and their updated_at. The rows and the values of
+// the expressions are all read before anything is written, so that a row
+// is updated once even when its new values still match the condition.
 func (e *DefaultExecutor) executeUpdate(ctx context.Context, stmt *statement.UpdateStatement) response.Response {
-	return response.NewUpdateResponse(false, "not implemented")
+	startTime := time.Now()
+	fail := func(err error, rows int64) response.Response {
+		return response.NewUpdateResponse(false, err.Error(), rows, time.Since(startTime).Milliseconds())
+	}
+
+	table, err := e.catalog.GetTable(stmt.Database, stmt.Schema, stmt.TableName)
+	if err != nil {
+		return fail(err, 0)
+	}
+
+	table.LockInsert()
+	defer table.UnlockInsert()
+
+	rows, err := e.updatedRows(ctx, table, stmt)
+	if err != nil {
+		return fail(err, 0)
+	}
+
+	now := time.Now()
+	values := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		row.values["updated_at"] = now
+		coerceRow(table, row.values)
+		values[i] = row.values
+	}
+	if err := table.CheckUnique(values); err != nil {
+		return fail(err, 0)
+	}
+
+	writer, err := table.Writer()
+	if err != nil {
+		return fail(err, 0)
+	}
+	defer writer.Close()
+
+	for _, row := range rows {
+		select {
+		case <-ctx.Done():
+			writer.Rollback()
+			return fail(ctx.Err(), 0)
+		default:
+		}
+
+		if err := writer.Update(row.values); err != nil {
+			writer.Rollback()
+			return fail(err, 0)
+		}
+	}
+	if err := writer.Commit(); err != nil {
+		writer.Rollback()
+		return fail(err, 0)
+	}
+
+	if err := reindexRows(table, rows); err != nil {
+		return fail(err, int64(len(rows)))
+	}
+
+	return response.NewUpdateResponse(true, "updated successfully", int64(len(rows)), time.Since(startTime).Milliseconds())
+}
+
+// updatedRows selects the rows stmt updates with their new values, and the
+// previous values of the assigned columns that are indexed.
+func (e *DefaultExecutor) updatedRows(ctx context.Context, table *catalog.Table, stmt *statement.UpdateStatement) ([]updatedRow, error) {
+	sel := &statement.SelectStatement{
+		Database:  stmt.Database,
+		Schema:    stmt.Schema,
+		TableName: stmt.TableName,
+		Columns:   []string{"id"},
+		Where:     stmt.Where,
+	}
+	var indexed []string
+	check := func(column string) error {
+		if _, err := table.GetFieldMeta(column); err != nil {
+			return fmt.Errorf("column %s not found in table %s", column, stmt.TableName)
+		}
+		if len(table.Indexes(column)) > 0 {
+			indexed = append(indexed, column)
+		}
+		return nil
+	}
+	for column := range stmt.Updates {
+		if err := check(column); err != nil {
+			return nil, err
+		}
+	}
+	// Expressions written alike are computed once.
+	computed := make(map[string]string, len(stmt.Expressions))
+	for column, expr := range stmt.Expressions {
+		if err := check(column); err != nil {
+			return nil, err
+		}
+		projection := statement.Projection{Expression: expr}
+		name := projection.Name()
+		if !slices.ContainsFunc(sel.Projections, func(p statement.Projection) bool { return p.Name() == name }) {
+			sel.Projections = append(sel.Projections, projection)
+		}
+		computed[column] = name
+	}
+	sel.Columns = append(sel.Columns, indexed...)
+
+	opened, err := e.openSelect(ctx, sel, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer opened.cursor.Close()
+
+	now := time.Now()
+	var rows []updatedRow
+	for opened.cursor.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		id, err := opened.cursor.ScanField("id")
+		if err != nil {
+			return nil, err
+		}
+		row := updatedRow{values: make(map[string]interface{}, len(stmt.Updates)+len(stmt.Expressions)+2)}
+		row.values["id"] = id
+		for column, value := range stmt.Updates {
+			row.values[column] = resolveValue(cloneValue(value), now)
+		}
+		for column, name := range computed {
+			if row.values[column], err = opened.cursor.ScanField(name); err != nil {
+				return nil, err
+			}
+		}
+		if len(indexed) > 0 {
+			row.previous = make(map[string]interface{}, len(indexed))
+			for _, column := range indexed {
+				if row.previous[column], err = opened.cursor.ScanField(column); err != nil {
+					return nil, err
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, opened.cursor.Err()
+}
+
+// cloneValue copies lists, which resolveValue resolves in place, so that
+// every row gets its own.
+func cloneValue(value interface{}) interface{} {
+	if list, ok := value.([]interface{}); ok {
+		cloned := make([]interface{}, len(list))
+		for i, elem := range list {
+			cloned[i] = cloneValue(elem)
+		}
+		return cloned
+	}
+	return value
+}
+
+// reindexRows moves the updated rows from their previous values to their
+// new ones in the indexes of the assigned columns.
+func reindexRows(table *catalog.Table, rows []updatedRow) error {
+	for _, row := range rows {
+		id := row.values["id"].(int64)
+		for column, previous := range row.previous {
+			value := row.values[column]
+			for _, idx := range table.Indexes(column) {
+				if previous != nil {
+					if err := idx.Remove(previous, id); err != nil {
+						return fmt.Errorf("failed to update index %s: %w", idx.Name, err)
+					}
+				}
+				if value != nil {
+					if err := idx.Add(value, id); err != nil {
+						return fmt.Errorf("failed to update index %s: %w", idx.Name, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,112 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestUpdateRollback(t *testing.T) {
+	path := t.TempDir()
+	e, cat := openExecutor(t, path, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE DATABASE db")
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, n INT8, s STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, n, s) VALUES (1, 10, 'x'), (2, 20, 'y'), (3, 30, 'x')")
+	mustExec(t, e, ctx, "CREATE INDEX t_n ON t (n)")
+	mustExec(t, e, ctx, "CREATE INDEX t_s ON t (s)")
+	before := fmt.Sprint(selectRows(t, e, "SELECT id, a, n, s, updated_at FROM t ORDER BY id"))
+
+	// The first two rows are written before the third overflows n.
+	if resp := run(e, ctx, "UPDATE t SET n = a * 60, s = 'z'"); resp.IsSuccess() {
+		t.Fatal("UPDATE stored a value out of the range of its column")
+	}
+
+	check := func(when string) {
+		t.Helper()
+		if got := fmt.Sprint(selectRows(t, e, "SELECT id, a, n, s, updated_at FROM t ORDER BY id")); got != before {
+			t.Errorf("rows %s = %s, want %s", when, got, before)
+		}
+		tests := []struct {
+			sql  string
+			want string
+		}{
+			{"SELECT a FROM t WHERE s = 'z'", "[]"},
+			{"SELECT a FROM t WHERE n = 60", "[]"},
+			{"SELECT a FROM t WHERE s = 'x' ORDER BY a", "[map[a:1] map[a:3]]"},
+			{"SELECT a FROM t WHERE n = 20", "[map[a:2]]"},
+		}
+		for _, tt := range tests {
+			if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+				t.Errorf("%s: %s = %s, want %s", when, tt.sql, got, tt.want)
+			}
+		}
+	}
+	check("after the rollback")
+
+	cat.Close()
+	e, _ = openExecutor(t, path, executor.Config{})
+	check("after reopening")
+}
+
+func TestUpdateShorterString(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32, s STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (a, s) VALUES (1, 'null-a'), (2, 'abcdef')")
+	mustExec(t, e, ctx, "UPDATE t SET s = 'hit' WHERE a = 1")
+	mustExec(t, e, ctx, "UPDATE t SET s = NULL WHERE a = 2")
+	mustExec(t, e, ctx, "UPDATE t SET s = 'xy' WHERE a = 2")
+
+	want := "[map[a:1 s:hit] map[a:2 s:xy]]"
+	if got := fmt.Sprint(selectRows(t, e, "SELECT a, s FROM t ORDER BY a")); got != want {
+		t.Errorf("rows = %s, want %s", got, want)
+	}
+}
+
+func TestInsertRollback(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (n INT8, s STRING(10))")
+
+	// The first row is written before the second overflows n.
+	if resp := run(e, ctx, "INSERT INTO t (n, s) VALUES (1, 'longvalue'), (1000, 'x')"); resp.IsSuccess() {
+		t.Fatal("INSERT stored a value out of the range of its column")
+	}
+	mustExec(t, e, ctx, "INSERT INTO t (n, s) VALUES (2, 'ab')")
+
+	want := "[map[n:2 s:ab]]"
+	if got := fmt.Sprint(selectRows(t, e, "SELECT n, s FROM t")); got != want {
+		t.Errorf("rows = %s, want %s", got, want)
+	}
+}
+
+func TestUpdateSkipsNulls(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (k INT32, a INT32, s STRING(10), n INT32)")
+	mustExec(t, e, ctx, "INSERT INTO t (k, a, s, n) VALUES (1, 0, 'x', 0), (2, 5, 'y', 0)")
+	mustExec(t, e, ctx, "INSERT INTO t (k, n) VALUES (3, 0)")
+
+	// A null compares as neither equal nor unequal to any value.
+	tests := []struct {
+		where string
+		want  string
+	}{
+		{"a = 0", "[map[k:1 n:1] map[k:2 n:0] map[k:3 n:0]]"},
+		{"a < 1", "[map[k:1 n:2] map[k:2 n:0] map[k:3 n:0]]"},
+		{"a <> 0", "[map[k:1 n:2] map[k:2 n:1] map[k:3 n:0]]"},
+		{"a NOT IN (1, 2)", "[map[k:1 n:3] map[k:2 n:2] map[k:3 n:0]]"},
+		{"s <> 'x'", "[map[k:1 n:3] map[k:2 n:3] map[k:3 n:0]]"},
+		{"s NOT IN ('x')", "[map[k:1 n:3] map[k:2 n:4] map[k:3 n:0]]"},
+		{"s NOT LIKE 'x%'", "[map[k:1 n:3] map[k:2 n:5] map[k:3 n:0]]"},
+	}
+	for _, tt := range tests {
+		mustExec(t, e, ctx, "UPDATE t SET n = n + 1 WHERE "+tt.where)
+		if got := fmt.Sprint(selectRows(t, e, "SELECT k, n FROM t ORDER BY k")); got != tt.want {
+			t.Errorf("WHERE %s: rows = %s, want %s", tt.where, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/response"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// upsertTarget is a row the rows of an upsert holding its key conflict
+// with: a row of the table, whose indexed columns are in previous, or a row
+// inserted by the upsert. current holds the columns the expressions of the
+// conflict clause read, as the upsert leaves them.
+type upsertTarget struct {
+	updatedRow
+	current  map[string]interface{}
+	existing bool
+	updated  bool
+}
+
+// conflictUpdate computes the values DO UPDATE sets. Its expressions are
+// prepared for scanners reading the columns of current, the row of the
+// table, and those of excluded, the row upserted.
+type conflictUpdate struct {
+	clause      statement.ConflictClause
+	key         string
+	expressions map[string]*expression.Expression
+	// reads are the columns of the row of the table the expressions read.
+	reads             []string
+	current, excluded map[string]interface{}
+}
+
+func newConflictUpdate(table *catalog.Table, clause statement.ConflictClause, key string) (*conflictUpdate, error) {
+	u := &conflictUpdate{
+		clause:      clause,
+		key:         key,
+		expressions: make(map[string]*expression.Expression, len(clause.Expressions)),
+	}
+	for column := range clause.Updates {
+		if _, err := table.GetFieldMeta(column); err != nil {
+			return nil, fmt.Errorf("column %s not found in table %s", column, table.Name)
+		}
+	}
+
+	scanMap := make(map[string]*buffer.Scanner)
+	for column, expr := range clause.Expressions {
+		if _, err := table.GetFieldMeta(column); err != nil {
+			return nil, fmt.Errorf("column %s not found in table %s", column, table.Name)
+		}
+		for _, name := range expr.Columns() {
+			if _, ok := scanMap[name]; ok {
+				continue
+			}
+			row, read := &u.current, strings.TrimPrefix(name, statement.ExcludedPrefix)
+			if read != name {
+				row = &u.excluded
+			} else {
+				u.reads = append(u.reads, read)
+			}
+			meta, err := table.GetFieldMeta(read)
+			if err != nil {
+				return nil, fmt.Errorf("column %s not found in table %s", name, table.Name)
+			}
+			scanMap[name] = &buffer.Scanner{
+				Type: meta.DataType(),
+				Scan: func(value interface{}) (bool, error) {
+					return scanValue((*row)[read], value)
+				},
+				Nullable: true,
+			}
+		}
+
+		// The clause is shared by the executions of a prepared statement:
+		// each prepares its own copy.
+		prepared, err := expr.Rename(func(name string) (string, error) { return name, nil })
+		if err != nil {
+			return nil, err
+		}
+		if err := prepared.Prepare(scanMap); err != nil {
+			return nil, fmt.Errorf("column %s: %w", column, err)
+		}
+		u.expressions[column] = prepared
+	}
+	return u, nil
+}
+
+// values returns the values the clause sets in target, which row upserted
+// conflicts with, keyed by column.
+func (u *conflictUpdate) values(table *catalog.Table, target *upsertTarget, row map[string]interface{}, now time.Time) (map[string]interface{}, error) {
+	update := make(map[string]interface{})
+	for _, column := range u.clause.Columns(row, u.key) {
+		update[column] = resolveValue(cloneValue(row[column]), now)
+	}
+	for column, value := range u.clause.Updates {
+		update[column] = resolveValue(cloneValue(value), now)
+	}
+	if len(u.expressions) > 0 {
+		u.current = target.current
+		u.excluded = make(map[string]interface{}, len(row))
+		for column, value := range row {
+			u.excluded[column] = resolveValue(cloneValue(value), now)
+		}
+		coerceRow(table, u.excluded)
+		for column, expr := range u.expressions {
+			value, err := expr.Eval()
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", column, err)
+			}
+			update[column] = value
+		}
+	}
+	coerceRow(table, update)
+	return update, nil
+}
+
+// scanValue stores value into the pointer dest the way a cursor scanner
+// does, reporting false for nulls.
+func scanValue(value, dest interface{}) (bool, error) {
+	if value == nil {
+		return false, nil
+	}
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return false, fmt.Errorf("output must be a non-nil pointer, got %T", dest)
+	}
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(target.Elem().Type()) {
+		return false, fmt.Errorf("cannot assign %T to %s", value, target.Elem().Type())
+	}
+	target.Elem().Set(v)
+	return true, nil
+}
+
+func (e *DefaultExecutor) executeUpsert(ctx context.Context, stmt *statement.UpsertStatement) response.Response {
+	startTime := time.Now()
+	table, err := e.catalog.GetTable(stmt.Database, stmt.Schema, stmt.TableName)
+	if err != nil {
+		return response.NewUpsertResponse(false, err.Error(), nil, time.Since(startTime).Milliseconds())
+	}
+
+	table.LockInsert()
+	defer table.UnlockInsert()
+
+	results, err := e.upsert(ctx, table, stmt.Database, stmt.Schema, stmt.Values, stmt.OnConflict)
+	if err != nil {
+		return response.NewUpsertResponse(false, err.Error(), nil, time.Since(startTime).Milliseconds())
+	}
+
+	return response.NewUpsertResponse(true, "upserted successfully", results, time.Since(startTime).Milliseconds())
+}
+
+// upsert inserts the rows of values whose key no row of the table holds,
+// and updates or skips the others as clause says. A row holding the key of
+// a row inserted before it by the same upsert conflicts with that row.
+func (e *DefaultExecutor) upsert(ctx context.Context, table *catalog.Table, database, schema string, values []map[string]interface{}, clause statement.ConflictClause) ([]response.UpsertResult, error) {
+	key, dataType, err := conflictKey(table, clause.UniqueKey)
+	if err != nil {
+		return nil, err
+	}
+	conflict, err := newConflictUpdate(table, clause, key)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := e.conflictTargets(ctx, table, database, schema, key, dataType, conflict.reads, values)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := table.Writer()
+	if err != nil {
+		return nil, err
+	}
+	defer writer.Close()
+	fail := func(err error) ([]response.UpsertResult, error) {
+		writer.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	id := table.GetNextID()
+	results := make([]response.UpsertResult, len(values))
+	var insertedIDs []interface{}
+	var insertedRows []map[string]interface{}
+	var updated []*upsertTarget
+	for i, row := range values {
+		select {
+		case <-ctx.Done():
+			return fail(ctx.Err())
+		default:
+		}
+
+		k, hasKey := conflictValue(dataType, row[key])
+		target, ok := targets[k]
+		if !hasKey || !ok {
+			inserted := newRow(table, row, id, now)
+			if err := writer.Write(inserted); err != nil {
+				return fail(err)
+			}
+			results[i] = response.UpsertResult{ID: id, Action: response.Inserted}
+			insertedIDs = append(insertedIDs, id)
+			insertedRows = append(insertedRows, inserted)
+			if hasKey {
+				targets[k] = &upsertTarget{updatedRow: updatedRow{values: inserted}, current: inserted}
+			}
+			id++
+			continue
+		}
+
+		targetID := target.values["id"].(int64)
+		if clause.Action == statement.DoNothing {
+			results[i] = response.UpsertResult{ID: targetID, Action: response.Unchanged}
+			continue
+		}
+		update, err := conflict.values(table, target, row, now)
+		if err != nil {
+			return fail(err)
+		}
+		if len(update) == 0 {
+			results[i] = response.UpsertResult{ID: targetID, Action: response.Unchanged}
+			continue
+		}
+		update["id"] = targetID
+		update["updated_at"] = now
+		if err := writer.Update(update); err != nil {
+			return fail(err)
+		}
+		maps.Copy(target.values, update)
+		maps.Copy(target.current, update)
+		// A row whose key is set to another value conflicts with the
+		// rows holding the new one.
+		if value, ok := update[key]; ok {
+			if moved, hasKey := conflictValue(dataType, value); moved != k {
+				delete(targets, k)
+				if hasKey {
+					targets[moved] = target
+				}
+			}
+		}
+		if target.existing && !target.updated {
+			target.updated = true
+			updated = append(updated, target)
+		}
+		results[i] = response.UpsertResult{ID: targetID, Action: response.Updated}
+	}
+
+	// The rows are checked as the upsert leaves them: the inserted rows
+	// hold the updates made to them.
+	written := slices.Clone(insertedRows)
+	for _, target := range updated {
+		written = append(written, target.values)
+	}
+	if err := table.CheckUnique(written); err != nil {
+		return fail(err)
+	}
+
+	if err := table.UpdateRowCount(table.RowCount() + int64(len(insertedIDs))); err != nil {
+		return fail(err)
+	}
+	if err := writer.Commit(); err != nil {
+		return fail(err)
+	}
+
+	if err := indexRows(table, insertedIDs, insertedRows); err != nil {
+		return nil, err
+	}
+	rows := make([]updatedRow, len(updated))
+	for i, target := range updated {
+		rows[i] = target.updatedRow
+		maps.DeleteFunc(rows[i].previous, func(column string, _ interface{}) bool {
+			_, ok := target.values[column]
+			return !ok
+		})
+	}
+	if err := reindexRows(table, rows); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// conflictTargets returns the rows of the table holding the keys of
+// values, by key, with the values of their indexed columns and of the
+// columns reads.
+func (e *DefaultExecutor) conflictTargets(ctx context.Context, table *catalog.Table, database, schema, key string, dataType fields.DataType, reads []string, values []map[string]interface{}) (map[string]*upsertTarget, error) {
+	targets := make(map[string]*upsertTarget)
+	wanted := make(map[string]bool)
+	var keys []interface{}
+	for _, row := range values {
+		if k, ok := conflictValue(dataType, row[key]); ok && !wanted[k] {
+			wanted[k] = true
+			keys = append(keys, row[key])
+		}
+	}
+	if len(keys) == 0 {
+		return targets, nil
+	}
+
+	sel := &statement.SelectStatement{
+		Database:  database,
+		Schema:    schema,
+		TableName: table.Name,
+		Columns:   []string{"id", key},
+	}
+	var indexed []string
+	for _, idx := range table.Indexes("") {
+		if !slices.Contains(indexed, idx.Column) {
+			indexed = append(indexed, idx.Column)
+		}
+	}
+	for _, column := range slices.Concat(indexed, reads) {
+		if !slices.Contains(sel.Columns, column) {
+			sel.Columns = append(sel.Columns, column)
+		}
+	}
+	// The planner looks the keys up in an index on the key column. Without
+	// one every row is read, and looking the keys up here is cheaper than
+	// the IN filter.
+	if slices.Contains(indexed, key) {
+		sel.Where = filters.NewCondition(key, filters.In, keys)
+	}
+
+	opened, err := e.openSelect(ctx, sel, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer opened.cursor.Close()
+
+	for opened.cursor.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		value, err := opened.cursor.ScanField(key)
+		if err != nil {
+			return nil, err
+		}
+		k, ok := conflictValue(dataType, value)
+		if !ok || !wanted[k] {
+			continue
+		}
+		if _, ok := targets[k]; ok {
+			return nil, fmt.Errorf("column %s is not unique: several rows hold %v", key, value)
+		}
+
+		id, err := opened.cursor.ScanField("id")
+		if err != nil {
+			return nil, err
+		}
+		target := &upsertTarget{
+			updatedRow: updatedRow{
+				values:   map[string]interface{}{"id": id},
+				previous: make(map[string]interface{}, len(indexed)),
+			},
+			current:  make(map[string]interface{}, len(reads)),
+			existing: true,
+		}
+		for _, column := range indexed {
+			if target.previous[column], err = opened.cursor.ScanField(column); err != nil {
+				return nil, err
+			}
+		}
+		for _, column := range reads {
+			if target.current[column], err = opened.cursor.ScanField(column); err != nil {
+				return nil, err
+			}
+		}
+		targets[k] = target
+	}
+	return targets, opened.cursor.Err()
+}
+
+// conflictKey returns the column an upsert is keyed on: name, or the column
+// of the index called name. The key has to be unique: the id, or a column
+// a unique index is on.
+func conflictKey(table *catalog.Table, name string) (string, fields.DataType, error) {
+	meta, err := table.GetFieldMeta(name)
+	if err == nil {
+		unique := name == "id" || slices.ContainsFunc(table.Indexes(name), func(idx *catalog.TableIndex) bool { return idx.Unique })
+		if !unique {
+			return "", nil, fmt.Errorf("column %s of table %s has no unique index to upsert on", name, table.Name)
+		}
+		return meta.Name, meta.DataType(), nil
+	}
+
+	idx, ok := table.Index(name)
+	if !ok {
+		return "", nil, fmt.Errorf("unique key %s is neither a column nor an index of table %s", name, table.Name)
+	}
+	if !idx.Unique {
+		return "", nil, fmt.Errorf("index %s of table %s is not unique", name, table.Name)
+	}
+	if meta, err = table.GetFieldMeta(idx.Column); err != nil {
+		return "", nil, err
+	}
+	return meta.Name, meta.DataType(), nil
+}
+
+// conflictValue returns the value of a key as compared by an upsert,
+// reporting false for nulls, which conflict with nothing.
+func conflictValue(dataType fields.DataType, value interface{}) (string, bool) {
+	switch v := fields.Coerce(dataType, value).(type) {
+	case nil:
+		return "", false
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
@@ -0,0 +1,110 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+	"github.com/onnasoft/ZenithSQL/io/response"
+)
+
+func TestUpsertConflicts(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (k INT32, n INT32, s STRING(10))")
+	mustExec(t, e, ctx, "INSERT INTO t (k, n, s) VALUES (1, 10, 'a'), (2, 20, 'b')")
+	mustExec(t, e, ctx, "CREATE UNIQUE INDEX t_k ON t (k)")
+
+	tests := []struct {
+		sql     string
+		results string
+		want    string
+	}{
+		{
+			"INSERT INTO t (k, n) VALUES (1, 5), (3, 30) ON CONFLICT (k) DO NOTHING",
+			"[{1 unchanged} {3 inserted}]",
+			"[map[k:1 n:10 s:a] map[k:2 n:20 s:b] map[k:3 n:30 s:<nil>]]",
+		},
+		{
+			"INSERT INTO t (k, n, s) VALUES (2, 7, 'x') ON CONFLICT (k) DO UPDATE",
+			"[{2 updated}]",
+			"[map[k:1 n:10 s:a] map[k:2 n:7 s:x] map[k:3 n:30 s:<nil>]]",
+		},
+		{
+			// Rows conflict with the rows the upsert inserted or updated
+			// before them.
+			"INSERT INTO t (k, n) VALUES (1, 1), (1, 2), (4, 40), (4, 1) ON CONFLICT (t_k) DO UPDATE SET n = n + excluded.n, s = 'z'",
+			"[{1 updated} {1 updated} {4 inserted} {4 updated}]",
+			"[map[k:1 n:13 s:z] map[k:2 n:7 s:x] map[k:3 n:30 s:<nil>] map[k:4 n:41 s:z]]",
+		},
+		{
+			"INSERT INTO t (k, n) VALUES (2, 0) ON CONFLICT (k) DO UPDATE SET n = 3",
+			"[{2 updated}]",
+			"[map[k:1 n:13 s:z] map[k:2 n:3 s:x] map[k:3 n:30 s:<nil>] map[k:4 n:41 s:z]]",
+		},
+		{
+			// A row whose key moves conflicts with the rows holding its
+			// new key.
+			"INSERT INTO t (k) VALUES (3), (13) ON CONFLICT (k) DO UPDATE SET k = k + 10",
+			"[{3 updated} {3 updated}]",
+			"[map[k:1 n:13 s:z] map[k:2 n:3 s:x] map[k:4 n:41 s:z] map[k:23 n:30 s:<nil>]]",
+		},
+	}
+	for _, tt := range tests {
+		resp := mustExec(t, e, ctx, tt.sql).(*response.UpsertResponse)
+		if got := fmt.Sprint(resp.Results); got != tt.results {
+			t.Errorf("%s: results = %s, want %s", tt.sql, got, tt.results)
+		}
+		if got := fmt.Sprint(selectRows(t, e, "SELECT k, n, s FROM t ORDER BY k")); got != tt.want {
+			t.Errorf("%s: rows = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	mustExec(t, e, ctx, "CREATE INDEX t_s ON t (s)")
+	rejected := []string{
+		"INSERT INTO t (k, n) VALUES (1, 1) ON CONFLICT (n) DO NOTHING",
+		"INSERT INTO t (k, n) VALUES (1, 1) ON CONFLICT (t_s) DO NOTHING",
+		"INSERT INTO t (k, n) VALUES (1, 1) ON CONFLICT (k) DO UPDATE SET n = m + 1",
+		"INSERT INTO t (k, n) VALUES (1, 1) ON CONFLICT (k) DO UPDATE SET n = s + 1",
+		"INSERT INTO t (k, n) VALUES (1, 1), (5, 1) ON CONFLICT (k) DO UPDATE SET k = 2",
+		"INSERT INTO t (k, n) VALUES (1, 1)",
+	}
+	for _, sql := range rejected {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+	want := "[map[k:1 n:13 s:z] map[k:2 n:3 s:x] map[k:4 n:41 s:z] map[k:23 n:30 s:<nil>]]"
+	if got := fmt.Sprint(selectRows(t, e, "SELECT k, n, s FROM t ORDER BY k")); got != want {
+		t.Errorf("rows after the rejected upserts = %s, want %s", got, want)
+	}
+}
+
+func TestUpsertOverwrites(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (k INT32, n INT32, s STRING(10))")
+	mustExec(t, e, ctx, "CREATE UNIQUE INDEX t_s ON t (s)")
+	mustExec(t, e, ctx, "CREATE UNIQUE INDEX t_k ON t (k)")
+	mustExec(t, e, ctx, "INSERT INTO t (k, n, s) VALUES (1, 10, 'abcdef')")
+
+	// A shorter value leaves nothing of the longer one, in the row and in
+	// the index.
+	mustExec(t, e, ctx, "INSERT INTO t (k, s) VALUES (1, 'abcdef') ON CONFLICT (s) DO UPDATE SET s = 'xy'")
+	mustExec(t, e, ctx, "INSERT INTO t (k, n) VALUES (1, 5) ON CONFLICT (k) DO UPDATE SET n = t.n + excluded.n")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT k, n, s FROM t", "[map[k:1 n:15 s:xy]]"},
+		{"SELECT k FROM t WHERE s = 'xy'", "[map[k:1]]"},
+		{"SELECT k FROM t WHERE s = 'abcdef'", "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+}
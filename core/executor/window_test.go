@@ -0,0 +1,69 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestWindowFrames(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (g STRING(10), k INT32, x INT32)")
+	mustExec(t, e, ctx, `INSERT INTO t (g, k, x) VALUES
+		('a', 1, 1), ('a', 2, 2), ('a', 2, 3), ('a', 4, 4), ('a', 7, 5),
+		('b', 1, 10), ('b', 3, 20)`)
+	mustExec(t, e, ctx, "CREATE TABLE d (day DATE, x INT32)")
+	mustExec(t, e, ctx, "INSERT INTO d (day, x) VALUES (DATE '2024-01-01', 1), (DATE '2024-01-02', 2), (DATE '2024-01-04', 4)")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT x, SUM(x) OVER (PARTITION BY g ORDER BY k, x ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) AS s FROM t ORDER BY x",
+			"[map[s:1 x:1] map[s:3 x:2] map[s:5 x:3] map[s:7 x:4] map[s:9 x:5] map[s:10 x:10] map[s:30 x:20]]"},
+		{"SELECT x, COUNT(x) OVER (PARTITION BY g ORDER BY k, x ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING) AS n FROM t ORDER BY x",
+			"[map[n:5 x:1] map[n:4 x:2] map[n:3 x:3] map[n:2 x:4] map[n:1 x:5] map[n:2 x:10] map[n:1 x:20]]"},
+		// A frame past the last row is empty.
+		{"SELECT x, MAX(x) OVER (ORDER BY x ROWS BETWEEN 1 FOLLOWING AND 2 FOLLOWING) AS m FROM t ORDER BY x",
+			"[map[m:3 x:1] map[m:4 x:2] map[m:5 x:3] map[m:10 x:4] map[m:20 x:5] map[m:20 x:10] map[m:<nil> x:20]]"},
+		// With ORDER BY, the default frame runs from the start of the
+		// partition to the last peer of the row.
+		{"SELECT x, SUM(x) OVER (PARTITION BY g ORDER BY k) AS s FROM t ORDER BY x",
+			"[map[s:1 x:1] map[s:6 x:2] map[s:6 x:3] map[s:10 x:4] map[s:15 x:5] map[s:10 x:10] map[s:30 x:20]]"},
+		// Without it, the frame is the whole partition.
+		{"SELECT x, SUM(x) OVER (PARTITION BY g) AS s FROM t ORDER BY x",
+			"[map[s:15 x:1] map[s:15 x:2] map[s:15 x:3] map[s:15 x:4] map[s:15 x:5] map[s:30 x:10] map[s:30 x:20]]"},
+		{"SELECT x, SUM(x) OVER (PARTITION BY g ORDER BY k RANGE BETWEEN 2 PRECEDING AND CURRENT ROW) AS s FROM t ORDER BY x",
+			"[map[s:1 x:1] map[s:6 x:2] map[s:6 x:3] map[s:9 x:4] map[s:5 x:5] map[s:10 x:10] map[s:30 x:20]]"},
+		{"SELECT x, SUM(x) OVER (ORDER BY day RANGE BETWEEN INTERVAL '1 day' PRECEDING AND CURRENT ROW) AS s FROM d ORDER BY x",
+			"[map[s:1 x:1] map[s:3 x:2] map[s:4 x:4]]"},
+		{"SELECT x, FIRST_VALUE(x) OVER (PARTITION BY g ORDER BY k, x) AS f, LAST_VALUE(x) OVER (PARTITION BY g ORDER BY k, x) AS l FROM t ORDER BY x",
+			"[map[f:1 l:1 x:1] map[f:1 l:2 x:2] map[f:1 l:3 x:3] map[f:1 l:4 x:4] map[f:1 l:5 x:5] map[f:10 l:10 x:10] map[f:10 l:20 x:20]]"},
+		{"SELECT x, LAST_VALUE(x) OVER (PARTITION BY g ORDER BY k, x ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS l, NTH_VALUE(x, 2) OVER (PARTITION BY g ORDER BY k, x) AS n FROM t ORDER BY x",
+			"[map[l:5 n:<nil> x:1] map[l:5 n:2 x:2] map[l:5 n:2 x:3] map[l:5 n:2 x:4] map[l:5 n:2 x:5] map[l:20 n:<nil> x:10] map[l:20 n:20 x:20]]"},
+		// Ranking and offset functions ignore the frame.
+		{"SELECT x, RANK() OVER (PARTITION BY g ORDER BY k) AS r, LAG(x) OVER (PARTITION BY g ORDER BY k, x ROWS BETWEEN CURRENT ROW AND CURRENT ROW) AS p FROM t ORDER BY x",
+			"[map[p:<nil> r:1 x:1] map[p:1 r:2 x:2] map[p:2 r:2 x:3] map[p:3 r:4 x:4] map[p:4 r:5 x:5] map[p:<nil> r:1 x:10] map[p:10 r:2 x:20]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	for _, sql := range []string{
+		"SELECT SUM(x) OVER (ORDER BY x ROWS BETWEEN CURRENT ROW AND 1 PRECEDING) AS s FROM t",
+		"SELECT SUM(x) OVER (ORDER BY x ROWS BETWEEN UNBOUNDED FOLLOWING AND CURRENT ROW) AS s FROM t",
+		"SELECT SUM(x) OVER (RANGE BETWEEN 1 PRECEDING AND CURRENT ROW) AS s FROM t",
+		"SELECT SUM(x) OVER (ORDER BY k, x RANGE BETWEEN 1 PRECEDING AND CURRENT ROW) AS s FROM t",
+		"SELECT SUM(x) OVER (ORDER BY x RANGE BETWEEN INTERVAL '1 day' PRECEDING AND CURRENT ROW) AS s FROM t",
+		"SELECT SUM(x) OVER (ORDER BY x ROWS BETWEEN INTERVAL '1 day' PRECEDING AND CURRENT ROW) AS s FROM t",
+	} {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+}
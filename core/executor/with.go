@@ -0,0 +1,370 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/planner"
+	"github.com/onnasoft/ZenithSQL/core/providers/columnstorage"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+)
+
+// relation is what a select reads in FROM or a join: a table of the
+// catalog or a common table expression.
+type relation interface {
+	// columns returns the names of the columns, in order.
+	columns() ([]string, error)
+	// plan returns the optimized plan of the rows matching query.
+	plan(query storage.Query) (storage.QueryPlan, error)
+}
+
+// tableRelation reads a table of the catalog, and its deleted rows with
+// includeDeleted.
+type tableRelation struct {
+	table          *catalog.Table
+	includeDeleted bool
+}
+
+func (r tableRelation) columns() ([]string, error) {
+	metas, err := r.table.ListFields()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(metas))
+	for i, meta := range metas {
+		columns[i] = meta.Name
+	}
+	return columns, nil
+}
+
+func (r tableRelation) plan(query storage.Query) (storage.QueryPlan, error) {
+	query.IncludeDeleted = r.includeDeleted
+	plan, err := planner.New(r.table).CreatePlan(query)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Optimize(), nil
+}
+
+// singleRowRelation is what a select without FROM reads: a single row
+// without columns, over which its select list is computed.
+type singleRowRelation struct{}
+
+func (singleRowRelation) columns() ([]string, error) {
+	return nil, nil
+}
+
+func (singleRowRelation) plan(query storage.Query) (storage.QueryPlan, error) {
+	return &singleRowPlan{query: query}, nil
+}
+
+// singleRowPlan reads the row of a select without FROM. It implements
+// storage.QueryPlan.
+type singleRowPlan struct {
+	query storage.Query
+}
+
+func (p *singleRowPlan) Explain() string {
+	return "Result"
+}
+
+func (p *singleRowPlan) Optimize() storage.QueryPlan {
+	return p
+}
+
+func (p *singleRowPlan) Execute() (storage.Cursor, error) {
+	rows := []map[string]interface{}{{}}
+	return applyQuery(columnstorage.NewValuesCursor(rows, map[string]*buffer.Scanner{}), p.query)
+}
+
+func (p *singleRowPlan) Annotate(*storage.PlanNode) {}
+
+// relation returns the common table expression in scope called name, or
+// else the table of the catalog, which also returns its deleted rows with
+// includeDeleted. An empty name is the single row a select without FROM
+// reads.
+func (e *DefaultExecutor) relation(ctx context.Context, ctes *cteScope, database, schema, name string, includeDeleted bool) (relation, error) {
+	if name == "" {
+		return singleRowRelation{}, nil
+	}
+	if b := ctes.lookup(name); b != nil {
+		if includeDeleted {
+			return nil, fmt.Errorf("WITH DELETED cannot be used with common table expression %s", name)
+		}
+		return &cteRelation{e: e, ctx: ctx, binding: b}, nil
+	}
+	table, err := e.catalog.GetTable(database, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	return tableRelation{table: table, includeDeleted: includeDeleted}, nil
+}
+
+// cteScope is a common table expression a select can read, linked to
+// those defined before it and by the selects it is nested in.
+type cteScope struct {
+	parent  *cteScope
+	binding *cteBinding
+}
+
+// cteBinding is a common table expression in scope: its select and the
+// scope the select runs in, or while a step of its recursion runs, the
+// work table holding the rows of the previous step.
+type cteBinding struct {
+	cte       statement.CommonTableExpression
+	scope     *cteScope
+	recursive bool
+	columns   []string
+	work      storage.Cursor
+	read      bool
+}
+
+// newCTEScope extends ctes with the expressions of a WITH clause. Each one
+// sees those before it, and recursive ones also see themselves.
+func newCTEScope(ctes *cteScope, with []statement.CommonTableExpression, recursive bool) *cteScope {
+	for _, cte := range with {
+		b := &cteBinding{cte: cte, scope: ctes}
+		ctes = &cteScope{parent: ctes, binding: b}
+		if recursive && cte.Select.ReadsTable(cte.Name) {
+			b.recursive, b.scope = true, ctes
+		}
+	}
+	return ctes
+}
+
+func (s *cteScope) lookup(name string) *cteBinding {
+	for ; s != nil; s = s.parent {
+		if s.binding.cte.Name == name {
+			return s.binding
+		}
+	}
+	return nil
+}
+
+// cteRelation reads the rows of a common table expression, running its
+// select every time it is read.
+type cteRelation struct {
+	e       *DefaultExecutor
+	ctx     context.Context
+	binding *cteBinding
+}
+
+// columns returns the columns the expression names, or else those of its
+// select without their table names. The select is opened the first time
+// to learn them.
+func (r *cteRelation) columns() ([]string, error) {
+	b := r.binding
+	if b.columns != nil {
+		return b.columns, nil
+	}
+	sel := b.cte.Select
+	if b.recursive {
+		sel = anchorOf(sel)
+	}
+	opened, err := r.e.openSelect(r.ctx, sel, b.scope)
+	if err != nil {
+		return nil, err
+	}
+	opened.cursor.Close()
+	return b.resolveColumns(opened.columns)
+}
+
+// resolveColumns names the columns of the expression from those its select
+// returns.
+func (b *cteBinding) resolveColumns(selected []string) ([]string, error) {
+	if len(b.cte.Columns) > 0 {
+		if len(b.cte.Columns) != len(selected) {
+			return nil, fmt.Errorf("common table expression %s has %d columns, its select returns %d",
+				b.cte.Name, len(b.cte.Columns), len(selected))
+		}
+		b.columns = b.cte.Columns
+		return b.columns, nil
+	}
+
+	columns := make([]string, len(selected))
+	for i, column := range selected {
+		if _, name, ok := strings.Cut(column, "."); ok && !strings.ContainsAny(column, "( ") {
+			column = name
+		}
+		if slices.Contains(columns[:i], column) {
+			return nil, fmt.Errorf("common table expression %s returns more than one column named %s", b.cte.Name, column)
+		}
+		columns[i] = column
+	}
+	b.columns = columns
+	return columns, nil
+}
+
+func (r *cteRelation) plan(query storage.Query) (storage.QueryPlan, error) {
+	return &ctePlan{relation: r, query: query}, nil
+}
+
+// open opens the cursor of the rows of the expression under its columns,
+// and returns how to annotate its plan tree.
+func (r *cteRelation) open() (storage.Cursor, func(*storage.PlanNode), error) {
+	b := r.binding
+	if b.work != nil {
+		if b.read {
+			return nil, nil, fmt.Errorf("the recursive select of %s can only read it once", b.cte.Name)
+		}
+		b.read = true
+		return b.work, func(*storage.PlanNode) {}, nil
+	}
+	if b.recursive {
+		return r.e.openRecursion(r.ctx, b)
+	}
+
+	sel, err := r.e.openSelect(r.ctx, b.cte.Select, b.scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, err := b.resolveColumns(sel.columns)
+	if err != nil {
+		sel.cursor.Close()
+		return nil, nil, err
+	}
+	cursor, err := renameColumns(sel.cursor, sel.columns, columns)
+	if err != nil {
+		sel.cursor.Close()
+		return nil, nil, err
+	}
+	return cursor, sel.annotate, nil
+}
+
+// renameColumns returns cursor with the columns from renamed to, which
+// may swap names, as computed columns hiding those of cursor.
+func renameColumns(cursor storage.Cursor, from, to []string) (storage.Cursor, error) {
+	if slices.Equal(from, to) {
+		return cursor, nil
+	}
+	projections := make([]storage.Projection, len(from))
+	for i := range from {
+		projections[i] = storage.Projection{Name: to[i], Expression: expression.NewColumn(from[i])}
+	}
+	return cursor.WithProjection(projections)
+}
+
+// anchorOf returns the selects of a recursive expression that do not read
+// it: all but the last one.
+func anchorOf(sel *statement.SelectStatement) *statement.SelectStatement {
+	anchor := *sel
+	anchor.SetOperations = sel.SetOperations[:len(sel.SetOperations)-1]
+	return &anchor
+}
+
+// openRecursion opens the cursor of a recursive expression: the rows of
+// its anchor, followed by those its last select derives from the rows
+// produced before, read under the name of the expression.
+func (e *DefaultExecutor) openRecursion(ctx context.Context, b *cteBinding) (storage.Cursor, func(*storage.PlanNode), error) {
+	anchor, err := e.openSelect(ctx, anchorOf(b.cte.Select), b.scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, err := b.resolveColumns(anchor.columns)
+	if err != nil {
+		anchor.cursor.Close()
+		return nil, nil, err
+	}
+
+	last := b.cte.Select.SetOperations[len(b.cte.Select.SetOperations)-1]
+	var annotateStep func(*storage.PlanNode)
+	cursor, err := anchor.cursor.WithRecursion(storage.RecursionConfig{
+		Name:         b.cte.Name,
+		Columns:      columns,
+		InputColumns: anchor.columns,
+		Distinct:     !last.All,
+		Step: func(previous storage.Cursor) (storage.Cursor, []string, error) {
+			work := &cteBinding{cte: b.cte, columns: columns, work: previous}
+			step, err := e.openSelect(ctx, last.Select, &cteScope{parent: b.scope, binding: work})
+			if err != nil {
+				return nil, nil, err
+			}
+			annotateStep = step.annotate
+			return step.cursor, step.columns, nil
+		},
+		Limit:       e.config.RecursionLimit,
+		MemoryLimit: e.config.SortMemoryLimit,
+		TempDir:     e.config.TempDir,
+	})
+	if err != nil {
+		anchor.cursor.Close()
+		return nil, nil, err
+	}
+
+	return cursor, func(node *storage.PlanNode) {
+		var recursion *storage.PlanNode
+		node.Walk(func(n *storage.PlanNode) {
+			if recursion == nil && n.Operation == "Recursive Union" {
+				recursion = n
+			}
+		})
+		if recursion == nil {
+			return
+		}
+		anchor.annotate(recursion.Children[0])
+		if annotateStep != nil {
+			annotateStep(recursion.Children[1])
+		}
+	}, nil
+}
+
+// ctePlan reads the rows of a common table expression matching a query.
+// It implements storage.QueryPlan.
+type ctePlan struct {
+	relation *cteRelation
+	query    storage.Query
+	annotate func(*storage.PlanNode)
+}
+
+func (p *ctePlan) Explain() string {
+	return "CTE Scan on " + p.relation.binding.cte.Name
+}
+
+func (p *ctePlan) Optimize() storage.QueryPlan {
+	return p
+}
+
+func (p *ctePlan) Execute() (storage.Cursor, error) {
+	cursor, annotate, err := p.relation.open()
+	if err != nil {
+		return nil, err
+	}
+	p.annotate = annotate
+	return applyQuery(cursor, p.query)
+}
+
+// applyQuery stacks on cursor the cursors keeping the rows matching the
+// filter of query, from its offset on and up to its limit. It closes
+// cursor when it fails.
+func applyQuery(cursor storage.Cursor, query storage.Query) (storage.Cursor, error) {
+	wrapped := cursor
+	var err error
+	if query.Filter != nil {
+		wrapped, err = wrapped.WithFilter(query.Filter)
+	}
+	if err == nil && query.Offset > 0 {
+		wrapped, err = wrapped.WithSkip(query.Offset)
+	}
+	if err == nil && query.Limit > 0 {
+		wrapped, err = wrapped.WithLimit(query.Limit)
+	}
+	if err != nil {
+		cursor.Close()
+		return nil, err
+	}
+	return wrapped, nil
+}
+
+// Annotate adds what the plans of the select of the expression know to
+// node, the plan tree of the cursor returned by Execute.
+func (p *ctePlan) Annotate(node *storage.PlanNode) {
+	if p.annotate != nil {
+		p.annotate(node)
+	}
+}
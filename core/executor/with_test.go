@@ -0,0 +1,42 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/executor"
+)
+
+func TestSelectWithoutFrom(t *testing.T) {
+	e := newExecutor(t, executor.Config{})
+	ctx := context.Background()
+	mustExec(t, e, ctx, "CREATE TABLE t (a INT32)")
+	mustExec(t, e, ctx, "INSERT INTO t (a) VALUES (1), (2), (3)")
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT 1 + 2 AS n, 'x' AS s", "[map[n:3 s:x]]"},
+		{"SELECT COUNT(*) AS n", "[map[n:1]]"},
+		{"SELECT 1 AS n UNION ALL SELECT 2 AS n ORDER BY n DESC", "[map[n:2] map[n:1]]"},
+		{"SELECT 1 AS n OFFSET 1", "[]"},
+		{"SELECT a FROM t WHERE a IN (SELECT 2 AS n)", "[map[a:2]]"},
+		{"WITH RECURSIVE r AS (SELECT 1 AS n UNION ALL SELECT n + 1 FROM r WHERE n < 5) SELECT n FROM r",
+			"[map[n:1] map[n:2] map[n:3] map[n:4] map[n:5]]"},
+		{"WITH RECURSIVE r (n) AS (SELECT 1 UNION SELECT n * 2 FROM r WHERE n < 8) SELECT SUM(n) AS total FROM r",
+			"[map[total:15]]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(selectRows(t, e, tt.sql)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+
+	for _, sql := range []string{"SELECT a", "SELECT *", "SELECT 1 AS n WITH DELETED"} {
+		if resp := run(e, ctx, sql); resp.IsSuccess() {
+			t.Errorf("%s: accepted", sql)
+		}
+	}
+}
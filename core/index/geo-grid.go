@@ -0,0 +1,163 @@
+package index
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+const defaultGeoGridCellDegrees = 0.1
+
+type GeoGridConfig struct {
+	// CellDegrees is the side of a grid cell. Smaller cells make lookups
+	// more selective at the cost of visiting more cells for wide boxes.
+	CellDegrees float64
+}
+
+// GeoGrid buckets points into a fixed latitude/longitude grid.
+type GeoGrid struct {
+	mu     sync.RWMutex
+	cell   float64
+	cells  map[geoCell][]int64
+	points map[int64]fields.LatLng
+}
+
+type geoCell struct {
+	lat int32
+	lng int32
+}
+
+func NewGeoGrid(config GeoGridConfig) (*GeoGrid, error) {
+	if config.CellDegrees < 0 || config.CellDegrees > 90 {
+		return nil, errors.New("geo grid: cell size must be between 0 and 90 degrees")
+	}
+	if config.CellDegrees == 0 {
+		config.CellDegrees = defaultGeoGridCellDegrees
+	}
+
+	return &GeoGrid{
+		cell:   config.CellDegrees,
+		cells:  make(map[geoCell][]int64),
+		points: make(map[int64]fields.LatLng),
+	}, nil
+}
+
+func (g *GeoGrid) Add(value interface{}, position int64) error {
+	p, err := fields.ToLatLng(value)
+	if err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if old, ok := g.points[position]; ok {
+		g.remove(old, position)
+	}
+	key := g.cellOf(p)
+	g.cells[key] = append(g.cells[key], position)
+	g.points[position] = p
+	return nil
+}
+
+func (g *GeoGrid) Remove(value interface{}, position int64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if p, ok := g.points[position]; ok {
+		g.remove(p, position)
+		delete(g.points, position)
+	}
+	return nil
+}
+
+func (g *GeoGrid) Find(filter storage.Filter) ([]int64, error) {
+	return nil, ErrFilterNotSupported
+}
+
+// Rebuild compacts the cell lists.
+func (g *GeoGrid) Rebuild() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.cells = make(map[geoCell][]int64, len(g.cells))
+	for id, p := range g.points {
+		key := g.cellOf(p)
+		g.cells[key] = append(g.cells[key], id)
+	}
+	return nil
+}
+
+func (g *GeoGrid) Stats() storage.IndexStats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return storage.IndexStats{
+		Size:         int64(len(g.points)),
+		UniqueValues: int64(len(g.cells)),
+		MemoryUsage:  int64(len(g.points))*24 + int64(len(g.cells))*32,
+	}
+}
+
+// WithinBox returns the ids of the points in the cells overlapping the box,
+// sorted ascending.
+func (g *GeoGrid) WithinBox(southWest, northEast fields.LatLng) ([]int64, error) {
+	if southWest.Lat > northEast.Lat {
+		return nil, errors.New("geo grid: south-west corner is north of the north-east corner")
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var result []int64
+	collect := func(minLng, maxLng float64) {
+		lo := g.cellOf(fields.LatLng{Lat: southWest.Lat, Lng: minLng})
+		hi := g.cellOf(fields.LatLng{Lat: northEast.Lat, Lng: maxLng})
+		for lat := lo.lat; lat <= hi.lat; lat++ {
+			for lng := lo.lng; lng <= hi.lng; lng++ {
+				for _, id := range g.cells[geoCell{lat: lat, lng: lng}] {
+					if g.points[id].InBox(southWest, northEast) {
+						result = append(result, id)
+					}
+				}
+			}
+		}
+	}
+
+	if southWest.Lng <= northEast.Lng {
+		collect(southWest.Lng, northEast.Lng)
+	} else {
+		collect(southWest.Lng, 180)
+		collect(-180, northEast.Lng)
+	}
+
+	slices.Sort(result)
+	return slices.Compact(result), nil
+}
+
+func (g *GeoGrid) cellOf(p fields.LatLng) geoCell {
+	return geoCell{
+		lat: int32(math.Floor(p.Lat / g.cell)),
+		lng: int32(math.Floor(p.Lng / g.cell)),
+	}
+}
+
+func (g *GeoGrid) remove(p fields.LatLng, position int64) {
+	key := g.cellOf(p)
+	ids := g.cells[key]
+	if i := slices.Index(ids, position); i >= 0 {
+		ids = slices.Delete(ids, i, i+1)
+	}
+	if len(ids) == 0 {
+		delete(g.cells, key)
+		return
+	}
+	g.cells[key] = ids
+}
@@ -0,0 +1,124 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+)
+
+// HashIndex maps the values of a column to the rows holding them and
+// answers equality lookups.
+type HashIndex struct {
+	mu     sync.RWMutex
+	values map[interface{}][]int64
+	size   int64
+}
+
+// timeKey is the hash key of an instant, so that equal instants in
+// different locations share a bucket.
+type timeKey int64
+
+func NewHashIndex() *HashIndex {
+	return &HashIndex{
+		values: make(map[interface{}][]int64),
+	}
+}
+
+func (h *HashIndex) Add(value interface{}, position int64) error {
+	key, err := hashKey(value)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ids := h.values[key]
+	i, found := slices.BinarySearch(ids, position)
+	if found {
+		return nil
+	}
+	h.values[key] = slices.Insert(ids, i, position)
+	h.size++
+	return nil
+}
+
+func (h *HashIndex) Remove(value interface{}, position int64) error {
+	key, err := hashKey(value)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ids := h.values[key]
+	i, found := slices.BinarySearch(ids, position)
+	if !found {
+		return nil
+	}
+	ids = slices.Delete(ids, i, i+1)
+	h.size--
+	if len(ids) == 0 {
+		delete(h.values, key)
+		return nil
+	}
+	h.values[key] = ids
+	return nil
+}
+
+// Lookup returns the rows holding value, sorted ascending.
+func (h *HashIndex) Lookup(value interface{}) ([]int64, error) {
+	key, err := hashKey(value)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return slices.Clone(h.values[key]), nil
+}
+
+func (h *HashIndex) Find(filter storage.Filter) ([]int64, error) {
+	return nil, ErrFilterNotSupported
+}
+
+// Rebuild releases the spare capacity left by removals.
+func (h *HashIndex) Rebuild() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, ids := range h.values {
+		h.values[key] = slices.Clip(ids)
+	}
+	return nil
+}
+
+func (h *HashIndex) Stats() storage.IndexStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return storage.IndexStats{
+		Size:         h.size,
+		UniqueValues: int64(len(h.values)),
+		MemoryUsage:  h.size*8 + int64(len(h.values))*48,
+	}
+}
+
+func hashKey(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("hash index: cannot index NULL")
+	case time.Time:
+		return timeKey(v.UnixNano()), nil
+	}
+	if !reflect.TypeOf(value).Comparable() {
+		return nil, fmt.Errorf("hash index: unsupported value type %T", value)
+	}
+	return value, nil
+}
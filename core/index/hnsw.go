@@ -0,0 +1,329 @@
+package index
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+const (
+	defaultHNSWM              = 16
+	defaultHNSWEfConstruction = 200
+	defaultHNSWEfSearch       = 64
+)
+
+var ErrFilterNotSupported = errors.New("index does not support filter lookups")
+
+type HNSWConfig struct {
+	Dimensions     int
+	Metric         fields.VectorMetric
+	M              int
+	EfConstruction int
+	EfSearch       int
+	Seed           int64
+}
+
+// HNSW is an in-memory Hierarchical Navigable Small World graph used for
+// approximate nearest-neighbour search over a vector column. Removed rows
+// are tombstoned and only dropped from the graph by Rebuild.
+type HNSW struct {
+	mu       sync.RWMutex
+	config   HNSWConfig
+	distance fields.DistanceFunc
+	nodes    map[int64]*hnswNode
+	entry    *hnswNode
+	maxLevel int
+	levelMul float64
+	rng      *rand.Rand
+	deleted  int64
+}
+
+type hnswNode struct {
+	id      int64
+	vector  []float32
+	friends [][]*hnswNode
+	deleted bool
+}
+
+func NewHNSW(config HNSWConfig) (*HNSW, error) {
+	if config.Dimensions <= 0 {
+		return nil, errors.New("hnsw: dimensions must be greater than zero")
+	}
+	distance, err := config.Metric.DistanceFunc()
+	if err != nil {
+		return nil, err
+	}
+	if config.M <= 1 {
+		config.M = defaultHNSWM
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = defaultHNSWEfConstruction
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = defaultHNSWEfSearch
+	}
+
+	return &HNSW{
+		config:   config,
+		distance: distance,
+		nodes:    make(map[int64]*hnswNode),
+		levelMul: 1 / math.Log(float64(config.M)),
+		rng:      rand.New(rand.NewSource(config.Seed)),
+	}, nil
+}
+
+func (h *HNSW) Add(value interface{}, position int64) error {
+	vector, err := fields.NewVectorType(h.config.Dimensions).Vector(value)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// The replaced node stays linked in the graph as a tombstone but is no
+	// longer tracked in nodes, so it is not counted as deleted.
+	if old, ok := h.nodes[position]; ok {
+		if old.deleted {
+			h.deleted--
+		}
+		old.deleted = true
+	}
+	h.insert(position, append([]float32(nil), vector...))
+	return nil
+}
+
+func (h *HNSW) Remove(value interface{}, position int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node, ok := h.nodes[position]
+	if !ok || node.deleted {
+		return nil
+	}
+	node.deleted = true
+	h.deleted++
+	return nil
+}
+
+func (h *HNSW) Find(filter storage.Filter) ([]int64, error) {
+	return nil, ErrFilterNotSupported
+}
+
+// Rebuild recreates the graph from the live vectors, dropping tombstones.
+func (h *HNSW) Rebuild() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	live := make([]*hnswNode, 0, len(h.nodes))
+	for _, node := range h.nodes {
+		if !node.deleted {
+			live = append(live, node)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].id < live[j].id })
+
+	h.nodes = make(map[int64]*hnswNode, len(live))
+	h.entry = nil
+	h.maxLevel = 0
+	h.deleted = 0
+	for _, node := range live {
+		h.insert(node.id, node.vector)
+	}
+	return nil
+}
+
+func (h *HNSW) Stats() storage.IndexStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var links int64
+	for _, node := range h.nodes {
+		for _, friends := range node.friends {
+			links += int64(len(friends))
+		}
+	}
+	live := int64(len(h.nodes)) - h.deleted
+	return storage.IndexStats{
+		Size:         live,
+		UniqueValues: live,
+		MemoryUsage:  int64(len(h.nodes))*int64(h.config.Dimensions*4) + links*8,
+	}
+}
+
+// Search returns the k rows closest to query, closest first.
+func (h *HNSW) Search(query []float32, k int) ([]storage.Neighbor, error) {
+	if len(query) != h.config.Dimensions {
+		return nil, fmt.Errorf("hnsw: query has %d dimensions, expected %d", len(query), h.config.Dimensions)
+	}
+	if k <= 0 {
+		return nil, nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.entry == nil {
+		return nil, nil
+	}
+
+	ep := []candidate{{node: h.entry, distance: h.distance(query, h.entry.vector)}}
+	for level := h.maxLevel; level > 0; level-- {
+		ep = h.searchLayer(query, ep, 1, level)
+	}
+	found := h.searchLayer(query, ep, max(h.config.EfSearch, k), 0)
+
+	result := make([]storage.Neighbor, 0, k)
+	for _, c := range found {
+		if c.node.deleted {
+			continue
+		}
+		result = append(result, storage.Neighbor{ID: c.node.id, Distance: c.distance})
+		if len(result) == k {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (h *HNSW) insert(id int64, vector []float32) {
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMul))
+	node := &hnswNode{
+		id:      id,
+		vector:  vector,
+		friends: make([][]*hnswNode, level+1),
+	}
+	h.nodes[id] = node
+
+	if h.entry == nil {
+		h.entry = node
+		h.maxLevel = level
+		return
+	}
+
+	ep := []candidate{{node: h.entry, distance: h.distance(vector, h.entry.vector)}}
+	for l := h.maxLevel; l > level; l-- {
+		ep = h.searchLayer(vector, ep, 1, l)
+	}
+
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		found := h.searchLayer(vector, ep, h.config.EfConstruction, l)
+		maxFriends := h.maxFriends(l)
+		for _, c := range found[:min(len(found), maxFriends)] {
+			node.friends[l] = append(node.friends[l], c.node)
+			c.node.friends[l] = append(c.node.friends[l], node)
+			if len(c.node.friends[l]) > maxFriends {
+				h.shrink(c.node, l, maxFriends)
+			}
+		}
+		ep = found
+	}
+
+	if level > h.maxLevel {
+		h.entry = node
+		h.maxLevel = level
+	}
+}
+
+func (h *HNSW) maxFriends(level int) int {
+	if level == 0 {
+		return 2 * h.config.M
+	}
+	return h.config.M
+}
+
+// shrink keeps the closest maxFriends links of node at level.
+func (h *HNSW) shrink(node *hnswNode, level, maxFriends int) {
+	friends := node.friends[level]
+	sort.Slice(friends, func(i, j int) bool {
+		return h.distance(node.vector, friends[i].vector) < h.distance(node.vector, friends[j].vector)
+	})
+	node.friends[level] = friends[:maxFriends]
+}
+
+// searchLayer runs a best-first search on one layer and returns up to ef
+// candidates ordered by distance.
+func (h *HNSW) searchLayer(query []float32, entries []candidate, ef, level int) []candidate {
+	visited := make(map[*hnswNode]struct{}, ef*4)
+	pending := &candidateHeap{}
+	results := &candidateHeap{max: true}
+
+	for _, e := range entries {
+		if _, ok := visited[e.node]; ok {
+			continue
+		}
+		visited[e.node] = struct{}{}
+		heap.Push(pending, e)
+		heap.Push(results, e)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+
+	for pending.Len() > 0 {
+		current := heap.Pop(pending).(candidate)
+		if results.Len() >= ef && current.distance > results.items[0].distance {
+			break
+		}
+		if level >= len(current.node.friends) {
+			continue
+		}
+		for _, friend := range current.node.friends[level] {
+			if _, ok := visited[friend]; ok {
+				continue
+			}
+			visited[friend] = struct{}{}
+
+			d := h.distance(query, friend.vector)
+			if results.Len() < ef || d < results.items[0].distance {
+				c := candidate{node: friend, distance: d}
+				heap.Push(pending, c)
+				heap.Push(results, c)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	sorted := results.items
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].distance < sorted[j].distance })
+	return sorted
+}
+
+type candidate struct {
+	node     *hnswNode
+	distance float32
+}
+
+// candidateHeap is a min-heap on distance, or a max-heap when max is set.
+type candidateHeap struct {
+	items []candidate
+	max   bool
+}
+
+func (h candidateHeap) Len() int { return len(h.items) }
+
+func (h candidateHeap) Less(i, j int) bool {
+	if h.max {
+		return h.items[i].distance > h.items[j].distance
+	}
+	return h.items[i].distance < h.items[j].distance
+}
+
+func (h candidateHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *candidateHeap) Push(x any) { h.items = append(h.items, x.(candidate)) }
+
+func (h *candidateHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
@@ -0,0 +1,116 @@
+package index_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/core/index"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// TestHNSWRecall checks that the neighbours a search finds are mostly the
+// exact ones a scan of every vector finds.
+func TestHNSWRecall(t *testing.T) {
+	const (
+		dimensions = 8
+		rows       = 2000
+		queries    = 50
+		k          = 10
+	)
+	rng := rand.New(rand.NewSource(1))
+	randomVector := func() []float32 {
+		vector := make([]float32, dimensions)
+		for i := range vector {
+			vector[i] = rng.Float32()
+		}
+		return vector
+	}
+
+	hnsw, err := index.NewHNSW(index.HNSWConfig{Dimensions: dimensions, Metric: fields.L2, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors := make([][]float32, rows)
+	for i := range vectors {
+		vectors[i] = randomVector()
+		if err := hnsw.Add(vectors[i], int64(i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if size := hnsw.Stats().Size; size != rows {
+		t.Fatalf("size = %d, want %d", size, rows)
+	}
+
+	found := 0
+	for range queries {
+		query := randomVector()
+		ids := make([]int64, rows)
+		for i := range ids {
+			ids[i] = int64(i + 1)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return fields.L2Distance(query, vectors[ids[i]-1]) < fields.L2Distance(query, vectors[ids[j]-1])
+		})
+		exact := make(map[int64]bool, k)
+		for _, id := range ids[:k] {
+			exact[id] = true
+		}
+
+		neighbors, err := hnsw.Search(query, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(neighbors) != k {
+			t.Fatalf("search returned %d neighbours, want %d", len(neighbors), k)
+		}
+		for i, n := range neighbors {
+			if i > 0 && n.Distance < neighbors[i-1].Distance {
+				t.Fatalf("neighbours out of order: %v", neighbors)
+			}
+			if exact[n.ID] {
+				found++
+			}
+		}
+	}
+	if recall := float64(found) / (queries * k); recall < 0.95 {
+		t.Errorf("recall = %.3f, want at least 0.95", recall)
+	}
+}
+
+func TestHNSWRemove(t *testing.T) {
+	hnsw, err := index.NewHNSW(index.HNSWConfig{Dimensions: 2, Metric: fields.L2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 10 {
+		if err := hnsw.Add([]float32{float32(i), 0}, int64(i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hnsw.Remove(nil, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rebuild := range []bool{false, true} {
+		if rebuild {
+			if err := hnsw.Rebuild(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		neighbors, err := hnsw.Search([]float32{0, 0}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(neighbors) != 2 || neighbors[0].ID != 2 || neighbors[1].ID != 3 {
+			t.Errorf("rebuilt %v: neighbours = %v, want ids 2 and 3", rebuild, neighbors)
+		}
+		if size := hnsw.Stats().Size; size != 9 {
+			t.Errorf("rebuilt %v: size = %d, want 9", rebuild, size)
+		}
+	}
+
+	if _, err := hnsw.Search([]float32{0}, 1); err == nil {
+		t.Error("search with a query of the wrong dimensions accepted")
+	}
+}
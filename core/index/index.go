@@ -0,0 +1,179 @@
+package index
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// Index kinds accepted by New.
+const (
+	KindHash    = "hash"
+	KindZoneMap = "zonemap"
+	KindHNSW    = "hnsw"
+	KindGeoGrid = "grid"
+)
+
+// DefaultKind is the kind of index built for a column when none is given:
+// HNSW for vectors, a grid for geographic points and a hash otherwise.
+func DefaultKind(dt fields.DataType) string {
+	switch dt.(type) {
+	case fields.VectorType:
+		return KindHNSW
+	case fields.GeoPointType:
+		return KindGeoGrid
+	}
+	return KindHash
+}
+
+// New builds an empty index of the given kind for a column of type dt.
+// Options tune the index: metric, m, ef_construction, ef_search and seed for
+// HNSW, cell_degrees for grids and block_size for zone maps.
+func New(kind string, dt fields.DataType, options map[string]interface{}) (storage.Index, error) {
+	kind = strings.ToLower(kind)
+	if kind == "" {
+		kind = DefaultKind(dt)
+	}
+
+	opts := indexOptions(maps.Clone(options))
+	var idx storage.Index
+	var err error
+	switch kind {
+	case KindHash:
+		switch dt.(type) {
+		case fields.ArrayType, fields.VectorType:
+			return nil, fmt.Errorf("hash index: unsupported column type %s", dt)
+		}
+		idx = NewHashIndex()
+	case KindZoneMap:
+		switch dt.(type) {
+		case fields.ArrayType, fields.VectorType, fields.GeoPointType, fields.BoolType:
+			return nil, fmt.Errorf("zone map: unsupported column type %s", dt)
+		}
+		cfg := ZoneMapConfig{}
+		if cfg.BlockSize, err = opts.int("block_size"); err != nil {
+			return nil, err
+		}
+		idx, err = NewZoneMap(cfg)
+	case KindHNSW:
+		vector, ok := dt.(fields.VectorType)
+		if !ok {
+			return nil, fmt.Errorf("hnsw: column type %s is not a vector", dt)
+		}
+		cfg := HNSWConfig{Dimensions: vector.Dimensions, Metric: fields.Cosine}
+		if metric, err := opts.string("metric"); err != nil {
+			return nil, err
+		} else if metric != "" {
+			cfg.Metric = fields.VectorMetric(strings.ToLower(metric))
+		}
+		m, err := opts.int("m")
+		if err != nil {
+			return nil, err
+		}
+		efConstruction, err := opts.int("ef_construction")
+		if err != nil {
+			return nil, err
+		}
+		efSearch, err := opts.int("ef_search")
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Seed, err = opts.int("seed"); err != nil {
+			return nil, err
+		}
+		cfg.M, cfg.EfConstruction, cfg.EfSearch = int(m), int(efConstruction), int(efSearch)
+		idx, err = NewHNSW(cfg)
+	case KindGeoGrid:
+		if _, ok := dt.(fields.GeoPointType); !ok {
+			return nil, fmt.Errorf("geo grid: column type %s is not a point", dt)
+		}
+		cfg := GeoGridConfig{}
+		if cfg.CellDegrees, err = opts.float("cell_degrees"); err != nil {
+			return nil, err
+		}
+		idx, err = NewGeoGrid(cfg)
+	default:
+		return nil, fmt.Errorf("unknown index kind %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if unknown := opts.unused(); unknown != "" {
+		return nil, fmt.Errorf("unknown %s index option %q", kind, unknown)
+	}
+	return idx, nil
+}
+
+// indexOptions reads typed options, remembering which ones were consumed.
+type indexOptions map[string]interface{}
+
+func (o indexOptions) take(key string) (interface{}, bool) {
+	for k, v := range o {
+		if strings.EqualFold(k, key) {
+			delete(o, k)
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (o indexOptions) int(key string) (int64, error) {
+	v, ok := o.take(key)
+	if !ok {
+		return 0, nil
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case uint64:
+		return int64(n), nil
+	case int:
+		return int64(n), nil
+	case float64:
+		if n == float64(int64(n)) {
+			return int64(n), nil
+		}
+	}
+	return 0, fmt.Errorf("index option %s must be an integer", key)
+}
+
+func (o indexOptions) float(key string) (float64, error) {
+	v, ok := o.take(key)
+	if !ok {
+		return 0, nil
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	}
+	return 0, fmt.Errorf("index option %s must be a number", key)
+}
+
+func (o indexOptions) string(key string) (string, error) {
+	v, ok := o.take(key)
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("index option %s must be a string", key)
+	}
+	return s, nil
+}
+
+func (o indexOptions) unused() string {
+	for k := range o {
+		return k
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package index
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+const defaultZoneMapBlockSize = 1024
+
+type ZoneMapConfig struct {
+	// BlockSize is the number of consecutive rows summarized by a zone.
+	BlockSize int64
+}
+
+// ZoneMap keeps the smallest and largest value of every block of rows. A
+// range lookup returns all the rows of the blocks whose bounds overlap the
+// range, letting scans skip the others. Removals never shrink a zone, so
+// lookups stay correct, only less selective, after updates and deletes.
+type ZoneMap struct {
+	mu        sync.RWMutex
+	blockSize int64
+	zones     []zone
+	maxID     int64
+	size      int64
+}
+
+type zone struct {
+	min, max interface{}
+	count    int64
+}
+
+func NewZoneMap(config ZoneMapConfig) (*ZoneMap, error) {
+	if config.BlockSize < 0 {
+		return nil, errors.New("zone map: block size must not be negative")
+	}
+	if config.BlockSize == 0 {
+		config.BlockSize = defaultZoneMapBlockSize
+	}
+
+	return &ZoneMap{blockSize: config.BlockSize}, nil
+}
+
+func (z *ZoneMap) Add(value interface{}, position int64) error {
+	if value == nil {
+		return nil
+	}
+	if position <= 0 {
+		return errors.New("zone map: positions start at 1")
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	block := (position - 1) / z.blockSize
+	for int64(len(z.zones)) <= block {
+		z.zones = append(z.zones, zone{})
+	}
+
+	zn := &z.zones[block]
+	if zn.count == 0 {
+		zn.min, zn.max = value, value
+	} else {
+		if c, err := fields.Compare(value, zn.min); err != nil {
+			return err
+		} else if c < 0 {
+			zn.min = value
+		}
+		if c, err := fields.Compare(value, zn.max); err != nil {
+			return err
+		} else if c > 0 {
+			zn.max = value
+		}
+	}
+	zn.count++
+	z.size++
+	z.maxID = max(z.maxID, position)
+	return nil
+}
+
+func (z *ZoneMap) Remove(value interface{}, position int64) error {
+	if value == nil {
+		return nil
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	block := (position - 1) / z.blockSize
+	if block < 0 || block >= int64(len(z.zones)) || z.zones[block].count == 0 {
+		return nil
+	}
+	z.zones[block].count--
+	z.size--
+	return nil
+}
+
+// Range returns the rows of every block that may hold a value between low
+// and high, sorted ascending.
+func (z *ZoneMap) Range(low, high interface{}, includeLow, includeHigh bool) ([]int64, error) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+
+	var ids []int64
+	for block, zn := range z.zones {
+		if zn.count == 0 {
+			continue
+		}
+		ok, err := zn.overlaps(low, high, includeLow, includeHigh)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		first := int64(block)*z.blockSize + 1
+		last := min(first+z.blockSize-1, z.maxID)
+		for id := first; id <= last; id++ {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (zn zone) overlaps(low, high interface{}, includeLow, includeHigh bool) (bool, error) {
+	if high != nil {
+		c, err := fields.Compare(zn.min, high)
+		if err != nil {
+			return false, err
+		}
+		if c > 0 || c == 0 && !includeHigh {
+			return false, nil
+		}
+	}
+	if low != nil {
+		c, err := fields.Compare(zn.max, low)
+		if err != nil {
+			return false, err
+		}
+		if c < 0 || c == 0 && !includeLow {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (z *ZoneMap) Find(filter storage.Filter) ([]int64, error) {
+	return nil, ErrFilterNotSupported
+}
+
+// Rebuild is a no-op: zones are only tightened by recreating the index.
+func (z *ZoneMap) Rebuild() error {
+	return nil
+}
+
+func (z *ZoneMap) Stats() storage.IndexStats {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+
+	return storage.IndexStats{
+		Size:         z.size,
+		UniqueValues: int64(len(z.zones)),
+		MemoryUsage:  int64(len(z.zones)) * 48,
+	}
+}
@@ -0,0 +1,453 @@
+package planner
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// Default selectivities of predicates no index can estimate.
+const (
+	equalSelectivity   = 0.05
+	rangeSelectivity   = 1.0 / 3
+	likeSelectivity    = 0.25
+	nullSelectivity    = 0.1
+	spatialSelectivity = 0.1
+)
+
+// candidateSet is the outcome of answering a filter with indexes: when ok,
+// every matching row is in ids, sorted ascending.
+type candidateSet struct {
+	ids     []int64
+	sources []string
+	ok      bool
+}
+
+// estimator answers filters with the indexes of a table and estimates the
+// selectivity and cost of their predicates. Index answers are memoized per
+// condition since they serve both the access path and the estimates.
+type estimator struct {
+	table *catalog.Table
+	rows  int64
+	now   time.Time
+	cache map[*filters.Filter]candidateSet
+}
+
+func newEstimator(table *catalog.Table, rows int64) *estimator {
+	return &estimator{
+		table: table,
+		rows:  rows,
+		now:   time.Now(),
+		cache: make(map[*filters.Filter]candidateSet),
+	}
+}
+
+// candidates intersects the answers of the conditions of an AND group and
+// unites those of an OR group whose children can all be answered.
+func (e *estimator) candidates(f *filters.Filter) candidateSet {
+	if c, ok := e.cache[f]; ok {
+		return c
+	}
+
+	var c candidateSet
+	switch {
+	case len(f.Children) == 0:
+		c = e.lookup(f)
+	case strings.EqualFold(f.JoinWith, filters.And):
+		for _, child := range f.Children {
+			cc := e.candidates(child)
+			if !cc.ok {
+				continue
+			}
+			if !c.ok {
+				c = candidateSet{ids: cc.ids, sources: slices.Clone(cc.sources), ok: true}
+				continue
+			}
+			c.ids = intersect(c.ids, cc.ids)
+			c.sources = append(c.sources, cc.sources...)
+		}
+	case strings.EqualFold(f.JoinWith, filters.Or):
+		c.ok = true
+		for _, child := range f.Children {
+			cc := e.candidates(child)
+			if !cc.ok {
+				c = candidateSet{}
+				break
+			}
+			c.ids = union(c.ids, cc.ids)
+			c.sources = append(c.sources, cc.sources...)
+		}
+	}
+
+	c.sources = slices.Compact(c.sources)
+	e.cache[f] = c
+	return c
+}
+
+// lookup answers a single condition with the first index of its column able
+// to, or reports that none can.
+func (e *estimator) lookup(f *filters.Filter) candidateSet {
+	indexes := e.table.Indexes(f.Field)
+	if len(indexes) == 0 {
+		return candidateSet{}
+	}
+	// The values of a subquery EXPLAIN has not run are not known.
+	if _, ok := f.Value.(filters.Subquery); ok {
+		return candidateSet{}
+	}
+	meta, err := e.table.GetFieldMeta(f.Field)
+	if err != nil {
+		return candidateSet{}
+	}
+	dt := meta.DataType()
+
+	for _, idx := range indexes {
+		ids, ok := e.lookupIndex(idx, dt, f)
+		if ok {
+			return candidateSet{ids: ids, sources: []string{describeIndex(idx)}, ok: true}
+		}
+	}
+	return candidateSet{}
+}
+
+func (e *estimator) lookupIndex(idx *catalog.TableIndex, dt fields.DataType, f *filters.Filter) ([]int64, bool) {
+	switch f.Operator {
+	case filters.Equal:
+		return e.lookupValues(idx, dt, []interface{}{f.Value})
+	case filters.In:
+		values, ok := f.Value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		return e.lookupValues(idx, dt, values)
+	case filters.GreaterThan, filters.GreaterThanOrEqual:
+		return e.lookupRange(idx, dt, f.Value, nil, f.Operator == filters.GreaterThanOrEqual, false)
+	case filters.LessThan, filters.LessThanOrEqual:
+		return e.lookupRange(idx, dt, nil, f.Value, false, f.Operator == filters.LessThanOrEqual)
+	case filters.Between:
+		bounds, ok := f.Value.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return nil, false
+		}
+		return e.lookupRange(idx, dt, bounds[0], bounds[1], true, true)
+	case filters.WithinBox, filters.WithinDistance:
+		return e.lookupSpatial(idx, f)
+	}
+	return nil, false
+}
+
+func (e *estimator) lookupValues(idx *catalog.TableIndex, dt fields.DataType, values []interface{}) ([]int64, bool) {
+	var result []int64
+	for _, value := range values {
+		key, ok := e.normalize(dt, value)
+		if !ok {
+			return nil, false
+		}
+
+		var ids []int64
+		var err error
+		switch index := idx.Index.(type) {
+		case storage.LookupIndex:
+			ids, err = index.Lookup(key)
+		case storage.RangeIndex:
+			ids, err = index.Range(key, key, true, true)
+		default:
+			return nil, false
+		}
+		if err != nil {
+			return nil, false
+		}
+		result = union(result, ids)
+	}
+	return result, true
+}
+
+func (e *estimator) lookupRange(idx *catalog.TableIndex, dt fields.DataType, low, high interface{}, includeLow, includeHigh bool) ([]int64, bool) {
+	index, ok := idx.Index.(storage.RangeIndex)
+	if !ok {
+		return nil, false
+	}
+	if low != nil {
+		if low, ok = e.normalize(dt, low); !ok {
+			return nil, false
+		}
+	}
+	if high != nil {
+		if high, ok = e.normalize(dt, high); !ok {
+			return nil, false
+		}
+	}
+	ids, err := index.Range(low, high, includeLow, includeHigh)
+	return ids, err == nil
+}
+
+func (e *estimator) lookupSpatial(idx *catalog.TableIndex, f *filters.Filter) ([]int64, bool) {
+	index, ok := idx.Index.(storage.SpatialIndex)
+	if !ok {
+		return nil, false
+	}
+
+	var southWest, northEast fields.LatLng
+	if f.Operator == filters.WithinBox {
+		sw, ne, err := filters.ParseBoundingBox(f.Value)
+		if err != nil {
+			return nil, false
+		}
+		southWest, northEast = sw, ne
+	} else {
+		center, meters, err := filters.ParseRadius(f.Value)
+		if err != nil {
+			return nil, false
+		}
+		southWest, northEast = filters.RadiusBoundingBox(center, meters)
+	}
+
+	ids, err := index.WithinBox(southWest, northEast)
+	return ids, err == nil
+}
+
+// normalize converts a filter value into the form the column stores, the
+// form indexes hold. Time expressions are resolved and dates truncated as
+// the filters do.
+func (e *estimator) normalize(dt fields.DataType, value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	value = fields.Coerce(dt, value)
+
+	switch dt.(type) {
+	case fields.TimestampType, fields.TimestampTZType, fields.DateType:
+		var t time.Time
+		switch v := value.(type) {
+		case time.Time:
+			t = v
+		case filters.TimeExpr:
+			t = v.Resolve(e.now)
+		case string:
+			parsed, err := fields.ParseTime(v)
+			if err != nil {
+				return nil, false
+			}
+			t = parsed
+		default:
+			return nil, false
+		}
+		if _, ok := dt.(fields.DateType); ok {
+			t = fields.TruncateToDate(t)
+		}
+		return t, true
+	}
+
+	switch value.(type) {
+	case []interface{}, filters.TimeExpr:
+		return nil, false
+	}
+	return value, true
+}
+
+// selectivity estimates the fraction of rows matching f.
+func (e *estimator) selectivity(f *filters.Filter) float64 {
+	if e.rows == 0 {
+		return 1
+	}
+	if c := e.candidates(f); c.ok {
+		return float64(len(c.ids)) / float64(e.rows)
+	}
+
+	switch {
+	case len(f.Children) == 0:
+		return e.conditionSelectivity(f)
+	case strings.EqualFold(f.JoinWith, filters.And):
+		s := 1.0
+		for _, child := range f.Children {
+			s *= e.selectivity(child)
+		}
+		return s
+	case strings.EqualFold(f.JoinWith, filters.Or):
+		miss := 1.0
+		for _, child := range f.Children {
+			miss *= 1 - e.selectivity(child)
+		}
+		return 1 - miss
+	case strings.EqualFold(f.JoinWith, filters.Not) && len(f.Children) == 1:
+		return 1 - e.selectivity(f.Children[0])
+	}
+	return 1
+}
+
+func (e *estimator) conditionSelectivity(f *filters.Filter) float64 {
+	switch f.Operator {
+	case filters.Equal:
+		return e.equalSelectivity(f.Field)
+	case filters.NotEqual:
+		return 1 - e.equalSelectivity(f.Field)
+	case filters.In, filters.Contains, filters.Any:
+		n := 1
+		if values, ok := f.Value.([]interface{}); ok {
+			n = len(values)
+		}
+		return min(1, float64(n)*e.equalSelectivity(f.Field))
+	case filters.NotIn:
+		n := 1
+		if values, ok := f.Value.([]interface{}); ok {
+			n = len(values)
+		}
+		return max(0, 1-float64(n)*e.equalSelectivity(f.Field))
+	case filters.GreaterThan, filters.GreaterThanOrEqual, filters.LessThan, filters.LessThanOrEqual:
+		return rangeSelectivity
+	case filters.Between:
+		return rangeSelectivity * rangeSelectivity
+	case filters.NotBetween:
+		return 1 - rangeSelectivity*rangeSelectivity
+	case filters.Like:
+		return likeSelectivity
+	case filters.NotLike:
+		return 1 - likeSelectivity
+	case filters.IsNull:
+		return nullSelectivity
+	case filters.IsNotNull:
+		return 1 - nullSelectivity
+	case filters.WithinBox, filters.WithinDistance:
+		return spatialSelectivity
+	}
+	return 1
+}
+
+// equalSelectivity uses the number of distinct values known to a hash index
+// on the column, if any.
+func (e *estimator) equalSelectivity(column string) float64 {
+	for _, idx := range e.table.Indexes(column) {
+		if _, ok := idx.Index.(storage.LookupIndex); !ok {
+			continue
+		}
+		if unique := idx.Stats().UniqueValues; unique > 0 {
+			return 1 / float64(unique)
+		}
+	}
+	return equalSelectivity
+}
+
+// cost estimates the work of evaluating f on a row, in simple predicates.
+func (e *estimator) cost(f *filters.Filter) float64 {
+	if len(f.Children) == 0 {
+		switch f.Operator {
+		case filters.Like, filters.NotLike:
+			return 4
+		case filters.Contains, filters.Any, filters.All:
+			return 4
+		case filters.WithinBox, filters.WithinDistance:
+			return 3
+		case filters.In, filters.NotIn:
+			if values, ok := f.Value.([]interface{}); ok {
+				return 1 + float64(len(values))/8
+			}
+		}
+		return 1
+	}
+
+	total := 0.0
+	for _, child := range f.Children {
+		total += e.cost(child)
+	}
+	return total
+}
+
+// reorder sorts the children of every AND group so that predicates that
+// are cheap and likely to reject a row run first, and those of OR groups so
+// that cheap predicates likely to accept it do. Both orders minimize the
+// expected work of the short-circuiting evaluation.
+func (e *estimator) reorder(f *filters.Filter) {
+	for _, child := range f.Children {
+		e.reorder(child)
+	}
+
+	var rank func(*filters.Filter) float64
+	switch {
+	case strings.EqualFold(f.JoinWith, filters.And):
+		rank = func(c *filters.Filter) float64 { return e.cost(c) / max(1-e.selectivity(c), 1e-9) }
+	case strings.EqualFold(f.JoinWith, filters.Or):
+		rank = func(c *filters.Filter) float64 { return e.cost(c) / max(e.selectivity(c), 1e-9) }
+	default:
+		return
+	}
+
+	ranks := make(map[*filters.Filter]float64, len(f.Children))
+	for _, child := range f.Children {
+		ranks[child] = rank(child)
+	}
+	sort.SliceStable(f.Children, func(i, j int) bool {
+		return ranks[f.Children[i]] < ranks[f.Children[j]]
+	})
+}
+
+func countPredicates(f *filters.Filter) int {
+	if f == nil {
+		return 0
+	}
+	if len(f.Children) == 0 {
+		return 1
+	}
+	n := 0
+	for _, child := range f.Children {
+		n += countPredicates(child)
+	}
+	return n
+}
+
+func describeIndex(idx *catalog.TableIndex) string {
+	kind := "index"
+	switch idx.Index.(type) {
+	case storage.LookupIndex:
+		kind = "hash"
+	case storage.RangeIndex:
+		kind = "zone map"
+	case storage.SpatialIndex:
+		kind = "grid"
+	}
+	return idx.Name + " (" + kind + " on " + idx.Column + ")"
+}
+
+// intersect returns the ids present in both sorted lists.
+func intersect(a, b []int64) []int64 {
+	result := make([]int64, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// union merges two sorted lists, dropping duplicates.
+func union(a, b []int64) []int64 {
+	result := make([]int64, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j >= len(b) || i < len(a) && a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case i >= len(a) || b[j] < a[i]:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
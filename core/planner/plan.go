@@ -0,0 +1,201 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+)
+
+var errNegativeLimit = errors.New("offset and limit must not be negative")
+
+type accessKind int
+
+const (
+	// fullScan reads every row of the table.
+	fullScan accessKind = iota
+	// idRange reads the rows a pushed down LIMIT selects.
+	idRange
+	// indexScan reads the candidate rows returned by indexes.
+	indexScan
+)
+
+// access is the way a plan reads the rows of its table.
+type access struct {
+	kind    accessKind
+	ids     []int64
+	sources []string
+	rows    int64
+}
+
+// Plan reads the rows of a table matching a filter. It implements
+// storage.QueryPlan.
+type Plan struct {
+	table  *catalog.Table
+	query  storage.Query
+	access access
+
+	// limitPushed tells that the ID range already applies the offset and
+	// the limit.
+	limitPushed bool
+	// estimate is the expected number of rows matching the filter.
+	estimate  float64
+	cost      float64
+	optimized bool
+}
+
+// Optimize picks the cheapest way to read the rows: index lookups and zone
+// map pruning when the filter allows them, an ID range when a LIMIT can be
+// pushed down to the scan, a full scan otherwise. The predicates of the
+// filter are reordered so that cheap and selective ones run first. Indexes
+// only hold the rows not deleted, and an ID range only selects the right
+// rows when none is hidden, so deleted rows restrict both.
+func (p *Plan) Optimize() storage.QueryPlan {
+	if p.optimized {
+		return p
+	}
+
+	opt := *p
+	opt.optimized = true
+	rows := opt.table.RowCount()
+	est := newEstimator(opt.table, rows)
+
+	if filter := opt.query.Filter; filter != nil {
+		c := est.candidates(filter)
+		if c.ok && !opt.query.IncludeDeleted && float64(len(c.ids))*indexRowCost < float64(rows) {
+			opt.access = access{kind: indexScan, ids: c.ids, sources: c.sources, rows: int64(len(c.ids))}
+		}
+		est.reorder(filter)
+		opt.estimate = float64(rows) * est.selectivity(filter)
+		opt.cost = float64(opt.access.rows) * (1 + est.cost(filter)*predicateCost)
+		if opt.access.kind == indexScan {
+			opt.cost *= indexRowCost
+		}
+		return &opt
+	}
+
+	opt.estimate = float64(rows)
+	if opt.query.Limit > 0 && (opt.query.IncludeDeleted || opt.table.DeletedRowCount() == 0) {
+		first := min(opt.query.Offset, rows) + 1
+		last := min(opt.query.Offset+opt.query.Limit, rows)
+		ids := make([]int64, 0, max(last-first+1, 0))
+		for id := first; id <= last; id++ {
+			ids = append(ids, id)
+		}
+		opt.access = access{kind: idRange, ids: ids, rows: int64(len(ids))}
+		opt.limitPushed = true
+		opt.cost = float64(len(ids))
+	}
+	return &opt
+}
+
+// Execute builds the cursor producing the rows of the plan.
+func (p *Plan) Execute() (storage.Cursor, error) {
+	open := p.table.Cursor
+	if p.query.IncludeDeleted {
+		open = p.table.CursorWithDeleted
+	}
+	base, err := open()
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := p.wrap(base)
+	if err != nil {
+		base.Close()
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func (p *Plan) wrap(cursor storage.Cursor) (storage.Cursor, error) {
+	var err error
+	if p.access.kind != fullScan {
+		if cursor, err = cursor.WithIDs(p.access.ids); err != nil {
+			return nil, err
+		}
+	}
+	if p.query.Filter != nil {
+		if cursor, err = cursor.WithFilter(p.query.Filter); err != nil {
+			return nil, err
+		}
+	}
+	if p.limitPushed {
+		return cursor, nil
+	}
+	if p.query.Offset > 0 {
+		if cursor, err = cursor.WithSkip(p.query.Offset); err != nil {
+			return nil, err
+		}
+	}
+	if p.query.Limit > 0 {
+		if cursor, err = cursor.WithLimit(p.query.Limit); err != nil {
+			return nil, err
+		}
+	}
+	return cursor, nil
+}
+
+// Annotate adds what the planner knows to node, the plan tree of a cursor
+// returned by Execute: the table and indexes the rows are read from and the
+// estimated number of rows of the filter. Cursors stacked on top of it, such
+// as the filter of a HAVING, are left alone.
+func (p *Plan) Annotate(node *storage.PlanNode) {
+	node.Walk(func(n *storage.PlanNode) {
+		switch n.Operation {
+		case "Seq Scan":
+			n.Detail = "on " + p.table.Name
+			n.EstimatedRows = p.access.rows
+		case "ID Scan":
+			switch p.access.kind {
+			case idRange:
+				n.Detail = fmt.Sprintf("on %s (offset %d, limit %d)", p.table.Name, p.query.Offset, p.query.Limit)
+			case indexScan:
+				n.Detail = fmt.Sprintf("on %s using %s", p.table.Name, strings.Join(p.access.sources, ", "))
+			}
+			n.EstimatedRows = p.access.rows
+		case "Filter":
+			if len(n.Children) == 1 && isScan(n.Children[0]) {
+				n.EstimatedRows = int64(p.estimate + 0.5)
+			}
+		}
+	})
+}
+
+func isScan(node *storage.PlanNode) bool {
+	return node.Operation == "Seq Scan" || node.Operation == "ID Scan"
+}
+
+// Explain describes the plan, one operation per line with the operations
+// feeding it indented below.
+func (p *Plan) Explain() string {
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, strings.Repeat("  ", len(lines))+fmt.Sprintf(format, args...))
+	}
+
+	if !p.limitPushed {
+		if p.query.Limit > 0 {
+			add("Limit %d", p.query.Limit)
+		}
+		if p.query.Offset > 0 {
+			add("Skip %d", p.query.Offset)
+		}
+	}
+	if p.query.Filter != nil {
+		add("Filter %s (estimated rows: %.0f)", p.query.Filter, p.estimate)
+	}
+
+	switch p.access.kind {
+	case fullScan:
+		add("Full Scan on %s (rows: %d)", p.table.Name, p.access.rows)
+	case idRange:
+		add("ID Range Scan on %s (offset: %d, limit: %d, rows: %d)", p.table.Name, p.query.Offset, p.query.Limit, p.access.rows)
+	case indexScan:
+		add("Index Scan on %s using %s (rows: %d)", p.table.Name, strings.Join(p.access.sources, ", "), p.access.rows)
+	}
+
+	return strings.Join(lines, "\n") + fmt.Sprintf("\nCost: %.2f", p.cost)
+}
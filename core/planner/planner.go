@@ -0,0 +1,43 @@
+package planner
+
+import (
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/model/catalog"
+)
+
+// Cost model constants, in units of one sequential row read.
+const (
+	// indexRowCost is the cost of reading a row picked by an index, which
+	// jumps around the column files instead of streaming them.
+	indexRowCost = 1.5
+	// predicateCost is the cost of evaluating a simple predicate on a row.
+	predicateCost = 0.25
+)
+
+// Planner builds query plans for a table from its row count and indexes. It
+// implements storage.QueryPlanner.
+type Planner struct {
+	table *catalog.Table
+}
+
+func New(table *catalog.Table) *Planner {
+	return &Planner{table: table}
+}
+
+// CreatePlan returns the straightforward plan for query, a full scan
+// applying the filter, the offset and the limit in that order. Call Optimize
+// on it to let the planner pick a cheaper access path.
+func (p *Planner) CreatePlan(query storage.Query) (storage.QueryPlan, error) {
+	if query.Offset < 0 || query.Limit < 0 {
+		return nil, errNegativeLimit
+	}
+
+	rows := p.table.RowCount()
+	plan := &Plan{
+		table:  p.table,
+		query:  query,
+		access: access{kind: fullScan, rows: rows},
+	}
+	plan.cost = float64(rows) * (1 + predicateCost*float64(countPredicates(query.Filter)))
+	return plan, nil
+}
@@ -0,0 +1,211 @@
+package columnstorage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/model/aggregate"
+)
+
+const (
+	// maxGroupDepth bounds how many times a partition is partitioned again
+	// when its groups still do not fit in memory.
+	maxGroupDepth = 4
+	// groupOverhead approximates the memory held by a group besides its key.
+	groupOverhead = 96
+	aggOverhead   = 64
+)
+
+// group is the state of one distinct value of the group by columns.
+type group struct {
+	keys []interface{}
+	aggs []aggregate.Aggregate
+}
+
+// groupTable aggregates rows in a hash table keyed by the group by values.
+// Once the table holds more than the memory limit, rows of groups it does
+// not know yet are written to partition files by the hash of their key, so
+// every group ends up complete either in the table or in one partition.
+// Partitions are aggregated later by tables of their own.
+type groupTable struct {
+	config  storage.GroupConfig
+	scanMap map[string]*buffer.Scanner
+	depth   int
+
+	groups     map[string]*group
+	order      []*group
+	size       int64
+	partitions []*spillFile
+}
+
+func newGroupTable(config storage.GroupConfig, scanMap map[string]*buffer.Scanner, depth int) *groupTable {
+	return &groupTable{
+		config:  config,
+		scanMap: scanMap,
+		depth:   depth,
+		groups:  make(map[string]*group),
+	}
+}
+
+// add aggregates the current row, whose group by values are keys. load
+// returns the columns of the row when it has to be spilled.
+func (t *groupTable) add(keys []interface{}, load func() (map[string]interface{}, error)) error {
+	key := string(appendGroupKey(nil, keys))
+	g, ok := t.groups[key]
+	if !ok {
+		if t.partitions != nil {
+			row, err := load()
+			if err != nil {
+				return err
+			}
+			return t.partitions[t.partition(key)].write(row)
+		}
+
+		var err error
+		if g, err = t.newGroup(keys); err != nil {
+			return err
+		}
+		t.groups[key] = g
+		t.order = append(t.order, g)
+		t.size += int64(len(key)) + groupOverhead + aggOverhead*int64(len(g.aggs))
+		if t.size > t.config.MemoryLimit && t.depth < maxGroupDepth {
+			if err := t.openPartitions(); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, agg := range g.aggs {
+		if err := agg.Execute(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *groupTable) newGroup(keys []interface{}) (*group, error) {
+	g := &group{
+		keys: append([]interface{}(nil), keys...),
+		aggs: make([]aggregate.Aggregate, len(t.config.Aggregations)),
+	}
+	for i, agg := range t.config.Aggregations {
+		if agg.Column == "*" {
+			g.aggs[i] = aggregate.NewCountRowsAggregate()
+			continue
+		}
+		scanner, ok := t.scanMap[agg.Column]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in cursor", agg.Column)
+		}
+		var fn aggregate.Aggregate
+		var err error
+		switch agg.Function {
+		case aggregate.PERCENTILE, aggregate.APPROX_PERCENTILE:
+			fn, err = aggregate.NewPercentile(scanner.Type, agg.Function, agg.Fraction, scanner)
+		default:
+			fn, err = aggregate.New(scanner.Type, agg.Function, scanner)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s(%s): %w", agg.Function, agg.Column, err)
+		}
+		g.aggs[i] = fn
+	}
+	return g, nil
+}
+
+func (t *groupTable) openPartitions() error {
+	t.partitions = make([]*spillFile, spillPartitions)
+	for i := range t.partitions {
+		file, err := newSpillFile(t.config.TempDir, "zenith-group-*.part")
+		if err != nil {
+			return err
+		}
+		t.partitions[i] = file
+	}
+	return nil
+}
+
+func (t *groupTable) partition(key string) int {
+	return partitionOf(key, t.depth)
+}
+
+// appendGroupKey encodes values so that equal group by values, and only
+// those, produce equal keys. Nulls group together.
+func appendGroupKey(buf []byte, values []interface{}) []byte {
+	for _, value := range values {
+		buf = appendKeyValue(buf, value)
+	}
+	return buf
+}
+
+func appendKeyValue(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0)
+	case string:
+		buf = append(buf, 1)
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		return append(buf, v...)
+	case bool:
+		if v {
+			return append(buf, 2, 1)
+		}
+		return append(buf, 2, 0)
+	case time.Time:
+		return binary.LittleEndian.AppendUint64(append(buf, 3), uint64(v.UnixNano()))
+	case []interface{}:
+		buf = append(buf, 4)
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		for _, elem := range v {
+			buf = appendKeyValue(buf, elem)
+		}
+		return buf
+	}
+
+	rv := reflect.ValueOf(value)
+	switch {
+	case isSignedKind(rv.Kind()):
+		return binary.LittleEndian.AppendUint64(append(buf, 5), uint64(rv.Int()))
+	case isUnsignedKind(rv.Kind()):
+		return binary.LittleEndian.AppendUint64(append(buf, 6), rv.Uint())
+	case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+		f := rv.Float()
+		if f == 0 {
+			f = 0 // -0 and 0 are the same group
+		}
+		return binary.LittleEndian.AppendUint64(append(buf, 7), math.Float64bits(f))
+	case rv.Kind() == reflect.Slice:
+		buf = append(buf, 4)
+		buf = binary.AppendUvarint(buf, uint64(rv.Len()))
+		for i := 0; i < rv.Len(); i++ {
+			buf = appendKeyValue(buf, rv.Index(i).Interface())
+		}
+		return buf
+	}
+
+	s := fmt.Sprintf("%T:%v", value, value)
+	buf = append(buf, 8)
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func isSignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUnsignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
@@ -29,6 +29,7 @@ type Column struct {
 
 	BasePath string
 	*buffer.MMapFile
+	heap *Heap
 }
 
 func (c *Column) Type() fields.DataType {
@@ -65,24 +66,39 @@ func NewColumn(name string, dataType fields.DataType, length int, required bool,
 }
 
 func (c *Column) init() error {
-	filepath := filepath.Join(c.BasePath, c.name+".data")
-	buff, err := buffer.Open(filepath, 0, (c.Length+2)*10_000_000)
+	path := filepath.Join(c.BasePath, c.name+".data")
+	buff, err := buffer.Open(path, 0, (c.Length+2)*10_000_000)
 	if err != nil {
 		return err
 	}
 	c.MMapFile = buff
 
+	if vt, ok := c.DataType.(fields.VariableLengthType); ok {
+		heap, err := OpenHeap(filepath.Join(c.BasePath, c.name+".heap"))
+		if err != nil {
+			return err
+		}
+		c.heap = heap
+		c.DataType = vt.WithHeap(heap)
+	}
+
 	return nil
 }
 
 func (c *Column) Truncate() error {
-	path := filepath.Join(c.BasePath, c.name+".data")
+	if err := c.Close(); err != nil {
+		return err
+	}
+
+	for _, ext := range []string{".data", ".heap"} {
+		path := filepath.Join(c.BasePath, c.name+ext)
 
-	if _, err := os.Stat(path); err == nil {
-		if err := os.Remove(path); err != nil {
-			return fmt.Errorf("failed to remove file %s: %w", path, err)
+		if _, err := os.Stat(path); err == nil {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove file %s: %w", path, err)
+			}
+			log.Printf("Removed existing file %s", path)
 		}
-		log.Printf("Removed existing file %s", path)
 	}
 
 	return c.init()
@@ -95,5 +111,11 @@ func (c *Column) Close() error {
 		}
 		c.MMapFile = nil
 	}
+	if c.heap != nil {
+		if err := c.heap.Close(); err != nil {
+			return fmt.Errorf("failed to close heap file: %w", err)
+		}
+		c.heap = nil
+	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,17 +17,39 @@ import (
 const statsFileName = "stats.bin"
 
 type ColumnStorage struct {
-	fields        fields.FieldsMeta
-	columns       map[string]*Column
+	fields fields.FieldsMeta
+	// columns are the columns open on the current column files. A purge
+	// replaces them, under columnsLock.
+	columns       *columnSet
+	columnsLock   sync.Mutex
 	BasePath      string
 	StatsFilePath string
 	Logger        *logrus.Logger
 	StorageStats  *storage.StorageStats
 
+	// columnsDir is the directory of the column files, relative to
+	// BasePath, as the manifest names it.
+	columnsDir string
+
 	insertLock sync.Mutex
 	importLock sync.Mutex
 }
 
+// columnSet is a set of open columns and the count of the readers reading
+// them. A purge retires the set it replaces, which is closed once its last
+// reader is, so that no reader is left reading unmapped files.
+type columnSet struct {
+	columns map[string]*Column
+	readers int
+	retired bool
+}
+
+func (c *columnSet) close() {
+	for _, col := range c.columns {
+		col.Close()
+	}
+}
+
 type ColumnStorageConfig struct {
 	Fields        fields.FieldsMeta
 	BasePath      string
@@ -52,12 +75,21 @@ func NewColumnStorage(cfg *ColumnStorageConfig) storage.Storage {
 }
 
 func (s *ColumnStorage) Initialize(ctx context.Context) error {
+	dir, err := s.loadManifest()
+	if err != nil {
+		return err
+	}
+	s.columnsDir = dir
+	if err := s.removeStaleColumnFiles(); err != nil {
+		return fmt.Errorf("failed to remove stale column files: %w", err)
+	}
+
 	columns := make(map[string]*Column)
 
 	for i := 0; i < len(s.fields); i++ {
 		meta := s.fields[i]
-		dataType := fields.NewDataType(meta.Type)
-		col, err := NewColumn(meta.Name, dataType, meta.Length, meta.Required, s.BasePath)
+		dataType := meta.DataType()
+		col, err := NewColumn(meta.Name, dataType, meta.Length, meta.Required, s.columnsPath())
 		if err != nil {
 			return fmt.Errorf("failed to initialize column %s: %w", meta.Name, err)
 		}
@@ -67,7 +99,7 @@ func (s *ColumnStorage) Initialize(ctx context.Context) error {
 		columns[meta.Name] = col
 	}
 
-	s.columns = columns
+	s.columns = &columnSet{columns: columns}
 
 	return nil
 }
@@ -76,19 +108,20 @@ func (s *ColumnStorage) Truncate() error {
 	s.Lock()
 	defer s.Unlock()
 
-	for _, col := range s.columns {
+	for _, col := range s.columns.columns {
 		if err := col.Truncate(); err != nil {
 			s.Logger.Error("Failed to truncate column ", col.Name(), err)
 			return err
 		}
 	}
 	s.StorageStats.TotalRows = 0
+	s.StorageStats.DeletedRows = 0
 	s.StorageStats.SaveToFile(s.StatsFilePath)
 	return nil
 }
 
 func (s *ColumnStorage) Close() error {
-	for _, col := range s.columns {
+	for _, col := range s.columns.columns {
 		if err := col.Close(); err != nil {
 			s.Logger.WithError(err).Error("Failed to close column")
 		}
@@ -108,10 +141,6 @@ func (s *ColumnStorage) Stats() storage.StorageStats {
 	return *s.StorageStats
 }
 
-func (s *ColumnStorage) Compact(ctx context.Context) error {
-	return nil
-}
-
 func (s *ColumnStorage) CreateField(meta fields.FieldMeta, validators ...storage.Validator) error {
 	return nil
 }
@@ -121,33 +150,109 @@ func (s *ColumnStorage) DeleteField(name string) error {
 }
 
 func (s *ColumnStorage) GetFieldMeta(name string) (fields.FieldMeta, error) {
-	return fields.FieldMeta{}, nil
+	for _, meta := range s.fields {
+		if meta.Name == name {
+			return meta, nil
+		}
+	}
+	return fields.FieldMeta{}, fmt.Errorf(errFieldNotFound, name)
 }
 
 func (s *ColumnStorage) ListFields() ([]fields.FieldMeta, error) {
-	return nil, nil
+	return slices.Clone(s.fields), nil
 }
 
+// UpdateField applies metadata changes that do not require rewriting the
+// column data. Currently that is appending values to an enum.
 func (s *ColumnStorage) UpdateField(name string, newMeta fields.FieldMeta) error {
+	i := slices.IndexFunc(s.fields, func(meta fields.FieldMeta) bool { return meta.Name == name })
+	if i < 0 {
+		return fmt.Errorf(errFieldNotFound, name)
+	}
+	meta := s.fields[i]
+
+	if newMeta.Name != meta.Name || newMeta.Type != meta.Type || newMeta.Length != meta.Length || newMeta.ElementType != meta.ElementType {
+		return fmt.Errorf("changing the definition of column %s requires rewriting it", name)
+	}
+
+	if meta.Type == fields.Enum {
+		if len(newMeta.Values) < len(meta.Values) || !slices.Equal(meta.Values, newMeta.Values[:len(meta.Values)]) {
+			return fmt.Errorf("enum values of column %s can only be appended", name)
+		}
+		enumType, ok := s.columns.columns[name].DataType.(fields.EnumType)
+		if !ok {
+			return fmt.Errorf("column %s is not an enum", name)
+		}
+		if err := enumType.AddValues(newMeta.Values[len(meta.Values):]...); err != nil {
+			return fmt.Errorf("failed to update column %s: %w", name, err)
+		}
+	}
+
+	s.fields[i] = newMeta
 	return nil
 }
 
 func (s *ColumnStorage) Writer() (storage.Writer, error) {
-	return NewColumnWriter(s.columns), nil
+	return NewColumnWriter(s.columns.columns), nil
 }
 
 func (s *ColumnStorage) Reader() (storage.Reader, error) {
-	return NewColumnReader(s.columns, s.StorageStats)
+	return s.reader(false)
 }
 
 func (s *ColumnStorage) Cursor() (storage.Cursor, error) {
-	reader, err := NewColumnReader(s.columns, s.StorageStats)
+	return s.cursor(false)
+}
+
+func (s *ColumnStorage) CursorWithDeleted() (storage.Cursor, error) {
+	return s.cursor(true)
+}
+
+func (s *ColumnStorage) cursor(includeDeleted bool) (storage.Cursor, error) {
+	reader, err := s.reader(includeDeleted)
 	if err != nil {
 		return nil, err
 	}
 	return NewColumnCursor(reader), nil
 }
 
+// reader returns a reader of the current columns, which stay open until
+// it is closed even if a purge replaces them meanwhile.
+func (s *ColumnStorage) reader(includeDeleted bool) (*ColumnReader, error) {
+	s.columnsLock.Lock()
+	defer s.columnsLock.Unlock()
+
+	set := s.columns
+	reader, err := NewColumnReader(set.columns, s.StorageStats, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	set.readers++
+	reader.release = func() {
+		s.columnsLock.Lock()
+		defer s.columnsLock.Unlock()
+		set.readers--
+		if set.retired && set.readers == 0 {
+			set.close()
+		}
+	}
+	return reader, nil
+}
+
+// replaceColumns makes columns the current columns, and closes the
+// previous ones once no reader reads them.
+func (s *ColumnStorage) replaceColumns(columns map[string]*Column) {
+	s.columnsLock.Lock()
+	defer s.columnsLock.Unlock()
+
+	previous := s.columns
+	s.columns = &columnSet{columns: columns}
+	previous.retired = true
+	if previous.readers == 0 {
+		previous.close()
+	}
+}
+
 func (s *ColumnStorage) Lock() error {
 	s.LockImport()
 	return nil
@@ -193,6 +298,17 @@ func (t *ColumnStorage) UpdateRowCount(count int64) error {
 	return t.StorageStats.SaveToFile(t.StatsFilePath)
 }
 
+func (t *ColumnStorage) DeletedRowCount() int64 {
+	return atomic.LoadInt64(&t.StorageStats.DeletedRows)
+}
+
+func (t *ColumnStorage) UpdateDeletedRowCount(count int64) error {
+	atomic.StoreInt64(&t.StorageStats.DeletedRows, count)
+	t.StorageStats.LastModified = time.Now()
+
+	return t.StorageStats.SaveToFile(t.StatsFilePath)
+}
+
 func (t *ColumnStorage) Columns() map[string]*Column {
-	return t.columns
+	return t.columns.columns
 }
@@ -1,9 +1,12 @@
 package columnstorage
 
 import (
+	"fmt"
+	"slices"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
 	"github.com/onnasoft/ZenithSQL/core/storage"
 	"github.com/onnasoft/ZenithSQL/io/filters"
-	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
 type ColumnCursorFromIds struct {
@@ -13,6 +16,7 @@ type ColumnCursorFromIds struct {
 	limit int
 	skip  int
 	err   error
+	stats cursorStats
 }
 
 func newColumnCursorFromIds(cursor storage.Cursor, ids []int64) (*ColumnCursorFromIds, error) {
@@ -29,6 +33,11 @@ func (c *ColumnCursorFromIds) ColumnsData() map[string]storage.ColumnData {
 }
 
 func (c *ColumnCursorFromIds) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *ColumnCursorFromIds) next() bool {
 	reader := c.base.Reader()
 	for {
 		c.index++
@@ -42,8 +51,13 @@ func (c *ColumnCursorFromIds) Next() bool {
 			return false
 		}
 		id := c.ids[c.index]
-		c.err = reader.See(id)
-		return c.err == nil
+		c.stats.scanned++
+		if c.err = reader.See(id); c.err != nil {
+			return false
+		}
+		if reader.Visible() {
+			return true
+		}
 	}
 }
 
@@ -101,6 +115,27 @@ func (c *ColumnCursorFromIds) Reader() storage.Reader {
 	return c.base.Reader()
 }
 
+func (c *ColumnCursorFromIds) ScanMap() map[string]*buffer.Scanner {
+	return c.base.ScanMap()
+}
+
+// Plan has no children: the base cursor only provides the reader that the
+// ids are looked up in.
+func (c *ColumnCursorFromIds) Plan() *storage.PlanNode {
+	return c.stats.node("ID Scan", fmt.Sprintf("%d ids", len(c.ids)))
+}
+
+func (c *ColumnCursorFromIds) Analyze() {
+	c.stats.analyze = true
+}
+
+func (c *ColumnCursorFromIds) orderedBy() []string {
+	if !slices.IsSorted(c.ids) {
+		return nil
+	}
+	return []string{"id"}
+}
+
 func (c *ColumnCursorFromIds) WithIDs(ids []int64) (storage.Cursor, error) {
 	return newColumnCursorFromIds(c, ids)
 }
@@ -109,8 +144,8 @@ func (c *ColumnCursorFromIds) WithFilter(filter *filters.Filter) (storage.Cursor
 	return newColumnCursorWithFilter(c, filter)
 }
 
-func (c *ColumnCursorFromIds) WithGroupBy(groupBy []string, aggregations []statement.Aggregation) (storage.Cursor, error) {
-	return newColumnCursorWithGroupBy(c, groupBy, aggregations)
+func (c *ColumnCursorFromIds) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
 }
 
 func (c *ColumnCursorFromIds) WithLimit(limit int64) (storage.Cursor, error) {
@@ -120,3 +155,35 @@ func (c *ColumnCursorFromIds) WithLimit(limit int64) (storage.Cursor, error) {
 func (c *ColumnCursorFromIds) WithSkip(skip int64) (storage.Cursor, error) {
 	return newColumnCursorWithSkip(c, skip)
 }
+
+func (c *ColumnCursorFromIds) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *ColumnCursorFromIds) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *ColumnCursorFromIds) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *ColumnCursorFromIds) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *ColumnCursorFromIds) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *ColumnCursorFromIds) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *ColumnCursorFromIds) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *ColumnCursorFromIds) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -0,0 +1,249 @@
+package columnstorage
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+)
+
+// rowBuffer holds rows to be read back once, in the order they were added:
+// in memory up to a number of bytes, and in a spill file beyond it.
+type rowBuffer struct {
+	memoryLimit int64
+	tempDir     string
+	rows        []map[string]interface{}
+	size        int64
+	file        *spillFile
+	count       int64
+	pos         int
+}
+
+func newRowBuffer(memoryLimit int64, tempDir string) *rowBuffer {
+	if memoryLimit <= 0 {
+		memoryLimit = storage.DefaultSortMemoryLimit
+	}
+	return &rowBuffer{memoryLimit: memoryLimit, tempDir: tempDir}
+}
+
+func (b *rowBuffer) add(row map[string]interface{}) error {
+	b.count++
+	size := rowSize(row)
+	// Rows keep going to the file once it is open, after those in memory.
+	if b.file == nil && b.size+size > b.memoryLimit {
+		file, err := newSpillFile(b.tempDir, "zenith-rows-*.spill")
+		if err != nil {
+			return err
+		}
+		b.file = file
+	}
+	if b.file != nil {
+		return b.file.write(row)
+	}
+	b.rows = append(b.rows, row)
+	b.size += size
+	return nil
+}
+
+// finish ends the writing of the rows, which can then be read back.
+func (b *rowBuffer) finish() error {
+	if b.file == nil {
+		return nil
+	}
+	return b.file.rewind()
+}
+
+func (b *rowBuffer) next() (map[string]interface{}, bool, error) {
+	if b.pos < len(b.rows) {
+		row := b.rows[b.pos]
+		b.rows[b.pos] = nil
+		b.pos++
+		return row, true, nil
+	}
+	if b.file == nil {
+		return nil, false, nil
+	}
+	return b.file.next()
+}
+
+func (b *rowBuffer) close() error {
+	b.rows = nil
+	if b.file == nil {
+		return nil
+	}
+	err := b.file.close()
+	b.file = nil
+	return err
+}
+
+// columnCursorFromRows reads the rows of a buffer, named as the columns of
+// scanMap, which gives their types. It is the work table a step of a
+// recursive cursor reads; Reader and ColumnsData are those of source, the
+// cursor of the recursion. Without a source, it reads rows given by the
+// caller, and has neither.
+type columnCursorFromRows struct {
+	source    storage.Cursor
+	operation string
+	name      string
+	rows      *rowBuffer
+	scanMap   map[string]*buffer.Scanner
+	current   map[string]interface{}
+	err       error
+	stats     cursorStats
+}
+
+func newColumnCursorFromRows(source storage.Cursor, name string, rows *rowBuffer, scanMap map[string]*buffer.Scanner) *columnCursorFromRows {
+	return &columnCursorFromRows{source: source, operation: "Work Table Scan", name: name, rows: rows, scanMap: scanMap}
+}
+
+// NewValuesCursor returns a cursor reading rows, named as the columns of
+// scanMap, which gives their types. It reads no table: it has no Reader
+// and no column data.
+func NewValuesCursor(rows []map[string]interface{}, scanMap map[string]*buffer.Scanner) storage.Cursor {
+	buffered := &rowBuffer{rows: slices.Clone(rows), count: int64(len(rows))}
+	return &columnCursorFromRows{operation: "Values Scan", rows: buffered, scanMap: scanMap}
+}
+
+func (c *columnCursorFromRows) ColumnsData() map[string]storage.ColumnData {
+	if c.source == nil {
+		return map[string]storage.ColumnData{}
+	}
+	return c.source.ColumnsData()
+}
+
+func (c *columnCursorFromRows) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorFromRows) next() bool {
+	if c.err != nil {
+		return false
+	}
+	var ok bool
+	c.current, ok, c.err = c.rows.next()
+	if ok {
+		c.stats.scanned++
+	}
+	return ok
+}
+
+func (c *columnCursorFromRows) Scan(dest map[string]interface{}) error {
+	if c.current == nil {
+		return fmt.Errorf("cursor is not positioned on a row")
+	}
+	for k, v := range c.current {
+		dest[k] = v
+	}
+	return nil
+}
+
+func (c *columnCursorFromRows) ScanField(field string) (interface{}, error) {
+	value, ok := c.current[field]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", field)
+	}
+	return value, nil
+}
+
+func (c *columnCursorFromRows) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	return scanRowValue(c.current, col.Name(), value)
+}
+
+func (c *columnCursorFromRows) ScanMap() map[string]*buffer.Scanner {
+	result := make(map[string]*buffer.Scanner, len(c.scanMap))
+	for name, scanner := range c.scanMap {
+		result[name] = &buffer.Scanner{
+			Type: scanner.Type,
+			Scan: func(value interface{}) (bool, error) {
+				return scanRowValue(c.current, name, value)
+			},
+			Nullable: scanner.Nullable,
+		}
+	}
+	return result
+}
+
+func (c *columnCursorFromRows) Err() error {
+	return c.err
+}
+
+func (c *columnCursorFromRows) Close() error {
+	return c.rows.close()
+}
+
+func (c *columnCursorFromRows) Count() (int64, error) {
+	return c.rows.count, nil
+}
+
+func (c *columnCursorFromRows) Reader() storage.Reader {
+	if c.source == nil {
+		return nil
+	}
+	return c.source.Reader()
+}
+
+func (c *columnCursorFromRows) Plan() *storage.PlanNode {
+	if c.name == "" {
+		return c.stats.node(c.operation, "")
+	}
+	return c.stats.node(c.operation, "on "+c.name)
+}
+
+func (c *columnCursorFromRows) Analyze() {
+	c.stats.analyze = true
+}
+
+func (c *columnCursorFromRows) WithIDs(ids []int64) (storage.Cursor, error) {
+	return newColumnCursorFromIds(c, ids)
+}
+
+func (c *columnCursorFromRows) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *columnCursorFromRows) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *columnCursorFromRows) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *columnCursorFromRows) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *columnCursorFromRows) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorFromRows) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorFromRows) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorFromRows) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorFromRows) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorFromRows) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorFromRows) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorFromRows) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
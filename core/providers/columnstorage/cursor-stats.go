@@ -0,0 +1,46 @@
+package columnstorage
+
+import (
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+)
+
+// cursorStats counts the rows a cursor produces and the rows it reads from
+// its input. The time spent in Next is only measured once the cursor is
+// analyzed, so plain queries do not pay for the clock.
+type cursorStats struct {
+	analyze bool
+	rows    int64
+	scanned int64
+	elapsed time.Duration
+}
+
+func (s *cursorStats) start() time.Time {
+	if !s.analyze {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// done records the outcome of a Next call that began at start.
+func (s *cursorStats) done(start time.Time, ok bool) bool {
+	if s.analyze {
+		s.elapsed += time.Since(start)
+	}
+	if ok {
+		s.rows++
+	}
+	return ok
+}
+
+func (s *cursorStats) node(operation, detail string, children ...*storage.PlanNode) *storage.PlanNode {
+	node := storage.NewPlanNode(operation, detail, children...)
+	if s.analyze {
+		node.Analyzed = true
+		node.ActualRows = s.rows
+		node.RowsScanned = s.scanned
+		node.Duration = s.elapsed
+	}
+	return node
+}
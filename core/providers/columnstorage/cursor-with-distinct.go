@@ -0,0 +1,359 @@
+package columnstorage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+)
+
+// distinctOverhead approximates the memory held by a value in the hash
+// table of a distinct cursor besides its key.
+const distinctOverhead = 48
+
+// ColumnCursorWithDistinct returns the rows of its base cursor whose values
+// of the distinct columns were not returned before. Rows are read through
+// from the base cursor while the values seen fit in memory. Past that, rows
+// of values not seen yet are written to partition files by the hash of
+// their key and deduplicated partition by partition once the base cursor is
+// exhausted, so every value ends up returned once.
+type ColumnCursorWithDistinct struct {
+	base    storage.Cursor
+	config  storage.DistinctConfig
+	scanMap map[string]*buffer.Scanner
+
+	seen map[string]struct{}
+	size int64
+	key  []byte
+	// depth is the partitioning depth of the rows being read, and
+	// partitions the files rows of new values are written to once seen
+	// holds more than the memory limit.
+	depth      int
+	partitions []*spillFile
+	pending    []groupPartition
+	spilled    int
+	// input is the partition being read, and replay its current row, once
+	// the base cursor is exhausted.
+	baseDone bool
+	input    *spillFile
+	replay   map[string]interface{}
+	err      error
+	stats    cursorStats
+}
+
+func newColumnCursorWithDistinct(cursor storage.Cursor, config storage.DistinctConfig) (*ColumnCursorWithDistinct, error) {
+	if len(config.Columns) == 0 {
+		return nil, fmt.Errorf("distinct requires columns")
+	}
+	if config.MemoryLimit <= 0 {
+		config.MemoryLimit = storage.DefaultGroupMemoryLimit
+	}
+
+	c := &ColumnCursorWithDistinct{
+		base:    cursor,
+		config:  config,
+		scanMap: make(map[string]*buffer.Scanner, len(config.Columns)),
+		seen:    make(map[string]struct{}),
+	}
+
+	scanMap := cursor.ScanMap()
+	for _, column := range config.Columns {
+		scanner, ok := scanMap[column]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in cursor", column)
+		}
+		if _, ok := c.scanMap[column]; ok {
+			return nil, fmt.Errorf("duplicate column %s in distinct", column)
+		}
+		c.scanMap[column] = &buffer.Scanner{
+			Type: scanner.Type,
+			Scan: func(value interface{}) (bool, error) {
+				if c.input != nil {
+					return scanRowValue(c.replay, column, value)
+				}
+				return scanner.Scan(value)
+			},
+			Nullable: scanner.Nullable,
+		}
+	}
+	return c, nil
+}
+
+func (c *ColumnCursorWithDistinct) ColumnsData() map[string]storage.ColumnData {
+	return c.base.ColumnsData()
+}
+
+func (c *ColumnCursorWithDistinct) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *ColumnCursorWithDistinct) next() bool {
+	for c.err == nil {
+		if !c.advance() {
+			if c.err != nil || !c.nextPartition() {
+				return false
+			}
+			continue
+		}
+		c.stats.scanned++
+		var ok bool
+		if ok, c.err = c.add(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// advance moves to the next row of the base cursor, or of the partition
+// being read once it is exhausted.
+func (c *ColumnCursorWithDistinct) advance() bool {
+	if c.input == nil {
+		if c.baseDone {
+			return false
+		}
+		if c.base.Next() {
+			return true
+		}
+		c.err = c.base.Err()
+		return false
+	}
+	var ok bool
+	c.replay, ok, c.err = c.input.next()
+	return ok
+}
+
+// nextPartition queues the partitions spilled while reading the last input
+// and moves to the next queued one, reporting false when none is left.
+func (c *ColumnCursorWithDistinct) nextPartition() bool {
+	c.baseDone = true
+	if c.input != nil {
+		c.input.close()
+		c.input, c.replay = nil, nil
+	}
+	for _, file := range c.partitions {
+		c.pending = append(c.pending, groupPartition{file: file, depth: c.depth + 1})
+		if c.err = file.rewind(); c.err != nil {
+			return false
+		}
+	}
+	c.spilled += len(c.partitions)
+	c.partitions = nil
+	if len(c.pending) == 0 {
+		return false
+	}
+
+	p := c.pending[len(c.pending)-1]
+	c.pending = c.pending[:len(c.pending)-1]
+	c.input, c.depth = p.file, p.depth
+	c.seen, c.size = make(map[string]struct{}), 0
+	return true
+}
+
+// add reports whether the values of the current row were not seen before,
+// spilling the row instead when its values are new but the table is full.
+func (c *ColumnCursorWithDistinct) add() (bool, error) {
+	c.key = c.key[:0]
+	for _, column := range c.config.Columns {
+		value, err := c.value(column)
+		if err != nil {
+			return false, err
+		}
+		c.key = appendKeyValue(c.key, value)
+	}
+	if _, ok := c.seen[string(c.key)]; ok {
+		return false, nil
+	}
+	key := string(c.key)
+
+	if c.partitions != nil {
+		row, err := c.load()
+		if err != nil {
+			return false, err
+		}
+		return false, c.partitions[partitionOf(key, c.depth)].write(row)
+	}
+
+	c.seen[key] = struct{}{}
+	c.size += int64(len(key)) + distinctOverhead
+	if c.size > c.config.MemoryLimit && c.depth < maxGroupDepth {
+		if err := c.openPartitions(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (c *ColumnCursorWithDistinct) openPartitions() error {
+	c.partitions = make([]*spillFile, spillPartitions)
+	for i := range c.partitions {
+		file, err := newSpillFile(c.config.TempDir, "zenith-distinct-*.part")
+		if err != nil {
+			return err
+		}
+		c.partitions[i] = file
+	}
+	return nil
+}
+
+func (c *ColumnCursorWithDistinct) value(column string) (interface{}, error) {
+	if c.input != nil {
+		return c.replay[column], nil
+	}
+	return c.base.ScanField(column)
+}
+
+// load returns the distinct columns of the current row, to be spilled.
+func (c *ColumnCursorWithDistinct) load() (map[string]interface{}, error) {
+	if c.input != nil {
+		return c.replay, nil
+	}
+	row := make(map[string]interface{}, len(c.config.Columns))
+	for _, column := range c.config.Columns {
+		value, err := c.base.ScanField(column)
+		if err != nil {
+			return nil, err
+		}
+		row[column] = value
+	}
+	return row, nil
+}
+
+func (c *ColumnCursorWithDistinct) Scan(dest map[string]interface{}) error {
+	for _, column := range c.config.Columns {
+		value, err := c.value(column)
+		if err != nil {
+			return err
+		}
+		dest[column] = value
+	}
+	return nil
+}
+
+func (c *ColumnCursorWithDistinct) ScanField(field string) (interface{}, error) {
+	if _, ok := c.scanMap[field]; !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", field)
+	}
+	return c.value(field)
+}
+
+func (c *ColumnCursorWithDistinct) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	if c.input != nil {
+		return scanRowValue(c.replay, col.Name(), value)
+	}
+	return c.base.FastScanField(col, value)
+}
+
+func (c *ColumnCursorWithDistinct) ScanMap() map[string]*buffer.Scanner {
+	return c.scanMap
+}
+
+func (c *ColumnCursorWithDistinct) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.base.Err()
+}
+
+func (c *ColumnCursorWithDistinct) Close() error {
+	var err error
+	for _, file := range c.partitions {
+		if closeErr := file.close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	for _, p := range c.pending {
+		if closeErr := p.file.close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	if c.input != nil {
+		if closeErr := c.input.close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	c.partitions, c.pending, c.input = nil, nil, nil
+	if closeErr := c.base.Close(); closeErr != nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (c *ColumnCursorWithDistinct) Count() (int64, error) {
+	var count int64
+	for c.Next() {
+		count++
+	}
+	return count, c.Err()
+}
+
+func (c *ColumnCursorWithDistinct) Reader() storage.Reader {
+	return c.base.Reader()
+}
+
+func (c *ColumnCursorWithDistinct) Plan() *storage.PlanNode {
+	detail := "on " + strings.Join(c.config.Columns, ", ")
+	if c.stats.analyze && c.spilled > 0 {
+		detail += fmt.Sprintf(" (spilled %d partitions)", c.spilled)
+	}
+	return c.stats.node("Hash Distinct", detail, c.base.Plan())
+}
+
+func (c *ColumnCursorWithDistinct) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
+func (c *ColumnCursorWithDistinct) WithIDs(ids []int64) (storage.Cursor, error) {
+	return newColumnCursorFromIds(c, ids)
+}
+
+func (c *ColumnCursorWithDistinct) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *ColumnCursorWithDistinct) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *ColumnCursorWithDistinct) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *ColumnCursorWithDistinct) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *ColumnCursorWithDistinct) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *ColumnCursorWithDistinct) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *ColumnCursorWithDistinct) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *ColumnCursorWithDistinct) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *ColumnCursorWithDistinct) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *ColumnCursorWithDistinct) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *ColumnCursorWithDistinct) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *ColumnCursorWithDistinct) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
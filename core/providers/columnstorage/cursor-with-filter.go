@@ -1,14 +1,16 @@
 package columnstorage
 
 import (
+	"github.com/onnasoft/ZenithSQL/core/buffer"
 	"github.com/onnasoft/ZenithSQL/core/storage"
 	"github.com/onnasoft/ZenithSQL/io/filters"
-	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
 type ColumnCursorWithFilter struct {
 	base   storage.Cursor
 	filter *filters.Filter
+	err    error
+	stats  cursorStats
 }
 
 func newColumnCursorWithFilter(cursor storage.Cursor, filter *filters.Filter) (*ColumnCursorWithFilter, error) {
@@ -17,7 +19,9 @@ func newColumnCursorWithFilter(cursor storage.Cursor, filter *filters.Filter) (*
 		filter: filter,
 	}
 
-	c.filter.Prepare(cursor.Reader().ScanMap())
+	if err := c.filter.Prepare(cursor.ScanMap()); err != nil {
+		return nil, err
+	}
 
 	return c, nil
 }
@@ -27,9 +31,16 @@ func (c *ColumnCursorWithFilter) ColumnsData() map[string]storage.ColumnData {
 }
 
 func (c *ColumnCursorWithFilter) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *ColumnCursorWithFilter) next() bool {
 	for c.base.Next() {
+		c.stats.scanned++
 		ok, err := c.filter.Execute()
 		if err != nil {
+			c.err = err
 			return false
 		}
 		if ok {
@@ -52,6 +63,13 @@ func (c *ColumnCursorWithFilter) FastScanField(col storage.ColumnData, value int
 	return c.base.FastScanField(col, value)
 }
 
+func (c *ColumnCursorWithFilter) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.base.Err()
+}
+
 func (c *ColumnCursorWithFilter) Close() error {
 	return c.base.Close()
 }
@@ -76,6 +94,23 @@ func (c *ColumnCursorWithFilter) Reader() storage.Reader {
 	return c.base.Reader()
 }
 
+func (c *ColumnCursorWithFilter) ScanMap() map[string]*buffer.Scanner {
+	return c.base.ScanMap()
+}
+
+func (c *ColumnCursorWithFilter) Plan() *storage.PlanNode {
+	return c.stats.node("Filter", c.filter.String(), c.base.Plan())
+}
+
+func (c *ColumnCursorWithFilter) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
+func (c *ColumnCursorWithFilter) orderedBy() []string {
+	return orderOf(c.base)
+}
+
 func (c *ColumnCursorWithFilter) WithIDs(ids []int64) (storage.Cursor, error) {
 	return newColumnCursorFromIds(c, ids)
 }
@@ -84,8 +119,8 @@ func (c *ColumnCursorWithFilter) WithFilter(filter *filters.Filter) (storage.Cur
 	return newColumnCursorWithFilter(c, filter)
 }
 
-func (c *ColumnCursorWithFilter) WithGroupBy(groupBy []string, aggregations []statement.Aggregation) (storage.Cursor, error) {
-	return newColumnCursorWithGroupBy(c, groupBy, aggregations)
+func (c *ColumnCursorWithFilter) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
 }
 
 func (c *ColumnCursorWithFilter) WithLimit(limit int64) (storage.Cursor, error) {
@@ -95,3 +130,35 @@ func (c *ColumnCursorWithFilter) WithLimit(limit int64) (storage.Cursor, error)
 func (c *ColumnCursorWithFilter) WithSkip(skip int64) (storage.Cursor, error) {
 	return newColumnCursorWithSkip(c, skip)
 }
+
+func (c *ColumnCursorWithFilter) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *ColumnCursorWithFilter) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *ColumnCursorWithFilter) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *ColumnCursorWithFilter) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *ColumnCursorWithFilter) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *ColumnCursorWithFilter) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *ColumnCursorWithFilter) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *ColumnCursorWithFilter) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -2,42 +2,105 @@ package columnstorage
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/onnasoft/ZenithSQL/core/buffer"
 	"github.com/onnasoft/ZenithSQL/core/storage"
 	"github.com/onnasoft/ZenithSQL/io/filters"
-	"github.com/onnasoft/ZenithSQL/io/statement"
-	"github.com/onnasoft/ZenithSQL/model/aggregate"
 )
 
+// ColumnCursorWithGroupBy produces one row per group of its base cursor,
+// holding the group by columns and the aggregations under their names. The
+// base cursor is drained by a hash aggregation on the first call to Next.
 type ColumnCursorWithGroupBy struct {
-	base     storage.Cursor
-	groupBy  []string
-	agg      []statement.Aggregation
-	aggFnMap map[string]aggregate.Aggregate
+	base   storage.Cursor
+	config storage.GroupConfig
+	// columns are read from the base cursor when a row is spilled: the
+	// group by columns and the aggregated ones.
+	columns []string
+	types   map[string]*buffer.Scanner
+
+	// replay is the spilled row being aggregated, read by replayMap.
+	replay    map[string]interface{}
+	replayMap map[string]*buffer.Scanner
+
+	grouped    bool
+	pending    []*group
+	partitions []groupPartition
+	spilled    int
+	current    map[string]interface{}
+	err        error
+	stats      cursorStats
+}
+
+// groupPartition is a spilled partition waiting to be aggregated.
+type groupPartition struct {
+	file  *spillFile
+	depth int
 }
 
-func newColumnCursorWithGroupBy(cursor storage.Cursor, groupBy []string, aggregation []statement.Aggregation) (*ColumnCursorWithGroupBy, error) {
-	aggFnMap := make(map[string]aggregate.Aggregate)
-	scanMap := cursor.Reader().ScanMap()
-	for _, agg := range aggregation {
-		scanner, ok := scanMap[agg.Column]
+func newColumnCursorWithGroupBy(cursor storage.Cursor, config storage.GroupConfig) (*ColumnCursorWithGroupBy, error) {
+	if len(config.Columns) == 0 && len(config.Aggregations) == 0 {
+		return nil, fmt.Errorf("group by requires columns or aggregations")
+	}
+	if config.MemoryLimit <= 0 {
+		config.MemoryLimit = storage.DefaultGroupMemoryLimit
+	}
+
+	c := &ColumnCursorWithGroupBy{
+		base:   cursor,
+		config: config,
+		types:  make(map[string]*buffer.Scanner),
+	}
+
+	scanMap := cursor.ScanMap()
+	for _, column := range config.Columns {
+		scanner, ok := scanMap[column]
 		if !ok {
-			return nil, fmt.Errorf("column %s not found in cursor", agg.Column)
+			return nil, fmt.Errorf("column %s not found in cursor", column)
 		}
+		c.addColumn(column)
+		c.types[column] = scanner
+	}
+	for _, agg := range config.Aggregations {
+		if agg.Column != "*" {
+			c.addColumn(agg.Column)
+		}
+	}
 
-		fn, err := aggregate.New(scanner.Type, agg.Function, scanner)
-		if err != nil {
-			return nil, fmt.Errorf("error creating aggregate function: %v", err)
+	// Aggregates are built once up front so that unsupported columns fail
+	// here rather than in the middle of the scan.
+	probe, err := newGroupTable(config, scanMap, 0).newGroup(nil)
+	if err != nil {
+		return nil, err
+	}
+	for i, agg := range config.Aggregations {
+		name := agg.Name()
+		if _, ok := c.types[name]; ok {
+			return nil, fmt.Errorf("duplicate column %s in group by result", name)
+		}
+		c.types[name] = &buffer.Scanner{Type: probe.aggs[i].ResultType(), Nullable: true}
+	}
+
+	c.replayMap = make(map[string]*buffer.Scanner, len(c.columns))
+	for _, column := range c.columns {
+		c.replayMap[column] = &buffer.Scanner{
+			Type: scanMap[column].Type,
+			Scan: func(value interface{}) (bool, error) {
+				return scanRowValue(c.replay, column, value)
+			},
+			Nullable: scanMap[column].Nullable,
 		}
-		aggFnMap[agg.Column] = fn
 	}
 
-	return &ColumnCursorWithGroupBy{
-		base:     cursor,
-		agg:      aggregation,
-		groupBy:  groupBy,
-		aggFnMap: aggFnMap,
-	}, nil
+	return c, nil
+}
+
+func (c *ColumnCursorWithGroupBy) addColumn(column string) {
+	if !slices.Contains(c.columns, column) {
+		c.columns = append(c.columns, column)
+	}
 }
 
 func (c *ColumnCursorWithGroupBy) ColumnsData() map[string]storage.ColumnData {
@@ -45,60 +108,217 @@ func (c *ColumnCursorWithGroupBy) ColumnsData() map[string]storage.ColumnData {
 }
 
 func (c *ColumnCursorWithGroupBy) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *ColumnCursorWithGroupBy) next() bool {
+	if c.err != nil {
+		return false
+	}
+	if !c.grouped {
+		c.grouped = true
+		if c.err = c.aggregate(); c.err != nil {
+			return false
+		}
+	}
+
+	for len(c.pending) == 0 {
+		if len(c.partitions) == 0 {
+			return false
+		}
+		p := c.partitions[len(c.partitions)-1]
+		c.partitions = c.partitions[:len(c.partitions)-1]
+		if c.err = c.aggregatePartition(p); c.err != nil {
+			return false
+		}
+	}
 
-	dataMap := make(map[string]interface{})
+	g := c.pending[0]
+	c.pending = c.pending[1:]
+	c.current, c.err = c.row(g)
+	return c.err == nil
+}
 
+func (c *ColumnCursorWithGroupBy) aggregate() error {
+	table := newGroupTable(c.config, c.base.ScanMap(), 0)
+	keys := make([]interface{}, len(c.config.Columns))
 	for c.base.Next() {
-		key := ""
-		for _, col := range c.groupBy {
-			val, err := c.base.ScanField(col)
+		c.stats.scanned++
+		for i, column := range c.config.Columns {
+			value, err := c.base.ScanField(column)
 			if err != nil {
-				return false
-			}
-			if val == nil {
-				continue
+				return err
 			}
-			key += fmt.Sprintf("%v", val)
+			keys[i] = value
+		}
+		if err := table.add(keys, c.load); err != nil {
+			return err
 		}
+	}
+	if err := c.base.Err(); err != nil {
+		return err
+	}
 
-		if key == "" {
-			continue
+	// Without group by columns there is a single group, even for no rows.
+	if len(c.config.Columns) == 0 && len(table.order) == 0 {
+		g, err := table.newGroup(nil)
+		if err != nil {
+			return err
+		}
+		table.order = append(table.order, g)
+	}
+	return c.finish(table)
+}
+
+func (c *ColumnCursorWithGroupBy) load() (map[string]interface{}, error) {
+	row := make(map[string]interface{}, len(c.columns))
+	for _, column := range c.columns {
+		value, err := c.base.ScanField(column)
+		if err != nil {
+			return nil, err
+		}
+		row[column] = value
+	}
+	return row, nil
+}
+
+func (c *ColumnCursorWithGroupBy) aggregatePartition(p groupPartition) error {
+	defer p.file.close()
+
+	table := newGroupTable(c.config, c.replayMap, p.depth)
+	keys := make([]interface{}, len(c.config.Columns))
+	for {
+		row, ok, err := p.file.next()
+		if err != nil {
+			return err
 		}
+		if !ok {
+			break
+		}
+		c.replay = row
+		for i, column := range c.config.Columns {
+			keys[i] = row[column]
+		}
+		if err := table.add(keys, func() (map[string]interface{}, error) { return row, nil }); err != nil {
+			return err
+		}
+	}
+	return c.finish(table)
+}
 
-		if _, ok := dataMap[key]; !ok {
-			dataMap[key] = make([]interface{}, 0, 1000)
+// finish queues the groups of table and the partitions it spilled.
+func (c *ColumnCursorWithGroupBy) finish(table *groupTable) error {
+	c.pending = table.order
+	for _, file := range table.partitions {
+		c.partitions = append(c.partitions, groupPartition{file: file, depth: table.depth + 1})
+		if err := file.rewind(); err != nil {
+			return err
 		}
+	}
+	c.spilled += len(table.partitions)
+	return nil
+}
 
-		record := c.base.Reader().Values()
-		fmt.Println("record", record)
+func (c *ColumnCursorWithGroupBy) row(g *group) (map[string]interface{}, error) {
+	row := make(map[string]interface{}, len(c.config.Columns)+len(g.aggs))
+	for i, column := range c.config.Columns {
+		row[column] = g.keys[i]
+	}
+	for i, agg := range g.aggs {
+		value, err := agg.Result()
+		if err != nil {
+			return nil, err
+		}
+		row[c.config.Aggregations[i].Name()] = value
 	}
-	return false
+	return row, nil
 }
 
 func (c *ColumnCursorWithGroupBy) Scan(dest map[string]interface{}) error {
-	return c.base.Scan(dest)
+	if c.current == nil {
+		return fmt.Errorf("cursor is not positioned on a row")
+	}
+	for k, v := range c.current {
+		dest[k] = v
+	}
+	return nil
 }
 
 func (c *ColumnCursorWithGroupBy) ScanField(field string) (interface{}, error) {
-	return c.base.ScanField(field)
+	value, ok := c.current[field]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", field)
+	}
+	return value, nil
 }
 
 func (c *ColumnCursorWithGroupBy) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
-	return c.base.FastScanField(col, value)
+	return scanRowValue(c.current, col.Name(), value)
+}
+
+func (c *ColumnCursorWithGroupBy) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.base.Err()
 }
 
 func (c *ColumnCursorWithGroupBy) Close() error {
+	for _, p := range c.partitions {
+		p.file.close()
+	}
+	c.partitions = nil
 	return c.base.Close()
 }
 
 func (c *ColumnCursorWithGroupBy) Count() (int64, error) {
-	return c.base.Count()
+	var count int64
+	for c.Next() {
+		count++
+	}
+	return count, c.Err()
 }
 
 func (c *ColumnCursorWithGroupBy) Reader() storage.Reader {
 	return c.base.Reader()
 }
 
+// ScanMap returns scanners for the columns of the grouped rows.
+func (c *ColumnCursorWithGroupBy) ScanMap() map[string]*buffer.Scanner {
+	result := make(map[string]*buffer.Scanner, len(c.types))
+	for name, scanner := range c.types {
+		result[name] = &buffer.Scanner{
+			Type: scanner.Type,
+			Scan: func(value interface{}) (bool, error) {
+				return scanRowValue(c.current, name, value)
+			},
+			Nullable: scanner.Nullable,
+		}
+	}
+	return result
+}
+
+func (c *ColumnCursorWithGroupBy) Plan() *storage.PlanNode {
+	items := make([]string, len(c.config.Aggregations))
+	for i, agg := range c.config.Aggregations {
+		items[i] = agg.Name()
+	}
+	detail := strings.Join(items, ", ")
+	if len(c.config.Columns) > 0 {
+		detail = strings.TrimSuffix("by "+strings.Join(c.config.Columns, ", ")+": "+detail, ": ")
+	}
+	if c.stats.analyze && c.spilled > 0 {
+		detail += fmt.Sprintf(" (spilled %d partitions)", c.spilled)
+	}
+	return c.stats.node("Hash Aggregate", detail, c.base.Plan())
+}
+
+func (c *ColumnCursorWithGroupBy) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
 func (c *ColumnCursorWithGroupBy) WithIDs(ids []int64) (storage.Cursor, error) {
 	return newColumnCursorFromIds(c, ids)
 }
@@ -107,8 +327,8 @@ func (c *ColumnCursorWithGroupBy) WithFilter(filter *filters.Filter) (storage.Cu
 	return newColumnCursorWithFilter(c, filter)
 }
 
-func (c *ColumnCursorWithGroupBy) WithGroupBy(groupBy []string, aggregations []statement.Aggregation) (storage.Cursor, error) {
-	return newColumnCursorWithGroupBy(c, groupBy, aggregations)
+func (c *ColumnCursorWithGroupBy) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
 }
 
 func (c *ColumnCursorWithGroupBy) WithLimit(limit int64) (storage.Cursor, error) {
@@ -118,3 +338,35 @@ func (c *ColumnCursorWithGroupBy) WithLimit(limit int64) (storage.Cursor, error)
 func (c *ColumnCursorWithGroupBy) WithSkip(skip int64) (storage.Cursor, error) {
 	return newColumnCursorWithSkip(c, skip)
 }
+
+func (c *ColumnCursorWithGroupBy) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *ColumnCursorWithGroupBy) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *ColumnCursorWithGroupBy) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *ColumnCursorWithGroupBy) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *ColumnCursorWithGroupBy) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *ColumnCursorWithGroupBy) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *ColumnCursorWithGroupBy) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *ColumnCursorWithGroupBy) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -0,0 +1,557 @@
+package columnstorage
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// columnCursorWithJoin produces the rows of its left input joined with
+// those of its right input. Joined rows hold every column under its
+// qualified name, and also under its bare name when no other column has
+// that name. The inputs are read on the first call to Next and the joined
+// rows are materialized, so the readers of the inputs no longer point at
+// the current row.
+type columnCursorWithJoin struct {
+	left   *joinInput
+	right  *joinInput
+	config storage.JoinConfig
+	merge  bool
+	// keepLeft and keepRight tell whether rows of an input without a match
+	// are produced, with nulls for the columns of the other input.
+	keepLeft  bool
+	keepRight bool
+	// semi tells that the join only filters the left rows, by whether they
+	// have a match, and that they keep the names of their columns.
+	semi  bool
+	types map[string]*buffer.Scanner
+	// short maps the qualified names that are also available bare to their
+	// bare names.
+	short map[string]string
+
+	hash    hashJoin
+	sorted  mergeJoin
+	queue   []joinPair
+	current map[string]interface{}
+	err     error
+	stats   cursorStats
+}
+
+// joinPair is a joined row before it is turned into a map. A nil side is
+// the missing match of an outer join.
+type joinPair struct {
+	left  []interface{}
+	right []interface{}
+}
+
+// joinColumn is a column of an input of a join: the name the input gives
+// it and its qualified name in the joined rows.
+type joinColumn struct {
+	source string
+	name   string
+}
+
+// joinInput reads the rows of one side of a join as value slices, ordered
+// like columns.
+type joinInput struct {
+	cursor  storage.Cursor
+	columns []joinColumn
+	types   []*buffer.Scanner
+	// keys are the indexes in columns of the join keys.
+	keys []int
+}
+
+// newJoinInput reads cursor as an input of a join. With all, every column
+// keeps the name the cursor gives it, as the left input of a semi join.
+func newJoinInput(cursor storage.Cursor, alias string, keys []string, all bool) (*joinInput, error) {
+	scanMap := cursor.ScanMap()
+	names := make([]string, 0, len(scanMap))
+	for name := range scanMap {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	in := &joinInput{cursor: cursor}
+	for _, name := range names {
+		qualified := strings.Contains(name, ".")
+		switch {
+		case all:
+			in.columns = append(in.columns, joinColumn{source: name, name: name})
+		case alias == "" && qualified:
+			in.columns = append(in.columns, joinColumn{source: name, name: name})
+		case alias != "" && !qualified:
+			in.columns = append(in.columns, joinColumn{source: name, name: alias + "." + name})
+		default:
+			continue
+		}
+		in.types = append(in.types, scanMap[name])
+	}
+
+	for _, key := range keys {
+		i := slices.IndexFunc(in.columns, func(c joinColumn) bool { return c.source == key || c.name == key })
+		if i < 0 {
+			return nil, fmt.Errorf("column %s not found in cursor", key)
+		}
+		in.keys = append(in.keys, i)
+	}
+	return in, nil
+}
+
+// keySources returns the names the input gives its join keys.
+func (in *joinInput) keySources() []string {
+	sources := make([]string, len(in.keys))
+	for i, k := range in.keys {
+		sources[i] = in.columns[k].source
+	}
+	return sources
+}
+
+// next advances the input and returns its row, or false at its end.
+func (in *joinInput) next() ([]interface{}, bool, error) {
+	if !in.cursor.Next() {
+		return nil, false, in.cursor.Err()
+	}
+	values := make([]interface{}, len(in.columns))
+	for i, column := range in.columns {
+		value, err := in.cursor.ScanField(column.source)
+		if err != nil {
+			return nil, false, err
+		}
+		values[i] = value
+	}
+	return values, true, nil
+}
+
+// read returns the next row of an input, counting it as scanned.
+func (c *columnCursorWithJoin) read(in *joinInput) ([]interface{}, bool, error) {
+	values, ok, err := in.next()
+	if ok {
+		c.stats.scanned++
+	}
+	return values, ok, err
+}
+
+// encode and decode convert rows to and from the maps of spill files.
+func (in *joinInput) encode(values []interface{}) map[string]interface{} {
+	row := make(map[string]interface{}, len(values))
+	for i, column := range in.columns {
+		row[column.source] = values[i]
+	}
+	return row
+}
+
+func (in *joinInput) decode(row map[string]interface{}) []interface{} {
+	values := make([]interface{}, len(in.columns))
+	for i, column := range in.columns {
+		values[i] = row[column.source]
+	}
+	return values
+}
+
+// keyValues returns the join keys of a row, or false when one is null and
+// the row cannot match.
+func (in *joinInput) keyValues(values []interface{}) ([]interface{}, bool) {
+	keys := make([]interface{}, len(in.keys))
+	for i, k := range in.keys {
+		if values[k] == nil {
+			return nil, false
+		}
+		keys[i] = values[k]
+	}
+	return keys, true
+}
+
+func newColumnCursorWithJoin(left, right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	if len(config.Keys) == 0 {
+		return nil, fmt.Errorf("join requires at least one pair of columns")
+	}
+	if config.MemoryLimit <= 0 {
+		config.MemoryLimit = storage.DefaultJoinMemoryLimit
+	}
+
+	leftKeys := make([]string, len(config.Keys))
+	rightKeys := make([]string, len(config.Keys))
+	for i, key := range config.Keys {
+		leftKeys[i], rightKeys[i] = key.Left, key.Right
+	}
+
+	c := &columnCursorWithJoin{
+		config:    config,
+		keepLeft:  config.Type == statement.LeftJoin || config.Type == statement.FullJoin || config.Type == statement.AntiJoin,
+		keepRight: config.Type == statement.RightJoin || config.Type == statement.FullJoin,
+		semi:      config.Type == statement.SemiJoin || config.Type == statement.AntiJoin,
+	}
+	var err error
+	if c.left, err = newJoinInput(left, config.LeftAlias, leftKeys, c.semi); err != nil {
+		return nil, err
+	}
+	if c.right, err = newJoinInput(right, config.RightAlias, rightKeys, false); err != nil {
+		return nil, err
+	}
+	switch {
+	case c.semi:
+		// Semi joins only look up the keys of the left rows, by hash.
+	case config.Strategy == statement.MergeJoin:
+		c.merge = true
+	case config.Strategy == statement.AutoJoin:
+		c.merge = isOrderedBy(left, c.left.keySources()) && isOrderedBy(right, c.right.keySources())
+	}
+	for i := range config.Keys {
+		l, r := c.left.keys[i], c.right.keys[i]
+		lt, rt := c.left.types[l].Type, c.right.types[r].Type
+		if kind := joinKeyKind(lt); kind == "" || kind != joinKeyKind(rt) {
+			return nil, fmt.Errorf("cannot join %s of type %s with %s of type %s",
+				c.left.columns[l].name, lt, c.right.columns[r].name, rt)
+		}
+	}
+
+	// Merge joins read their inputs ordered by the keys, sorting the ones
+	// that are not. A sorted cursor names its columns like the cursor it
+	// sorts, so only the cursor of the input is replaced.
+	if c.merge {
+		for _, in := range []*joinInput{c.left, c.right} {
+			if isOrderedBy(in.cursor, in.keySources()) {
+				continue
+			}
+			sorted, err := in.cursor.WithSort(c.sortConfig(in.keySources()))
+			if err != nil {
+				return nil, err
+			}
+			in.cursor = sorted
+		}
+	}
+
+	c.types = make(map[string]*buffer.Scanner)
+	c.short = make(map[string]string)
+	if c.semi {
+		for i, column := range c.left.columns {
+			c.types[column.name] = c.left.types[i]
+		}
+		return c, nil
+	}
+	counts := make(map[string]int)
+	for _, in := range []*joinInput{c.left, c.right} {
+		nullable := (in == c.left && c.keepRight) || (in == c.right && c.keepLeft)
+		for i, column := range in.columns {
+			if _, ok := c.types[column.name]; ok {
+				return nil, fmt.Errorf("duplicate column %s in join result", column.name)
+			}
+			c.types[column.name] = &buffer.Scanner{
+				Type:     in.types[i].Type,
+				Nullable: in.types[i].Nullable || nullable,
+			}
+			counts[bareName(column.name)]++
+		}
+	}
+	for _, in := range []*joinInput{c.left, c.right} {
+		for _, column := range in.columns {
+			if bare := bareName(column.name); counts[bare] == 1 {
+				c.short[column.name] = bare
+				c.types[bare] = c.types[column.name]
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (c *columnCursorWithJoin) sortConfig(keys []string) storage.SortConfig {
+	config := storage.SortConfig{MemoryLimit: c.config.MemoryLimit, TempDir: c.config.TempDir}
+	for _, key := range keys {
+		config.Keys = append(config.Keys, storage.SortKey{Column: key, NullsLast: true})
+	}
+	return config
+}
+
+func bareName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// orderedCursor is implemented by cursors that produce their rows in
+// ascending order of some columns, nulls last.
+type orderedCursor interface {
+	orderedBy() []string
+}
+
+func orderOf(cursor storage.Cursor) []string {
+	if ordered, ok := cursor.(orderedCursor); ok {
+		return ordered.orderedBy()
+	}
+	return nil
+}
+
+// isOrderedBy tells whether cursor produces its rows ordered by columns.
+func isOrderedBy(cursor storage.Cursor, columns []string) bool {
+	order := orderOf(cursor)
+	return len(order) >= len(columns) && slices.Equal(order[:len(columns)], columns)
+}
+
+// joinKeyKind groups the types whose values can be compared with each
+// other, and is empty for types that cannot be join keys.
+func joinKeyKind(dt fields.DataType) string {
+	switch dt.(type) {
+	case fields.Int8Type, fields.Int16Type, fields.Int32Type, fields.Int64Type,
+		fields.Uint8Type, fields.Uint16Type, fields.Uint32Type, fields.Uint64Type,
+		fields.Float32Type, fields.Float64Type:
+		return "number"
+	case fields.StringType, fields.EnumType:
+		return "string"
+	case fields.TimestampType, fields.TimestampTZType, fields.DateType:
+		return "time"
+	case fields.BoolType, fields.IntervalType, fields.TimeOfDayType:
+		return dt.String()
+	}
+	return ""
+}
+
+// appendJoinKey encodes join keys so that values comparing equal, such as
+// an int32 and a float64 holding the same number, produce equal keys.
+func appendJoinKey(buf []byte, keys []interface{}) []byte {
+	for _, key := range keys {
+		buf = appendKeyValue(buf, normalizeJoinKey(key))
+	}
+	return buf
+}
+
+func normalizeJoinKey(value interface{}) interface{} {
+	switch value.(type) {
+	case fields.TimeOfDay:
+		return value
+	}
+
+	rv := reflect.ValueOf(value)
+	switch {
+	case isSignedKind(rv.Kind()):
+		return rv.Int()
+	case isUnsignedKind(rv.Kind()):
+		if u := rv.Uint(); u > math.MaxInt64 {
+			return u
+		}
+		return int64(rv.Uint())
+	case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+		if f := rv.Float(); f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+			return int64(f)
+		}
+		return rv.Float()
+	}
+	return value
+}
+
+func (c *columnCursorWithJoin) ColumnsData() map[string]storage.ColumnData {
+	return c.left.cursor.ColumnsData()
+}
+
+func (c *columnCursorWithJoin) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithJoin) next() bool {
+	if c.err != nil {
+		return false
+	}
+	for len(c.queue) == 0 {
+		var ok bool
+		if c.merge {
+			ok, c.err = c.mergeStep()
+		} else {
+			ok, c.err = c.hashStep()
+		}
+		if c.err != nil || !ok {
+			c.current = nil
+			return false
+		}
+	}
+
+	pair := c.queue[0]
+	c.queue = c.queue[1:]
+	c.current = c.row(pair)
+	return true
+}
+
+// emit queues a joined row.
+func (c *columnCursorWithJoin) emit(left, right []interface{}) {
+	c.queue = append(c.queue, joinPair{left: left, right: right})
+}
+
+func (c *columnCursorWithJoin) row(pair joinPair) map[string]interface{} {
+	type side struct {
+		in     *joinInput
+		values []interface{}
+	}
+	sides := []side{{c.left, pair.left}, {c.right, pair.right}}
+	if c.semi {
+		sides = sides[:1]
+	}
+	row := make(map[string]interface{}, len(c.types))
+	for _, side := range sides {
+		for i, column := range side.in.columns {
+			var value interface{}
+			if side.values != nil {
+				value = side.values[i]
+			}
+			row[column.name] = value
+			if bare, ok := c.short[column.name]; ok {
+				row[bare] = value
+			}
+		}
+	}
+	return row
+}
+
+func (c *columnCursorWithJoin) Scan(dest map[string]interface{}) error {
+	if c.current == nil {
+		return fmt.Errorf("cursor is not positioned on a row")
+	}
+	for k, v := range c.current {
+		dest[k] = v
+	}
+	return nil
+}
+
+func (c *columnCursorWithJoin) ScanField(field string) (interface{}, error) {
+	value, ok := c.current[field]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", field)
+	}
+	return value, nil
+}
+
+func (c *columnCursorWithJoin) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	return scanRowValue(c.current, col.Name(), value)
+}
+
+func (c *columnCursorWithJoin) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	if err := c.left.cursor.Err(); err != nil {
+		return err
+	}
+	return c.right.cursor.Err()
+}
+
+func (c *columnCursorWithJoin) Close() error {
+	c.hash.close()
+	leftErr := c.left.cursor.Close()
+	if err := c.right.cursor.Close(); err != nil {
+		return err
+	}
+	return leftErr
+}
+
+func (c *columnCursorWithJoin) Count() (int64, error) {
+	var count int64
+	for c.Next() {
+		count++
+	}
+	return count, c.Err()
+}
+
+func (c *columnCursorWithJoin) Reader() storage.Reader {
+	return c.left.cursor.Reader()
+}
+
+// ScanMap returns scanners for the columns of the joined rows, under their
+// qualified names and their unambiguous bare names.
+func (c *columnCursorWithJoin) ScanMap() map[string]*buffer.Scanner {
+	result := make(map[string]*buffer.Scanner, len(c.types))
+	for name, scanner := range c.types {
+		result[name] = &buffer.Scanner{
+			Type: scanner.Type,
+			Scan: func(value interface{}) (bool, error) {
+				return scanRowValue(c.current, name, value)
+			},
+			Nullable: scanner.Nullable,
+		}
+	}
+	return result
+}
+
+func (c *columnCursorWithJoin) Plan() *storage.PlanNode {
+	conditions := make([]string, len(c.config.Keys))
+	for i := range c.config.Keys {
+		conditions[i] = c.left.columns[c.left.keys[i]].name + " = " + c.right.columns[c.right.keys[i]].name
+	}
+	detail := fmt.Sprintf("%s on %s", c.config.Type, strings.Join(conditions, " AND "))
+
+	operation := "Hash Join"
+	switch {
+	case c.merge:
+		operation = "Merge Join"
+	case c.config.Type == statement.SemiJoin:
+		operation, detail = "Hash Semi Join", "on "+strings.Join(conditions, " AND ")
+	case c.config.Type == statement.AntiJoin:
+		operation, detail = "Hash Anti Join", "on "+strings.Join(conditions, " AND ")
+	}
+	if c.stats.analyze && c.hash.spilled > 0 {
+		detail += fmt.Sprintf(" (spilled %d partitions)", c.hash.spilled)
+	}
+	return c.stats.node(operation, detail, c.left.cursor.Plan(), c.right.cursor.Plan())
+}
+
+func (c *columnCursorWithJoin) Analyze() {
+	c.stats.analyze = true
+	c.left.cursor.Analyze()
+	c.right.cursor.Analyze()
+}
+
+func (c *columnCursorWithJoin) WithIDs(ids []int64) (storage.Cursor, error) {
+	return nil, fmt.Errorf("cannot select rows by id from a join")
+}
+
+func (c *columnCursorWithJoin) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *columnCursorWithJoin) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *columnCursorWithJoin) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *columnCursorWithJoin) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *columnCursorWithJoin) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithJoin) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithJoin) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithJoin) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithJoin) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithJoin) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithJoin) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithJoin) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
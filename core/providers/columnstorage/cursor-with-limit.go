@@ -1,15 +1,18 @@
 package columnstorage
 
 import (
+	"strconv"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
 	"github.com/onnasoft/ZenithSQL/core/storage"
 	"github.com/onnasoft/ZenithSQL/io/filters"
-	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
 type columnCursorWithLimit struct {
 	base     storage.Cursor
 	limit    int64
 	returned int64
+	stats    cursorStats
 }
 
 func newColumnCursorWithLimit(base storage.Cursor, limit int64) (storage.Cursor, error) {
@@ -24,10 +27,16 @@ func (c *columnCursorWithLimit) ColumnsData() map[string]storage.ColumnData {
 }
 
 func (c *columnCursorWithLimit) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithLimit) next() bool {
 	if c.limit >= 0 && c.returned >= c.limit {
 		return false
 	}
 	if c.base.Next() {
+		c.stats.scanned++
 		c.returned++
 		return true
 	}
@@ -46,6 +55,10 @@ func (c *columnCursorWithLimit) FastScanField(col storage.ColumnData, value inte
 	return c.base.FastScanField(col, value)
 }
 
+func (c *columnCursorWithLimit) Err() error {
+	return c.base.Err()
+}
+
 func (c *columnCursorWithLimit) Close() error {
 	return c.base.Close()
 }
@@ -58,6 +71,23 @@ func (c *columnCursorWithLimit) Reader() storage.Reader {
 	return c.base.Reader()
 }
 
+func (c *columnCursorWithLimit) ScanMap() map[string]*buffer.Scanner {
+	return c.base.ScanMap()
+}
+
+func (c *columnCursorWithLimit) Plan() *storage.PlanNode {
+	return c.stats.node("Limit", strconv.FormatInt(c.limit, 10), c.base.Plan())
+}
+
+func (c *columnCursorWithLimit) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
+func (c *columnCursorWithLimit) orderedBy() []string {
+	return orderOf(c.base)
+}
+
 func (c *columnCursorWithLimit) WithIDs(ids []int64) (storage.Cursor, error) {
 	return c.base.WithIDs(ids)
 }
@@ -66,8 +96,8 @@ func (c *columnCursorWithLimit) WithFilter(f *filters.Filter) (storage.Cursor, e
 	return c.base.WithFilter(f)
 }
 
-func (c *columnCursorWithLimit) WithGroupBy(groupBy []string, aggs []statement.Aggregation) (storage.Cursor, error) {
-	return c.base.WithGroupBy(groupBy, aggs)
+func (c *columnCursorWithLimit) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return c.base.WithGroupBy(config)
 }
 
 func (c *columnCursorWithLimit) WithLimit(limit int64) (storage.Cursor, error) {
@@ -77,3 +107,35 @@ func (c *columnCursorWithLimit) WithLimit(limit int64) (storage.Cursor, error) {
 func (c *columnCursorWithLimit) WithSkip(skip int64) (storage.Cursor, error) {
 	return newColumnCursorWithSkip(c, skip)
 }
+
+func (c *columnCursorWithLimit) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithLimit) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithLimit) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithLimit) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithLimit) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithLimit) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithLimit) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithLimit) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
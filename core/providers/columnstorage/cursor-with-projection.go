@@ -0,0 +1,218 @@
+package columnstorage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+)
+
+// columnCursorWithProjection adds computed columns to the rows of its base
+// cursor. Each expression is evaluated at most once per row, when one of
+// its columns is first read.
+type columnCursorWithProjection struct {
+	base        storage.Cursor
+	projections []storage.Projection
+	index       map[string]int
+	values      []interface{}
+	evaluated   []bool
+	stats       cursorStats
+}
+
+func newColumnCursorWithProjection(base storage.Cursor, projections []storage.Projection) (storage.Cursor, error) {
+	c := &columnCursorWithProjection{
+		base:        base,
+		projections: projections,
+		index:       make(map[string]int, len(projections)),
+		values:      make([]interface{}, len(projections)),
+		evaluated:   make([]bool, len(projections)),
+	}
+
+	scanMap := base.ScanMap()
+	for i, p := range projections {
+		if _, ok := c.index[p.Name]; ok {
+			return nil, fmt.Errorf("duplicate column %s in projection", p.Name)
+		}
+		if err := p.Expression.Prepare(scanMap); err != nil {
+			return nil, fmt.Errorf("invalid expression %s: %w", p.Expression, err)
+		}
+		c.index[p.Name] = i
+	}
+	return c, nil
+}
+
+func (c *columnCursorWithProjection) ColumnsData() map[string]storage.ColumnData {
+	return c.base.ColumnsData()
+}
+
+func (c *columnCursorWithProjection) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithProjection) next() bool {
+	clear(c.evaluated)
+	if !c.base.Next() {
+		return false
+	}
+	c.stats.scanned++
+	return true
+}
+
+func (c *columnCursorWithProjection) value(i int) (interface{}, error) {
+	if !c.evaluated[i] {
+		value, err := c.projections[i].Expression.Eval()
+		if err != nil {
+			return nil, err
+		}
+		c.values[i], c.evaluated[i] = value, true
+	}
+	return c.values[i], nil
+}
+
+func (c *columnCursorWithProjection) Scan(dest map[string]interface{}) error {
+	if err := c.base.Scan(dest); err != nil {
+		return err
+	}
+	for i, p := range c.projections {
+		value, err := c.value(i)
+		if err != nil {
+			return err
+		}
+		dest[p.Name] = value
+	}
+	return nil
+}
+
+func (c *columnCursorWithProjection) ScanField(field string) (interface{}, error) {
+	if i, ok := c.index[field]; ok {
+		return c.value(i)
+	}
+	return c.base.ScanField(field)
+}
+
+func (c *columnCursorWithProjection) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	if i, ok := c.index[col.Name()]; ok {
+		return c.scanValue(i, value)
+	}
+	return c.base.FastScanField(col, value)
+}
+
+func (c *columnCursorWithProjection) scanValue(i int, value interface{}) (bool, error) {
+	current, err := c.value(i)
+	if err != nil || current == nil {
+		return false, err
+	}
+	if err := assignValue(value, current); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *columnCursorWithProjection) ScanMap() map[string]*buffer.Scanner {
+	scanMap := c.base.ScanMap()
+	result := make(map[string]*buffer.Scanner, len(scanMap)+len(c.projections))
+	for name, scanner := range scanMap {
+		result[name] = scanner
+	}
+	for i, p := range c.projections {
+		result[p.Name] = &buffer.Scanner{
+			Type: p.Expression.DataType(),
+			Scan: func(value interface{}) (bool, error) {
+				return c.scanValue(i, value)
+			},
+			Nullable: true,
+		}
+	}
+	return result
+}
+
+func (c *columnCursorWithProjection) Err() error {
+	return c.base.Err()
+}
+
+func (c *columnCursorWithProjection) Close() error {
+	return c.base.Close()
+}
+
+func (c *columnCursorWithProjection) Count() (int64, error) {
+	return c.base.Count()
+}
+
+func (c *columnCursorWithProjection) Reader() storage.Reader {
+	return c.base.Reader()
+}
+
+func (c *columnCursorWithProjection) Plan() *storage.PlanNode {
+	items := make([]string, len(c.projections))
+	for i, p := range c.projections {
+		items[i] = p.Expression.String()
+		if p.Name != items[i] {
+			items[i] += " AS " + p.Name
+		}
+	}
+	return c.stats.node("Project", strings.Join(items, ", "), c.base.Plan())
+}
+
+func (c *columnCursorWithProjection) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
+func (c *columnCursorWithProjection) orderedBy() []string {
+	return orderOf(c.base)
+}
+
+func (c *columnCursorWithProjection) WithIDs(ids []int64) (storage.Cursor, error) {
+	return newColumnCursorFromIds(c, ids)
+}
+
+func (c *columnCursorWithProjection) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *columnCursorWithProjection) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *columnCursorWithProjection) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *columnCursorWithProjection) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *columnCursorWithProjection) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithProjection) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithProjection) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithProjection) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithProjection) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithProjection) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithProjection) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithProjection) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
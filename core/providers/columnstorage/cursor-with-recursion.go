@@ -0,0 +1,326 @@
+package columnstorage
+
+import (
+	"fmt"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// columnCursorWithRecursion returns the rows of its base cursor, the anchor
+// of a recursive common table expression, and then those of the steps of
+// the recursion. The rows returned are kept in a buffer, which becomes the
+// work table the next step reads once the current input is exhausted.
+type columnCursorWithRecursion struct {
+	base    storage.Cursor
+	config  storage.RecursionConfig
+	types   []fields.DataType
+	scanMap map[string]*buffer.Scanner
+	// input is the cursor read from, base or the last step, and columns
+	// are its columns matching those of the recursion.
+	input    storage.Cursor
+	columns  []string
+	produced *rowBuffer
+	seen     map[string]bool
+	key      []byte
+	steps    int
+	current  map[string]interface{}
+	err      error
+	stats    cursorStats
+}
+
+func newColumnCursorWithRecursion(base storage.Cursor, config storage.RecursionConfig) (storage.Cursor, error) {
+	if len(config.Columns) != len(config.InputColumns) {
+		return nil, fmt.Errorf("recursive common table expression %s has %d columns, its anchor returns %d",
+			config.Name, len(config.Columns), len(config.InputColumns))
+	}
+	if config.Limit <= 0 {
+		config.Limit = storage.DefaultRecursionLimit
+	}
+
+	c := &columnCursorWithRecursion{
+		base:     base,
+		config:   config,
+		scanMap:  make(map[string]*buffer.Scanner, len(config.Columns)),
+		input:    base,
+		columns:  config.InputColumns,
+		produced: newRowBuffer(config.MemoryLimit, config.TempDir),
+	}
+	if config.Distinct {
+		c.seen = make(map[string]bool)
+	}
+
+	scanMap := base.ScanMap()
+	for i, name := range config.InputColumns {
+		scanner, ok := scanMap[name]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in cursor", name)
+		}
+		c.types = append(c.types, scanner.Type)
+		c.scanMap[config.Columns[i]] = &buffer.Scanner{Type: scanner.Type, Nullable: true}
+	}
+	return c, nil
+}
+
+func (c *columnCursorWithRecursion) ColumnsData() map[string]storage.ColumnData {
+	return c.base.ColumnsData()
+}
+
+func (c *columnCursorWithRecursion) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithRecursion) next() bool {
+	for c.err == nil {
+		if c.input.Next() {
+			c.stats.scanned++
+			var ok bool
+			if ok, c.err = c.read(); ok || c.err != nil {
+				return ok
+			}
+			continue
+		}
+		if c.err = c.input.Err(); c.err != nil {
+			return false
+		}
+		if c.produced.count == 0 {
+			return false
+		}
+		c.err = c.step()
+	}
+	return false
+}
+
+// read makes the current row of the input the current row, unless it was
+// already returned by a recursion without ALL.
+func (c *columnCursorWithRecursion) read() (bool, error) {
+	row := make(map[string]interface{}, len(c.config.Columns))
+	c.key = c.key[:0]
+	for i, column := range c.columns {
+		value, err := c.input.ScanField(column)
+		if err != nil {
+			return false, err
+		}
+		value = fields.Coerce(c.types[i], value)
+		row[c.config.Columns[i]] = value
+		if c.seen != nil {
+			c.key = appendKeyValue(c.key, normalizeJoinKey(value))
+		}
+	}
+	if c.seen != nil {
+		if c.seen[string(c.key)] {
+			return false, nil
+		}
+		c.seen[string(c.key)] = true
+	}
+	if err := c.produced.add(row); err != nil {
+		return false, err
+	}
+	c.current = row
+	return true, nil
+}
+
+// step opens the next step of the recursion on the rows produced since the
+// previous one.
+func (c *columnCursorWithRecursion) step() error {
+	if c.steps >= c.config.Limit {
+		return fmt.Errorf("recursive common table expression %s did not end after %d steps", c.config.Name, c.config.Limit)
+	}
+	c.steps++
+
+	if err := c.produced.finish(); err != nil {
+		return err
+	}
+	work := newColumnCursorFromRows(c, c.config.Name, c.produced, c.scanMap)
+	c.produced = newRowBuffer(c.config.MemoryLimit, c.config.TempDir)
+	cursor, columns, err := c.config.Step(work)
+	if err != nil {
+		work.Close()
+		return err
+	}
+	if err := c.checkStep(cursor, columns); err != nil {
+		cursor.Close()
+		return err
+	}
+	if c.stats.analyze {
+		cursor.Analyze()
+	}
+
+	if c.input != c.base {
+		c.input.Close()
+	}
+	c.input, c.columns = cursor, columns
+	return nil
+}
+
+// checkStep checks that the columns of a step can be returned as those of
+// the anchor.
+func (c *columnCursorWithRecursion) checkStep(cursor storage.Cursor, columns []string) error {
+	if len(columns) != len(c.config.Columns) {
+		return fmt.Errorf("the recursive select of %s returns %d columns, its anchor %d",
+			c.config.Name, len(columns), len(c.config.Columns))
+	}
+	scanMap := cursor.ScanMap()
+	for i, column := range columns {
+		scanner, ok := scanMap[column]
+		if !ok {
+			return fmt.Errorf("column %s not found in cursor", column)
+		}
+		t := c.types[i]
+		kind := joinKeyKind(t)
+		if scanner.Type.String() != t.String() && (kind == "" || kind != joinKeyKind(scanner.Type)) {
+			return fmt.Errorf("column %s of the recursive select of %s has type %s, the anchor has %s",
+				column, c.config.Name, scanner.Type, t)
+		}
+	}
+	return nil
+}
+
+func (c *columnCursorWithRecursion) Scan(dest map[string]interface{}) error {
+	if c.current == nil {
+		return fmt.Errorf("cursor is not positioned on a row")
+	}
+	for k, v := range c.current {
+		dest[k] = v
+	}
+	return nil
+}
+
+func (c *columnCursorWithRecursion) ScanField(field string) (interface{}, error) {
+	value, ok := c.current[field]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", field)
+	}
+	return value, nil
+}
+
+func (c *columnCursorWithRecursion) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	return scanRowValue(c.current, col.Name(), value)
+}
+
+func (c *columnCursorWithRecursion) ScanMap() map[string]*buffer.Scanner {
+	result := make(map[string]*buffer.Scanner, len(c.scanMap))
+	for name, scanner := range c.scanMap {
+		result[name] = &buffer.Scanner{
+			Type: scanner.Type,
+			Scan: func(value interface{}) (bool, error) {
+				return scanRowValue(c.current, name, value)
+			},
+			Nullable: true,
+		}
+	}
+	return result
+}
+
+func (c *columnCursorWithRecursion) Err() error {
+	return c.err
+}
+
+func (c *columnCursorWithRecursion) Close() error {
+	err := c.produced.close()
+	if c.input != c.base {
+		if closeErr := c.input.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	if closeErr := c.base.Close(); closeErr != nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (c *columnCursorWithRecursion) Count() (int64, error) {
+	var count int64
+	for c.Next() {
+		count++
+	}
+	return count, c.err
+}
+
+func (c *columnCursorWithRecursion) Reader() storage.Reader {
+	return c.base.Reader()
+}
+
+// Plan describes the anchor and the last step run, or before any step a
+// step opened on an empty work table.
+func (c *columnCursorWithRecursion) Plan() *storage.PlanNode {
+	detail := c.config.Name
+	if c.stats.analyze {
+		detail += fmt.Sprintf(" (%d steps)", c.steps)
+	}
+	return c.stats.node("Recursive Union", detail, c.base.Plan(), c.stepPlan())
+}
+
+func (c *columnCursorWithRecursion) stepPlan() *storage.PlanNode {
+	if c.input != c.base {
+		return c.input.Plan()
+	}
+	work := newColumnCursorFromRows(c, c.config.Name, newRowBuffer(c.config.MemoryLimit, c.config.TempDir), c.scanMap)
+	cursor, _, err := c.config.Step(work)
+	if err != nil {
+		work.Close()
+		return work.Plan()
+	}
+	defer cursor.Close()
+	return cursor.Plan()
+}
+
+func (c *columnCursorWithRecursion) Analyze() {
+	c.stats.analyze = true
+	c.input.Analyze()
+}
+
+func (c *columnCursorWithRecursion) WithIDs(ids []int64) (storage.Cursor, error) {
+	return newColumnCursorFromIds(c, ids)
+}
+
+func (c *columnCursorWithRecursion) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *columnCursorWithRecursion) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *columnCursorWithRecursion) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *columnCursorWithRecursion) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *columnCursorWithRecursion) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithRecursion) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithRecursion) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithRecursion) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithRecursion) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithRecursion) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithRecursion) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithRecursion) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -0,0 +1,396 @@
+package columnstorage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// sideColumn holds, in the rows a set operation sorts, the input they were
+// read from. No column can be named so.
+const sideColumn = "#side"
+
+// columnCursorWithSetOperation combines the rows of two cursors. UNION ALL
+// reads the left rows and then the right ones as they come; the other
+// operations drain both inputs into a sorter on the first call to Next and
+// read the sorted rows back by groups of equal rows, returning each group
+// as many times as the operation keeps it.
+type columnCursorWithSetOperation struct {
+	left   storage.Cursor
+	right  storage.Cursor
+	config storage.SetOperationConfig
+	types  []fields.DataType
+	sorter *sorter
+	sorted bool
+	// leftDone tells that UNION ALL has read all the left rows.
+	leftDone bool
+	current  map[string]interface{}
+	// pending is the sorted row read past the end of the current group,
+	// and drained tells that there is none left.
+	pending map[string]interface{}
+	drained bool
+	// repeat is the number of times current is still to be returned.
+	repeat int64
+	err    error
+	stats  cursorStats
+}
+
+func newColumnCursorWithSetOperation(left, right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	if len(config.LeftColumns) != len(config.RightColumns) {
+		return nil, fmt.Errorf("each %s select must return the same number of columns, not %d and %d",
+			config.Operator, len(config.LeftColumns), len(config.RightColumns))
+	}
+
+	leftMap, rightMap := left.ScanMap(), right.ScanMap()
+	c := &columnCursorWithSetOperation{left: left, right: right, config: config}
+	for i, name := range config.LeftColumns {
+		l, ok := leftMap[name]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in cursor", name)
+		}
+		r, ok := rightMap[config.RightColumns[i]]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in cursor", config.RightColumns[i])
+		}
+		if _, ok := l.Type.(fields.GeoPointType); ok && (config.Operator != statement.Union || !config.All) {
+			return nil, fmt.Errorf("cannot compare geo point column %s in %s", name, config.Operator)
+		}
+		kind := joinKeyKind(l.Type)
+		if l.Type.String() != r.Type.String() && (kind == "" || kind != joinKeyKind(r.Type)) {
+			return nil, fmt.Errorf("column %s of %s has type %s, which cannot be combined with %s",
+				config.RightColumns[i], config.Operator, r.Type, l.Type)
+		}
+		c.types = append(c.types, l.Type)
+	}
+
+	if config.Operator != statement.Union || !config.All {
+		keys := make([]storage.SortKey, len(config.LeftColumns))
+		for i, name := range config.LeftColumns {
+			keys[i] = storage.SortKey{Column: name}
+		}
+		c.sorter = newSorter(storage.SortConfig{
+			Keys:        keys,
+			MemoryLimit: config.MemoryLimit,
+			TempDir:     config.TempDir,
+		})
+	}
+	return c, nil
+}
+
+func (c *columnCursorWithSetOperation) ColumnsData() map[string]storage.ColumnData {
+	return c.left.ColumnsData()
+}
+
+func (c *columnCursorWithSetOperation) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithSetOperation) next() bool {
+	if c.err != nil {
+		return false
+	}
+	if c.sorter == nil {
+		return c.append()
+	}
+	if !c.sorted {
+		c.sorted = true
+		if c.err = c.sort(); c.err != nil {
+			return false
+		}
+	}
+
+	var ok bool
+	ok, c.err = c.nextGroup()
+	return ok
+}
+
+// append returns the left rows and then the right ones.
+func (c *columnCursorWithSetOperation) append() bool {
+	if !c.leftDone {
+		if c.left.Next() {
+			c.stats.scanned++
+			c.current, c.err = c.read(c.left, c.config.LeftColumns)
+			return c.err == nil
+		}
+		if c.left.Err() != nil {
+			return false
+		}
+		c.leftDone = true
+	}
+	if !c.right.Next() {
+		return false
+	}
+	c.stats.scanned++
+	c.current, c.err = c.read(c.right, c.config.RightColumns)
+	return c.err == nil
+}
+
+// read returns the current row of an input under the names of the left
+// columns, with the values of the right input converted to their types.
+func (c *columnCursorWithSetOperation) read(cursor storage.Cursor, columns []string) (map[string]interface{}, error) {
+	row := make(map[string]interface{}, len(columns)+1)
+	for i, column := range columns {
+		value, err := cursor.ScanField(column)
+		if err != nil {
+			return nil, err
+		}
+		row[c.config.LeftColumns[i]] = fields.Coerce(c.types[i], value)
+	}
+	return row, nil
+}
+
+func (c *columnCursorWithSetOperation) keys(row map[string]interface{}) []interface{} {
+	keys := make([]interface{}, len(c.config.LeftColumns))
+	for i, column := range c.config.LeftColumns {
+		keys[i] = row[column]
+	}
+	return keys
+}
+
+func (c *columnCursorWithSetOperation) sort() error {
+	for side, in := range []struct {
+		cursor  storage.Cursor
+		columns []string
+	}{{c.left, c.config.LeftColumns}, {c.right, c.config.RightColumns}} {
+		for in.cursor.Next() {
+			c.stats.scanned++
+			row, err := c.read(in.cursor, in.columns)
+			if err != nil {
+				return err
+			}
+			keys := c.keys(row)
+			row[sideColumn] = side
+			if err := c.sorter.add(keys, row); err != nil {
+				return err
+			}
+		}
+		if err := in.cursor.Err(); err != nil {
+			return err
+		}
+	}
+	return c.sorter.finish()
+}
+
+// nextGroup moves to the next row to return, reading the sorted rows a
+// group of equal rows at a time.
+func (c *columnCursorWithSetOperation) nextGroup() (bool, error) {
+	for {
+		if c.repeat > 0 {
+			c.repeat--
+			return true, nil
+		}
+
+		first := c.pending
+		if first == nil {
+			if c.drained {
+				return false, nil
+			}
+			row, ok, err := c.sorter.next()
+			if err != nil || !ok {
+				return false, err
+			}
+			first = row
+		}
+
+		var counts [2]int64
+		counts[first[sideColumn].(int)]++
+		keys := sortRow{keys: c.keys(first)}
+		c.pending = nil
+		for {
+			row, ok, err := c.sorter.next()
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				c.drained = true
+				break
+			}
+			if c.sorter.compare(keys, sortRow{keys: c.keys(row)}) != 0 {
+				c.pending = row
+				break
+			}
+			counts[row[sideColumn].(int)]++
+		}
+		if c.sorter.err != nil {
+			return false, c.sorter.err
+		}
+
+		if n := c.copies(counts[0], counts[1]); n > 0 {
+			delete(first, sideColumn)
+			c.current, c.repeat = first, n-1
+			return true, nil
+		}
+	}
+}
+
+// copies returns how many times a row found l times in the left input and
+// r times in the right one is returned.
+func (c *columnCursorWithSetOperation) copies(l, r int64) int64 {
+	var n int64
+	switch c.config.Operator {
+	case statement.Union:
+		n = l + r
+	case statement.Intersect:
+		n = min(l, r)
+	case statement.Except:
+		n = max(l-r, 0)
+		if !c.config.All && r > 0 {
+			n = 0
+		}
+	}
+	if !c.config.All {
+		n = min(n, 1)
+	}
+	return n
+}
+
+func (c *columnCursorWithSetOperation) Scan(dest map[string]interface{}) error {
+	if c.current == nil {
+		return fmt.Errorf("cursor is not positioned on a row")
+	}
+	for k, v := range c.current {
+		dest[k] = v
+	}
+	return nil
+}
+
+func (c *columnCursorWithSetOperation) ScanField(field string) (interface{}, error) {
+	value, ok := c.current[field]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", field)
+	}
+	return value, nil
+}
+
+func (c *columnCursorWithSetOperation) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	return scanRowValue(c.current, col.Name(), value)
+}
+
+// ScanMap returns scanners of the left columns that read the current row,
+// which may come from either input and so may always be null.
+func (c *columnCursorWithSetOperation) ScanMap() map[string]*buffer.Scanner {
+	result := make(map[string]*buffer.Scanner, len(c.config.LeftColumns))
+	for i, name := range c.config.LeftColumns {
+		result[name] = &buffer.Scanner{
+			Type: c.types[i],
+			Scan: func(value interface{}) (bool, error) {
+				return scanRowValue(c.current, name, value)
+			},
+			Nullable: true,
+		}
+	}
+	return result
+}
+
+func (c *columnCursorWithSetOperation) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	if err := c.left.Err(); err != nil {
+		return err
+	}
+	return c.right.Err()
+}
+
+func (c *columnCursorWithSetOperation) Close() error {
+	var err error
+	if c.sorter != nil {
+		err = c.sorter.close()
+	}
+	if closeErr := c.left.Close(); closeErr != nil {
+		err = closeErr
+	}
+	if closeErr := c.right.Close(); closeErr != nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (c *columnCursorWithSetOperation) Count() (int64, error) {
+	var count int64
+	for c.Next() {
+		count++
+	}
+	return count, c.Err()
+}
+
+func (c *columnCursorWithSetOperation) Reader() storage.Reader {
+	return c.left.Reader()
+}
+
+func (c *columnCursorWithSetOperation) Plan() *storage.PlanNode {
+	op := string(c.config.Operator)
+	operation := op[:1] + strings.ToLower(op[1:])
+	if c.config.All {
+		operation += " All"
+	}
+	var detail string
+	if c.sorter != nil && c.stats.analyze && len(c.sorter.runs) > 0 {
+		detail = fmt.Sprintf("(spilled %d runs)", len(c.sorter.runs))
+	}
+	return c.stats.node(operation, detail, c.left.Plan(), c.right.Plan())
+}
+
+func (c *columnCursorWithSetOperation) Analyze() {
+	c.stats.analyze = true
+	c.left.Analyze()
+	c.right.Analyze()
+}
+
+func (c *columnCursorWithSetOperation) WithIDs(ids []int64) (storage.Cursor, error) {
+	return newColumnCursorFromIds(c, ids)
+}
+
+func (c *columnCursorWithSetOperation) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *columnCursorWithSetOperation) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *columnCursorWithSetOperation) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *columnCursorWithSetOperation) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *columnCursorWithSetOperation) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithSetOperation) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithSetOperation) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithSetOperation) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithSetOperation) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithSetOperation) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithSetOperation) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithSetOperation) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -1,15 +1,18 @@
 package columnstorage
 
 import (
+	"strconv"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
 	"github.com/onnasoft/ZenithSQL/core/storage"
 	"github.com/onnasoft/ZenithSQL/io/filters"
-	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
 type columnCursorWithSkip struct {
 	base    storage.Cursor
 	skip    int64
 	skipped int64
+	stats   cursorStats
 }
 
 func newColumnCursorWithSkip(base storage.Cursor, skip int64) (storage.Cursor, error) {
@@ -24,13 +27,23 @@ func (c *columnCursorWithSkip) ColumnsData() map[string]storage.ColumnData {
 }
 
 func (c *columnCursorWithSkip) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithSkip) next() bool {
 	for c.skipped < c.skip {
 		if !c.base.Next() {
 			return false
 		}
+		c.stats.scanned++
 		c.skipped++
 	}
-	return c.base.Next()
+	if !c.base.Next() {
+		return false
+	}
+	c.stats.scanned++
+	return true
 }
 
 func (c *columnCursorWithSkip) Scan(dest map[string]interface{}) error {
@@ -45,6 +58,10 @@ func (c *columnCursorWithSkip) FastScanField(col storage.ColumnData, value inter
 	return c.base.FastScanField(col, value)
 }
 
+func (c *columnCursorWithSkip) Err() error {
+	return c.base.Err()
+}
+
 func (c *columnCursorWithSkip) Close() error {
 	return c.base.Close()
 }
@@ -57,6 +74,23 @@ func (c *columnCursorWithSkip) Reader() storage.Reader {
 	return c.base.Reader()
 }
 
+func (c *columnCursorWithSkip) ScanMap() map[string]*buffer.Scanner {
+	return c.base.ScanMap()
+}
+
+func (c *columnCursorWithSkip) Plan() *storage.PlanNode {
+	return c.stats.node("Skip", strconv.FormatInt(c.skip, 10), c.base.Plan())
+}
+
+func (c *columnCursorWithSkip) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
+func (c *columnCursorWithSkip) orderedBy() []string {
+	return orderOf(c.base)
+}
+
 func (c *columnCursorWithSkip) WithIDs(ids []int64) (storage.Cursor, error) {
 	return c.base.WithIDs(ids)
 }
@@ -65,8 +99,8 @@ func (c *columnCursorWithSkip) WithFilter(f *filters.Filter) (storage.Cursor, er
 	return c.base.WithFilter(f)
 }
 
-func (c *columnCursorWithSkip) WithGroupBy(groupBy []string, aggs []statement.Aggregation) (storage.Cursor, error) {
-	return c.base.WithGroupBy(groupBy, aggs)
+func (c *columnCursorWithSkip) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return c.base.WithGroupBy(config)
 }
 
 func (c *columnCursorWithSkip) WithLimit(limit int64) (storage.Cursor, error) {
@@ -76,3 +110,35 @@ func (c *columnCursorWithSkip) WithLimit(limit int64) (storage.Cursor, error) {
 func (c *columnCursorWithSkip) WithSkip(skip int64) (storage.Cursor, error) {
 	return newColumnCursorWithSkip(c, skip)
 }
+
+func (c *columnCursorWithSkip) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithSkip) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithSkip) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithSkip) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithSkip) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithSkip) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithSkip) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithSkip) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -0,0 +1,250 @@
+package columnstorage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// columnCursorWithSort produces the rows of its base cursor ordered by the
+// sort keys. The base cursor is drained on the first call to Next, so the
+// rows are materialized and the base reader no longer points at the
+// current row.
+type columnCursorWithSort struct {
+	base    storage.Cursor
+	config  storage.SortConfig
+	sorter  *sorter
+	sorted  bool
+	current map[string]interface{}
+	err     error
+	stats   cursorStats
+}
+
+func newColumnCursorWithSort(base storage.Cursor, config storage.SortConfig) (storage.Cursor, error) {
+	if len(config.Keys) == 0 {
+		return nil, fmt.Errorf("sort requires at least one key")
+	}
+	scanMap := base.ScanMap()
+	for _, key := range config.Keys {
+		scanner, ok := scanMap[key.Column]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in cursor", key.Column)
+		}
+		if _, ok := scanner.Type.(fields.GeoPointType); ok {
+			return nil, fmt.Errorf("cannot order by geo point column %s", key.Column)
+		}
+	}
+
+	return &columnCursorWithSort{
+		base:   base,
+		config: config,
+		sorter: newSorter(config),
+	}, nil
+}
+
+func (c *columnCursorWithSort) ColumnsData() map[string]storage.ColumnData {
+	return c.base.ColumnsData()
+}
+
+func (c *columnCursorWithSort) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithSort) next() bool {
+	if c.err != nil {
+		return false
+	}
+	if !c.sorted {
+		c.sorted = true
+		if c.err = c.sort(); c.err != nil {
+			return false
+		}
+	}
+
+	var ok bool
+	c.current, ok, c.err = c.sorter.next()
+	return ok
+}
+
+func (c *columnCursorWithSort) sort() error {
+	keys := make([]interface{}, len(c.config.Keys))
+	for c.base.Next() {
+		c.stats.scanned++
+		for i, key := range c.config.Keys {
+			value, err := c.base.ScanField(key.Column)
+			if err != nil {
+				return err
+			}
+			keys[i] = value
+		}
+		if !c.sorter.accepts(keys) {
+			continue
+		}
+
+		values := make(map[string]interface{})
+		if err := c.base.Scan(values); err != nil {
+			return err
+		}
+		if err := c.sorter.add(keys, values); err != nil {
+			return err
+		}
+		keys = make([]interface{}, len(c.config.Keys))
+	}
+	return c.sorter.finish()
+}
+
+func (c *columnCursorWithSort) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.base.Err()
+}
+
+func (c *columnCursorWithSort) Scan(dest map[string]interface{}) error {
+	if c.current == nil {
+		return fmt.Errorf("cursor is not positioned on a row")
+	}
+	for k, v := range c.current {
+		dest[k] = v
+	}
+	return nil
+}
+
+func (c *columnCursorWithSort) ScanField(field string) (interface{}, error) {
+	value, ok := c.current[field]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", field)
+	}
+	return value, nil
+}
+
+func (c *columnCursorWithSort) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	return scanRowValue(c.current, col.Name(), value)
+}
+
+// ScanMap returns scanners that read the current sorted row instead of the
+// base reader.
+func (c *columnCursorWithSort) ScanMap() map[string]*buffer.Scanner {
+	scanMap := c.base.ScanMap()
+	result := make(map[string]*buffer.Scanner, len(scanMap))
+	for name, scanner := range scanMap {
+		result[name] = &buffer.Scanner{
+			Type: scanner.Type,
+			Scan: func(value interface{}) (bool, error) {
+				return scanRowValue(c.current, name, value)
+			},
+			Nullable: scanner.Nullable,
+		}
+	}
+	return result
+}
+
+func (c *columnCursorWithSort) Close() error {
+	err := c.sorter.close()
+	if closeErr := c.base.Close(); closeErr != nil {
+		return closeErr
+	}
+	return err
+}
+
+func (c *columnCursorWithSort) Count() (int64, error) {
+	var count int64
+	for c.Next() {
+		count++
+	}
+	return count, c.err
+}
+
+func (c *columnCursorWithSort) Reader() storage.Reader {
+	return c.base.Reader()
+}
+
+func (c *columnCursorWithSort) Plan() *storage.PlanNode {
+	keys := make([]string, len(c.config.Keys))
+	for i, key := range c.config.Keys {
+		keys[i] = key.String()
+	}
+	detail := strings.Join(keys, ", ")
+	if c.config.Limit > 0 {
+		detail += fmt.Sprintf(" (top %d)", c.config.Limit)
+	}
+	if runs := len(c.sorter.runs); c.stats.analyze && runs > 0 {
+		detail += fmt.Sprintf(" (spilled %d runs)", runs)
+	}
+	return c.stats.node("Sort", detail, c.base.Plan())
+}
+
+func (c *columnCursorWithSort) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
+// orderedBy returns the leading keys the rows are in ascending order of,
+// with nulls last.
+func (c *columnCursorWithSort) orderedBy() []string {
+	var columns []string
+	for _, key := range c.config.Keys {
+		if key.Descending || key.NullsSortFirst() {
+			break
+		}
+		columns = append(columns, key.Column)
+	}
+	return columns
+}
+
+func (c *columnCursorWithSort) WithIDs(ids []int64) (storage.Cursor, error) {
+	return newColumnCursorFromIds(c, ids)
+}
+
+func (c *columnCursorWithSort) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *columnCursorWithSort) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *columnCursorWithSort) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *columnCursorWithSort) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *columnCursorWithSort) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithSort) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithSort) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithSort) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithSort) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithSort) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithSort) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithSort) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -0,0 +1,239 @@
+package columnstorage
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// columnCursorWithUnnest expands an array column, producing one row per
+// element. Rows whose array is null or empty produce no rows.
+type columnCursorWithUnnest struct {
+	base    storage.Cursor
+	column  string
+	scanner *buffer.Scanner
+	element fields.DataType
+	values  []interface{}
+	index   int
+	err     error
+	stats   cursorStats
+}
+
+func newColumnCursorWithUnnest(base storage.Cursor, column string) (storage.Cursor, error) {
+	scanner, ok := base.ScanMap()[column]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", column)
+	}
+	arrayType, ok := scanner.Type.(fields.ArrayType)
+	if !ok {
+		return nil, fmt.Errorf("column %s is not an array", column)
+	}
+
+	return &columnCursorWithUnnest{
+		base:    base,
+		column:  column,
+		scanner: scanner,
+		element: arrayType.Element(),
+	}, nil
+}
+
+func (c *columnCursorWithUnnest) ColumnsData() map[string]storage.ColumnData {
+	return c.base.ColumnsData()
+}
+
+func (c *columnCursorWithUnnest) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithUnnest) next() bool {
+	c.index++
+	for c.index >= len(c.values) {
+		if !c.base.Next() {
+			return false
+		}
+		c.stats.scanned++
+		c.values = c.values[:0]
+		if _, err := c.scanner.Scan(&c.values); err != nil {
+			c.err = err
+			return false
+		}
+		c.index = 0
+	}
+	return true
+}
+
+func (c *columnCursorWithUnnest) current() interface{} {
+	if c.index < 0 || c.index >= len(c.values) {
+		return nil
+	}
+	return c.values[c.index]
+}
+
+func (c *columnCursorWithUnnest) Scan(dest map[string]interface{}) error {
+	if err := c.base.Scan(dest); err != nil {
+		return err
+	}
+	dest[c.column] = c.current()
+	return nil
+}
+
+func (c *columnCursorWithUnnest) ScanField(field string) (interface{}, error) {
+	if field == c.column {
+		return c.current(), nil
+	}
+	return c.base.ScanField(field)
+}
+
+func (c *columnCursorWithUnnest) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	if col.Name() == c.column {
+		return c.scanCurrent(value)
+	}
+	return c.base.FastScanField(col, value)
+}
+
+func (c *columnCursorWithUnnest) scanCurrent(value interface{}) (bool, error) {
+	current := c.current()
+	if current == nil {
+		return false, nil
+	}
+	if err := assignValue(value, current); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *columnCursorWithUnnest) ScanMap() map[string]*buffer.Scanner {
+	scanMap := c.base.ScanMap()
+	result := make(map[string]*buffer.Scanner, len(scanMap))
+	for name, scanner := range scanMap {
+		result[name] = scanner
+	}
+	result[c.column] = &buffer.Scanner{
+		Type:     c.element,
+		Scan:     c.scanCurrent,
+		Nullable: false,
+	}
+	return result
+}
+
+func (c *columnCursorWithUnnest) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.base.Err()
+}
+
+func (c *columnCursorWithUnnest) Close() error {
+	return c.base.Close()
+}
+
+// Count consumes the cursor, counting the elements of the arrays left.
+func (c *columnCursorWithUnnest) Count() (int64, error) {
+	var count int64
+	for c.Next() {
+		count++
+	}
+	return count, c.Err()
+}
+
+func (c *columnCursorWithUnnest) Reader() storage.Reader {
+	return c.base.Reader()
+}
+
+func (c *columnCursorWithUnnest) Plan() *storage.PlanNode {
+	return c.stats.node("Unnest", c.column, c.base.Plan())
+}
+
+func (c *columnCursorWithUnnest) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
+func (c *columnCursorWithUnnest) WithIDs(ids []int64) (storage.Cursor, error) {
+	return newColumnCursorFromIds(c, ids)
+}
+
+func (c *columnCursorWithUnnest) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *columnCursorWithUnnest) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *columnCursorWithUnnest) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *columnCursorWithUnnest) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *columnCursorWithUnnest) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithUnnest) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithUnnest) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithUnnest) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithUnnest) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithUnnest) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithUnnest) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithUnnest) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
+
+// scanRowValue stores the field of a materialized row into value, the way
+// a scanner reads a column: it reports false for nulls.
+func scanRowValue(row map[string]interface{}, field string, value interface{}) (bool, error) {
+	current, ok := row[field]
+	if !ok {
+		return false, fmt.Errorf("column %s not found in cursor", field)
+	}
+	if current == nil {
+		return false, nil
+	}
+	if err := assignValue(value, current); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// assignValue stores v into the pointer dest, the way DataType.Read fills
+// its output argument.
+func assignValue(dest interface{}, v interface{}) error {
+	ptr := reflect.ValueOf(dest)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("output must be a non-nil pointer, got %T", dest)
+	}
+	target := ptr.Elem()
+	value := reflect.ValueOf(v)
+	if !value.Type().AssignableTo(target.Type()) {
+		return fmt.Errorf("cannot assign %T to %s", v, target.Type())
+	}
+	target.Set(value)
+	return nil
+}
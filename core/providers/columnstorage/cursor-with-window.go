@@ -0,0 +1,717 @@
+package columnstorage
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/aggregate"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// columnCursorWithWindow adds window functions to the rows of its base
+// cursor. The base cursor is drained into a sorter ordering the rows by
+// partition and then by the window order on the first call to Next, and
+// the sorted rows are read back one partition at a time, which has to fit
+// in memory, to compute the functions of all its rows.
+type columnCursorWithWindow struct {
+	base   storage.Cursor
+	config storage.WindowConfig
+	sorter *sorter
+	frames []windowFrame
+	// aggs holds the aggregate of every aggregate function, nil for the
+	// others, reading the row of the partition in replay.
+	aggs      []aggregate.Aggregate
+	replay    map[string]interface{}
+	types     map[string]*buffer.Scanner
+	names     []string
+	sorted    bool
+	rows      []map[string]interface{}
+	pos       int
+	pending   map[string]interface{}
+	current   map[string]interface{}
+	partition int64
+	err       error
+	stats     cursorStats
+}
+
+// windowFrame is a statement.WindowFrame with its offsets read for the
+// type of the ORDER BY key.
+type windowFrame struct {
+	unit       statement.FrameUnit
+	start, end frameBound
+}
+
+// frameBound is a bound of a frame. ROWS offsets are counted in rows, and
+// RANGE offsets are a float64 or an interval added to the ORDER BY key.
+type frameBound struct {
+	kind   statement.FrameBoundType
+	rows   int
+	offset interface{}
+}
+
+func newColumnCursorWithWindow(base storage.Cursor, config storage.WindowConfig) (storage.Cursor, error) {
+	if len(config.Functions) == 0 {
+		return nil, fmt.Errorf("window requires at least one function")
+	}
+
+	scanMap := base.ScanMap()
+	keys := make([]storage.SortKey, 0, len(config.PartitionBy)+len(config.OrderBy))
+	for _, column := range config.PartitionBy {
+		keys = append(keys, storage.SortKey{Column: column})
+	}
+	keys = append(keys, config.OrderBy...)
+	for _, key := range keys {
+		scanner, ok := scanMap[key.Column]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found in cursor", key.Column)
+		}
+		if _, ok := scanner.Type.(fields.GeoPointType); ok {
+			return nil, fmt.Errorf("cannot partition or order a window by geo point column %s", key.Column)
+		}
+	}
+
+	c := &columnCursorWithWindow{
+		base:   base,
+		config: config,
+		sorter: newSorter(storage.SortConfig{Keys: keys, MemoryLimit: config.MemoryLimit, TempDir: config.TempDir}),
+		frames: make([]windowFrame, len(config.Functions)),
+		aggs:   make([]aggregate.Aggregate, len(config.Functions)),
+		types:  make(map[string]*buffer.Scanner, len(scanMap)+len(config.Functions)),
+		names:  make([]string, len(config.Functions)),
+	}
+	for name, scanner := range scanMap {
+		c.types[name] = scanner
+	}
+
+	for i, fn := range config.Functions {
+		name := fn.Name()
+		if _, ok := c.types[name]; ok {
+			return nil, fmt.Errorf("duplicate column %s in window result", name)
+		}
+		resultType, err := c.prepare(i, fn, scanMap)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		c.names[i] = name
+		c.types[name] = &buffer.Scanner{Type: resultType, Nullable: true}
+	}
+	return c, nil
+}
+
+// prepare builds the frame and the aggregate of the function at position i
+// and returns the type of its results.
+func (c *columnCursorWithWindow) prepare(i int, fn statement.WindowFunction, scanMap map[string]*buffer.Scanner) (fields.DataType, error) {
+	var scanner *buffer.Scanner
+	if fn.Column != "" && fn.Column != "*" {
+		var ok bool
+		if scanner, ok = scanMap[fn.Column]; !ok {
+			return nil, fmt.Errorf("column %s not found in cursor", fn.Column)
+		}
+	}
+
+	frame, err := c.frame(fn.Over.Frame, scanMap)
+	if err != nil {
+		return nil, err
+	}
+	c.frames[i] = frame
+
+	switch fn.Function {
+	case statement.RowNumber, statement.Rank, statement.DenseRank, statement.Ntile:
+		return fields.Int64Type{}, nil
+	case statement.PercentRank, statement.CumeDist:
+		return fields.Float64Type{}, nil
+	case statement.Lag, statement.Lead, statement.FirstValue, statement.LastValue, statement.NthValue:
+		return scanner.Type, nil
+	}
+
+	if fn.Column == "*" {
+		c.aggs[i] = aggregate.NewCountRowsAggregate()
+		return c.aggs[i].ResultType(), nil
+	}
+	column := fn.Column
+	replayScanner := &buffer.Scanner{
+		Type: scanner.Type,
+		Scan: func(value interface{}) (bool, error) {
+			return scanRowValue(c.replay, column, value)
+		},
+		Nullable: scanner.Nullable,
+	}
+	if c.aggs[i], err = aggregate.New(scanner.Type, aggregate.AggregateType(fn.Function), replayScanner); err != nil {
+		return nil, err
+	}
+	return c.aggs[i].ResultType(), nil
+}
+
+// frame resolves the frame of a function. Without one, the frame is the
+// whole partition, or the rows up to the peers of the current one when
+// the window is ordered.
+func (c *columnCursorWithWindow) frame(frame *statement.WindowFrame, scanMap map[string]*buffer.Scanner) (windowFrame, error) {
+	if frame == nil {
+		result := windowFrame{
+			unit:  statement.RangeFrame,
+			start: frameBound{kind: statement.UnboundedPreceding},
+			end:   frameBound{kind: statement.UnboundedFollowing},
+		}
+		if len(c.config.OrderBy) > 0 {
+			result.end.kind = statement.CurrentRow
+		}
+		return result, nil
+	}
+
+	result := windowFrame{unit: frame.Unit}
+	bounds := []*frameBound{&result.start, &result.end}
+	for i, bound := range []statement.FrameBound{frame.Start, frame.End} {
+		bounds[i].kind = bound.Type
+		if bound.Type != statement.Preceding && bound.Type != statement.Following {
+			continue
+		}
+		if frame.Unit == statement.RowsFrame {
+			rows, err := strconv.ParseInt(bound.Offset, 10, 0)
+			if err != nil || rows < 0 {
+				return result, fmt.Errorf("invalid ROWS offset %q", bound.Offset)
+			}
+			bounds[i].rows = int(rows)
+			continue
+		}
+
+		if len(c.config.OrderBy) != 1 {
+			return result, fmt.Errorf("RANGE with an offset requires exactly one ORDER BY key")
+		}
+		key := c.config.OrderBy[0].Column
+		switch joinKeyKind(scanMap[key].Type) {
+		case "number":
+			offset, err := strconv.ParseFloat(bound.Offset, 64)
+			if err != nil || offset < 0 {
+				return result, fmt.Errorf("invalid RANGE offset %q for number column %s", bound.Offset, key)
+			}
+			bounds[i].offset = offset
+		case "time":
+			offset, err := fields.ParseInterval(bound.Offset)
+			if err != nil {
+				return result, fmt.Errorf("invalid RANGE offset %q for column %s: %w", bound.Offset, key, err)
+			}
+			bounds[i].offset = offset
+		default:
+			return result, fmt.Errorf("RANGE offsets require a number, date or timestamp ORDER BY key, not %s", scanMap[key].Type)
+		}
+	}
+	return result, nil
+}
+
+func (c *columnCursorWithWindow) ColumnsData() map[string]storage.ColumnData {
+	return c.base.ColumnsData()
+}
+
+func (c *columnCursorWithWindow) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *columnCursorWithWindow) next() bool {
+	if c.err != nil {
+		return false
+	}
+	if !c.sorted {
+		c.sorted = true
+		if c.err = c.sort(); c.err != nil {
+			return false
+		}
+	}
+
+	if c.pos >= len(c.rows) {
+		var ok bool
+		if ok, c.err = c.nextPartition(); !ok {
+			return false
+		}
+	}
+	c.current = c.rows[c.pos]
+	c.pos++
+	return true
+}
+
+func (c *columnCursorWithWindow) sort() error {
+	for c.base.Next() {
+		c.stats.scanned++
+		values := make(map[string]interface{})
+		if err := c.base.Scan(values); err != nil {
+			return err
+		}
+		keys := make([]interface{}, len(c.sorter.config.Keys))
+		for i, key := range c.sorter.config.Keys {
+			keys[i] = values[key.Column]
+		}
+		if err := c.sorter.add(keys, values); err != nil {
+			return err
+		}
+	}
+	if err := c.base.Err(); err != nil {
+		return err
+	}
+	return c.sorter.finish()
+}
+
+// nextPartition reads the rows of the next partition and computes their
+// window functions.
+func (c *columnCursorWithWindow) nextPartition() (bool, error) {
+	row := c.pending
+	if row == nil {
+		var ok bool
+		var err error
+		if row, ok, err = c.sorter.next(); !ok || err != nil {
+			return false, err
+		}
+	}
+
+	key := c.partitionKey(row)
+	c.rows, c.pos, c.pending = []map[string]interface{}{row}, 0, nil
+	for {
+		next, ok, err := c.sorter.next()
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			break
+		}
+		if c.partitionKey(next) != key {
+			c.pending = next
+			break
+		}
+		c.rows = append(c.rows, next)
+	}
+	c.partition++
+	return true, c.compute(c.rows)
+}
+
+func (c *columnCursorWithWindow) partitionKey(row map[string]interface{}) string {
+	var buf []byte
+	for _, column := range c.config.PartitionBy {
+		buf = appendKeyValue(buf, row[column])
+	}
+	return string(buf)
+}
+
+// compute stores the value of every function in the rows of a partition.
+func (c *columnCursorWithWindow) compute(rows []map[string]interface{}) error {
+	peerStart, peerEnd := c.peers(rows)
+	for i, fn := range c.config.Functions {
+		name := c.names[i]
+		frame := c.frames[i]
+		aggStart, aggEnd := 0, 0
+		if c.aggs[i] != nil {
+			if err := c.aggs[i].Reset(); err != nil {
+				return err
+			}
+		}
+
+		dense := int64(0)
+		for r, row := range rows {
+			if peerStart[r] == r {
+				dense++
+			}
+
+			var value interface{}
+			switch fn.Function {
+			case statement.RowNumber:
+				value = int64(r + 1)
+			case statement.Rank:
+				value = int64(peerStart[r] + 1)
+			case statement.DenseRank:
+				value = dense
+			case statement.PercentRank:
+				value = 0.0
+				if len(rows) > 1 {
+					value = float64(peerStart[r]) / float64(len(rows)-1)
+				}
+			case statement.CumeDist:
+				value = float64(peerEnd[r]) / float64(len(rows))
+			case statement.Ntile:
+				value = ntile(r, len(rows), fn.Offset)
+			case statement.Lag, statement.Lead:
+				offset := int(fn.Offset)
+				if fn.Function == statement.Lag {
+					offset = -offset
+				}
+				value = fn.Default
+				if j := r + offset; j >= 0 && j < len(rows) {
+					value = rows[j][fn.Column]
+				}
+			default:
+				start, end, err := c.bounds(frame, rows, r, peerStart, peerEnd)
+				if err != nil {
+					return err
+				}
+				switch fn.Function {
+				case statement.FirstValue:
+					if start < end {
+						value = rows[start][fn.Column]
+					}
+				case statement.LastValue:
+					if start < end {
+						value = rows[end-1][fn.Column]
+					}
+				case statement.NthValue:
+					if j := start + int(fn.Offset) - 1; j < end {
+						value = rows[j][fn.Column]
+					}
+				default:
+					// Frames that keep their start and only grow, such as
+					// running totals, are aggregated incrementally.
+					if start != aggStart || end < aggEnd {
+						if err := c.aggs[i].Reset(); err != nil {
+							return err
+						}
+						aggStart, aggEnd = start, start
+					}
+					for ; aggEnd < end; aggEnd++ {
+						c.replay = rows[aggEnd]
+						if err := c.aggs[i].Execute(); err != nil {
+							return err
+						}
+					}
+					var err error
+					if value, err = c.aggs[i].Result(); err != nil {
+						return err
+					}
+				}
+			}
+			row[name] = value
+		}
+	}
+	return nil
+}
+
+// peers returns, for every row of a partition, the positions of the first
+// row of its peers, the rows with the same ORDER BY keys, and the position
+// past the last one.
+func (c *columnCursorWithWindow) peers(rows []map[string]interface{}) (start, end []int) {
+	start, end = make([]int, len(rows)), make([]int, len(rows))
+	var prev string
+	for r, row := range rows {
+		var buf []byte
+		for _, key := range c.config.OrderBy {
+			buf = appendKeyValue(buf, row[key.Column])
+		}
+		key := string(buf)
+		if r > 0 && key == prev {
+			start[r] = start[r-1]
+		} else {
+			start[r] = r
+		}
+		prev = key
+	}
+	for r := len(rows) - 1; r >= 0; r-- {
+		if r+1 < len(rows) && start[r+1] == start[r] {
+			end[r] = end[r+1]
+		} else {
+			end[r] = r + 1
+		}
+	}
+	return start, end
+}
+
+// bounds returns the frame of row r as the positions of its first row and
+// past its last one.
+func (c *columnCursorWithWindow) bounds(frame windowFrame, rows []map[string]interface{}, r int, peerStart, peerEnd []int) (int, int, error) {
+	var start, end int
+	switch frame.start.kind {
+	case statement.UnboundedPreceding:
+		start = 0
+	case statement.CurrentRow:
+		start = r
+		if frame.unit == statement.RangeFrame {
+			start = peerStart[r]
+		}
+	default:
+		if frame.unit == statement.RowsFrame {
+			start = r + frame.start.rows
+			if frame.start.kind == statement.Preceding {
+				start = r - frame.start.rows
+			}
+			start = max(0, min(start, len(rows)))
+			break
+		}
+		var err error
+		if start, err = c.seek(rows, r, frame.start, false, peerStart, peerEnd); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	switch frame.end.kind {
+	case statement.UnboundedFollowing:
+		end = len(rows)
+	case statement.CurrentRow:
+		end = r + 1
+		if frame.unit == statement.RangeFrame {
+			end = peerEnd[r]
+		}
+	default:
+		if frame.unit == statement.RowsFrame {
+			end = r + frame.end.rows + 1
+			if frame.end.kind == statement.Preceding {
+				end = r - frame.end.rows + 1
+			}
+			end = max(0, min(end, len(rows)))
+			break
+		}
+		var err error
+		if end, err = c.seek(rows, r, frame.end, true, peerStart, peerEnd); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, max(start, end), nil
+}
+
+// seek finds the first row whose ORDER BY key is at least the key of row r
+// moved by the offset of a RANGE bound, or past it when after is set. Rows
+// with a null key only frame their peers.
+func (c *columnCursorWithWindow) seek(rows []map[string]interface{}, r int, bound frameBound, after bool, peerStart, peerEnd []int) (int, error) {
+	key := c.config.OrderBy[0]
+	value := rows[r][key.Column]
+	if value == nil {
+		if after {
+			return peerEnd[r], nil
+		}
+		return peerStart[r], nil
+	}
+
+	forward := (bound.kind == statement.Following) != key.Descending
+	target, err := shiftValue(value, bound.offset, forward)
+	if err != nil {
+		return 0, err
+	}
+
+	var compareErr error
+	pos := sort.Search(len(rows), func(j int) bool {
+		x := rows[j][key.Column]
+		if x == nil {
+			return !key.NullsSortFirst()
+		}
+		cmp, err := fields.Compare(x, target)
+		if err != nil {
+			compareErr = err
+			return true
+		}
+		if key.Descending {
+			cmp = -cmp
+		}
+		if after {
+			return cmp > 0
+		}
+		return cmp >= 0
+	})
+	return pos, compareErr
+}
+
+// shiftValue adds a RANGE offset to a number or a time, or subtracts it
+// unless forward.
+func shiftValue(value, offset interface{}, forward bool) (interface{}, error) {
+	switch o := offset.(type) {
+	case fields.IntervalValue:
+		t, ok := value.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("cannot add an interval to %T", value)
+		}
+		if forward {
+			return o.AddTo(t), nil
+		}
+		return o.SubFrom(t), nil
+	case float64:
+		rv := reflect.ValueOf(value)
+		var f float64
+		switch {
+		case isSignedKind(rv.Kind()):
+			f = float64(rv.Int())
+		case isUnsignedKind(rv.Kind()):
+			f = float64(rv.Uint())
+		case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+			f = rv.Float()
+		default:
+			return nil, fmt.Errorf("cannot add a number to %T", value)
+		}
+		if forward {
+			return f + o, nil
+		}
+		return f - o, nil
+	}
+	return nil, fmt.Errorf("invalid RANGE offset %v", offset)
+}
+
+// ntile returns the bucket, from 1 to buckets, of row r of n rows split
+// in buckets as even as possible, the first ones taking one more row.
+func ntile(r, n int, buckets int64) int64 {
+	size, extra := int64(n)/buckets, int64(n)%buckets
+	i := int64(r)
+	if i < extra*(size+1) {
+		return i/(size+1) + 1
+	}
+	return extra + (i-extra*(size+1))/size + 1
+}
+
+func (c *columnCursorWithWindow) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.base.Err()
+}
+
+func (c *columnCursorWithWindow) Scan(dest map[string]interface{}) error {
+	if c.current == nil {
+		return fmt.Errorf("cursor is not positioned on a row")
+	}
+	for k, v := range c.current {
+		dest[k] = v
+	}
+	return nil
+}
+
+func (c *columnCursorWithWindow) ScanField(field string) (interface{}, error) {
+	value, ok := c.current[field]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in cursor", field)
+	}
+	return value, nil
+}
+
+func (c *columnCursorWithWindow) FastScanField(col storage.ColumnData, value interface{}) (bool, error) {
+	return scanRowValue(c.current, col.Name(), value)
+}
+
+// ScanMap returns scanners that read the current row, window functions
+// included, instead of the base reader.
+func (c *columnCursorWithWindow) ScanMap() map[string]*buffer.Scanner {
+	result := make(map[string]*buffer.Scanner, len(c.types))
+	for name, scanner := range c.types {
+		result[name] = &buffer.Scanner{
+			Type: scanner.Type,
+			Scan: func(value interface{}) (bool, error) {
+				return scanRowValue(c.current, name, value)
+			},
+			Nullable: scanner.Nullable,
+		}
+	}
+	return result
+}
+
+func (c *columnCursorWithWindow) Close() error {
+	err := c.sorter.close()
+	if closeErr := c.base.Close(); closeErr != nil {
+		return closeErr
+	}
+	return err
+}
+
+func (c *columnCursorWithWindow) Count() (int64, error) {
+	var count int64
+	for c.Next() {
+		count++
+	}
+	return count, c.Err()
+}
+
+func (c *columnCursorWithWindow) Reader() storage.Reader {
+	return c.base.Reader()
+}
+
+func (c *columnCursorWithWindow) Plan() *storage.PlanNode {
+	var parts []string
+	if len(c.config.PartitionBy) > 0 {
+		parts = append(parts, "partition by "+strings.Join(c.config.PartitionBy, ", "))
+	}
+	if len(c.config.OrderBy) > 0 {
+		keys := make([]string, len(c.config.OrderBy))
+		for i, key := range c.config.OrderBy {
+			keys[i] = key.String()
+		}
+		parts = append(parts, "order by "+strings.Join(keys, ", "))
+	}
+	detail := strings.Join(c.names, ", ")
+	if len(parts) > 0 {
+		detail = strings.Join(parts, " ") + ": " + detail
+	}
+	if c.stats.analyze {
+		detail += fmt.Sprintf(" (%d partitions)", c.partition)
+		if runs := len(c.sorter.runs); runs > 0 {
+			detail += fmt.Sprintf(" (spilled %d runs)", runs)
+		}
+	}
+	return c.stats.node("Window", detail, c.base.Plan())
+}
+
+func (c *columnCursorWithWindow) Analyze() {
+	c.stats.analyze = true
+	c.base.Analyze()
+}
+
+// orderedBy returns the leading keys the rows are in ascending order of,
+// with nulls last: the partition columns and then the window order.
+func (c *columnCursorWithWindow) orderedBy() []string {
+	columns := slices.Clone(c.config.PartitionBy)
+	for _, key := range c.config.OrderBy {
+		if key.Descending || key.NullsSortFirst() {
+			break
+		}
+		columns = append(columns, key.Column)
+	}
+	return columns
+}
+
+func (c *columnCursorWithWindow) WithIDs(ids []int64) (storage.Cursor, error) {
+	return newColumnCursorFromIds(c, ids)
+}
+
+func (c *columnCursorWithWindow) WithFilter(filter *filters.Filter) (storage.Cursor, error) {
+	return newColumnCursorWithFilter(c, filter)
+}
+
+func (c *columnCursorWithWindow) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
+}
+
+func (c *columnCursorWithWindow) WithLimit(limit int64) (storage.Cursor, error) {
+	return newColumnCursorWithLimit(c, limit)
+}
+
+func (c *columnCursorWithWindow) WithSkip(skip int64) (storage.Cursor, error) {
+	return newColumnCursorWithSkip(c, skip)
+}
+
+func (c *columnCursorWithWindow) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *columnCursorWithWindow) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *columnCursorWithWindow) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *columnCursorWithWindow) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *columnCursorWithWindow) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *columnCursorWithWindow) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *columnCursorWithWindow) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *columnCursorWithWindow) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -1,9 +1,9 @@
 package columnstorage
 
 import (
+	"github.com/onnasoft/ZenithSQL/core/buffer"
 	"github.com/onnasoft/ZenithSQL/core/storage"
 	"github.com/onnasoft/ZenithSQL/io/filters"
-	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
 type ColumnCursor struct {
@@ -11,6 +11,7 @@ type ColumnCursor struct {
 	limit  int64
 	skip   int64
 	count  int64
+	stats  cursorStats
 }
 
 func NewColumnCursor(reader *ColumnReader) *ColumnCursor {
@@ -27,11 +28,17 @@ func (c *ColumnCursor) ColumnsData() map[string]storage.ColumnData {
 }
 
 func (c *ColumnCursor) Next() bool {
+	start := c.stats.start()
+	return c.stats.done(start, c.next())
+}
+
+func (c *ColumnCursor) next() bool {
 	// Skip rows
 	for c.count < c.skip {
 		if !c.reader.Next() {
 			return false
 		}
+		c.stats.scanned++
 		c.count++
 	}
 
@@ -44,6 +51,7 @@ func (c *ColumnCursor) Next() bool {
 		return false
 	}
 
+	c.stats.scanned++
 	c.count++
 	return true
 }
@@ -69,6 +77,10 @@ func (c *ColumnCursor) FastScanField(col storage.ColumnData, value interface{})
 	return c.reader.FastGetValue(col, value)
 }
 
+func (c *ColumnCursor) Err() error {
+	return nil
+}
+
 func (c *ColumnCursor) Close() error {
 	return c.reader.Close()
 }
@@ -89,6 +101,24 @@ func (c *ColumnCursor) Reader() storage.Reader {
 	return c.reader
 }
 
+func (c *ColumnCursor) ScanMap() map[string]*buffer.Scanner {
+	return c.reader.ScanMap()
+}
+
+func (c *ColumnCursor) Plan() *storage.PlanNode {
+	return c.stats.node("Seq Scan", "")
+}
+
+func (c *ColumnCursor) Analyze() {
+	c.stats.analyze = true
+}
+
+// orderedBy reports that rows are read in the order they were inserted,
+// which is the order of their ids.
+func (c *ColumnCursor) orderedBy() []string {
+	return []string{"id"}
+}
+
 func (c *ColumnCursor) WithIDs(ids []int64) (storage.Cursor, error) {
 	return newColumnCursorFromIds(c, ids)
 }
@@ -97,8 +127,8 @@ func (c *ColumnCursor) WithFilter(filter *filters.Filter) (storage.Cursor, error
 	return newColumnCursorWithFilter(c, filter)
 }
 
-func (c *ColumnCursor) WithGroupBy(groupBy []string, aggregations []statement.Aggregation) (storage.Cursor, error) {
-	return newColumnCursorWithGroupBy(c, groupBy, aggregations)
+func (c *ColumnCursor) WithGroupBy(config storage.GroupConfig) (storage.Cursor, error) {
+	return newColumnCursorWithGroupBy(c, config)
 }
 
 func (c *ColumnCursor) WithLimit(limit int64) (storage.Cursor, error) {
@@ -108,3 +138,35 @@ func (c *ColumnCursor) WithLimit(limit int64) (storage.Cursor, error) {
 func (c *ColumnCursor) WithSkip(skip int64) (storage.Cursor, error) {
 	return newColumnCursorWithSkip(c, skip)
 }
+
+func (c *ColumnCursor) WithUnnest(column string) (storage.Cursor, error) {
+	return newColumnCursorWithUnnest(c, column)
+}
+
+func (c *ColumnCursor) WithSort(config storage.SortConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSort(c, config)
+}
+
+func (c *ColumnCursor) WithJoin(right storage.Cursor, config storage.JoinConfig) (storage.Cursor, error) {
+	return newColumnCursorWithJoin(c, right, config)
+}
+
+func (c *ColumnCursor) WithProjection(projections []storage.Projection) (storage.Cursor, error) {
+	return newColumnCursorWithProjection(c, projections)
+}
+
+func (c *ColumnCursor) WithWindow(config storage.WindowConfig) (storage.Cursor, error) {
+	return newColumnCursorWithWindow(c, config)
+}
+
+func (c *ColumnCursor) WithSetOperation(right storage.Cursor, config storage.SetOperationConfig) (storage.Cursor, error) {
+	return newColumnCursorWithSetOperation(c, right, config)
+}
+
+func (c *ColumnCursor) WithRecursion(config storage.RecursionConfig) (storage.Cursor, error) {
+	return newColumnCursorWithRecursion(c, config)
+}
+
+func (c *ColumnCursor) WithDistinct(config storage.DistinctConfig) (storage.Cursor, error) {
+	return newColumnCursorWithDistinct(c, config)
+}
@@ -0,0 +1,288 @@
+package columnstorage
+
+const (
+	// maxJoinDepth bounds how many times a partition is partitioned again
+	// when its right rows still do not fit in memory.
+	maxJoinDepth = 4
+	// joinRowOverhead approximates the memory held by a row in the hash
+	// table besides its values.
+	joinRowOverhead = 64
+)
+
+// hashJoin is the state of a hash join. The right input is loaded into a
+// hash table and the left input probes it. When the right input does not
+// fit in memory, both inputs are written to partitions by the hash of their
+// keys instead, and each pair of partitions is joined on its own.
+type hashJoin struct {
+	started bool
+	table   *joinTable
+	// probe returns the next left row to look up, and is nil once the
+	// left rows of the table have all been read.
+	probe func() ([]interface{}, bool, error)
+	// probing is the partition the left rows are read from, if any.
+	probing *spillFile
+	// unmatched is the position in the table of the next right row to
+	// check for a missing match.
+	unmatched  int
+	partitions []joinPartition
+	spilled    int
+}
+
+// joinPartition is a pair of spilled partitions waiting to be joined.
+type joinPartition struct {
+	right *spillFile
+	left  *spillFile
+	depth int
+}
+
+// joinTable holds right rows by the encoding of their keys. Rows with a
+// null key cannot match and are only kept in order, for outer joins.
+type joinTable struct {
+	rows  map[string][]*joinRow
+	order []*joinRow
+	size  int64
+	depth int
+	// right and left are the partitions rows go to once the table is full.
+	right []*spillFile
+	left  []*spillFile
+}
+
+type joinRow struct {
+	values  []interface{}
+	matched bool
+}
+
+// hashStep queues the joined rows of the next left row, the next unmatched
+// right rows or the rows of the next partition, and returns false once
+// there is nothing left.
+func (c *columnCursorWithJoin) hashStep() (bool, error) {
+	h := &c.hash
+	if !h.started {
+		h.started = true
+		table, err := c.buildTable(func() ([]interface{}, bool, error) { return c.read(c.right) }, 0)
+		if err != nil {
+			return false, err
+		}
+		h.table = table
+		h.probe = func() ([]interface{}, bool, error) { return c.read(c.left) }
+	}
+
+	if h.probe != nil {
+		values, ok, err := h.probe()
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			h.probe = nil
+			return true, c.queuePartitions()
+		}
+		return true, c.probeTable(values)
+	}
+
+	if c.keepRight && h.table.right == nil {
+		for ; h.unmatched < len(h.table.order) && len(c.queue) == 0; h.unmatched++ {
+			if row := h.table.order[h.unmatched]; !row.matched {
+				c.emit(nil, row.values)
+			}
+		}
+		if len(c.queue) > 0 {
+			return true, nil
+		}
+	}
+
+	if len(h.partitions) == 0 {
+		return false, nil
+	}
+	p := h.partitions[len(h.partitions)-1]
+	h.partitions = h.partitions[:len(h.partitions)-1]
+	defer p.right.close()
+
+	table, err := c.buildTable(spillSource(p.right, c.right), p.depth)
+	if err != nil {
+		p.left.close()
+		return false, err
+	}
+	h.table, h.unmatched, h.probing = table, 0, p.left
+	left := spillSource(p.left, c.left)
+	h.probe = func() ([]interface{}, bool, error) {
+		values, ok, err := left()
+		if !ok {
+			h.probing = nil
+			p.left.close()
+		}
+		return values, ok, err
+	}
+	return true, nil
+}
+
+// spillSource reads the rows of a partition of an input.
+func spillSource(file *spillFile, in *joinInput) func() ([]interface{}, bool, error) {
+	return func() ([]interface{}, bool, error) {
+		row, ok, err := file.next()
+		if !ok || err != nil {
+			return nil, false, err
+		}
+		return in.decode(row), true, nil
+	}
+}
+
+func (c *columnCursorWithJoin) buildTable(next func() ([]interface{}, bool, error), depth int) (*joinTable, error) {
+	t := &joinTable{rows: make(map[string][]*joinRow), depth: depth}
+	for {
+		values, ok, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return t, nil
+		}
+		if err := c.addRight(t, values); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *columnCursorWithJoin) addRight(t *joinTable, values []interface{}) error {
+	keys, ok := c.right.keyValues(values)
+	if !ok && !c.keepRight {
+		return nil
+	}
+	var key string
+	if ok {
+		key = string(appendJoinKey(nil, keys))
+	}
+
+	if t.right != nil {
+		return t.right[t.partition(key)].write(c.right.encode(values))
+	}
+
+	row := &joinRow{values: values}
+	t.order = append(t.order, row)
+	if ok {
+		t.rows[key] = append(t.rows[key], row)
+	}
+	t.size += joinRowOverhead
+	for _, value := range values {
+		t.size += valueSize(value)
+	}
+	// Rows sharing a single key cannot be split by partitioning them.
+	if t.size > c.config.MemoryLimit && t.depth < maxJoinDepth && len(t.rows) > 1 {
+		return c.spillTable(t)
+	}
+	return nil
+}
+
+// spillTable moves the rows of a full table to partitions, where the rest
+// of the right rows and the left rows will follow.
+func (c *columnCursorWithJoin) spillTable(t *joinTable) error {
+	t.right = make([]*spillFile, spillPartitions)
+	t.left = make([]*spillFile, spillPartitions)
+	for i := range t.right {
+		var err error
+		if t.right[i], err = newSpillFile(c.config.TempDir, "zenith-join-*.part"); err != nil {
+			return err
+		}
+		if t.left[i], err = newSpillFile(c.config.TempDir, "zenith-join-*.part"); err != nil {
+			return err
+		}
+	}
+	c.hash.spilled += spillPartitions
+
+	order := t.order
+	t.rows, t.order, t.size = nil, nil, 0
+	for _, row := range order {
+		var key string
+		if keys, ok := c.right.keyValues(row.values); ok {
+			key = string(appendJoinKey(nil, keys))
+		}
+		if err := t.right[t.partition(key)].write(c.right.encode(row.values)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// probeTable queues the rows joining a left row with the table, or sends
+// the left row to its partition when the table has spilled.
+func (c *columnCursorWithJoin) probeTable(values []interface{}) error {
+	t := c.hash.table
+	keys, ok := c.left.keyValues(values)
+	if !ok {
+		if c.keepLeft {
+			c.emit(values, nil)
+		}
+		return nil
+	}
+	key := string(appendJoinKey(nil, keys))
+
+	if t.left != nil {
+		return t.left[t.partition(key)].write(c.left.encode(values))
+	}
+
+	matches := t.rows[key]
+	if len(matches) == 0 && c.keepLeft {
+		c.emit(values, nil)
+	}
+	if c.semi {
+		if len(matches) > 0 && !c.keepLeft {
+			c.emit(values, nil)
+		}
+		return nil
+	}
+	for _, row := range matches {
+		row.matched = true
+		c.emit(values, row.values)
+	}
+	return nil
+}
+
+// queuePartitions queues the partitions of the table once all its left
+// rows have been read.
+func (c *columnCursorWithJoin) queuePartitions() error {
+	t := c.hash.table
+	if t.right == nil {
+		return nil
+	}
+	for i := range t.right {
+		c.hash.partitions = append(c.hash.partitions, joinPartition{right: t.right[i], left: t.left[i], depth: t.depth + 1})
+	}
+	right, left := t.right, t.left
+	t.right, t.left = nil, nil
+	for i := range right {
+		if err := right[i].rewind(); err != nil {
+			return err
+		}
+		if err := left[i].rewind(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *joinTable) partition(key string) int {
+	return partitionOf(key, t.depth)
+}
+
+// close removes the partitions not joined yet.
+func (h *hashJoin) close() {
+	for _, p := range h.partitions {
+		p.right.close()
+		p.left.close()
+	}
+	h.partitions = nil
+	if h.probing != nil {
+		h.probing.close()
+		h.probing = nil
+	}
+	if t := h.table; t != nil {
+		for i := range t.right {
+			if t.right[i] != nil {
+				t.right[i].close()
+			}
+			if t.left[i] != nil {
+				t.left[i].close()
+			}
+		}
+		t.right, t.left = nil, nil
+	}
+}
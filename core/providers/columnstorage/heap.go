@@ -0,0 +1,66 @@
+package columnstorage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+)
+
+// heapHeaderSize is reserved at the start of the heap file to persist the
+// offset where the next value is appended.
+const heapHeaderSize = 8
+
+const heapPageSize = 1 << 20
+
+// Heap is an append-only file holding the payload of variable-length
+// columns. Space of overwritten values is only reclaimed by compaction.
+type Heap struct {
+	*buffer.MMapFile
+	mu sync.Mutex
+}
+
+func OpenHeap(path string) (*Heap, error) {
+	file, err := buffer.Open(path, 0, heapPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Heap{MMapFile: file}
+	if h.tail() < heapHeaderSize {
+		h.setTail(heapHeaderSize)
+	}
+
+	return h, nil
+}
+
+func (h *Heap) Append(data []byte) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	offset := h.tail()
+	if len(data) == 0 {
+		return offset, nil
+	}
+
+	if !h.CanWrite(int(offset), len(data)) {
+		return 0, fmt.Errorf("heap exceeds buffer capacity at offset %d", offset)
+	}
+	copy(h.Data()[offset:], data)
+	h.setTail(offset + int64(len(data)))
+
+	return offset, nil
+}
+
+func (h *Heap) Read(offset int64, length int) ([]byte, error) {
+	return h.ReadAt(int(offset), length)
+}
+
+func (h *Heap) tail() int64 {
+	return int64(binary.LittleEndian.Uint64(h.Data()[:heapHeaderSize]))
+}
+
+func (h *Heap) setTail(offset int64) {
+	binary.LittleEndian.PutUint64(h.Data()[:heapHeaderSize], uint64(offset))
+}
@@ -0,0 +1,135 @@
+package columnstorage
+
+import "github.com/onnasoft/ZenithSQL/model/fields"
+
+// mergeJoin is the state of a merge join. Both inputs are read in ascending
+// order of their keys, with null keys last, so matching rows are found by
+// walking them side by side. Only the right rows sharing the current key
+// are held in memory.
+type mergeJoin struct {
+	started bool
+	// left and right are the current rows of the inputs, nil once an input
+	// is exhausted.
+	left  []interface{}
+	right []interface{}
+}
+
+// mergeStep queues the joined rows of the next key, or the next row without
+// a match, and returns false once both inputs are exhausted.
+func (c *columnCursorWithJoin) mergeStep() (bool, error) {
+	m := &c.sorted
+	if !m.started {
+		m.started = true
+		if err := c.advanceLeft(); err != nil {
+			return false, err
+		}
+		if err := c.advanceRight(); err != nil {
+			return false, err
+		}
+	}
+
+	switch {
+	case m.left == nil && m.right == nil:
+		return false, nil
+	case m.right == nil:
+		return true, c.unmatchedLeft()
+	case m.left == nil:
+		return true, c.unmatchedRight()
+	}
+
+	leftKeys, ok := c.left.keyValues(m.left)
+	if !ok {
+		return true, c.unmatchedLeft()
+	}
+	rightKeys, ok := c.right.keyValues(m.right)
+	if !ok {
+		return true, c.unmatchedRight()
+	}
+	cmp, err := compareJoinKeys(leftKeys, rightKeys)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case cmp < 0:
+		return true, c.unmatchedLeft()
+	case cmp > 0:
+		return true, c.unmatchedRight()
+	}
+
+	group := [][]interface{}{m.right}
+	for {
+		if err := c.advanceRight(); err != nil {
+			return false, err
+		}
+		same, err := c.sameKeys(c.right, m.right, rightKeys)
+		if err != nil {
+			return false, err
+		}
+		if !same {
+			break
+		}
+		group = append(group, m.right)
+	}
+	for {
+		for _, right := range group {
+			c.emit(m.left, right)
+		}
+		if err := c.advanceLeft(); err != nil {
+			return false, err
+		}
+		same, err := c.sameKeys(c.left, m.left, leftKeys)
+		if err != nil || !same {
+			return true, err
+		}
+	}
+}
+
+// sameKeys tells whether a row of an input, nil at its end, has the given
+// keys.
+func (c *columnCursorWithJoin) sameKeys(in *joinInput, values, keys []interface{}) (bool, error) {
+	if values == nil {
+		return false, nil
+	}
+	other, ok := in.keyValues(values)
+	if !ok {
+		return false, nil
+	}
+	cmp, err := compareJoinKeys(other, keys)
+	return cmp == 0, err
+}
+
+func (c *columnCursorWithJoin) unmatchedLeft() error {
+	if c.keepLeft {
+		c.emit(c.sorted.left, nil)
+	}
+	return c.advanceLeft()
+}
+
+func (c *columnCursorWithJoin) unmatchedRight() error {
+	if c.keepRight {
+		c.emit(nil, c.sorted.right)
+	}
+	return c.advanceRight()
+}
+
+func (c *columnCursorWithJoin) advanceLeft() error {
+	values, _, err := c.read(c.left)
+	c.sorted.left = values
+	return err
+}
+
+func (c *columnCursorWithJoin) advanceRight() error {
+	values, _, err := c.read(c.right)
+	c.sorted.right = values
+	return err
+}
+
+func compareJoinKeys(a, b []interface{}) (int, error) {
+	for i := range a {
+		cmp, err := fields.Compare(a[i], b[i])
+		if err != nil || cmp != 0 {
+			return cmp, err
+		}
+	}
+	return 0, nil
+}
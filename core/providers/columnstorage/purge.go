@@ -0,0 +1,266 @@
+package columnstorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// manifestFileName names the file recording the directory of the
+	// column files of a table, when they are not in the table directory
+	// itself. Replacing it is how a purge commits.
+	manifestFileName = "manifest.json"
+	// columnsDirPrefix starts the names of the directories a purge writes
+	// column files to.
+	columnsDirPrefix = "columns-"
+)
+
+type manifest struct {
+	Directory string `json:"directory"`
+}
+
+// Compact purges every row whose deleted_at is set.
+func (s *ColumnStorage) Compact(ctx context.Context) error {
+	s.Lock()
+	defer s.Unlock()
+
+	reader, err := NewColumnReader(s.columns.columns, s.StorageStats, true)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for reader.Next() {
+		if reader.isDeleted() {
+			ids = append(ids, reader.CurrentID())
+		}
+	}
+	reader.Close()
+
+	return s.purge(ctx, ids)
+}
+
+// Purge removes the rows ids, sorted ascending, from the table files. The
+// table is copied to a new directory of column files, where the rows
+// purged are left as tombstones: they keep their positions, so that no id
+// changes or is given again, but hold no values. Only the space their
+// values took in the heaps is reclaimed; the data files keep a record of
+// the width of the column for each of them. Rewriting the manifest to
+// name the new directory commits the purge; a purge interrupted before
+// leaves the table as it was. The columns of the new files then replace
+// the open ones, which the readers opened before keep reading until they
+// are closed.
+func (s *ColumnStorage) Purge(ctx context.Context, ids []int64) error {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.purge(ctx, ids)
+}
+
+func (s *ColumnStorage) purge(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	dir, err := os.MkdirTemp(s.BasePath, columnsDirPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to create purge directory: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	columns := make(map[string]*Column, len(s.fields))
+	closeColumns := func() {
+		for _, col := range columns {
+			col.Close()
+		}
+	}
+	for _, meta := range s.fields {
+		col, err := NewColumn(meta.Name, meta.DataType(), meta.Length, meta.Required, dir)
+		if err != nil {
+			closeColumns()
+			return fmt.Errorf("failed to initialize column %s: %w", meta.Name, err)
+		}
+		columns[meta.Name] = col
+	}
+
+	if err := s.copyRows(ctx, columns, ids); err != nil {
+		closeColumns()
+		return err
+	}
+
+	previous := s.columnsDir
+	if err := s.saveManifest(filepath.Base(dir)); err != nil {
+		closeColumns()
+		return err
+	}
+	committed = true
+	s.columnsDir = filepath.Base(dir)
+	s.replaceColumns(columns)
+
+	// The readers of the columns replaced keep their files mapped after
+	// they are removed.
+	return s.removeColumnFiles(previous)
+}
+
+// copyRows writes every row of the table to columns at its position,
+// leaving the rows ids, and the rows purged before, as tombstones.
+func (s *ColumnStorage) copyRows(ctx context.Context, columns map[string]*Column, ids []int64) error {
+	reader, err := NewColumnReader(s.columns.columns, s.StorageStats, true)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	writer := NewColumnWriter(columns)
+	for position := int64(0); position < s.StorageStats.TotalRows; position++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if err := reader.See(position + 1); err != nil {
+			return err
+		}
+		purged := reader.isPurged()
+		if len(ids) > 0 && ids[0] == position+1 {
+			ids = ids[1:]
+			purged = true
+		}
+
+		values := reader.Values()
+		for name, value := range values {
+			if purged {
+				value = nil
+			}
+			if err := writer.writeFieldInternal(position, name, value); err != nil {
+				return err
+			}
+		}
+		if purged {
+			col := columns[deletedColumn]
+			col.Data()[position*int64(col.Length+2)+statusByteOffset] = purgedStatus
+		}
+	}
+
+	for name, col := range columns {
+		if err := col.Sync(); err != nil {
+			return fmt.Errorf("failed to flush column %s: %w", name, err)
+		}
+		if col.heap != nil {
+			if err := col.heap.Sync(); err != nil {
+				return fmt.Errorf("failed to sync heap of column %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadManifest returns the directory of the column files the manifest
+// names, relative to the table directory, or "" for the table directory
+// itself when there is no manifest.
+func (s *ColumnStorage) loadManifest() (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.BasePath, manifestFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return m.Directory, nil
+}
+
+// saveManifest replaces the manifest by one naming dir. The new manifest
+// is written and synced aside, then renamed over the current one, so that
+// a crash leaves either of them.
+func (s *ColumnStorage) saveManifest(dir string) error {
+	data, err := json.Marshal(manifest{Directory: dir})
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.BasePath, manifestFileName)
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace manifest: %w", err)
+	}
+
+	base, err := os.Open(s.BasePath)
+	if err != nil {
+		return err
+	}
+	defer base.Close()
+	return base.Sync()
+}
+
+// removeStaleColumnFiles removes the column files the manifest does not
+// name: those of an interrupted purge, and those a purge replaced but
+// could not remove.
+func (s *ColumnStorage) removeStaleColumnFiles() error {
+	entries, err := os.ReadDir(s.BasePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), columnsDirPrefix) && entry.Name() != s.columnsDir {
+			if err := os.RemoveAll(filepath.Join(s.BasePath, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	if s.columnsDir != "" {
+		return s.removeColumnFiles("")
+	}
+	return nil
+}
+
+// removeColumnFiles removes the column files in dir, relative to the table
+// directory.
+func (s *ColumnStorage) removeColumnFiles(dir string) error {
+	if dir != "" {
+		return os.RemoveAll(filepath.Join(s.BasePath, dir))
+	}
+	for _, meta := range s.fields {
+		for _, ext := range []string{".data", ".heap"} {
+			err := os.Remove(filepath.Join(s.BasePath, meta.Name+ext))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// columnsPath returns the directory of the column files.
+func (s *ColumnStorage) columnsPath() string {
+	return filepath.Join(s.BasePath, s.columnsDir)
+}
@@ -7,13 +7,29 @@ import (
 	"github.com/onnasoft/ZenithSQL/core/storage"
 )
 
+// deletedColumn is the column marking the rows deleted, by holding when
+// they were.
+const deletedColumn = "deleted_at"
+
+// purgedStatus is the status byte of the deleted_at value of a purged row.
+// The row keeps its position, so that no other row changes id, but none of
+// its values, and no reader returns it.
+const purgedStatus = 2
+
 type ColumnReader struct {
 	columnsData map[string]*ColumnData
 	current     int64
 	totalRows   int64
+	// deleted is the deleted_at column, which Next skips the rows set in
+	// unless includeDeleted.
+	deleted        *ColumnData
+	includeDeleted bool
+	// release, when set, tells the storage the reader no longer reads its
+	// columns.
+	release func()
 }
 
-func NewColumnReader(columns map[string]*Column, stats *storage.StorageStats) (*ColumnReader, error) {
+func NewColumnReader(columns map[string]*Column, stats *storage.StorageStats, includeDeleted bool) (*ColumnReader, error) {
 	columnsData := make(map[string]*ColumnData, len(columns))
 	for name, col := range columns {
 		data, err := col.AllocateView()
@@ -27,9 +43,11 @@ func NewColumnReader(columns map[string]*Column, stats *storage.StorageStats) (*
 	}
 
 	return &ColumnReader{
-		current:     -1,
-		totalRows:   stats.TotalRows,
-		columnsData: columnsData,
+		current:        -1,
+		totalRows:      stats.TotalRows,
+		columnsData:    columnsData,
+		deleted:        columnsData[deletedColumn],
+		includeDeleted: includeDeleted,
 	}, nil
 }
 
@@ -42,11 +60,37 @@ func (r *ColumnReader) ColumnsData() map[string]storage.ColumnData {
 }
 
 func (r *ColumnReader) Next() bool {
-	if r.current+1 >= r.totalRows {
-		return false
+	for r.current+1 < r.totalRows {
+		r.current++
+		if r.Visible() {
+			return true
+		}
 	}
-	r.current++
-	return true
+	return false
+}
+
+func (r *ColumnReader) Visible() bool {
+	status := r.deletedStatus()
+	return status != purgedStatus && (r.includeDeleted || status == 0)
+}
+
+// isDeleted reports whether deleted_at is set in the current row, as it is
+// in a purged one.
+func (r *ColumnReader) isDeleted() bool {
+	return r.deletedStatus() != 0
+}
+
+// isPurged reports whether the current row was purged.
+func (r *ColumnReader) isPurged() bool {
+	return r.deletedStatus() == purgedStatus
+}
+
+func (r *ColumnReader) deletedStatus() byte {
+	if r.deleted == nil {
+		return 0
+	}
+	offset := r.current * int64(r.deleted.Length+2)
+	return r.deleted.data[offset]
 }
 
 func (r *ColumnReader) See(id int64) error {
@@ -101,7 +145,7 @@ func (r *ColumnReader) FastGetValue(col storage.ColumnData, value interface{}) (
 		return false, nil
 	}
 
-	if err := colData.DataType.Read(data[1:], value); err != nil {
+	if err := colData.DataType.Read(data[1:recordLength-1], value); err != nil {
 		return false, fmt.Errorf("failed to read value: %w", err)
 	}
 
@@ -133,13 +177,17 @@ func (r *ColumnReader) GetValue(field string) (interface{}, error) {
 		return nil, nil
 	}
 
-	return col.DataType.Parse(data[1:]), nil
+	return col.DataType.Parse(data[1 : recordLength-1]), nil
 }
 
 func (r *ColumnReader) Close() error {
 	for _, col := range r.columnsData {
 		col.FreeView(col.data)
 	}
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
 
 	return nil
 }
@@ -0,0 +1,226 @@
+package columnstorage
+
+import (
+	"container/heap"
+	"errors"
+	"slices"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// sortRow is a row held by a sorter with the values of its sort keys.
+type sortRow struct {
+	keys   []interface{}
+	values map[string]interface{}
+	size   int64
+	// source is the run the row was read from while merging.
+	source int
+}
+
+// sorter orders rows by a list of keys. Rows are kept in memory up to the
+// memory limit and spilled as sorted runs to temporary files beyond it,
+// which are merged when the rows are read back. With a limit the rows in
+// memory form a heap of the best rows seen so far, so that a run never
+// holds more rows than the limit.
+type sorter struct {
+	config storage.SortConfig
+	rows   rowHeap
+	size   int64
+	runs   []*spillFile
+	err    error
+
+	merge   rowHeap
+	sources []rowSource
+	emitted int64
+}
+
+func newSorter(config storage.SortConfig) *sorter {
+	if config.MemoryLimit <= 0 {
+		config.MemoryLimit = storage.DefaultSortMemoryLimit
+	}
+	s := &sorter{config: config}
+	// The rows in memory are a heap with the worst row on top.
+	s.rows.less = func(a, b sortRow) bool { return s.compare(a, b) > 0 }
+	s.merge.less = func(a, b sortRow) bool { return s.compare(a, b) < 0 }
+	return s
+}
+
+func (s *sorter) compare(a, b sortRow) int {
+	for i, key := range s.config.Keys {
+		x, y := a.keys[i], b.keys[i]
+		switch {
+		case x == nil && y == nil:
+			continue
+		case x == nil || y == nil:
+			if (x == nil) == key.NullsSortFirst() {
+				return -1
+			}
+			return 1
+		}
+
+		c, err := fields.Compare(x, y)
+		if err != nil {
+			if s.err == nil {
+				s.err = err
+			}
+			return 0
+		}
+		if key.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// accepts tells whether a row with the given sort keys would be kept, which
+// lets the caller skip reading rows a top-K sort is going to drop.
+func (s *sorter) accepts(keys []interface{}) bool {
+	if s.config.Limit <= 0 || int64(s.rows.Len()) < s.config.Limit {
+		return true
+	}
+	return s.compare(sortRow{keys: keys}, s.rows.rows[0]) < 0
+}
+
+// add keeps a row that accepts approved, keys being the values of its sort
+// keys.
+func (s *sorter) add(keys []interface{}, values map[string]interface{}) error {
+	row := sortRow{keys: keys, values: values, size: rowSize(values)}
+
+	switch limit := s.config.Limit; {
+	case limit <= 0:
+		s.rows.rows = append(s.rows.rows, row)
+	case int64(s.rows.Len()) < limit:
+		heap.Push(&s.rows, row)
+	default:
+		s.size -= s.rows.rows[0].size
+		s.rows.rows[0] = row
+		heap.Fix(&s.rows, 0)
+	}
+	s.size += row.size
+
+	if s.err == nil && s.size > s.config.MemoryLimit {
+		return s.spill()
+	}
+	return s.err
+}
+
+// sorted returns the rows in memory in order and releases them.
+func (s *sorter) sorted() []sortRow {
+	rows := s.rows.rows
+	slices.SortStableFunc(rows, s.compare)
+	s.rows.rows, s.size = nil, 0
+	return rows
+}
+
+func (s *sorter) spill() error {
+	run, err := newSpillFile(s.config.TempDir, "zenith-sort-*.run")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, run)
+
+	for _, row := range s.sorted() {
+		if err := run.write(row.values); err != nil {
+			return err
+		}
+	}
+	if err := run.rewind(); err != nil {
+		return err
+	}
+	return s.err
+}
+
+// finish ends the input and prepares the merge of the runs and the rows
+// left in memory.
+func (s *sorter) finish() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.sources = append(s.sources, &memorySource{rows: s.sorted()})
+	for _, run := range s.runs {
+		s.sources = append(s.sources, run)
+	}
+
+	for i, source := range s.sources {
+		if err := s.pull(i, source); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func (s *sorter) pull(i int, source rowSource) error {
+	values, ok, err := source.next()
+	if err != nil || !ok {
+		return err
+	}
+	row := sortRow{keys: make([]interface{}, len(s.config.Keys)), values: values, source: i}
+	for k, key := range s.config.Keys {
+		row.keys[k] = values[key.Column]
+	}
+	heap.Push(&s.merge, row)
+	return nil
+}
+
+// next returns the rows in order once finish was called.
+func (s *sorter) next() (map[string]interface{}, bool, error) {
+	if s.merge.Len() == 0 || (s.config.Limit > 0 && s.emitted >= s.config.Limit) {
+		return nil, false, nil
+	}
+	row := heap.Pop(&s.merge).(sortRow)
+	if err := s.pull(row.source, s.sources[row.source]); err != nil {
+		return nil, false, err
+	}
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	s.emitted++
+	return row.values, true, nil
+}
+
+func (s *sorter) close() error {
+	var errs []error
+	for _, run := range s.runs {
+		errs = append(errs, run.close())
+	}
+	s.runs, s.sources, s.rows.rows, s.merge.rows = nil, nil, nil, nil
+	return errors.Join(errs...)
+}
+
+type rowHeap struct {
+	rows []sortRow
+	less func(a, b sortRow) bool
+}
+
+func (h *rowHeap) Len() int           { return len(h.rows) }
+func (h *rowHeap) Less(i, j int) bool { return h.less(h.rows[i], h.rows[j]) }
+func (h *rowHeap) Swap(i, j int)      { h.rows[i], h.rows[j] = h.rows[j], h.rows[i] }
+func (h *rowHeap) Push(x any)         { h.rows = append(h.rows, x.(sortRow)) }
+
+func (h *rowHeap) Pop() any {
+	last := h.rows[len(h.rows)-1]
+	h.rows = h.rows[:len(h.rows)-1]
+	return last
+}
+
+type rowSource interface {
+	next() (map[string]interface{}, bool, error)
+}
+
+type memorySource struct {
+	rows []sortRow
+	pos  int
+}
+
+func (m *memorySource) next() (map[string]interface{}, bool, error) {
+	if m.pos >= len(m.rows) {
+		return nil, false, nil
+	}
+	m.pos++
+	return m.rows[m.pos-1].values, true, nil
+}
@@ -0,0 +1,129 @@
+package columnstorage
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"hash/fnv"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// Spill files are written with gob rather than msgpack because gob keeps
+// the concrete Go type of every value, so a row read back from a file is
+// the row the cursor produced: an int32 stays an int32 and a timestamptz keeps
+// its offset.
+func init() {
+	gob.Register(time.Time{})
+	gob.Register([]interface{}{})
+	gob.Register([]float32{})
+	gob.Register(fields.IntervalValue{})
+	gob.Register(fields.TimeOfDay(0))
+	gob.Register(fields.LatLng{})
+}
+
+// spillPartitions is the number of files the rows of a group by or a hash
+// join are spread over once they do not fit in memory.
+const spillPartitions = 16
+
+// partitionOf picks the partition of a key at a depth of partitioning. Each
+// depth uses other bits of the hash, so the keys of a partition that is
+// partitioned again are split between the new partitions.
+func partitionOf(key string, depth int) int {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	// FNV leaves runs of bits correlated; the finalizer of MurmurHash3
+	// spreads every input bit over the whole hash.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return int(x >> (60 - 4*depth) % spillPartitions)
+}
+
+// spillFile is a temporary file holding rows that did not fit in memory.
+// It is written once and then read back from the start.
+type spillFile struct {
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *gob.Encoder
+	decoder *gob.Decoder
+}
+
+func newSpillFile(dir, pattern string) (*spillFile, error) {
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(file)
+	return &spillFile{file: file, writer: writer, encoder: gob.NewEncoder(writer)}, nil
+}
+
+func (r *spillFile) write(values map[string]interface{}) error {
+	return r.encoder.Encode(values)
+}
+
+func (r *spillFile) rewind() error {
+	if err := r.writer.Flush(); err != nil {
+		return err
+	}
+	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r.writer, r.encoder = nil, nil
+	r.decoder = gob.NewDecoder(bufio.NewReader(r.file))
+	return nil
+}
+
+func (r *spillFile) next() (map[string]interface{}, bool, error) {
+	var values map[string]interface{}
+	if err := r.decoder.Decode(&values); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return values, true, nil
+}
+
+func (r *spillFile) close() error {
+	closeErr := r.file.Close()
+	if err := os.Remove(r.file.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// rowSize approximates the memory held by a row.
+func rowSize(values map[string]interface{}) int64 {
+	size := int64(48)
+	for name, value := range values {
+		size += int64(len(name)) + valueSize(value)
+	}
+	return size
+}
+
+func valueSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 16
+	case string:
+		return 32 + int64(len(v))
+	case []interface{}:
+		size := int64(40)
+		for _, elem := range v {
+			size += valueSize(elem)
+		}
+		return size
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice {
+		return 40 + int64(rv.Len())*int64(rv.Type().Elem().Size())
+	}
+	return 32
+}
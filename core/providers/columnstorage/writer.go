@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"slices"
 	"sync"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
 )
 
 const (
@@ -21,8 +23,11 @@ const (
 )
 
 type ColumnWriter struct {
-	columns   map[string]*Column
-	pending   map[int64]struct{} // Using map for faster lookups
+	columns map[string]*Column
+	pending map[int64]struct{} // Using map for faster lookups
+	// updated holds, for the rows overwritten by Update, the records of
+	// the columns as they were before, which a rollback writes back.
+	updated   map[int64]map[string][]byte
 	mu        sync.Mutex
 	closed    bool
 	committed bool
@@ -32,6 +37,7 @@ func NewColumnWriter(columns map[string]*Column) *ColumnWriter {
 	return &ColumnWriter{
 		columns: columns,
 		pending: make(map[int64]struct{}),
+		updated: make(map[int64]map[string][]byte),
 	}
 }
 
@@ -58,31 +64,99 @@ func (w *ColumnWriter) Write(values map[string]interface{}) error {
 		return fmt.Errorf("record with id %d already exists in this transaction", id+1)
 	}
 
-	// Validate all fields
-	for name, value := range values {
-		col, ok := w.columns[name]
-		if !ok {
+	// Validate all fields, narrowing numeric literals to the column type.
+	// The values of the caller are left as given. The columns missing from
+	// values are null, which the columns that are required reject.
+	for name := range values {
+		if _, ok := w.columns[name]; !ok {
 			return fmt.Errorf(errFieldNotFound, name)
 		}
-		if err := col.DataType.Valid(value); err != nil {
+	}
+	record := make(map[string]interface{}, len(w.columns))
+	for name, col := range w.columns {
+		value := fields.Coerce(col.DataType, values[name])
+		if value == nil {
+			if col.Required {
+				return fmt.Errorf("column %s cannot be null", name)
+			}
+		} else if err := col.DataType.Valid(value); err != nil {
 			return fmt.Errorf(errFieldInvalid, name, err)
 		}
+		record[name] = value
 	}
 
-	for name := range w.columns {
-		if _, ok := values[name]; !ok {
-			values[name] = nil
+	// Write each field
+	for name, value := range record {
+		if err := w.writeFieldInternal(id, name, value); err != nil {
+			return err
 		}
 	}
 
-	// Write each field
+	w.pending[id] = struct{}{}
+	return nil
+}
+
+// Update overwrites in place the columns in values of an existing row,
+// which may set the columns that are not required to null. The previous
+// records are kept until the writer commits, so that a rollback restores
+// them.
+func (w *ColumnWriter) Update(values map[string]interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errors.New(errColumnWriterClosed)
+	}
+
+	id, ok := values["id"].(int64)
+	if !ok {
+		return fmt.Errorf("missing or invalid id field")
+	}
+	if id == 0 {
+		return errors.New(errIDZero)
+	}
+	id--
+
+	record := make(map[string]interface{}, len(values))
 	for name, value := range values {
+		if name == "id" {
+			continue
+		}
+		col, ok := w.columns[name]
+		if !ok {
+			return fmt.Errorf(errFieldNotFound, name)
+		}
+		value = fields.Coerce(col.DataType, value)
+		record[name] = value
+		if value == nil {
+			if col.Required {
+				return fmt.Errorf("column %s cannot be null", name)
+			}
+		} else if err := col.DataType.Valid(value); err != nil {
+			return fmt.Errorf(errFieldInvalid, name, err)
+		}
+		recordLength := col.Length + 2
+		if offset := id * int64(recordLength); offset+int64(recordLength) > int64(len(col.Data())) {
+			return fmt.Errorf("record with id %d not found", id+1)
+		}
+	}
+
+	saved, ok := w.updated[id]
+	if !ok {
+		saved = make(map[string][]byte, len(record))
+		w.updated[id] = saved
+	}
+	for name, value := range record {
+		if _, ok := saved[name]; !ok {
+			col := w.columns[name]
+			recordLength := col.Length + 2
+			offset := id * int64(recordLength)
+			saved[name] = slices.Clone(col.Data()[offset : offset+int64(recordLength)])
+		}
 		if err := w.writeFieldInternal(id, name, value); err != nil {
 			return err
 		}
 	}
-
-	w.pending[id] = struct{}{}
 	return nil
 }
 
@@ -96,6 +170,9 @@ func (w *ColumnWriter) writeFieldInternal(id int64, name string, value interface
 	}
 
 	data := col.Data()[offset : offset+int64(recordLength)]
+	// The types write only the bytes of their value, so the bytes a
+	// longer previous value left are cleared first.
+	clear(data[valueByteOffset : valueByteOffset+col.Length])
 	if value == nil {
 		data[statusByteOffset] = 0
 		data[col.Length+1] = '\n'
@@ -146,6 +223,7 @@ func (w *ColumnWriter) Close() error {
 
 	// Clear pending regardless of commit state
 	w.pending = make(map[int64]struct{})
+	w.updated = make(map[int64]map[string][]byte)
 	return err
 }
 
@@ -157,7 +235,7 @@ func (w *ColumnWriter) Commit() error {
 		return errors.New(errColumnWriterClosed)
 	}
 
-	if len(w.pending) == 0 {
+	if len(w.pending) == 0 && len(w.updated) == 0 {
 		w.committed = true
 		return nil
 	}
@@ -168,10 +246,15 @@ func (w *ColumnWriter) Commit() error {
 	}
 
 	// Obtener los ids ordenados
-	ids := make([]int64, 0, len(w.pending))
+	ids := make([]int64, 0, len(w.pending)+len(w.updated))
 	for id := range w.pending {
 		ids = append(ids, id)
 	}
+	for id := range w.updated {
+		if _, ok := w.pending[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
 	slices.Sort(ids)
 
 	// Agrupar rangos contiguos
@@ -205,10 +288,17 @@ func (w *ColumnWriter) Commit() error {
 					name, offset, err)
 			}
 		}
+
+		if col.heap != nil {
+			if err := col.heap.Sync(); err != nil {
+				return fmt.Errorf("failed to sync heap of column %s: %w", name, err)
+			}
+		}
 	}
 
 	w.committed = true
 	w.pending = make(map[int64]struct{})
+	w.updated = make(map[int64]map[string][]byte)
 	return nil
 }
 
@@ -224,14 +314,24 @@ func (w *ColumnWriter) Rollback() error {
 }
 
 func (w *ColumnWriter) rollbackInternal() error {
+	for id, saved := range w.updated {
+		for name, record := range saved {
+			col := w.columns[name]
+			offset := id * int64(col.Length+2)
+			copy(col.Data()[offset:], record)
+		}
+	}
+	w.updated = make(map[int64]map[string][]byte)
+
 	for id := range w.pending {
 		for _, col := range w.columns {
 			recordLength := col.Length + 2 // +2 for status and newline
 			offset := id * int64(recordLength)
 
-			// Check bounds before writing
-			if offset < int64(len(col.Data())) {
-				col.Data()[offset] = 0 // Reset status byte
+			// Clear the whole record, so that no bytes of the value
+			// are left behind for the next row written in its place.
+			if offset+int64(recordLength) <= int64(len(col.Data())) {
+				clear(col.Data()[offset : offset+int64(recordLength)])
 			}
 		}
 	}
@@ -15,8 +15,20 @@ const (
 )
 
 type TableConfig struct {
-	Fields []fields.FieldMeta `json:"fields"`
-	Stats  *StorageStats      `json:"-"`
+	Fields  []fields.FieldMeta `json:"fields"`
+	Indexes []IndexConfig      `json:"indexes,omitempty"`
+	Stats   *StorageStats      `json:"-"`
+}
+
+// IndexConfig describes a secondary index of a table, so that it can be
+// built again when the table is opened: the column it indexes, its kind and
+// the options it was created with.
+type IndexConfig struct {
+	Name    string                 `json:"name"`
+	Column  string                 `json:"column"`
+	Kind    string                 `json:"kind"`
+	Unique  bool                   `json:"unique,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty"`
 }
 
 type ConfigManager struct {
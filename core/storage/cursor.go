@@ -1,8 +1,8 @@
 package storage
 
 import (
+	"github.com/onnasoft/ZenithSQL/core/buffer"
 	"github.com/onnasoft/ZenithSQL/io/filters"
-	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
 // Cursor provides query result iteration
@@ -13,11 +13,43 @@ type Cursor interface {
 	ScanField(field string) (interface{}, error)
 	FastScanField(col ColumnData, value interface{}) (bool, error)
 	Close() error
+	// Err returns the error that ended the iteration early, if any.
+	Err() error
+	// Count returns how many rows the cursor yields. A table cursor knows
+	// it from the table stats; the cursors built on another one count by
+	// reading their rows, which consumes them: iterating after Count yields
+	// nothing.
 	Count() (int64, error)
 	Reader() Reader
+	ScanMap() map[string]*buffer.Scanner
 	WithIDs(ids []int64) (Cursor, error)
 	WithFilter(filter *filters.Filter) (Cursor, error)
-	WithGroupBy(groupBy []string, aggregations []statement.Aggregation) (Cursor, error)
+	WithGroupBy(config GroupConfig) (Cursor, error)
 	WithLimit(limit int64) (Cursor, error)
 	WithSkip(skip int64) (Cursor, error)
+	WithUnnest(column string) (Cursor, error)
+	WithSort(config SortConfig) (Cursor, error)
+	// WithJoin joins the rows of the cursor, the left input, with those of
+	// right.
+	WithJoin(right Cursor, config JoinConfig) (Cursor, error)
+	// WithProjection adds computed columns to the rows of the cursor.
+	WithProjection(projections []Projection) (Cursor, error)
+	// WithWindow adds the window functions of config to the rows of the
+	// cursor.
+	WithWindow(config WindowConfig) (Cursor, error)
+	// WithSetOperation combines the rows of the cursor, the left input,
+	// with those of right.
+	WithSetOperation(right Cursor, config SetOperationConfig) (Cursor, error)
+	// WithRecursion returns the rows of the cursor followed by those the
+	// steps of config derive from them.
+	WithRecursion(config RecursionConfig) (Cursor, error)
+	// WithDistinct returns the rows of the cursor that differ from those
+	// returned before it.
+	WithDistinct(config DistinctConfig) (Cursor, error)
+
+	// Plan describes the cursor and the cursors it reads from.
+	Plan() *PlanNode
+	// Analyze makes the cursor and its inputs measure the time they spend
+	// producing rows, reported by Plan.
+	Analyze()
 }
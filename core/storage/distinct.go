@@ -0,0 +1,13 @@
+package storage
+
+// DistinctConfig describes how a distinct cursor drops duplicate rows: it
+// returns the values of Columns of the rows whose values were not returned
+// before, nulls being equal to each other. The values seen are kept in a
+// hash table; beyond MemoryLimit bytes of them, rows of unseen values are
+// spilled to partitions in TempDir and deduplicated once the input is read,
+// which default to DefaultGroupMemoryLimit and the system temp directory.
+type DistinctConfig struct {
+	Columns     []string
+	MemoryLimit int64
+	TempDir     string
+}
@@ -0,0 +1,20 @@
+package storage
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// DefaultGroupMemoryLimit is the number of bytes of groups a hash
+// aggregation holds in memory before it spills rows of new groups to disk.
+const DefaultGroupMemoryLimit = 64 << 20
+
+// GroupConfig describes how a group by cursor aggregates its rows: one row
+// is produced per distinct value of Columns, holding those columns and the
+// aggregations under their names. Without Columns all rows form a single
+// group. Beyond MemoryLimit bytes of groups, rows of groups not yet seen are
+// spilled to partitions in TempDir, which default to
+// DefaultGroupMemoryLimit and the system temp directory.
+type GroupConfig struct {
+	Columns      []string
+	Aggregations []statement.Aggregation
+	MemoryLimit  int64
+	TempDir      string
+}
@@ -1,5 +1,7 @@
 package storage
 
+import "github.com/onnasoft/ZenithSQL/model/fields"
+
 // Index provides indexing capabilities
 type Index interface {
 	Add(value interface{}, position int64) error
@@ -15,3 +17,37 @@ type IndexStats struct {
 	UniqueValues int64
 	MemoryUsage  int64
 }
+
+// Neighbor is a row returned by a nearest-neighbour search.
+type Neighbor struct {
+	ID       int64
+	Distance float32
+}
+
+// VectorIndex is an Index answering approximate nearest-neighbour queries.
+type VectorIndex interface {
+	Index
+	Search(query []float32, k int) ([]Neighbor, error)
+}
+
+// SpatialIndex is an Index answering geographic lookups. Results are
+// candidates: callers still apply the exact predicate.
+type SpatialIndex interface {
+	Index
+	WithinBox(southWest, northEast fields.LatLng) ([]int64, error)
+}
+
+// LookupIndex is an Index answering equality lookups. Lookup returns the
+// rows holding value, sorted ascending.
+type LookupIndex interface {
+	Index
+	Lookup(value interface{}) ([]int64, error)
+}
+
+// RangeIndex is an Index bounding the rows that may hold values between low
+// and high; a nil bound is open. Results are candidates sorted ascending:
+// callers still apply the exact predicate.
+type RangeIndex interface {
+	Index
+	Range(low, high interface{}, includeLow, includeHigh bool) ([]int64, error)
+}
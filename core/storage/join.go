@@ -0,0 +1,32 @@
+package storage
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// DefaultJoinMemoryLimit is the number of bytes of rows a hash join holds
+// in memory before it partitions both inputs to disk.
+const DefaultJoinMemoryLimit = 64 << 20
+
+// JoinKey pairs a column of the left input of a join with the column of
+// the right input it must equal.
+type JoinKey struct {
+	Left  string
+	Right string
+}
+
+// JoinConfig describes how a join cursor combines its two inputs. Columns
+// of an input are renamed alias.column, and an input without an alias
+// contributes only its columns that are already qualified. Rows match when
+// all their keys are equal and none is null. A hash join keeps the right
+// input in a hash table and spills both inputs to partitions in TempDir
+// beyond MemoryLimit bytes; a merge join reads both inputs ordered by
+// their keys. Semi and anti joins are hash joins producing the left rows
+// as they are named by the left input.
+type JoinConfig struct {
+	Type        statement.JoinType
+	Strategy    statement.JoinStrategy
+	LeftAlias   string
+	RightAlias  string
+	Keys        []JoinKey
+	MemoryLimit int64
+	TempDir     string
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PlanNode is an operation of an executed or explained query, with the
+// operations feeding it as children. EstimatedRows is negative when the
+// planner made no estimate. ActualRows, RowsScanned and Duration are only
+// measured by EXPLAIN ANALYZE; Duration includes the time spent in the
+// children.
+type PlanNode struct {
+	Operation     string        `msgpack:"operation"`
+	Detail        string        `msgpack:"detail"`
+	EstimatedRows int64         `msgpack:"estimated_rows"`
+	Analyzed      bool          `msgpack:"analyzed"`
+	ActualRows    int64         `msgpack:"actual_rows"`
+	RowsScanned   int64         `msgpack:"rows_scanned"`
+	Duration      time.Duration `msgpack:"duration"`
+	Children      []*PlanNode   `msgpack:"children"`
+}
+
+func NewPlanNode(operation, detail string, children ...*PlanNode) *PlanNode {
+	return &PlanNode{
+		Operation:     operation,
+		Detail:        detail,
+		EstimatedRows: -1,
+		Children:      children,
+	}
+}
+
+// Walk calls fn for n and its descendants, parents first.
+func (n *PlanNode) Walk(fn func(*PlanNode)) {
+	fn(n)
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// String renders the tree one node per line, children indented below their
+// parent.
+func (n *PlanNode) String() string {
+	var sb strings.Builder
+	n.write(&sb, 0)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (n *PlanNode) write(sb *strings.Builder, depth int) {
+	if depth > 0 {
+		sb.WriteString(strings.Repeat("  ", depth-1) + "-> ")
+	}
+	sb.WriteString(n.Operation)
+	if n.Detail != "" {
+		sb.WriteString(" " + n.Detail)
+	}
+
+	var figures []string
+	if n.EstimatedRows >= 0 {
+		figures = append(figures, fmt.Sprintf("estimated rows=%d", n.EstimatedRows))
+	}
+	if n.Analyzed {
+		figures = append(figures,
+			fmt.Sprintf("rows=%d", n.ActualRows),
+			fmt.Sprintf("scanned=%d", n.RowsScanned),
+			fmt.Sprintf("time=%.3fms", float64(n.Duration.Microseconds())/1000),
+		)
+	}
+	if len(figures) > 0 {
+		sb.WriteString(" (" + strings.Join(figures, " ") + ")")
+	}
+	sb.WriteString("\n")
+
+	for _, child := range n.Children {
+		child.write(sb, depth+1)
+	}
+}
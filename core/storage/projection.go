@@ -0,0 +1,10 @@
+package storage
+
+import "github.com/onnasoft/ZenithSQL/io/expression"
+
+// Projection is a computed column: Expression evaluated on every row of a
+// cursor and returned under Name.
+type Projection struct {
+	Name       string
+	Expression *expression.Expression
+}
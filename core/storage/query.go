@@ -1,13 +1,30 @@
 package storage
 
+import "github.com/onnasoft/ZenithSQL/io/filters"
+
+// Query describes the rows a plan has to produce: the rows matching Filter,
+// skipping the first Offset of them and keeping at most Limit when Limit is
+// positive. Fields lists the columns the caller is going to read. Rows
+// whose deleted_at is set are left out unless IncludeDeleted.
+type Query struct {
+	Filter         *filters.Filter
+	Fields         []string
+	Offset         int64
+	Limit          int64
+	IncludeDeleted bool
+}
+
 // QueryPlan represents a query execution plan
 type QueryPlan interface {
 	Explain() string
 	Execute() (Cursor, error)
 	Optimize() QueryPlan
+	// Annotate adds the planner's estimates to node, the plan tree of a
+	// cursor returned by Execute.
+	Annotate(node *PlanNode)
 }
 
 // QueryPlanner creates query execution plans
 type QueryPlanner interface {
-	CreatePlan(filter Filter, fields []string) QueryPlan
+	CreatePlan(query Query) (QueryPlan, error)
 }
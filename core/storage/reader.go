@@ -13,5 +13,9 @@ type Reader interface {
 	Close() error
 	See(id int64) error
 	CurrentID() int64
+	// Visible reports whether the current row is returned: its deleted_at
+	// is not set, or the reader also returns deleted rows. Next skips the
+	// rows that are not, See does not.
+	Visible() bool
 	ScanMap() map[string]*buffer.Scanner
 }
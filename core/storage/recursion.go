@@ -0,0 +1,26 @@
+package storage
+
+// DefaultRecursionLimit is the number of steps a recursive cursor runs
+// before it fails, taking the recursion for an endless one.
+const DefaultRecursionLimit = 1000
+
+// RecursionConfig describes how a recursive cursor computes a recursive
+// common table expression. The rows of its input come first, returned
+// under Columns, which match InputColumns by position. Step is then given
+// a cursor over the rows produced last, under Columns, and returns the
+// cursor of the next rows with its columns matching Columns by position,
+// until it produces none. With Distinct, the rows already produced are
+// dropped, all of them being remembered in memory. The rows a step reads
+// are held in memory up to MemoryLimit bytes and in a file in TempDir
+// beyond it. Name is the name of the expression, and Limit the number of
+// steps allowed, DefaultRecursionLimit when zero.
+type RecursionConfig struct {
+	Name         string
+	Columns      []string
+	InputColumns []string
+	Distinct     bool
+	Step         func(previous Cursor) (Cursor, []string, error)
+	Limit        int
+	MemoryLimit  int64
+	TempDir      string
+}
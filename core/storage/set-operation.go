@@ -0,0 +1,19 @@
+package storage
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// SetOperationConfig describes how a set operation cursor combines its two
+// inputs. The columns of the right input, RightColumns, are matched by
+// position to LeftColumns, under whose names and types the rows are
+// returned. UNION ALL returns the left rows and then the right ones; the
+// other operations sort both inputs by all their columns, spilling beyond
+// MemoryLimit bytes of rows to TempDir like a sort cursor, and count the
+// equal rows of each side, nulls being equal to each other.
+type SetOperationConfig struct {
+	Operator     statement.SetOperator
+	All          bool
+	LeftColumns  []string
+	RightColumns []string
+	MemoryLimit  int64
+	TempDir      string
+}
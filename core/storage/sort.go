@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSortMemoryLimit is the number of bytes of rows a sort holds in
+// memory before it spills sorted runs to disk.
+const DefaultSortMemoryLimit = 64 << 20
+
+// SortKey orders rows by a column. Nulls sort last in ascending order and
+// first in descending order unless NullsFirst or NullsLast say otherwise.
+type SortKey struct {
+	Column     string
+	Descending bool
+	NullsFirst bool
+	NullsLast  bool
+}
+
+// NullsSortFirst tells whether nulls come before the other values.
+func (k SortKey) NullsSortFirst() bool {
+	if k.NullsFirst || k.NullsLast {
+		return k.NullsFirst
+	}
+	return k.Descending
+}
+
+func (k SortKey) String() string {
+	s := k.Column
+	if k.Descending {
+		s += " DESC"
+	}
+	switch {
+	case k.NullsFirst:
+		s += " NULLS FIRST"
+	case k.NullsLast:
+		s += " NULLS LAST"
+	}
+	return s
+}
+
+// ParseSortKey reads a key written as "col [ASC|DESC] [NULLS FIRST|LAST]",
+// the form SelectStatement.OrderBy keeps them in.
+func ParseSortKey(s string) (SortKey, error) {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return SortKey{}, fmt.Errorf("empty sort key")
+	}
+
+	key := SortKey{Column: words[0]}
+	rest := words[1:]
+	if len(rest) > 0 {
+		switch strings.ToUpper(rest[0]) {
+		case "DESC":
+			key.Descending = true
+			rest = rest[1:]
+		case "ASC":
+			rest = rest[1:]
+		}
+	}
+	if len(rest) == 2 && strings.EqualFold(rest[0], "NULLS") {
+		switch strings.ToUpper(rest[1]) {
+		case "FIRST":
+			key.NullsFirst = true
+			rest = nil
+		case "LAST":
+			key.NullsLast = true
+			rest = nil
+		}
+	}
+	if len(rest) > 0 {
+		return SortKey{}, fmt.Errorf("invalid sort key %q", s)
+	}
+	return key, nil
+}
+
+// SortConfig describes how a sort cursor orders its rows. With a positive
+// Limit only the first Limit rows are produced and kept while sorting.
+// Rows beyond MemoryLimit bytes are spilled to sorted runs in TempDir,
+// which default to DefaultSortMemoryLimit and the system temp directory.
+type SortConfig struct {
+	Keys        []SortKey
+	Limit       int64
+	MemoryLimit int64
+	TempDir     string
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"io"
@@ -8,6 +9,8 @@ import (
 	"sync/atomic"
 	"time"
 	"unsafe"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
 )
 
 type Validator interface {
@@ -17,11 +20,16 @@ type Validator interface {
 type storageStats struct {
 	TotalRows    int64
 	LastModified int64
+	DeletedRows  int64
 }
 
+// StorageStats counts the rows of a table. DeletedRows are the rows among
+// TotalRows whose deleted_at is set, which cursors hide; the rows purged
+// stay among both as tombstones.
 type StorageStats struct {
 	TotalRows    int64
 	LastModified time.Time
+	DeletedRows  int64
 }
 
 func (s *StorageStats) UpdateTotalRows(count int64) {
@@ -33,6 +41,7 @@ func (s *StorageStats) SaveToFile(filePath string) error {
 	temp := storageStats{
 		TotalRows:    s.TotalRows,
 		LastModified: s.LastModified.Unix(),
+		DeletedRows:  s.DeletedRows,
 	}
 
 	file, err := os.Create(filePath)
@@ -51,17 +60,22 @@ func (s *StorageStats) SaveToFile(filePath string) error {
 func (s *StorageStats) LoadFromFile(filePath string) error {
 	temp := storageStats{}
 
-	file, err := os.Open(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	// Files written before a field was added end before it: the missing
+	// fields are zero.
+	if size := binary.Size(temp); len(data) < size {
+		data = append(data, make([]byte, size-len(data))...)
+	}
 
-	if err := binary.Read(file, binary.LittleEndian, &temp); err != nil {
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &temp); err != nil {
 		return err
 	}
 
 	atomic.StoreInt64(&s.TotalRows, temp.TotalRows)
+	atomic.StoreInt64(&s.DeletedRows, temp.DeletedRows)
 	lastModified := time.Unix(temp.LastModified, 0)
 	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&s.LastModified)), unsafe.Pointer(&lastModified))
 
@@ -90,13 +104,27 @@ type Storage interface {
 	Backup(ctx context.Context, writer io.Writer) error
 	Restore(ctx context.Context, reader io.Reader) error
 	Stats() StorageStats
+	// Compact purges every row whose deleted_at is set.
 	Compact(ctx context.Context) error
+	// Purge removes the values of the rows ids, sorted ascending, from
+	// the table files and reclaims the space they took outside the rows.
+	// The rows are left as tombstones no cursor returns, so that no id
+	// changes or is given again: each still takes the fixed width of its
+	// columns.
+	Purge(ctx context.Context, ids []int64) error
 
 	Truncate() error
 
+	ListFields() ([]fields.FieldMeta, error)
+	GetFieldMeta(name string) (fields.FieldMeta, error)
+	UpdateField(name string, meta fields.FieldMeta) error
+
 	Writer() (Writer, error)
 	Reader() (Reader, error)
+	// Cursor reads the rows of the table, hiding those whose deleted_at
+	// is set, which CursorWithDeleted also returns.
 	Cursor() (Cursor, error)
+	CursorWithDeleted() (Cursor, error)
 
 	Lock() error
 	Unlock() error
@@ -108,4 +136,8 @@ type Storage interface {
 	GetNextID() int64
 	RowCount() int64
 	UpdateRowCount(count int64) error
+	// DeletedRowCount is the number of rows counted by RowCount whose
+	// deleted_at is set.
+	DeletedRowCount() int64
+	UpdateDeletedRowCount(count int64) error
 }
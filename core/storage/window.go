@@ -0,0 +1,16 @@
+package storage
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// WindowConfig describes how a window cursor computes window functions:
+// the rows are sorted by PartitionBy and then OrderBy, and every partition
+// is read into memory to compute Functions, which all share that window,
+// under their names. Sorting spills beyond MemoryLimit bytes of rows to
+// TempDir like a sort cursor does.
+type WindowConfig struct {
+	PartitionBy []string
+	OrderBy     []SortKey
+	Functions   []statement.WindowFunction
+	MemoryLimit int64
+	TempDir     string
+}
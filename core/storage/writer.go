@@ -3,6 +3,9 @@ package storage
 // Writer provides data writing operations
 type Writer interface {
 	Write(values map[string]interface{}) error
+	// Update overwrites the columns in values of the existing row whose id
+	// is values["id"], leaving the other columns as they are.
+	Update(values map[string]interface{}) error
 	Flush() error
 	Close() error
 	Commit() error
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"slices"
+
+	"github.com/onnasoft/ZenithSQL/core/storage"
 	"github.com/onnasoft/ZenithSQL/io/filters"
 	"github.com/onnasoft/ZenithSQL/io/statement"
 	"github.com/sirupsen/logrus"
@@ -36,11 +39,11 @@ func main() {
 		Aggregations: []statement.Aggregation{
 			{
 				Function: "COUNT",
-				Column:   "country",
+				Column:   "*",
 				Alias:    "count",
 			},
 		},
-		GroupBy: []string{"name"},
+		GroupBy: []string{"country"},
 		Where:   filters.NewCondition("age", filters.Equal, int8(12)),
 	})
 	if err != nil {
@@ -63,14 +66,21 @@ func main() {
 		log.Fatalf("error getting cursor: %v", err)
 	}*/
 
-	cursor, err = cursor.WithGroupBy(stmt.GroupBy, stmt.Aggregations)
+	cursor, err = cursor.WithGroupBy(storage.GroupConfig{
+		Columns:      stmt.GroupBy,
+		Aggregations: stmt.Aggregations,
+	})
 	if err != nil {
 		log.Fatalf("error getting cursor: %v", err)
 	}
 
 	for cursor.Next() {
 		record := make(map[string]interface{})
-		for _, column := range stmt.Columns {
+		columns := slices.Clone(stmt.Columns)
+		for _, agg := range stmt.Aggregations {
+			columns = append(columns, agg.Name())
+		}
+		for _, column := range columns {
 			value, err := cursor.ScanField(column)
 			if err != nil {
 				log.Fatalf("error scanning field: %v", err)
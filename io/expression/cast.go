@@ -0,0 +1,182 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// numericTypes are the Go types numeric columns are read into.
+var numericTypes = map[fields.Types]reflect.Type{
+	fields.Int8:    reflect.TypeOf(int8(0)),
+	fields.Int16:   reflect.TypeOf(int16(0)),
+	fields.Int32:   reflect.TypeOf(int32(0)),
+	fields.Int64:   reflect.TypeOf(int64(0)),
+	fields.Uint8:   reflect.TypeOf(uint8(0)),
+	fields.Uint16:  reflect.TypeOf(uint16(0)),
+	fields.Uint32:  reflect.TypeOf(uint32(0)),
+	fields.Uint64:  reflect.TypeOf(uint64(0)),
+	fields.Float32: reflect.TypeOf(float32(0)),
+	fields.Float64: reflect.TypeOf(float64(0)),
+}
+
+// castType returns the type CAST converts to. Only scalar types qualify.
+func castType(to fields.Types) (fields.DataType, error) {
+	switch to {
+	case fields.Array, fields.Vector, fields.Enum, fields.GeoPoint, fields.Unknown:
+		return nil, fmt.Errorf("cannot cast to %s", to)
+	}
+	dt := fields.NewDataType(to)
+	if _, ok := dt.(fields.UnknownType); ok {
+		return nil, fmt.Errorf("unknown type %s", to)
+	}
+	return dt, nil
+}
+
+// converter returns the conversion of values of type from into values of
+// type to, or nil when they need none.
+func converter(from, to fields.DataType) (func(interface{}) (interface{}, error), error) {
+	fc, tc := categoryOf(from), categoryOf(to)
+	if fc == nullCategory || sameType(from, to) {
+		return nil, nil
+	}
+
+	// Some types name themselves capitalized.
+	name := fields.Types(strings.ToLower(to.String()))
+	switch tc {
+	case signedCategory, unsignedCategory, floatCategory:
+		goType := numericTypes[name]
+		switch fc {
+		case signedCategory, unsignedCategory, floatCategory:
+			return func(v interface{}) (interface{}, error) { return toNumber(v, goType) }, nil
+		case boolCategory:
+			return func(v interface{}) (interface{}, error) {
+				if v.(bool) {
+					return toNumber(int64(1), goType)
+				}
+				return toNumber(int64(0), goType)
+			}, nil
+		case stringCategory:
+			return func(v interface{}) (interface{}, error) { return parseNumber(v.(string), goType) }, nil
+		}
+	case stringCategory:
+		return func(v interface{}) (interface{}, error) { return formatValue(v, from), nil }, nil
+	case boolCategory:
+		switch fc {
+		case signedCategory, unsignedCategory, floatCategory:
+			return func(v interface{}) (interface{}, error) { return asFloat64(v) != 0, nil }, nil
+		case stringCategory:
+			return func(v interface{}) (interface{}, error) {
+				b, err := strconv.ParseBool(strings.TrimSpace(v.(string)))
+				if err != nil {
+					return nil, fmt.Errorf("invalid bool %q", v)
+				}
+				return b, nil
+			}, nil
+		}
+	case timeCategory:
+		_, date := to.(fields.DateType)
+		normalize := func(t time.Time) interface{} {
+			if date {
+				return fields.TruncateToDate(t)
+			}
+			return t
+		}
+		switch fc {
+		case timeCategory:
+			return func(v interface{}) (interface{}, error) { return normalize(v.(time.Time)), nil }, nil
+		case stringCategory:
+			return func(v interface{}) (interface{}, error) {
+				t, err := fields.ParseTime(v.(string))
+				if err != nil {
+					return nil, err
+				}
+				return normalize(t), nil
+			}, nil
+		}
+	case timeOfDayCategory:
+		switch fc {
+		case timeCategory:
+			return func(v interface{}) (interface{}, error) { return fields.TimeOfDayFromTime(v.(time.Time)), nil }, nil
+		case stringCategory:
+			return func(v interface{}) (interface{}, error) { return fields.ParseTimeOfDay(v.(string)) }, nil
+		}
+	case intervalCategory:
+		if fc == stringCategory {
+			return func(v interface{}) (interface{}, error) { return fields.ParseInterval(v.(string)) }, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot cast %s to %s", from, to)
+}
+
+// toNumber converts a number to goType, rounding floats converted to
+// integers, and fails when it does not fit.
+func toNumber(v interface{}, goType reflect.Type) (interface{}, error) {
+	switch goType.Kind() {
+	case reflect.Float32:
+		return float32(asFloat64(v)), nil
+	case reflect.Float64:
+		return asFloat64(v), nil
+	}
+
+	switch v.(type) {
+	case float32, float64:
+		f := math.Round(asFloat64(v))
+		switch {
+		case f >= math.MinInt64 && f < math.MaxInt64:
+			v = int64(f)
+		case f >= 0 && f < math.MaxUint64:
+			v = uint64(f)
+		default:
+			return nil, fmt.Errorf("%v out of range for %s", f, goType)
+		}
+	}
+	// Conversions between signed and unsigned integers wrap around
+	// without losing bits, so the sign is checked on its own.
+	unsigned := goType.Kind() >= reflect.Uint && goType.Kind() <= reflect.Uint64
+	n, signed := asInt64(v)
+	converted, err := fields.ConvertElement(goType, v)
+	if err != nil || (signed && n < 0 && unsigned) || (!signed && !unsigned) {
+		return nil, fmt.Errorf("%v out of range for %s", v, goType)
+	}
+	return converted, nil
+}
+
+func parseNumber(s string, goType reflect.Type) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return toNumber(n, goType)
+	}
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return toNumber(n, goType)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return toNumber(f, goType)
+}
+
+// formatValue renders a value of type dt as text, the way CAST to string
+// does.
+func formatValue(v interface{}, dt fields.DataType) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case time.Time:
+		if _, ok := dt.(fields.DateType); ok {
+			return x.Format(time.DateOnly)
+		}
+		return x.Format(time.RFC3339Nano)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,358 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// Kind is the kind of node of an expression tree.
+type Kind string
+
+const (
+	Column   Kind = "column"
+	Literal  Kind = "literal"
+	Unary    Kind = "unary"
+	Binary   Kind = "binary"
+	Case     Kind = "case"
+	Cast     Kind = "cast"
+	Function Kind = "function"
+)
+
+type Operator string
+
+const (
+	Add      Operator = "+"
+	Subtract Operator = "-"
+	Multiply Operator = "*"
+	Divide   Operator = "/"
+	Modulo   Operator = "%"
+
+	Equal              Operator = "="
+	NotEqual           Operator = "!="
+	LessThan           Operator = "<"
+	LessThanOrEqual    Operator = "<="
+	GreaterThan        Operator = ">"
+	GreaterThanOrEqual Operator = ">="
+
+	And Operator = "AND"
+	Or  Operator = "OR"
+
+	// Unary operators.
+	Negate    Operator = "-"
+	Not       Operator = "NOT"
+	IsNull    Operator = "IS NULL"
+	IsNotNull Operator = "IS NOT NULL"
+)
+
+// evalFn computes the value of a prepared expression for the current row of
+// the cursor it was prepared for. Nil stands for NULL.
+type evalFn func() (interface{}, error)
+
+// Expression is a scalar expression over the columns of a row. Column
+// nodes name a column, Literal nodes hold a value, Unary and Binary nodes
+// apply Operator to their Args, Case nodes hold WHEN and THEN pairs in Args
+// optionally followed by the ELSE result, Cast nodes convert their only
+// argument to Type and Function nodes call Name with Args.
+//
+// Like a filter, an expression is prepared for the scanners of a cursor,
+// which type checks it and compiles it into closures, and then evaluated
+// for every row.
+type Expression struct {
+	Kind     Kind          `msgpack:"kind"`
+	Name     string        `msgpack:"name,omitempty"`
+	Value    interface{}   `msgpack:"value"`
+	Operator Operator      `msgpack:"operator,omitempty"`
+	Args     []*Expression `msgpack:"args,omitempty"`
+	Type     fields.Types  `msgpack:"type,omitempty"`
+
+	dataType fields.DataType
+	eval     evalFn
+}
+
+func NewColumn(name string) *Expression {
+	return &Expression{Kind: Column, Name: name}
+}
+
+func NewLiteral(value interface{}) *Expression {
+	return &Expression{Kind: Literal, Value: value}
+}
+
+func NewUnary(op Operator, operand *Expression) *Expression {
+	return &Expression{Kind: Unary, Operator: op, Args: []*Expression{operand}}
+}
+
+func NewBinary(op Operator, left, right *Expression) *Expression {
+	return &Expression{Kind: Binary, Operator: op, Args: []*Expression{left, right}}
+}
+
+// NewCase builds CASE WHEN whens[i] THEN thens[i] ... ELSE otherwise END.
+// A nil otherwise stands for ELSE NULL.
+func NewCase(whens, thens []*Expression, otherwise *Expression) *Expression {
+	e := &Expression{Kind: Case}
+	for i := range whens {
+		e.Args = append(e.Args, whens[i], thens[i])
+	}
+	if otherwise != nil {
+		e.Args = append(e.Args, otherwise)
+	}
+	return e
+}
+
+func NewCast(operand *Expression, to fields.Types) *Expression {
+	return &Expression{Kind: Cast, Type: to, Args: []*Expression{operand}}
+}
+
+func NewFunction(name string, args ...*Expression) *Expression {
+	return &Expression{Kind: Function, Name: strings.ToUpper(name), Args: args}
+}
+
+// Walk calls fn for e and its descendants, parents before their arguments.
+func (e *Expression) Walk(fn func(*Expression)) {
+	fn(e)
+	for _, arg := range e.Args {
+		arg.Walk(fn)
+	}
+}
+
+// Columns returns the columns the expression reads, once each, in order of
+// appearance.
+func (e *Expression) Columns() []string {
+	var columns []string
+	seen := make(map[string]bool)
+	e.Walk(func(node *Expression) {
+		if node.Kind == Column && !seen[node.Name] {
+			seen[node.Name] = true
+			columns = append(columns, node.Name)
+		}
+	})
+	return columns
+}
+
+// Rename copies the expression, renaming its columns.
+func (e *Expression) Rename(rename func(string) (string, error)) (*Expression, error) {
+	clone := &Expression{Kind: e.Kind, Name: e.Name, Value: e.Value, Operator: e.Operator, Type: e.Type}
+	if e.Kind == Column {
+		name, err := rename(e.Name)
+		if err != nil {
+			return nil, err
+		}
+		clone.Name = name
+	}
+	for _, arg := range e.Args {
+		renamed, err := arg.Rename(rename)
+		if err != nil {
+			return nil, err
+		}
+		clone.Args = append(clone.Args, renamed)
+	}
+	return clone, nil
+}
+
+// SetValue replaces the value of a literal. The expression has to be
+// prepared again before it is evaluated.
+func (e *Expression) SetValue(value interface{}) {
+	e.Value = value
+	e.eval = nil
+}
+
+// Prepare type checks the expression against the scanners of a cursor and
+// compiles it. Literals relative to now() are resolved at this point.
+func (e *Expression) Prepare(scanMap map[string]*buffer.Scanner) error {
+	return e.prepare(scanMap, time.Now())
+}
+
+func (e *Expression) prepare(scanMap map[string]*buffer.Scanner, now time.Time) error {
+	e.eval, e.dataType = nil, nil
+	for _, arg := range e.Args {
+		if err := arg.prepare(scanMap, now); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch e.Kind {
+	case Column:
+		err = e.prepareColumn(scanMap)
+	case Literal:
+		err = e.prepareLiteral(now)
+	case Unary:
+		err = e.prepareUnary()
+	case Binary:
+		err = e.prepareBinary()
+	case Case:
+		err = e.prepareCase()
+	case Cast:
+		err = e.prepareCast()
+	case Function:
+		err = e.prepareFunction(now)
+	default:
+		err = fmt.Errorf("unsupported expression kind %q", e.Kind)
+	}
+	if err != nil {
+		e.eval, e.dataType = nil, nil
+	}
+	return err
+}
+
+// DataType is the type of the values of a prepared expression. NULL
+// literals have the unknown type.
+func (e *Expression) DataType() fields.DataType {
+	return e.dataType
+}
+
+// Eval computes the expression for the current row of the cursor it was
+// prepared for. It returns nil for NULL.
+func (e *Expression) Eval() (interface{}, error) {
+	if e.eval == nil {
+		return nil, errors.New("expression not prepared")
+	}
+	return e.eval()
+}
+
+func (e *Expression) prepareColumn(scanMap map[string]*buffer.Scanner) error {
+	scanner, ok := scanMap[e.Name]
+	if !ok {
+		return fmt.Errorf("column %s not found", e.Name)
+	}
+	eval, dt, err := scanColumn(scanner)
+	if err != nil {
+		return fmt.Errorf("column %s: %w", e.Name, err)
+	}
+	e.eval, e.dataType = eval, dt
+	return nil
+}
+
+func (e *Expression) prepareLiteral(now time.Time) error {
+	value, dt, err := literal(e.Value, now)
+	if err != nil {
+		return err
+	}
+	e.eval = func() (interface{}, error) { return value, nil }
+	e.dataType = dt
+	return nil
+}
+
+func (e *Expression) prepareCase() error {
+	n := len(e.Args)
+	if n < 2 {
+		return errors.New("CASE requires at least one WHEN clause")
+	}
+	pairs := n / 2
+	results := make([]*Expression, 0, pairs+1)
+	for i := 0; i < pairs; i++ {
+		if c := categoryOf(e.Args[2*i].dataType); c != boolCategory && c != nullCategory {
+			return fmt.Errorf("CASE condition %s must be bool, not %s", e.Args[2*i], e.Args[2*i].dataType)
+		}
+		results = append(results, e.Args[2*i+1])
+	}
+	if n%2 == 1 {
+		results = append(results, e.Args[n-1])
+	}
+
+	dt, converted, err := unify("CASE", results)
+	if err != nil {
+		return err
+	}
+	conditions := make([]evalFn, pairs)
+	for i := range conditions {
+		conditions[i] = e.Args[2*i].eval
+	}
+	var otherwise evalFn
+	if n%2 == 1 {
+		otherwise = converted[pairs]
+	}
+
+	e.dataType = dt
+	e.eval = func() (interface{}, error) {
+		for i, condition := range conditions {
+			ok, err := condition()
+			if err != nil {
+				return nil, err
+			}
+			if ok == true {
+				return converted[i]()
+			}
+		}
+		if otherwise == nil {
+			return nil, nil
+		}
+		return otherwise()
+	}
+	return nil
+}
+
+func (e *Expression) prepareCast() error {
+	if len(e.Args) != 1 {
+		return errors.New("CAST requires a single operand")
+	}
+	to, err := castType(e.Type)
+	if err != nil {
+		return err
+	}
+	operand := e.Args[0]
+	convert, err := converter(operand.dataType, to)
+	if err != nil {
+		return err
+	}
+	e.dataType = to
+	e.eval = convertEval(operand.eval, convert)
+	return nil
+}
+
+func (e *Expression) prepareFunction(now time.Time) error {
+	fn, ok := functions[e.Name]
+	if !ok {
+		return fmt.Errorf("unknown function %s", e.Name)
+	}
+	if err := fn.checkArity(len(e.Args)); err != nil {
+		return fmt.Errorf("%s: %w", e.Name, err)
+	}
+	dt, eval, err := fn.compile(e.Args, now)
+	if err != nil {
+		return fmt.Errorf("%s: %w", e.Name, err)
+	}
+	e.dataType, e.eval = dt, eval
+	return nil
+}
+
+// unify converts the values of exprs to the type they share, as the
+// results of CASE and COALESCE are.
+func unify(what string, exprs []*Expression) (fields.DataType, []evalFn, error) {
+	types := make([]fields.DataType, len(exprs))
+	for i, expr := range exprs {
+		types[i] = expr.dataType
+	}
+	dt, err := commonType(types)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %w", what, err)
+	}
+	evals := make([]evalFn, len(exprs))
+	for i, expr := range exprs {
+		convert, err := converter(expr.dataType, dt)
+		if err != nil {
+			return nil, nil, err
+		}
+		evals[i] = convertEval(expr.eval, convert)
+	}
+	return dt, evals, nil
+}
+
+// convertEval applies convert to the non-null values of eval. A nil
+// convert leaves them as they are.
+func convertEval(eval evalFn, convert func(interface{}) (interface{}, error)) evalFn {
+	if convert == nil {
+		return eval
+	}
+	return func() (interface{}, error) {
+		value, err := eval()
+		if err != nil || value == nil {
+			return nil, err
+		}
+		return convert(value)
+	}
+}
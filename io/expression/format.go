@@ -0,0 +1,110 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// precedence orders operators from the loosest binding to the tightest.
+var precedence = map[Operator]int{
+	Or:                 1,
+	And:                2,
+	Equal:              4,
+	NotEqual:           4,
+	LessThan:           4,
+	LessThanOrEqual:    4,
+	GreaterThan:        4,
+	GreaterThanOrEqual: 4,
+	Add:                5,
+	Subtract:           5,
+	Multiply:           6,
+	Divide:             6,
+	Modulo:             6,
+}
+
+// String renders the expression as SQL, the way it names a computed column
+// that has no alias.
+func (e *Expression) String() string {
+	switch e.Kind {
+	case Column:
+		return e.Name
+	case Literal:
+		return formatLiteral(e.Value)
+	case Unary:
+		switch e.Operator {
+		case IsNull, IsNotNull:
+			return e.Args[0].operand(4, false) + " " + string(e.Operator)
+		case Not:
+			return "NOT " + e.Args[0].operand(3, false)
+		}
+		operand := e.Args[0].operand(7, false)
+		if strings.HasPrefix(operand, "-") {
+			operand = "(" + operand + ")"
+		}
+		return string(e.Operator) + operand
+	case Binary:
+		p := precedence[e.Operator]
+		return e.Args[0].operand(p, false) + " " + string(e.Operator) + " " + e.Args[1].operand(p, true)
+	case Case:
+		var sb strings.Builder
+		sb.WriteString("CASE")
+		for i := 0; i+1 < len(e.Args); i += 2 {
+			sb.WriteString(" WHEN " + e.Args[i].String() + " THEN " + e.Args[i+1].String())
+		}
+		if len(e.Args)%2 == 1 {
+			sb.WriteString(" ELSE " + e.Args[len(e.Args)-1].String())
+		}
+		sb.WriteString(" END")
+		return sb.String()
+	case Cast:
+		return fmt.Sprintf("CAST(%s AS %s)", e.Args[0], strings.ToUpper(string(e.Type)))
+	case Function:
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = arg.String()
+		}
+		return e.Name + "(" + strings.Join(args, ", ") + ")"
+	}
+	return "?"
+}
+
+// operand renders e as an operand of an operator of precedence p, in
+// parentheses when it binds looser, or as loose on the right side.
+func (e *Expression) operand(p int, right bool) string {
+	var q int
+	switch {
+	case e.Kind == Binary:
+		q = precedence[e.Operator]
+	case e.Kind == Unary && e.Operator == Not:
+		q = 3
+	case e.Kind == Unary && e.Operator != Negate:
+		q = 4
+	default:
+		return e.String()
+	}
+	if q < p || (q == p && right) {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+func formatLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case time.Time:
+		return "TIMESTAMP '" + v.Format(time.RFC3339Nano) + "'"
+	case fields.IntervalValue:
+		return "INTERVAL '" + v.String() + "'"
+	case fields.TimeOfDay:
+		return "TIME '" + v.String() + "'"
+	case fields.LatLng:
+		return fmt.Sprintf("POINT(%v, %v)", v.Lat, v.Lng)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,150 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// function is a scalar function of the catalog. Calls take between minArgs
+// and maxArgs arguments, with maxArgs -1 for any number. Compile type
+// checks the prepared arguments of a call and compiles it; now is the time
+// the statement runs at. Arguments are evaluated by the function, so it
+// can skip those it does not need.
+type function struct {
+	minArgs int
+	maxArgs int
+	compile func(args []*Expression, now time.Time) (fields.DataType, evalFn, error)
+}
+
+// functions are the scalar functions expressions can call, by name.
+var functions = map[string]function{
+	"COALESCE": {1, -1, coalesce},
+	"NULLIF":   {2, 2, nullIf},
+
+	"LOWER":  {1, 1, caseMapper(strings.ToLower)},
+	"UPPER":  {1, 1, caseMapper(strings.ToUpper)},
+	"SUBSTR": {2, 3, substr},
+	"LENGTH": {1, 1, length},
+	"TRIM":   {1, 2, trim},
+	"CONCAT": {1, -1, concat},
+
+	"ABS":   {1, 1, abs},
+	"ROUND": {1, 2, round},
+	"FLOOR": {1, 1, floor},
+
+	"NOW":        {0, 0, now},
+	"DATE_TRUNC": {2, 2, dateTrunc},
+	"EXTRACT":    {2, 2, extract},
+}
+
+// IsFunction reports whether name is a scalar function.
+func IsFunction(name string) bool {
+	_, ok := functions[name]
+	return ok
+}
+
+func (f function) checkArity(n int) error {
+	switch {
+	case f.minArgs == f.maxArgs && n != f.minArgs:
+		return fmt.Errorf("requires %d arguments, got %d", f.minArgs, n)
+	case n < f.minArgs:
+		return fmt.Errorf("requires at least %d arguments, got %d", f.minArgs, n)
+	case f.maxArgs >= 0 && n > f.maxArgs:
+		return fmt.Errorf("accepts at most %d arguments, got %d", f.maxArgs, n)
+	}
+	return nil
+}
+
+// checkArg fails unless argument i, counted from zero, is NULL or belongs
+// to one of the categories, which what describes.
+func checkArg(args []*Expression, i int, what string, categories ...category) error {
+	c := categoryOf(args[i].dataType)
+	if c == nullCategory {
+		return nil
+	}
+	for _, allowed := range categories {
+		if c == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("argument %d must be %s, not %s", i+1, what, args[i].dataType)
+}
+
+// constantString returns the value of an argument that has to be known
+// when the call is prepared, such as the unit of DATE_TRUNC.
+func constantString(args []*Expression, i int, what string) (string, error) {
+	arg := args[i]
+	if arg.Kind != Literal || categoryOf(arg.dataType) != stringCategory {
+		return "", fmt.Errorf("argument %d must be a constant %s", i+1, what)
+	}
+	value, err := arg.eval()
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(strings.TrimSpace(value.(string))), nil
+}
+
+// strict compiles a call that is NULL when any argument is, applying fn to
+// the values of the others.
+func strict(args []*Expression, fn func(values []interface{}) (interface{}, error)) evalFn {
+	evals := make([]evalFn, len(args))
+	for i, arg := range args {
+		evals[i] = arg.eval
+	}
+	return func() (interface{}, error) {
+		values := make([]interface{}, len(evals))
+		for i, eval := range evals {
+			value, err := eval()
+			if err != nil || value == nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return fn(values)
+	}
+}
+
+// coalesce returns its first argument that is not null.
+func coalesce(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	dt, evals, err := unify("arguments", args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dt, func() (interface{}, error) {
+		for _, eval := range evals {
+			value, err := eval()
+			if err != nil || value != nil {
+				return value, err
+			}
+		}
+		return nil, nil
+	}, nil
+}
+
+// nullIf returns NULL when its arguments are equal and the first one
+// otherwise.
+func nullIf(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	equal, err := comparison(Equal, args[0].dataType, args[1].dataType)
+	if err != nil {
+		return nil, nil, err
+	}
+	evalValue, evalOther := args[0].eval, args[1].eval
+	return args[0].dataType, func() (interface{}, error) {
+		value, err := evalValue()
+		if err != nil || value == nil {
+			return nil, err
+		}
+		other, err := evalOther()
+		if err != nil || other == nil {
+			return value, err
+		}
+		same, err := equal(value, other)
+		if err != nil || same == true {
+			return nil, err
+		}
+		return value, nil
+	}, nil
+}
@@ -0,0 +1,152 @@
+package expression
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// numericResult is the type a math function returns for a number of type
+// dt: int64, uint64 or float64.
+func numericResult(dt fields.DataType) fields.DataType {
+	if categoryOf(dt) == nullCategory {
+		return fields.Float64Type{}
+	}
+	return numericType(dt, dt)
+}
+
+// abs returns the absolute value of a number.
+func abs(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	if err := checkArg(args, 0, "a number", signedCategory, unsignedCategory, floatCategory); err != nil {
+		return nil, nil, err
+	}
+	dt := numericResult(args[0].dataType)
+	return dt, strict(args, func(values []interface{}) (interface{}, error) {
+		switch dt.(type) {
+		case fields.Float64Type:
+			return math.Abs(asFloat64(values[0])), nil
+		case fields.Uint64Type:
+			u, _ := asUint64(values[0])
+			return u, nil
+		}
+		n, _ := asInt64(values[0])
+		if n == math.MinInt64 {
+			return nil, errors.New("int64 out of range")
+		}
+		if n < 0 {
+			n = -n
+		}
+		return n, nil
+	}), nil
+}
+
+// round rounds a number half away from zero to the given number of
+// decimal places, 0 by default. Negative places round integers to tens,
+// hundreds and so on.
+func round(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	if err := checkArg(args, 0, "a number", signedCategory, unsignedCategory, floatCategory); err != nil {
+		return nil, nil, err
+	}
+	if len(args) == 2 {
+		if err := checkArg(args, 1, "an integer", signedCategory, unsignedCategory); err != nil {
+			return nil, nil, err
+		}
+	}
+	dt := numericResult(args[0].dataType)
+	return dt, strict(args, func(values []interface{}) (interface{}, error) {
+		var places int64
+		if len(values) == 2 {
+			places = integerArg(values[1])
+		}
+		switch dt.(type) {
+		case fields.Float64Type:
+			return roundFloat(asFloat64(values[0]), places), nil
+		case fields.Uint64Type:
+			u, _ := asUint64(values[0])
+			return roundUint(u, places)
+		}
+		n, _ := asInt64(values[0])
+		return roundInt(n, places)
+	}), nil
+}
+
+func roundFloat(f float64, places int64) float64 {
+	if places == 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return math.Round(f)
+	}
+	scale := math.Pow(10, float64(places))
+	if math.IsInf(scale, 0) || scale == 0 {
+		return f
+	}
+	rounded := math.Round(f*scale) / scale
+	if math.IsInf(rounded, 0) || math.IsNaN(rounded) {
+		return f
+	}
+	return rounded
+}
+
+// roundInt rounds n to a multiple of 10^-places when places is negative.
+func roundInt(n int64, places int64) (interface{}, error) {
+	if places >= 0 {
+		return n, nil
+	}
+	if places < -18 {
+		return int64(0), nil
+	}
+	step := int64(math.Pow10(int(-places)))
+	rest := n % step
+	n -= rest
+	switch {
+	case rest >= 0 && rest >= step-rest:
+		if n > math.MaxInt64-step {
+			return nil, errors.New("int64 out of range")
+		}
+		n += step
+	case rest < 0 && -rest >= step+rest:
+		if n < math.MinInt64+step {
+			return nil, errors.New("int64 out of range")
+		}
+		n -= step
+	}
+	return n, nil
+}
+
+func roundUint(u uint64, places int64) (interface{}, error) {
+	if places >= 0 {
+		return u, nil
+	}
+	if places < -19 {
+		return uint64(0), nil
+	}
+	step := uint64(math.Pow10(int(-places)))
+	rest := u % step
+	u -= rest
+	if rest >= step-rest {
+		if u > math.MaxUint64-step {
+			return nil, errors.New("uint64 out of range")
+		}
+		u += step
+	}
+	return u, nil
+}
+
+// floor returns the largest integer not greater than a number.
+func floor(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	if err := checkArg(args, 0, "a number", signedCategory, unsignedCategory, floatCategory); err != nil {
+		return nil, nil, err
+	}
+	dt := numericResult(args[0].dataType)
+	return dt, strict(args, func(values []interface{}) (interface{}, error) {
+		switch dt.(type) {
+		case fields.Float64Type:
+			return math.Floor(asFloat64(values[0])), nil
+		case fields.Uint64Type:
+			u, _ := asUint64(values[0])
+			return u, nil
+		}
+		n, _ := asInt64(values[0])
+		return n, nil
+	}), nil
+}
@@ -0,0 +1,350 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+var errDivisionByZero = errors.New("division by zero")
+
+// binaryFn combines two non-null values.
+type binaryFn func(a, b interface{}) (interface{}, error)
+
+func (e *Expression) prepareUnary() error {
+	if len(e.Args) != 1 {
+		return fmt.Errorf("operator %s requires a single operand", e.Operator)
+	}
+	operand := e.Args[0]
+	eval, dt := operand.eval, operand.dataType
+
+	switch e.Operator {
+	case IsNull, IsNotNull:
+		isNull := e.Operator == IsNull
+		e.dataType = fields.BoolType{}
+		e.eval = func() (interface{}, error) {
+			value, err := eval()
+			if err != nil {
+				return nil, err
+			}
+			return (value == nil) == isNull, nil
+		}
+		return nil
+	case Not:
+		if c := categoryOf(dt); c != boolCategory && c != nullCategory {
+			return fmt.Errorf("operator NOT cannot be applied to %s", dt)
+		}
+		e.dataType = fields.BoolType{}
+		e.eval = func() (interface{}, error) {
+			value, err := eval()
+			if err != nil || value == nil {
+				return nil, err
+			}
+			return !value.(bool), nil
+		}
+		return nil
+	case Negate:
+		negate, result, err := negation(dt)
+		if err != nil {
+			return err
+		}
+		e.dataType = result
+		e.eval = func() (interface{}, error) {
+			value, err := eval()
+			if err != nil || value == nil {
+				return nil, err
+			}
+			return negate(value)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported unary operator %s", e.Operator)
+}
+
+func negation(dt fields.DataType) (func(interface{}) (interface{}, error), fields.DataType, error) {
+	switch categoryOf(dt) {
+	case nullCategory:
+		return nil, dt, nil
+	case signedCategory, unsignedCategory:
+		return func(v interface{}) (interface{}, error) {
+			n, ok := asInt64(v)
+			if !ok || n == math.MinInt64 {
+				return nil, errors.New("int64 out of range")
+			}
+			return -n, nil
+		}, fields.Int64Type{}, nil
+	case floatCategory:
+		return func(v interface{}) (interface{}, error) {
+			return -asFloat64(v), nil
+		}, fields.Float64Type{}, nil
+	case intervalCategory:
+		return func(v interface{}) (interface{}, error) {
+			return v.(fields.IntervalValue).Negate(), nil
+		}, dt, nil
+	}
+	return nil, nil, fmt.Errorf("operator - cannot be applied to %s", dt)
+}
+
+func (e *Expression) prepareBinary() error {
+	if len(e.Args) != 2 {
+		return fmt.Errorf("operator %s requires two operands", e.Operator)
+	}
+	left, right := e.Args[0], e.Args[1]
+
+	switch e.Operator {
+	case And, Or:
+		return e.prepareLogical(left, right)
+	}
+
+	var fn binaryFn
+	var err error
+	switch e.Operator {
+	case Add, Subtract, Multiply, Divide, Modulo:
+		fn, e.dataType, err = arithmetic(e.Operator, left.dataType, right.dataType)
+	case Equal, NotEqual, LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual:
+		fn, err = comparison(e.Operator, left.dataType, right.dataType)
+		e.dataType = fields.BoolType{}
+	default:
+		err = fmt.Errorf("unsupported operator %s", e.Operator)
+	}
+	if err != nil {
+		return err
+	}
+
+	evalLeft, evalRight := left.eval, right.eval
+	e.eval = func() (interface{}, error) {
+		a, err := evalLeft()
+		if err != nil || a == nil {
+			return nil, err
+		}
+		b, err := evalRight()
+		if err != nil || b == nil {
+			return nil, err
+		}
+		return fn(a, b)
+	}
+	return nil
+}
+
+// prepareLogical compiles AND and OR with the three-valued logic of SQL:
+// a false operand makes AND false and a true one makes OR true even when
+// the other is NULL.
+func (e *Expression) prepareLogical(left, right *Expression) error {
+	for _, operand := range []*Expression{left, right} {
+		if c := categoryOf(operand.dataType); c != boolCategory && c != nullCategory {
+			return fmt.Errorf("operator %s cannot be applied to %s", e.Operator, operand.dataType)
+		}
+	}
+	// decisive is the value of an operand that settles the outcome.
+	decisive := e.Operator == Or
+	evalLeft, evalRight := left.eval, right.eval
+	e.dataType = fields.BoolType{}
+	e.eval = func() (interface{}, error) {
+		a, err := evalLeft()
+		if err != nil || a == decisive {
+			return a, err
+		}
+		b, err := evalRight()
+		if err != nil || b == decisive {
+			return b, err
+		}
+		if a == nil || b == nil {
+			return nil, nil
+		}
+		return !decisive, nil
+	}
+	return nil
+}
+
+// comparison compares values of the two types, which must be both numbers
+// or both of the same kind.
+func comparison(op Operator, a, b fields.DataType) (binaryFn, error) {
+	ca, cb := categoryOf(a), categoryOf(b)
+	switch {
+	case ca == nullCategory || cb == nullCategory:
+	case isNumeric(ca) && isNumeric(cb):
+	case ca == cb && ca != otherCategory:
+	default:
+		return nil, fmt.Errorf("cannot compare %s with %s", a, b)
+	}
+
+	var test func(int) bool
+	switch op {
+	case Equal:
+		test = func(c int) bool { return c == 0 }
+	case NotEqual:
+		test = func(c int) bool { return c != 0 }
+	case LessThan:
+		test = func(c int) bool { return c < 0 }
+	case LessThanOrEqual:
+		test = func(c int) bool { return c <= 0 }
+	case GreaterThan:
+		test = func(c int) bool { return c > 0 }
+	case GreaterThanOrEqual:
+		test = func(c int) bool { return c >= 0 }
+	}
+	return func(x, y interface{}) (interface{}, error) {
+		c, err := fields.Compare(x, y)
+		if err != nil {
+			return nil, err
+		}
+		return test(c), nil
+	}, nil
+}
+
+// arithmetic returns the operation op performs on values of the two types
+// and the type of its result. Besides numbers, intervals can be added to
+// and subtracted from timestamps and intervals, and subtracting timestamps
+// gives an interval.
+func arithmetic(op Operator, a, b fields.DataType) (binaryFn, fields.DataType, error) {
+	ca, cb := categoryOf(a), categoryOf(b)
+	switch {
+	case ca == nullCategory && cb == nullCategory:
+		return nil, nullType, nil
+	case ca == nullCategory && isNumeric(cb):
+		return nil, numericType(b, b), nil
+	case cb == nullCategory && isNumeric(ca):
+		return nil, numericType(a, a), nil
+	case isNumeric(ca) && isNumeric(cb):
+		result := numericType(a, b)
+		switch categoryOf(result) {
+		case floatCategory:
+			return floatOperation(op), result, nil
+		case unsignedCategory:
+			return unsignedOperation(op), result, nil
+		}
+		return signedOperation(op), result, nil
+	}
+
+	switch op {
+	case Add:
+		switch {
+		case ca == timeCategory && cb == intervalCategory:
+			return func(x, y interface{}) (interface{}, error) {
+				return y.(fields.IntervalValue).AddTo(x.(time.Time)), nil
+			}, timestampOf(a), nil
+		case ca == intervalCategory && cb == timeCategory:
+			return func(x, y interface{}) (interface{}, error) {
+				return x.(fields.IntervalValue).AddTo(y.(time.Time)), nil
+			}, timestampOf(b), nil
+		case ca == intervalCategory && cb == intervalCategory:
+			return func(x, y interface{}) (interface{}, error) {
+				return x.(fields.IntervalValue).Add(y.(fields.IntervalValue)), nil
+			}, a, nil
+		}
+	case Subtract:
+		switch {
+		case ca == timeCategory && cb == intervalCategory:
+			return func(x, y interface{}) (interface{}, error) {
+				return y.(fields.IntervalValue).SubFrom(x.(time.Time)), nil
+			}, timestampOf(a), nil
+		case ca == intervalCategory && cb == intervalCategory:
+			return func(x, y interface{}) (interface{}, error) {
+				return x.(fields.IntervalValue).Add(y.(fields.IntervalValue).Negate()), nil
+			}, a, nil
+		case ca == timeCategory && cb == timeCategory:
+			return func(x, y interface{}) (interface{}, error) {
+				return fields.NewInterval(0, 0, x.(time.Time).Sub(y.(time.Time))), nil
+			}, fields.IntervalType{}, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("operator %s cannot be applied to %s and %s", op, a, b)
+}
+
+// timestampOf is the type of a point in time shifted by an interval: a
+// date becomes a timestamp, which keeps its time zone handling.
+func timestampOf(dt fields.DataType) fields.DataType {
+	if _, ok := dt.(fields.TimestampTZType); ok {
+		return dt
+	}
+	return fields.TimestampType{}
+}
+
+func signedOperation(op Operator) binaryFn {
+	return func(x, y interface{}) (interface{}, error) {
+		a, ok1 := asInt64(x)
+		b, ok2 := asInt64(y)
+		if !ok1 || !ok2 {
+			return nil, errors.New("int64 out of range")
+		}
+		var c int64
+		overflow := false
+		switch op {
+		case Add:
+			c = a + b
+			overflow = (b > 0 && c < a) || (b < 0 && c > a)
+		case Subtract:
+			c = a - b
+			overflow = (b > 0 && c > a) || (b < 0 && c < a)
+		case Multiply:
+			c = a * b
+			overflow = a != 0 && (c/a != b || (a == -1 && b == math.MinInt64))
+		case Divide, Modulo:
+			if b == 0 {
+				return nil, errDivisionByZero
+			}
+			if op == Modulo {
+				return a % b, nil
+			}
+			c = a / b
+			overflow = a == math.MinInt64 && b == -1
+		}
+		if overflow {
+			return nil, errors.New("int64 out of range")
+		}
+		return c, nil
+	}
+}
+
+func unsignedOperation(op Operator) binaryFn {
+	return func(x, y interface{}) (interface{}, error) {
+		a, _ := asUint64(x)
+		b, _ := asUint64(y)
+		var c, carry uint64
+		switch op {
+		case Add:
+			c, carry = bits.Add64(a, b, 0)
+		case Subtract:
+			c, carry = bits.Sub64(a, b, 0)
+		case Multiply:
+			carry, c = bits.Mul64(a, b)
+		case Divide, Modulo:
+			if b == 0 {
+				return nil, errDivisionByZero
+			}
+			if op == Modulo {
+				return a % b, nil
+			}
+			c = a / b
+		}
+		if carry != 0 {
+			return nil, errors.New("uint64 out of range")
+		}
+		return c, nil
+	}
+}
+
+func floatOperation(op Operator) binaryFn {
+	return func(x, y interface{}) (interface{}, error) {
+		a, b := asFloat64(x), asFloat64(y)
+		switch op {
+		case Add:
+			return a + b, nil
+		case Subtract:
+			return a - b, nil
+		case Multiply:
+			return a * b, nil
+		}
+		if b == 0 {
+			return nil, errDivisionByZero
+		}
+		if op == Modulo {
+			return math.Mod(a, b), nil
+		}
+		return a / b, nil
+	}
+}
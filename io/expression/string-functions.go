@@ -0,0 +1,116 @@
+package expression
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// caseMapper compiles LOWER and UPPER.
+func caseMapper(mapCase func(string) string) func([]*Expression, time.Time) (fields.DataType, evalFn, error) {
+	return func(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+		if err := checkArg(args, 0, "a string", stringCategory); err != nil {
+			return nil, nil, err
+		}
+		return fields.StringType{}, strict(args, func(values []interface{}) (interface{}, error) {
+			return mapCase(values[0].(string)), nil
+		}), nil
+	}
+}
+
+// substr returns count characters of a string from the position start,
+// counted from 1, or all of them up to the end without count. Positions
+// before the first character count towards count, as they do in
+// PostgreSQL.
+func substr(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	if err := checkArg(args, 0, "a string", stringCategory); err != nil {
+		return nil, nil, err
+	}
+	for i := 1; i < len(args); i++ {
+		if err := checkArg(args, i, "an integer", signedCategory, unsignedCategory); err != nil {
+			return nil, nil, err
+		}
+	}
+	return fields.StringType{}, strict(args, func(values []interface{}) (interface{}, error) {
+		runes := []rune(values[0].(string))
+		size := int64(len(runes))
+		from := integerArg(values[1]) - 1
+		to := size
+		if len(values) == 3 {
+			count := integerArg(values[2])
+			if count < 0 {
+				return nil, errors.New("SUBSTR: negative length")
+			}
+			if from < size && count < size-from {
+				to = from + count
+			}
+		}
+		from = min(max(from, 0), size)
+		to = min(max(to, from), size)
+		return string(runes[from:to]), nil
+	}), nil
+}
+
+// integerArg reads an integer argument, saturating unsigned values that do
+// not fit in an int64.
+func integerArg(v interface{}) int64 {
+	if n, ok := asInt64(v); ok {
+		return n
+	}
+	return 1<<63 - 1
+}
+
+// length returns the number of characters of a string, or of elements of
+// an array or vector.
+func length(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	if err := checkArg(args, 0, "a string or a list", stringCategory, listCategory); err != nil {
+		return nil, nil, err
+	}
+	return fields.Int64Type{}, strict(args, func(values []interface{}) (interface{}, error) {
+		if s, ok := values[0].(string); ok {
+			return int64(utf8.RuneCountInString(s)), nil
+		}
+		return int64(reflect.ValueOf(values[0]).Len()), nil
+	}), nil
+}
+
+// trim removes the whitespace, or the given characters, around a string.
+func trim(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	for i := range args {
+		if err := checkArg(args, i, "a string", stringCategory); err != nil {
+			return nil, nil, err
+		}
+	}
+	return fields.StringType{}, strict(args, func(values []interface{}) (interface{}, error) {
+		if len(values) == 2 {
+			return strings.Trim(values[0].(string), values[1].(string)), nil
+		}
+		return strings.TrimSpace(values[0].(string)), nil
+	}), nil
+}
+
+// concat joins its arguments as text, skipping the NULL ones.
+func concat(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	evals := make([]evalFn, len(args))
+	types := make([]fields.DataType, len(args))
+	for i, arg := range args {
+		evals[i], types[i] = arg.eval, arg.dataType
+	}
+	return fields.StringType{}, func() (interface{}, error) {
+		var sb strings.Builder
+		for i, eval := range evals {
+			value, err := eval()
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				sb.WriteString(formatValue(value, types[i]))
+			}
+		}
+		return sb.String(), nil
+	}, nil
+}
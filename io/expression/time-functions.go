@@ -0,0 +1,142 @@
+package expression
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// now returns the time the statement runs at, the same for every row.
+func now(_ []*Expression, at time.Time) (fields.DataType, evalFn, error) {
+	return fields.TimestampType{}, func() (interface{}, error) { return at, nil }, nil
+}
+
+// truncations cut a time down to the start of a unit, in its own location.
+var truncations = map[string]func(time.Time) time.Time{
+	"microsecond": func(t time.Time) time.Time { return t.Truncate(time.Microsecond) },
+	"millisecond": func(t time.Time) time.Time { return t.Truncate(time.Millisecond) },
+	"second":      func(t time.Time) time.Time { return t.Truncate(time.Second) },
+	"minute": func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	},
+	"hour": func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	},
+	"day": func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	},
+	// Weeks start on Monday, as ISO 8601 weeks do.
+	"week": func(t time.Time) time.Time {
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+	},
+	"month": func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	},
+	"quarter": func(t time.Time) time.Time {
+		month := (t.Month()-1)/3*3 + 1
+		return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+	},
+	"year": func(t time.Time) time.Time {
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	},
+}
+
+// dateTrunc truncates a date or timestamp to the start of a unit, such as
+// DATE_TRUNC('day', created_at). Dates become timestamps.
+func dateTrunc(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	unit, err := constantString(args, 0, "unit")
+	if err != nil {
+		return nil, nil, err
+	}
+	truncate, ok := truncations[unit]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown unit %q", unit)
+	}
+	if err := checkArg(args, 1, "a timestamp", timeCategory); err != nil {
+		return nil, nil, err
+	}
+	return timestampOf(args[1].dataType), strict(args[1:], func(values []interface{}) (interface{}, error) {
+		return truncate(values[0].(time.Time)), nil
+	}), nil
+}
+
+// timeField is a part EXTRACT reads, as an int64 unless it has fractions.
+type timeField struct {
+	fromTime      func(time.Time) interface{}
+	fromTimeOfDay func(fields.TimeOfDay) interface{}
+	dataType      fields.DataType
+}
+
+var timeFields = map[string]timeField{
+	"year":    {fromTime: func(t time.Time) interface{} { return int64(t.Year()) }},
+	"quarter": {fromTime: func(t time.Time) interface{} { return int64(t.Month()-1)/3 + 1 }},
+	"month":   {fromTime: func(t time.Time) interface{} { return int64(t.Month()) }},
+	"week": {fromTime: func(t time.Time) interface{} {
+		_, week := t.ISOWeek()
+		return int64(week)
+	}},
+	"day": {fromTime: func(t time.Time) interface{} { return int64(t.Day()) }},
+	// Days of the week count from Sunday as 0.
+	"dow": {fromTime: func(t time.Time) interface{} { return int64(t.Weekday()) }},
+	"doy": {fromTime: func(t time.Time) interface{} { return int64(t.YearDay()) }},
+	"hour": {
+		fromTime:      func(t time.Time) interface{} { return int64(t.Hour()) },
+		fromTimeOfDay: func(t fields.TimeOfDay) interface{} { return int64(time.Duration(t) / time.Hour) },
+	},
+	"minute": {
+		fromTime:      func(t time.Time) interface{} { return int64(t.Minute()) },
+		fromTimeOfDay: func(t fields.TimeOfDay) interface{} { return int64(time.Duration(t) % time.Hour / time.Minute) },
+	},
+	"second": {
+		fromTime: func(t time.Time) interface{} {
+			return float64(t.Second()) + float64(t.Nanosecond())/1e9
+		},
+		fromTimeOfDay: func(t fields.TimeOfDay) interface{} {
+			return (time.Duration(t) % time.Minute).Seconds()
+		},
+		dataType: fields.Float64Type{},
+	},
+	"epoch": {
+		fromTime: func(t time.Time) interface{} {
+			return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+		},
+		fromTimeOfDay: func(t fields.TimeOfDay) interface{} {
+			return time.Duration(t).Seconds()
+		},
+		dataType: fields.Float64Type{},
+	},
+}
+
+// extract reads a part of a date, timestamp or time of day, such as
+// EXTRACT(YEAR FROM created_at), which is a call with the field as a
+// string.
+func extract(args []*Expression, _ time.Time) (fields.DataType, evalFn, error) {
+	name, err := constantString(args, 0, "field")
+	if err != nil {
+		return nil, nil, err
+	}
+	field, ok := timeFields[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown field %q", name)
+	}
+	categories := []category{timeCategory}
+	if field.fromTimeOfDay != nil {
+		categories = append(categories, timeOfDayCategory)
+	}
+	if err := checkArg(args, 1, "a timestamp", categories...); err != nil {
+		return nil, nil, err
+	}
+
+	dt := field.dataType
+	if dt == nil {
+		dt = fields.Int64Type{}
+	}
+	return dt, strict(args[1:], func(values []interface{}) (interface{}, error) {
+		if t, ok := values[0].(fields.TimeOfDay); ok {
+			return field.fromTimeOfDay(t), nil
+		}
+		return field.fromTime(values[0].(time.Time)), nil
+	}), nil
+}
@@ -0,0 +1,257 @@
+package expression
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// nullType is the type of NULL literals. It takes the type of whatever it
+// is combined with.
+var nullType fields.DataType = fields.UnknownType{}
+
+// category groups the types that operators treat alike.
+type category int
+
+const (
+	otherCategory category = iota
+	nullCategory
+	signedCategory
+	unsignedCategory
+	floatCategory
+	stringCategory
+	boolCategory
+	timeCategory
+	timeOfDayCategory
+	intervalCategory
+	listCategory
+)
+
+func categoryOf(dt fields.DataType) category {
+	switch dt.(type) {
+	case fields.UnknownType:
+		return nullCategory
+	case fields.Int8Type, fields.Int16Type, fields.Int32Type, fields.Int64Type:
+		return signedCategory
+	case fields.Uint8Type, fields.Uint16Type, fields.Uint32Type, fields.Uint64Type:
+		return unsignedCategory
+	case fields.Float32Type, fields.Float64Type:
+		return floatCategory
+	case fields.StringType, fields.EnumType:
+		return stringCategory
+	case fields.BoolType:
+		return boolCategory
+	case fields.TimestampType, fields.TimestampTZType, fields.DateType:
+		return timeCategory
+	case fields.TimeOfDayType:
+		return timeOfDayCategory
+	case fields.IntervalType:
+		return intervalCategory
+	case fields.ArrayType, fields.VectorType:
+		return listCategory
+	}
+	return otherCategory
+}
+
+func isNumeric(c category) bool {
+	return c == signedCategory || c == unsignedCategory || c == floatCategory
+}
+
+// numericType is the type arithmetic on a and b produces: float64 when
+// either is a float, uint64 when both are unsigned and int64 otherwise.
+func numericType(a, b fields.DataType) fields.DataType {
+	ca, cb := categoryOf(a), categoryOf(b)
+	switch {
+	case ca == floatCategory || cb == floatCategory:
+		return fields.Float64Type{}
+	case ca == unsignedCategory && cb == unsignedCategory:
+		return fields.Uint64Type{}
+	}
+	return fields.Int64Type{}
+}
+
+// sameType reports whether values of a and b are interchangeable.
+func sameType(a, b fields.DataType) bool {
+	if x, ok := a.(fields.ArrayType); ok {
+		y, ok := b.(fields.ArrayType)
+		return ok && x.ElementType == y.ElementType
+	}
+	return a.String() == b.String()
+}
+
+// commonType returns the type values of the given types are converted to
+// when they are results of the same CASE or COALESCE: the type they all
+// have, the promoted type of numbers, timestamp for dates and timestamps,
+// and string for strings and enums.
+func commonType(types []fields.DataType) (fields.DataType, error) {
+	result := nullType
+	for _, dt := range types {
+		rc, c := categoryOf(result), categoryOf(dt)
+		switch {
+		case c == nullCategory:
+		case rc == nullCategory:
+			result = dt
+		case sameType(result, dt):
+		case isNumeric(rc) && isNumeric(c):
+			result = numericType(result, dt)
+		case rc == timeCategory && c == timeCategory:
+			result = fields.TimestampType{}
+		case rc == stringCategory && c == stringCategory:
+			result = fields.StringType{}
+		default:
+			return nil, fmt.Errorf("types %s and %s cannot be matched", result, dt)
+		}
+	}
+	if _, ok := result.(fields.EnumType); ok {
+		result = fields.StringType{}
+	}
+	return result, nil
+}
+
+// timeResolver is implemented by literals standing for a point in time
+// relative to now(), such as filters.TimeExpr.
+type timeResolver interface {
+	Resolve(now time.Time) time.Time
+}
+
+// literal returns the value a literal evaluates to and its type. Integers
+// are widened to int64 or uint64 and floats to float64.
+func literal(value interface{}, now time.Time) (interface{}, fields.DataType, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nullType, nil
+	case int8, int16, int32, int64, int:
+		n, _ := asInt64(v)
+		return n, fields.Int64Type{}, nil
+	case uint8, uint16, uint32, uint64, uint:
+		n, _ := asUint64(v)
+		return n, fields.Uint64Type{}, nil
+	case float32, float64:
+		return asFloat64(v), fields.Float64Type{}, nil
+	case string:
+		return v, fields.StringType{}, nil
+	case bool:
+		return v, fields.BoolType{}, nil
+	case time.Time:
+		return v, fields.TimestampType{}, nil
+	case fields.IntervalValue:
+		return v, fields.IntervalType{}, nil
+	case fields.TimeOfDay:
+		return v, fields.TimeOfDayType{}, nil
+	case fields.LatLng:
+		return v, fields.GeoPointType{}, nil
+	case timeResolver:
+		return v.Resolve(now), fields.TimestampType{}, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported literal %v of type %T", value, value)
+}
+
+// scanColumn compiles the read of a column. Values keep the Go type the
+// column is scanned into, except enums which are read as their string.
+func scanColumn(scanner *buffer.Scanner) (evalFn, fields.DataType, error) {
+	dt := scanner.Type
+	scan := scanner.Scan
+	switch dt.(type) {
+	case fields.Int8Type:
+		return scanAs[int8](scan), dt, nil
+	case fields.Int16Type:
+		return scanAs[int16](scan), dt, nil
+	case fields.Int32Type:
+		return scanAs[int32](scan), dt, nil
+	case fields.Int64Type:
+		return scanAs[int64](scan), dt, nil
+	case fields.Uint8Type:
+		return scanAs[uint8](scan), dt, nil
+	case fields.Uint16Type:
+		return scanAs[uint16](scan), dt, nil
+	case fields.Uint32Type:
+		return scanAs[uint32](scan), dt, nil
+	case fields.Uint64Type:
+		return scanAs[uint64](scan), dt, nil
+	case fields.Float32Type:
+		return scanAs[float32](scan), dt, nil
+	case fields.Float64Type:
+		return scanAs[float64](scan), dt, nil
+	case fields.StringType:
+		return scanAs[string](scan), dt, nil
+	case fields.EnumType:
+		return scanAs[string](scan), fields.StringType{}, nil
+	case fields.BoolType:
+		return scanAs[bool](scan), dt, nil
+	case fields.TimestampType, fields.TimestampTZType, fields.DateType:
+		return scanAs[time.Time](scan), dt, nil
+	case fields.TimeOfDayType:
+		return scanAs[fields.TimeOfDay](scan), dt, nil
+	case fields.IntervalType:
+		return scanAs[fields.IntervalValue](scan), dt, nil
+	case fields.GeoPointType:
+		return scanAs[fields.LatLng](scan), dt, nil
+	case fields.ArrayType:
+		return scanAs[[]interface{}](scan), dt, nil
+	case fields.VectorType:
+		return scanAs[[]float32](scan), dt, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported type %s", dt)
+}
+
+func scanAs[T any](scan buffer.ScanFunc) evalFn {
+	return func() (interface{}, error) {
+		var value T
+		ok, err := scan(&value)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+func asInt64(v interface{}) (int64, bool) {
+	switch x := v.(type) {
+	case int8:
+		return int64(x), true
+	case int16:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	case int:
+		return int64(x), true
+	}
+	if u, ok := asUint64(v); ok && u <= 1<<63-1 {
+		return int64(u), true
+	}
+	return 0, false
+}
+
+func asUint64(v interface{}) (uint64, bool) {
+	switch x := v.(type) {
+	case uint8:
+		return uint64(x), true
+	case uint16:
+		return uint64(x), true
+	case uint32:
+		return uint64(x), true
+	case uint64:
+		return x, true
+	case uint:
+		return uint64(x), true
+	}
+	return 0, false
+}
+
+func asFloat64(v interface{}) float64 {
+	switch x := v.(type) {
+	case float32:
+		return float64(x)
+	case float64:
+		return x
+	}
+	if n, ok := asInt64(v); ok {
+		return float64(n)
+	}
+	u, _ := asUint64(v)
+	return float64(u)
+}
@@ -0,0 +1,114 @@
+package filters
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+const errorUnsupportedOperatorArray = "unsupported operator %s for type array"
+
+// filterArray compiles a condition on an array column. CONTAINS matches rows
+// whose array holds every given value, ANY rows holding at least one of them
+// and ALL rows whose elements are all among them.
+func filterArray(f *Filter, dt fields.ArrayType) (filterFn, error) {
+	switch f.Operator {
+	case Equal:
+		return compareArray(f, dt, true)
+	case NotEqual:
+		return compareArray(f, dt, false)
+	case Contains:
+		return matchArray(f, dt, func(values, wanted []interface{}) bool {
+			for _, w := range wanted {
+				if !containsElement(values, w) {
+					return false
+				}
+			}
+			return true
+		})
+	case Any:
+		return matchArray(f, dt, func(values, wanted []interface{}) bool {
+			return slices.ContainsFunc(values, func(v interface{}) bool {
+				return containsElement(wanted, v)
+			})
+		})
+	case All:
+		return matchArray(f, dt, func(values, wanted []interface{}) bool {
+			for _, v := range values {
+				if !containsElement(wanted, v) {
+					return false
+				}
+			}
+			return true
+		})
+	case IsNull:
+		return isNullArray(f, true)
+	case IsNotNull:
+		return isNullArray(f, false)
+	default:
+		return nil, fmt.Errorf(errorUnsupportedOperatorArray, f.Operator)
+	}
+}
+
+func compareArray(f *Filter, dt fields.ArrayType, equal bool) (filterFn, error) {
+	data, err := dt.Elements(f.Value)
+	if err != nil {
+		return nil, fmt.Errorf("operator %s requires an array value: %w", f.Operator, err)
+	}
+	return func() (bool, error) {
+		var values []interface{}
+		ok, err := f.scanFunc(&values)
+		if err != nil || !ok {
+			return false, err
+		}
+		same := slices.EqualFunc(values, data, equalElement)
+		return same == equal, nil
+	}, nil
+}
+
+func matchArray(f *Filter, dt fields.ArrayType, match func(values, wanted []interface{}) bool) (filterFn, error) {
+	wanted, err := extractArrayValues(f.Value, dt)
+	if err != nil || len(wanted) == 0 {
+		return nil, fmt.Errorf("operator %s requires a value or a non-empty slice of %s", f.Operator, dt.ElementType)
+	}
+	return func() (bool, error) {
+		var values []interface{}
+		ok, err := f.scanFunc(&values)
+		if err != nil || !ok {
+			return false, err
+		}
+		return match(values, wanted), nil
+	}, nil
+}
+
+func isNullArray(f *Filter, expectNull bool) (filterFn, error) {
+	return func() (bool, error) {
+		var values []interface{}
+		ok, _ := f.scanFunc(&values)
+		return expectNull != ok, nil
+	}, nil
+}
+
+func extractArrayValues(value interface{}, dt fields.ArrayType) ([]interface{}, error) {
+	if reflect.ValueOf(value).Kind() == reflect.Slice {
+		return dt.Elements(value)
+	}
+	return dt.Elements([]interface{}{value})
+}
+
+func containsElement(values []interface{}, v interface{}) bool {
+	return slices.ContainsFunc(values, func(e interface{}) bool {
+		return equalElement(e, v)
+	})
+}
+
+func equalElement(a, b interface{}) bool {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return a == b
+}
@@ -2,28 +2,49 @@ package filters
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/model/fields"
 )
 
-type operator string
+type Operator string
 
 const (
-	Equal              operator = "="
-	NotEqual           operator = "!="
-	GreaterThan        operator = ">"
-	GreaterThanOrEqual operator = ">="
-	LessThan           operator = "<"
-	LessThanOrEqual    operator = "<="
-	Like               operator = "LIKE"
-	NotLike            operator = "NOT LIKE"
-	In                 operator = "IN"
-	NotIn              operator = "NOT IN"
-	IsNull             operator = "IS NULL"
-	IsNotNull          operator = "IS NOT NULL"
-	Between            operator = "BETWEEN"
-	NotBetween         operator = "NOT BETWEEN"
+	Equal              Operator = "="
+	NotEqual           Operator = "!="
+	GreaterThan        Operator = ">"
+	GreaterThanOrEqual Operator = ">="
+	LessThan           Operator = "<"
+	LessThanOrEqual    Operator = "<="
+	Like               Operator = "LIKE"
+	NotLike            Operator = "NOT LIKE"
+	In                 Operator = "IN"
+	NotIn              Operator = "NOT IN"
+	IsNull             Operator = "IS NULL"
+	IsNotNull          Operator = "IS NOT NULL"
+	Between            Operator = "BETWEEN"
+	NotBetween         Operator = "NOT BETWEEN"
+	Contains           Operator = "CONTAINS"
+	Any                Operator = "ANY"
+	All                Operator = "ALL"
+	WithinBox          Operator = "WITHIN BOX"
+	WithinDistance     Operator = "WITHIN DISTANCE"
+	// Exists and NotExists test whether the Subquery in Value returns
+	// rows. Their conditions have no Field.
+	Exists    Operator = "EXISTS"
+	NotExists Operator = "NOT EXISTS"
+)
+
+// Logical connectives used as Filter.JoinWith. A NOT group holds a single
+// child and negates it.
+const (
+	And = "AND"
+	Or  = "OR"
+	Not = "NOT"
 )
 
 type Filter struct {
@@ -31,10 +52,16 @@ type Filter struct {
 	Schema   string
 	Table    string
 	Field    string
-	Operator operator
+	Operator Operator
 	Value    interface{}
-	scanFunc buffer.ScanFunc
-	filter   filterFn
+	// Expression replaces Field, Operator and Value in conditions that
+	// compare computed values, such as two columns. The rows it is true
+	// for pass, and those it is false or null for do not.
+	Expression *expression.Expression
+	scanFunc   buffer.ScanFunc
+	filter     filterFn
+	// prepared is the column type filter was compiled for.
+	prepared fields.DataType
 
 	JoinWith string
 	Children []*Filter
@@ -47,7 +74,7 @@ func NewGroup(joinWith string) *Filter {
 	}
 }
 
-func NewCondition(field string, op operator, value interface{}) *Filter {
+func NewCondition(field string, op Operator, value interface{}) *Filter {
 	return &Filter{
 		Field:    field,
 		Operator: op,
@@ -55,12 +82,40 @@ func NewCondition(field string, op operator, value interface{}) *Filter {
 	}
 }
 
+// NewExpressionCondition returns a condition holding for the rows e is
+// true for.
+func NewExpressionCondition(e *expression.Expression) *Filter {
+	return &Filter{Expression: e}
+}
+
 func (f *Filter) Add(child *Filter) *Filter {
 	f.Children = append(f.Children, child)
 	return f
 }
 
+// Walk calls fn for f and its descendants, groups before their conditions.
+func (f *Filter) Walk(fn func(*Filter)) {
+	fn(f)
+	for _, child := range f.Children {
+		child.Walk(fn)
+	}
+}
+
+// SetValue replaces the value of a condition. The compiled comparison is
+// dropped, so the next Prepare compiles it again for the new value.
+func (f *Filter) SetValue(value interface{}) {
+	f.Value = value
+	f.filter = nil
+	f.prepared = nil
+}
+
 func (f *Filter) Build() (string, []interface{}, error) {
+	if f.Expression != nil {
+		return f.Expression.String(), nil, nil
+	}
+	if sub, ok := f.Value.(Subquery); ok && len(f.Children) == 0 {
+		return buildSubqueryCondition(f, sub)
+	}
 	if f.Field != "" && f.Operator != "" {
 		return buildSimpleCondition(f)
 	}
@@ -69,6 +124,17 @@ func (f *Filter) Build() (string, []interface{}, error) {
 		return "", nil, errors.New("empty filter group")
 	}
 
+	if strings.EqualFold(f.JoinWith, Not) {
+		if len(f.Children) != 1 {
+			return "", nil, errors.New("NOT group requires exactly one condition")
+		}
+		part, values, err := f.Children[0].Build()
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + part + ")", values, nil
+	}
+
 	var parts []string
 	var values []interface{}
 
@@ -84,16 +150,88 @@ func (f *Filter) Build() (string, []interface{}, error) {
 	return strings.Join(parts, " "+f.JoinWith+" "), values, nil
 }
 
+// String renders the filter as a condition with its values inline, the way
+// EXPLAIN shows it.
+func (f *Filter) String() string {
+	if f.Expression != nil {
+		return f.Expression.String()
+	}
+	if len(f.Children) == 0 {
+		switch f.Operator {
+		case IsNull, IsNotNull:
+			return fmt.Sprintf("%s %s", f.Field, f.Operator)
+		case Exists, NotExists:
+			return fmt.Sprintf("%s %s", f.Operator, formatValue(f.Value))
+		case Between, NotBetween:
+			if bounds, ok := f.Value.([]interface{}); ok && len(bounds) == 2 {
+				return fmt.Sprintf("%s %s %s AND %s", f.Field, f.Operator, formatValue(bounds[0]), formatValue(bounds[1]))
+			}
+		}
+		return fmt.Sprintf("%s %s %s", f.Field, f.Operator, formatValue(f.Value))
+	}
+
+	parts := make([]string, len(f.Children))
+	for i, child := range f.Children {
+		parts[i] = child.String()
+		if len(child.Children) > 0 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	if strings.EqualFold(f.JoinWith, Not) {
+		return "NOT " + strings.Join(parts, "")
+	}
+	return strings.Join(parts, " "+strings.ToUpper(f.JoinWith)+" ")
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			parts[i] = formatValue(elem)
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	case Subquery:
+		return "(" + v.SQL() + ")"
+	case nil:
+		return "NULL"
+	}
+	return fmt.Sprint(value)
+}
+
+// Prepare binds the conditions of the tree to the scanners of a cursor and
+// compiles them. A condition that was already compiled for the same column
+// type is only rebound to the new scanner, so a filter kept across cursors,
+// as prepared statements do, is compiled once per value. Conditions on now()
+// are always compiled again.
 func (f *Filter) Prepare(scanMap map[string]*buffer.Scanner) error {
+	if f.Expression != nil {
+		return f.prepareExpression(scanMap)
+	}
 	if len(f.Children) == 0 {
+		if sub, ok := f.Value.(Subquery); ok {
+			// Only EXPLAIN prepares a condition on a subquery it has not
+			// run, to describe it without reading any row.
+			f.filter = func() (bool, error) {
+				return false, fmt.Errorf("subquery (%s) must be evaluated before the filter is run", sub.SQL())
+			}
+			f.prepared = nil
+			return nil
+		}
 		columnData, ok := scanMap[f.Field]
 		if !ok {
 			return errors.New("field not found")
 		}
 
 		f.scanFunc = columnData.Scan
+		if f.filter != nil && sameType(f.prepared, columnData.Type) && !dependsOnNow(f.Value) {
+			return nil
+		}
+		f.Value = fields.Coerce(columnData.Type, f.Value)
 
-		filter, ok := mapEqOps[columnData.Type]
+		filter, ok := filterFor(columnData.Type)
 		if !ok {
 			return errors.New("unsupported type")
 		}
@@ -102,6 +240,7 @@ func (f *Filter) Prepare(scanMap map[string]*buffer.Scanner) error {
 			return err
 		}
 		f.filter = fn
+		f.prepared = columnData.Type
 
 		return nil
 	}
@@ -112,9 +251,83 @@ func (f *Filter) Prepare(scanMap map[string]*buffer.Scanner) error {
 		}
 	}
 
+	fn, err := joinChildren(f)
+	if err != nil {
+		return err
+	}
+	f.filter = fn
+
+	return nil
+}
+
+// prepareExpression compiles an expression condition. It is compiled again
+// on every call, since the columns it reads may have other types.
+func (f *Filter) prepareExpression(scanMap map[string]*buffer.Scanner) error {
+	e := f.Expression
+	if err := e.Prepare(scanMap); err != nil {
+		return err
+	}
+	switch e.DataType().(type) {
+	case fields.BoolType, fields.UnknownType:
+	default:
+		return fmt.Errorf("condition %s must be bool, not %s", e, e.DataType())
+	}
+	f.filter = func() (bool, error) {
+		value, err := e.Eval()
+		return value == true, err
+	}
 	return nil
 }
 
+// joinChildren combines the prepared children of a group, short-circuiting
+// as soon as the outcome is known.
+func joinChildren(f *Filter) (filterFn, error) {
+	children := f.Children
+	switch strings.ToUpper(f.JoinWith) {
+	case And:
+		return func() (bool, error) {
+			for _, child := range children {
+				ok, err := child.Execute()
+				if err != nil || !ok {
+					return false, err
+				}
+			}
+			return true, nil
+		}, nil
+	case Or:
+		return func() (bool, error) {
+			for _, child := range children {
+				ok, err := child.Execute()
+				if err != nil || ok {
+					return ok, err
+				}
+			}
+			return false, nil
+		}, nil
+	case Not:
+		if len(children) != 1 {
+			return nil, errors.New("NOT group requires exactly one condition")
+		}
+		return func() (bool, error) {
+			ok, err := children[0].Execute()
+			return !ok, err
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported filter group %q", f.JoinWith)
+}
+
+// sameType reports whether a and b are the same column type. Types with
+// parameters that are not comparable, such as enums, are compared deeply.
+func sameType(a, b fields.DataType) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if !reflect.TypeOf(a).Comparable() {
+		return reflect.DeepEqual(a, b)
+	}
+	return a == b
+}
+
 func (f *Filter) Execute() (bool, error) {
 	if f.filter == nil {
 		return false, errors.New("filter not prepared")
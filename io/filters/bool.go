@@ -30,7 +30,8 @@ func compareBool(f *Filter, cmp func(a, b bool) bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value bool
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		return cmp(value, data), nil
@@ -19,4 +19,27 @@ var mapEqOps = map[fields.DataType]applyFilter{
 	fields.StringType{}:    filterString,
 	fields.BoolType{}:      filterBool,
 	fields.TimestampType{}: filterTimestamp,
+
+	fields.TimestampTZType{}: filterTimestamp,
+	fields.DateType{}:        filterDate,
+	fields.TimeOfDayType{}:   filterTimeOfDay,
+	fields.IntervalType{}:    filterInterval,
+
+	fields.GeoPointType{}: filterGeoPoint,
+}
+
+// filterFor returns the filter builder for a data type. Parameterized types
+// carry state and are matched by kind before falling back to mapEqOps.
+func filterFor(dt fields.DataType) (applyFilter, bool) {
+	switch t := dt.(type) {
+	case fields.ArrayType:
+		return func(f *Filter) (filterFn, error) {
+			return filterArray(f, t)
+		}, true
+	case fields.EnumType:
+		return filterString, true
+	}
+
+	filter, ok := mapEqOps[dt]
+	return filter, ok
 }
@@ -49,14 +49,15 @@ func compareFloat64(f *Filter, cmp func(a, b float64) bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value float64
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		return cmp(value, data), nil
 	}, nil
 }
 
-func unsupportedLikeFloat64(op operator) (filterFn, error) {
+func unsupportedLikeFloat64(op Operator) (filterFn, error) {
 	return func() (bool, error) {
 		return false, fmt.Errorf("%s operator is not applicable for float64", op)
 	}, nil
@@ -69,7 +70,8 @@ func containsFloat64(f *Filter, shouldContain bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value float64
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		found := slices.Contains(values, value)
@@ -95,7 +97,8 @@ func betweenFloat64(f *Filter, inclusive bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value float64
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		if inclusive {
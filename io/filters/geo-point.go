@@ -0,0 +1,151 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+const errorUnsupportedOperatorGeoPoint = "unsupported operator %s for type geopoint"
+
+func filterGeoPoint(f *Filter) (filterFn, error) {
+	switch f.Operator {
+	case Equal:
+		return compareGeoPoint(f, true)
+	case NotEqual:
+		return compareGeoPoint(f, false)
+	case WithinBox:
+		sw, ne, err := ParseBoundingBox(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		return matchGeoPoint(f, func(p fields.LatLng) bool { return p.InBox(sw, ne) })
+	case WithinDistance:
+		center, meters, err := ParseRadius(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		sw, ne := RadiusBoundingBox(center, meters)
+		return matchGeoPoint(f, func(p fields.LatLng) bool {
+			return p.InBox(sw, ne) && center.DistanceTo(p) <= meters
+		})
+	case IsNull:
+		return isNullGeoPoint(f, true)
+	case IsNotNull:
+		return isNullGeoPoint(f, false)
+	default:
+		return nil, fmt.Errorf(errorUnsupportedOperatorGeoPoint, f.Operator)
+	}
+}
+
+func compareGeoPoint(f *Filter, equal bool) (filterFn, error) {
+	data, err := fields.ToLatLng(f.Value)
+	if err != nil {
+		return nil, fmt.Errorf(errorUnsupportedOperatorGeoPoint, f.Operator)
+	}
+	return matchGeoPoint(f, func(p fields.LatLng) bool { return (p == data) == equal })
+}
+
+func matchGeoPoint(f *Filter, match func(p fields.LatLng) bool) (filterFn, error) {
+	return func() (bool, error) {
+		var value fields.LatLng
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
+			return false, err
+		}
+		return match(value), nil
+	}, nil
+}
+
+func isNullGeoPoint(f *Filter, expectNull bool) (filterFn, error) {
+	return func() (bool, error) {
+		var value fields.LatLng
+		ok, _ := f.scanFunc(&value)
+		return expectNull != ok, nil
+	}, nil
+}
+
+// ParseBoundingBox reads the value of a WITHIN BOX condition: either the
+// south-west and north-east corners or [minLat, minLng, maxLat, maxLng].
+func ParseBoundingBox(value interface{}) (fields.LatLng, fields.LatLng, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return fields.LatLng{}, fields.LatLng{}, fmt.Errorf("operator %s requires [south_west, north_east]", WithinBox)
+	}
+
+	var sw, ne fields.LatLng
+	var err1, err2 error
+	switch rv.Len() {
+	case 2:
+		sw, err1 = fields.ToLatLng(rv.Index(0).Interface())
+		ne, err2 = fields.ToLatLng(rv.Index(1).Interface())
+	case 4:
+		sw, err1 = fields.ToLatLng([]interface{}{rv.Index(0).Interface(), rv.Index(1).Interface()})
+		ne, err2 = fields.ToLatLng([]interface{}{rv.Index(2).Interface(), rv.Index(3).Interface()})
+	default:
+		return fields.LatLng{}, fields.LatLng{}, fmt.Errorf("operator %s requires [south_west, north_east]", WithinBox)
+	}
+	if err1 != nil || err2 != nil {
+		return fields.LatLng{}, fields.LatLng{}, fmt.Errorf("operator %s requires [south_west, north_east]", WithinBox)
+	}
+	if err := sw.Validate(); err != nil {
+		return fields.LatLng{}, fields.LatLng{}, err
+	}
+	if err := ne.Validate(); err != nil {
+		return fields.LatLng{}, fields.LatLng{}, err
+	}
+	if sw.Lat > ne.Lat {
+		return fields.LatLng{}, fields.LatLng{}, fmt.Errorf("invalid range for %s operator", WithinBox)
+	}
+	return sw, ne, nil
+}
+
+// ParseRadius reads the value of a WITHIN DISTANCE condition: a center
+// point and a radius in meters.
+func ParseRadius(value interface{}) (fields.LatLng, float64, error) {
+	raw, ok := value.([]interface{})
+	if !ok || len(raw) != 2 {
+		return fields.LatLng{}, 0, fmt.Errorf("operator %s requires [center, meters]", WithinDistance)
+	}
+	center, err := fields.ToLatLng(raw[0])
+	if err != nil {
+		return fields.LatLng{}, 0, fmt.Errorf("operator %s requires [center, meters]", WithinDistance)
+	}
+	if err := center.Validate(); err != nil {
+		return fields.LatLng{}, 0, err
+	}
+	meters, err := fields.ConvertElement(reflect.TypeOf(float64(0)), raw[1])
+	if err != nil || meters.(float64) < 0 {
+		return fields.LatLng{}, 0, fmt.Errorf("operator %s requires a non-negative distance", WithinDistance)
+	}
+	return center, meters.(float64), nil
+}
+
+// RadiusBoundingBox returns a box enclosing the circle around center, used
+// to discard far away points before computing the haversine distance.
+func RadiusBoundingBox(center fields.LatLng, meters float64) (fields.LatLng, fields.LatLng) {
+	dLat := meters / fields.EarthRadiusMeters * 180 / math.Pi
+	minLat, maxLat := center.Lat-dLat, center.Lat+dLat
+	if minLat <= -90 || maxLat >= 90 {
+		return fields.LatLng{Lat: math.Max(minLat, -90), Lng: -180}, fields.LatLng{Lat: math.Min(maxLat, 90), Lng: 180}
+	}
+
+	dLng := dLat / math.Cos(math.Max(math.Abs(minLat), math.Abs(maxLat))*math.Pi/180)
+	if dLng >= 180 {
+		return fields.LatLng{Lat: minLat, Lng: -180}, fields.LatLng{Lat: maxLat, Lng: 180}
+	}
+	return fields.LatLng{Lat: minLat, Lng: wrapLongitude(center.Lng - dLng)},
+		fields.LatLng{Lat: maxLat, Lng: wrapLongitude(center.Lng + dLng)}
+}
+
+func wrapLongitude(lng float64) float64 {
+	switch {
+	case lng < -180:
+		return lng + 360
+	case lng > 180:
+		return lng - 360
+	}
+	return lng
+}
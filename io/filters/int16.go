@@ -49,14 +49,15 @@ func compareInt16(f *Filter, cmp func(a, b int16) bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value int16
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		return cmp(value, data), nil
 	}, nil
 }
 
-func unsupportedLikeInt16(op operator) (filterFn, error) {
+func unsupportedLikeInt16(op Operator) (filterFn, error) {
 	return func() (bool, error) {
 		return false, fmt.Errorf("%s operator is not applicable for int16", op)
 	}, nil
@@ -69,7 +70,8 @@ func containsInt16(f *Filter, shouldContain bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value int16
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		found := slices.Contains(values, value)
@@ -95,7 +97,8 @@ func betweenInt16(f *Filter, inclusive bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value int16
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		if inclusive {
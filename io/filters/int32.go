@@ -47,14 +47,15 @@ func compareInt32(f *Filter, cmp func(a, b int32) bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value int32
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		return cmp(value, data), nil
 	}, nil
 }
 
-func unsupportedLikeInt32(op operator) (filterFn, error) {
+func unsupportedLikeInt32(op Operator) (filterFn, error) {
 	return func() (bool, error) {
 		return false, fmt.Errorf("%s operator is not applicable for int32", op)
 	}, nil
@@ -67,7 +68,8 @@ func containsInt32(f *Filter, shouldContain bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value int32
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		found := slices.Contains(values, value)
@@ -93,7 +95,8 @@ func betweenInt32(f *Filter, inclusive bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value int32
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		if inclusive {
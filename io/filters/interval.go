@@ -0,0 +1,154 @@
+package filters
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+const errorUnsupportedOperatorInterval = "unsupported operator %s for type interval"
+
+func filterInterval(f *Filter) (filterFn, error) {
+	switch f.Operator {
+	case Equal:
+		return compareInterval(f, func(a, b fields.IntervalValue) bool { return a.Compare(b) == 0 })
+	case NotEqual:
+		return compareInterval(f, func(a, b fields.IntervalValue) bool { return a.Compare(b) != 0 })
+	case GreaterThan:
+		return compareInterval(f, func(a, b fields.IntervalValue) bool { return a.Compare(b) > 0 })
+	case GreaterThanOrEqual:
+		return compareInterval(f, func(a, b fields.IntervalValue) bool { return a.Compare(b) >= 0 })
+	case LessThan:
+		return compareInterval(f, func(a, b fields.IntervalValue) bool { return a.Compare(b) < 0 })
+	case LessThanOrEqual:
+		return compareInterval(f, func(a, b fields.IntervalValue) bool { return a.Compare(b) <= 0 })
+	case Like, NotLike:
+		return unsupportedLikeInterval(f.Operator)
+	case In:
+		return containsInterval(f, true)
+	case NotIn:
+		return containsInterval(f, false)
+	case IsNull:
+		return isNullInterval(f, true)
+	case IsNotNull:
+		return isNullInterval(f, false)
+	case Between:
+		return betweenInterval(f, true)
+	case NotBetween:
+		return betweenInterval(f, false)
+	default:
+		return nil, fmt.Errorf(errorUnsupportedOperatorInterval, f.Operator)
+	}
+}
+
+func compareInterval(f *Filter, cmp func(a, b fields.IntervalValue) bool) (filterFn, error) {
+	data, ok := toInterval(f.Value)
+	if !ok {
+		return nil, fmt.Errorf(errorUnsupportedOperatorInterval, f.Operator)
+	}
+	return func() (bool, error) {
+		var value fields.IntervalValue
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
+			return false, err
+		}
+		return cmp(value, data), nil
+	}, nil
+}
+
+func unsupportedLikeInterval(op Operator) (filterFn, error) {
+	return func() (bool, error) {
+		return false, fmt.Errorf("%s operator is not applicable for interval", op)
+	}, nil
+}
+
+func containsInterval(f *Filter, shouldContain bool) (filterFn, error) {
+	values, err := extractIntervalSlice(f.Value)
+	if err != nil || len(values) == 0 {
+		return nil, fmt.Errorf("operator %s requires a non-empty slice of fields.IntervalValue", f.Operator)
+	}
+	return func() (bool, error) {
+		var value fields.IntervalValue
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
+			return false, err
+		}
+		found := slices.ContainsFunc(values, func(v fields.IntervalValue) bool {
+			return v.Compare(value) == 0
+		})
+		if shouldContain {
+			return found, nil
+		}
+		return !found, nil
+	}, nil
+}
+
+func isNullInterval(f *Filter, expectNull bool) (filterFn, error) {
+	return func() (bool, error) {
+		var value fields.IntervalValue
+		ok, _ := f.scanFunc(&value)
+		return expectNull != ok, nil
+	}, nil
+}
+
+func betweenInterval(f *Filter, inclusive bool) (filterFn, error) {
+	minVal, maxVal, err := extractRangeInterval(f.Value)
+	if err != nil || minVal.Compare(maxVal) > 0 {
+		return nil, fmt.Errorf("invalid range for %s operator", f.Operator)
+	}
+	return func() (bool, error) {
+		var value fields.IntervalValue
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
+			return false, err
+		}
+		if inclusive {
+			return value.Compare(minVal) >= 0 && value.Compare(maxVal) <= 0, nil
+		}
+		return value.Compare(minVal) < 0 || value.Compare(maxVal) > 0, nil
+	}, nil
+}
+
+func toInterval(value interface{}) (fields.IntervalValue, bool) {
+	switch v := value.(type) {
+	case fields.IntervalValue:
+		return v, true
+	case time.Duration:
+		return fields.IntervalValue{Nanos: int64(v)}, true
+	case string:
+		iv, err := fields.ParseInterval(v)
+		return iv, err == nil
+	}
+	return fields.IntervalValue{}, false
+}
+
+func extractIntervalSlice(value interface{}) ([]fields.IntervalValue, error) {
+	raw, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value must be slice of interface{}")
+	}
+	res := make([]fields.IntervalValue, len(raw))
+	for i, v := range raw {
+		iv, ok := toInterval(v)
+		if !ok {
+			return nil, fmt.Errorf("value %v is not fields.IntervalValue", v)
+		}
+		res[i] = iv
+	}
+	return res, nil
+}
+
+func extractRangeInterval(value interface{}) (fields.IntervalValue, fields.IntervalValue, error) {
+	raw, ok := value.([]interface{})
+	if !ok || len(raw) != 2 {
+		return fields.IntervalValue{}, fields.IntervalValue{}, fmt.Errorf("value must be [min, max]")
+	}
+	min, ok1 := toInterval(raw[0])
+	max, ok2 := toInterval(raw[1])
+	if !ok1 || !ok2 {
+		return fields.IntervalValue{}, fields.IntervalValue{}, fmt.Errorf("range values must be fields.IntervalValue")
+	}
+	return min, max, nil
+}
@@ -51,7 +51,8 @@ func compareString(f *Filter, cmp func(a, b string) bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value string
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		return cmp(value, data), nil
@@ -69,7 +70,8 @@ func likeMatchString(f *Filter, positive bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value string
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		matched := re.MatchString(value)
@@ -87,7 +89,8 @@ func containsString(f *Filter, shouldContain bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value string
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		found := slices.Contains(values, value)
@@ -113,7 +116,8 @@ func betweenString(f *Filter, inclusive bool) (filterFn, error) {
 	}
 	return func() (bool, error) {
 		var value string
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
 		if inclusive {
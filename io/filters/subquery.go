@@ -0,0 +1,29 @@
+package filters
+
+import "fmt"
+
+// Subquery is a select nested in a condition: the list of an IN or NOT IN
+// condition, the single value a comparison is made with, or the rows an
+// EXISTS or NOT EXISTS condition, which has no Field, tests for. Filters
+// only carry subqueries, which statement.Subquery implements; the executor
+// replaces them by their results, or by a join, before running the filter.
+// EXPLAIN prepares them as they are, to describe the filter.
+type Subquery interface {
+	// SQL renders the nested select, without parentheses.
+	SQL() string
+}
+
+// buildSubqueryCondition renders a condition on a subquery, whose values
+// stay in the nested select.
+func buildSubqueryCondition(f *Filter, sub Subquery) (string, []interface{}, error) {
+	switch f.Operator {
+	case Exists, NotExists:
+		return fmt.Sprintf("%s (%s)", f.Operator, sub.SQL()), nil, nil
+	case In, NotIn, Equal, NotEqual, GreaterThan, GreaterThanOrEqual, LessThan, LessThanOrEqual:
+		if f.Field == "" {
+			return "", nil, fmt.Errorf("operator %s requires a field", f.Operator)
+		}
+		return fmt.Sprintf("%s %s (%s)", f.Field, f.Operator, sub.SQL()), nil, nil
+	}
+	return "", nil, fmt.Errorf("operator %s does not accept a subquery", f.Operator)
+}
@@ -0,0 +1,138 @@
+package filters
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// TimeExprExtID is the msgpack extension id used to ship TimeExpr values
+// inside Filter.Value.
+const TimeExprExtID int8 = 10
+
+// TimeExpr is a point in time expressed as a base plus an interval. A nil
+// Base stands for now(), resolved when the filter is prepared, so
+// Now().Sub(fields.NewInterval(0, 7, 0)) reads as "now() - interval '7 days'".
+type TimeExpr struct {
+	Base   *time.Time
+	Offset fields.IntervalValue
+}
+
+func Now() TimeExpr {
+	return TimeExpr{}
+}
+
+func At(t time.Time) TimeExpr {
+	return TimeExpr{Base: &t}
+}
+
+func (e TimeExpr) Add(interval fields.IntervalValue) TimeExpr {
+	e.Offset = e.Offset.Add(interval)
+	return e
+}
+
+func (e TimeExpr) Sub(interval fields.IntervalValue) TimeExpr {
+	e.Offset = e.Offset.Add(interval.Negate())
+	return e
+}
+
+func (e TimeExpr) Resolve(now time.Time) time.Time {
+	base := now
+	if e.Base != nil {
+		base = *e.Base
+	}
+	return e.Offset.AddTo(base)
+}
+
+func (e TimeExpr) String() string {
+	base := "now()"
+	if e.Base != nil {
+		base = "'" + e.Base.Format(time.RFC3339Nano) + "'"
+	}
+	if e.Offset == (fields.IntervalValue{}) {
+		return base
+	}
+	if e.Offset.Duration() < 0 {
+		return fmt.Sprintf("%s - interval '%s'", base, e.Offset.Negate())
+	}
+	return fmt.Sprintf("%s + interval '%s'", base, e.Offset)
+}
+
+// resolveTime converts a filter value into an instant. It accepts
+// time.Time, RFC3339 strings and TimeExpr values.
+func resolveTime(value interface{}, now time.Time) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case TimeExpr:
+		return v.Resolve(now), true
+	case *TimeExpr:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return v.Resolve(now), true
+	case string:
+		t, err := fields.ParseTime(v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// dependsOnNow reports whether value holds a time expression relative to
+// now(), which has to be resolved again each time the filter is prepared.
+func dependsOnNow(value interface{}) bool {
+	switch v := value.(type) {
+	case TimeExpr:
+		return v.Base == nil
+	case *TimeExpr:
+		return v != nil && v.Base == nil
+	case []interface{}:
+		for _, elem := range v {
+			if dependsOnNow(elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	msgpack.RegisterExtEncoder(TimeExprExtID, TimeExpr{}, func(e *msgpack.Encoder, v reflect.Value) ([]byte, error) {
+		expr := v.Interface().(TimeExpr)
+		b := make([]byte, 25)
+		if expr.Base != nil {
+			b[0] = 1
+			binary.LittleEndian.PutUint64(b[1:], uint64(expr.Base.UnixNano()))
+		}
+		binary.LittleEndian.PutUint32(b[9:], uint32(expr.Offset.Months))
+		binary.LittleEndian.PutUint32(b[13:], uint32(expr.Offset.Days))
+		binary.LittleEndian.PutUint64(b[17:], uint64(expr.Offset.Nanos))
+		return b, nil
+	})
+	msgpack.RegisterExtDecoder(TimeExprExtID, TimeExpr{}, func(d *msgpack.Decoder, v reflect.Value, extLen int) error {
+		if extLen != 25 {
+			return errors.New("msgpack: invalid TimeExpr length")
+		}
+		b := make([]byte, extLen)
+		if err := d.ReadFull(b); err != nil {
+			return err
+		}
+		var expr TimeExpr
+		if b[0] == 1 {
+			base := time.Unix(0, int64(binary.LittleEndian.Uint64(b[1:])))
+			expr.Base = &base
+		}
+		expr.Offset = fields.IntervalValue{
+			Months: int32(binary.LittleEndian.Uint32(b[9:])),
+			Days:   int32(binary.LittleEndian.Uint32(b[13:])),
+			Nanos:  int64(binary.LittleEndian.Uint64(b[17:])),
+		}
+		v.Set(reflect.ValueOf(expr))
+		return nil
+	})
+}
@@ -0,0 +1,39 @@
+package filters_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestTimeExprMsgpack(t *testing.T) {
+	base := time.Date(1969, 7, 20, 20, 17, 40, 123456789, time.UTC)
+	tests := []filters.TimeExpr{
+		filters.Now(),
+		filters.Now().Sub(fields.NewInterval(0, 7, 0)),
+		filters.At(base),
+		filters.At(base).Add(fields.NewInterval(-14, 3, -90*time.Minute)),
+	}
+
+	for _, want := range tests {
+		data, err := msgpack.Marshal(filters.NewCondition("t", filters.LessThan, want))
+		if err != nil {
+			t.Fatalf("%s: %v", want, err)
+		}
+		var f filters.Filter
+		if err := msgpack.Unmarshal(data, &f); err != nil {
+			t.Fatalf("%s: %v", want, err)
+		}
+		got, ok := f.Value.(filters.TimeExpr)
+		if !ok {
+			t.Fatalf("%s: decoded a %T", want, f.Value)
+		}
+		if got.Offset != want.Offset || (got.Base == nil) != (want.Base == nil) ||
+			(got.Base != nil && !got.Base.Equal(*want.Base)) {
+			t.Errorf("decoded %s, want %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package filters
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+const errorUnsupportedOperatorTimeOfDay = "unsupported operator %s for type time"
+
+func filterTimeOfDay(f *Filter) (filterFn, error) {
+	switch f.Operator {
+	case Equal:
+		return compareTimeOfDay(f, func(a, b fields.TimeOfDay) bool { return a == b })
+	case NotEqual:
+		return compareTimeOfDay(f, func(a, b fields.TimeOfDay) bool { return a != b })
+	case GreaterThan:
+		return compareTimeOfDay(f, func(a, b fields.TimeOfDay) bool { return a > b })
+	case GreaterThanOrEqual:
+		return compareTimeOfDay(f, func(a, b fields.TimeOfDay) bool { return a >= b })
+	case LessThan:
+		return compareTimeOfDay(f, func(a, b fields.TimeOfDay) bool { return a < b })
+	case LessThanOrEqual:
+		return compareTimeOfDay(f, func(a, b fields.TimeOfDay) bool { return a <= b })
+	case Like, NotLike:
+		return unsupportedLikeTimeOfDay(f.Operator)
+	case In:
+		return containsTimeOfDay(f, true)
+	case NotIn:
+		return containsTimeOfDay(f, false)
+	case IsNull:
+		return isNullTimeOfDay(f, true)
+	case IsNotNull:
+		return isNullTimeOfDay(f, false)
+	case Between:
+		return betweenTimeOfDay(f, true)
+	case NotBetween:
+		return betweenTimeOfDay(f, false)
+	default:
+		return nil, fmt.Errorf(errorUnsupportedOperatorTimeOfDay, f.Operator)
+	}
+}
+
+func compareTimeOfDay(f *Filter, cmp func(a, b fields.TimeOfDay) bool) (filterFn, error) {
+	data, ok := toTimeOfDay(f.Value)
+	if !ok {
+		return nil, fmt.Errorf(errorUnsupportedOperatorTimeOfDay, f.Operator)
+	}
+	return func() (bool, error) {
+		var value fields.TimeOfDay
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
+			return false, err
+		}
+		return cmp(value, data), nil
+	}, nil
+}
+
+func unsupportedLikeTimeOfDay(op Operator) (filterFn, error) {
+	return func() (bool, error) {
+		return false, fmt.Errorf("%s operator is not applicable for time", op)
+	}, nil
+}
+
+func containsTimeOfDay(f *Filter, shouldContain bool) (filterFn, error) {
+	values, err := extractTimeOfDaySlice(f.Value)
+	if err != nil || len(values) == 0 {
+		return nil, fmt.Errorf("operator %s requires a non-empty slice of fields.TimeOfDay", f.Operator)
+	}
+	return func() (bool, error) {
+		var value fields.TimeOfDay
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
+			return false, err
+		}
+		found := slices.Contains(values, value)
+		if shouldContain {
+			return found, nil
+		}
+		return !found, nil
+	}, nil
+}
+
+func isNullTimeOfDay(f *Filter, expectNull bool) (filterFn, error) {
+	return func() (bool, error) {
+		var value fields.TimeOfDay
+		ok, _ := f.scanFunc(&value)
+		return expectNull != ok, nil
+	}, nil
+}
+
+func betweenTimeOfDay(f *Filter, inclusive bool) (filterFn, error) {
+	minVal, maxVal, err := extractRangeTimeOfDay(f.Value)
+	if err != nil || minVal > maxVal {
+		return nil, fmt.Errorf("invalid range for %s operator", f.Operator)
+	}
+	return func() (bool, error) {
+		var value fields.TimeOfDay
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
+			return false, err
+		}
+		if inclusive {
+			return value >= minVal && value <= maxVal, nil
+		}
+		return value < minVal || value > maxVal, nil
+	}, nil
+}
+
+func toTimeOfDay(value interface{}) (fields.TimeOfDay, bool) {
+	switch v := value.(type) {
+	case fields.TimeOfDay:
+		return v, true
+	case time.Time:
+		return fields.TimeOfDayFromTime(v), true
+	case string:
+		t, err := fields.ParseTimeOfDay(v)
+		return t, err == nil
+	}
+	return 0, false
+}
+
+func extractTimeOfDaySlice(value interface{}) ([]fields.TimeOfDay, error) {
+	raw, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value must be slice of interface{}")
+	}
+	res := make([]fields.TimeOfDay, len(raw))
+	for i, v := range raw {
+		vTime, ok := toTimeOfDay(v)
+		if !ok {
+			return nil, fmt.Errorf("value %v is not fields.TimeOfDay", v)
+		}
+		res[i] = vTime
+	}
+	return res, nil
+}
+
+func extractRangeTimeOfDay(value interface{}) (fields.TimeOfDay, fields.TimeOfDay, error) {
+	raw, ok := value.([]interface{})
+	if !ok || len(raw) != 2 {
+		return 0, 0, fmt.Errorf("value must be [min, max]")
+	}
+	min, ok1 := toTimeOfDay(raw[0])
+	max, ok2 := toTimeOfDay(raw[1])
+	if !ok1 || !ok2 {
+		return 0, 0, fmt.Errorf("range values must be fields.TimeOfDay")
+	}
+	return min, max, nil
+}
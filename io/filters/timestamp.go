@@ -4,67 +4,92 @@ import (
 	"fmt"
 	"slices"
 	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
 )
 
 const errorUnsupportedOperatorTimestamp = "unsupported operator %s for type timestamp"
 
+// timeNormalizer maps both the stored value and the filter value onto the
+// precision of the column before they are compared.
+type timeNormalizer func(time.Time) time.Time
+
 func filterTimestamp(f *Filter) (filterFn, error) {
+	return filterTime(f, nil)
+}
+
+func filterDate(f *Filter) (filterFn, error) {
+	return filterTime(f, fields.TruncateToDate)
+}
+
+func filterTime(f *Filter, normalize timeNormalizer) (filterFn, error) {
 	switch f.Operator {
 	case Equal:
-		return compareTimestamp(f, func(a, b time.Time) bool { return a.Equal(b) })
+		return compareTimestamp(f, normalize, func(a, b time.Time) bool { return a.Equal(b) })
 	case NotEqual:
-		return compareTimestamp(f, func(a, b time.Time) bool { return !a.Equal(b) })
+		return compareTimestamp(f, normalize, func(a, b time.Time) bool { return !a.Equal(b) })
 	case GreaterThan:
-		return compareTimestamp(f, func(a, b time.Time) bool { return a.After(b) })
+		return compareTimestamp(f, normalize, func(a, b time.Time) bool { return a.After(b) })
 	case GreaterThanOrEqual:
-		return compareTimestamp(f, func(a, b time.Time) bool { return a.After(b) || a.Equal(b) })
+		return compareTimestamp(f, normalize, func(a, b time.Time) bool { return a.After(b) || a.Equal(b) })
 	case LessThan:
-		return compareTimestamp(f, func(a, b time.Time) bool { return a.Before(b) })
+		return compareTimestamp(f, normalize, func(a, b time.Time) bool { return a.Before(b) })
 	case LessThanOrEqual:
-		return compareTimestamp(f, func(a, b time.Time) bool { return a.Before(b) || a.Equal(b) })
+		return compareTimestamp(f, normalize, func(a, b time.Time) bool { return a.Before(b) || a.Equal(b) })
 	case Like, NotLike:
 		return unsupportedLikeTimestamp(f.Operator)
 	case In:
-		return containsTimestamp(f, true)
+		return containsTimestamp(f, normalize, true)
 	case NotIn:
-		return containsTimestamp(f, false)
+		return containsTimestamp(f, normalize, false)
 	case IsNull:
 		return isNullTimestamp(f, true)
 	case IsNotNull:
 		return isNullTimestamp(f, false)
 	case Between:
-		return betweenTimestamp(f, true)
+		return betweenTimestamp(f, normalize, true)
 	case NotBetween:
-		return betweenTimestamp(f, false)
+		return betweenTimestamp(f, normalize, false)
 	default:
 		return nil, fmt.Errorf(errorUnsupportedOperatorTimestamp, f.Operator)
 	}
 }
 
-func compareTimestamp(f *Filter, cmp func(a, b time.Time) bool) (filterFn, error) {
-	data, ok := f.Value.(time.Time)
+func compareTimestamp(f *Filter, normalize timeNormalizer, cmp func(a, b time.Time) bool) (filterFn, error) {
+	data, ok := resolveTime(f.Value, time.Now())
 	if !ok {
 		return nil, fmt.Errorf(errorUnsupportedOperatorTimestamp, f.Operator)
 	}
+	if normalize != nil {
+		data = normalize(data)
+	}
 	return func() (bool, error) {
 		var value time.Time
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
+		if normalize != nil {
+			value = normalize(value)
+		}
 		return cmp(value, data), nil
 	}, nil
 }
 
-func containsTimestamp(f *Filter, shouldContain bool) (filterFn, error) {
-	values, err := extractTimestampSlice(f.Value)
+func containsTimestamp(f *Filter, normalize timeNormalizer, shouldContain bool) (filterFn, error) {
+	values, err := extractTimestampSlice(f.Value, normalize)
 	if err != nil || len(values) == 0 {
 		return nil, fmt.Errorf("operator %s requires a non-empty slice of time.Time", f.Operator)
 	}
 	return func() (bool, error) {
 		var value time.Time
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
+		if normalize != nil {
+			value = normalize(value)
+		}
 		found := slices.ContainsFunc(values, func(t time.Time) bool {
 			return t.Equal(value)
 		})
@@ -83,16 +108,20 @@ func isNullTimestamp(f *Filter, expectNull bool) (filterFn, error) {
 	}, nil
 }
 
-func betweenTimestamp(f *Filter, inclusive bool) (filterFn, error) {
-	minVal, maxVal, err := extractRangeTimestamp(f.Value)
+func betweenTimestamp(f *Filter, normalize timeNormalizer, inclusive bool) (filterFn, error) {
+	minVal, maxVal, err := extractRangeTimestamp(f.Value, normalize)
 	if err != nil || minVal.After(maxVal) {
 		return nil, fmt.Errorf("invalid range for %s operator", f.Operator)
 	}
 	return func() (bool, error) {
 		var value time.Time
-		if _, err := f.scanFunc(&value); err != nil {
+		ok, err := f.scanFunc(&value)
+		if err != nil || !ok {
 			return false, err
 		}
+		if normalize != nil {
+			value = normalize(value)
+		}
 		if inclusive {
 			return (value.After(minVal) || value.Equal(minVal)) && (value.Before(maxVal) || value.Equal(maxVal)), nil
 		}
@@ -100,37 +129,45 @@ func betweenTimestamp(f *Filter, inclusive bool) (filterFn, error) {
 	}, nil
 }
 
-func unsupportedLikeTimestamp(op operator) (filterFn, error) {
+func unsupportedLikeTimestamp(op Operator) (filterFn, error) {
 	return func() (bool, error) {
 		return false, fmt.Errorf("%s operator is not applicable for timestamp", op)
 	}, nil
 }
 
-func extractTimestampSlice(value interface{}) ([]time.Time, error) {
+func extractTimestampSlice(value interface{}, normalize timeNormalizer) ([]time.Time, error) {
 	raw, ok := value.([]interface{})
 	if !ok {
 		return nil, fmt.Errorf("value must be slice of interface{}")
 	}
+	now := time.Now()
 	res := make([]time.Time, len(raw))
 	for i, v := range raw {
-		vTime, ok := v.(time.Time)
+		vTime, ok := resolveTime(v, now)
 		if !ok {
 			return nil, fmt.Errorf("value %v is not time.Time", v)
 		}
+		if normalize != nil {
+			vTime = normalize(vTime)
+		}
 		res[i] = vTime
 	}
 	return res, nil
 }
 
-func extractRangeTimestamp(value interface{}) (time.Time, time.Time, error) {
+func extractRangeTimestamp(value interface{}, normalize timeNormalizer) (time.Time, time.Time, error) {
 	raw, ok := value.([]interface{})
 	if !ok || len(raw) != 2 {
 		return time.Time{}, time.Time{}, fmt.Errorf("value must be [min, max]")
 	}
-	min, ok1 := raw[0].(time.Time)
-	max, ok2 := raw[1].(time.Time)
+	now := time.Now()
+	min, ok1 := resolveTime(raw[0], now)
+	max, ok2 := resolveTime(raw[1], now)
 	if !ok1 || !ok2 {
 		return time.Time{}, time.Time{}, fmt.Errorf("range values must be time.Time")
 	}
+	if normalize != nil {
+		min, max = normalize(min), normalize(max)
+	}
 	return min, max, nil
 }
@@ -17,6 +17,17 @@ func buildSimpleCondition(f *Filter) (string, []interface{}, error) {
 		placeholders := strings.Repeat("?, ", len(slice))
 		placeholders = placeholders[:len(placeholders)-2]
 		return fmt.Sprintf("%s %s (%s)", f.Field, f.Operator, placeholders), slice, nil
+	case Contains, Any, All:
+		slice, ok := f.Value.([]interface{})
+		if !ok {
+			return fmt.Sprintf("%s %s ?", f.Field, f.Operator), []interface{}{f.Value}, nil
+		}
+		if len(slice) == 0 {
+			return "", nil, fmt.Errorf("operator %s requires a non-empty slice", f.Operator)
+		}
+		placeholders := strings.Repeat("?, ", len(slice))
+		placeholders = placeholders[:len(placeholders)-2]
+		return fmt.Sprintf("%s %s (%s)", f.Field, f.Operator, placeholders), slice, nil
 	case Between, NotBetween:
 		rangeVals, ok := f.Value.([]interface{})
 		if !ok || len(rangeVals) != 2 {
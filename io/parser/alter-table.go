@@ -0,0 +1,71 @@
+package parser
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// parseAlterTable reads
+//
+//	ALTER TABLE table ALTER [COLUMN] col ADD VALUE 'v' [, ALTER ...]
+//	ALTER TABLE table ALTER [COLUMN] col ADD VALUES ('v', 'w')
+func (p *Parser) parseAlterTable() (statement.Statement, error) {
+	start := p.next()
+	if err := p.expectKeyword("TABLE"); err != nil {
+		return nil, err
+	}
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []statement.AlterTableChange
+	for {
+		change, err := p.parseAlterChange()
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	stmt, err := statement.NewAlterTableStatement(ref.Database, ref.Schema, ref.Table, changes...)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseAlterChange() (statement.AlterTableChange, error) {
+	if err := p.expectKeyword("ALTER"); err != nil {
+		return statement.AlterTableChange{}, err
+	}
+	p.acceptKeyword("COLUMN")
+	column, err := p.expectIdent("a column name")
+	if err != nil {
+		return statement.AlterTableChange{}, err
+	}
+	if err := p.expectKeyword("ADD"); err != nil {
+		return statement.AlterTableChange{}, err
+	}
+
+	change := statement.AlterTableChange{Action: statement.AddEnumValues, Column: column}
+	switch {
+	case p.acceptKeyword("VALUE"):
+		tok := p.peek()
+		if tok.kind != tokenString {
+			return statement.AlterTableChange{}, p.unexpected("a quoted value")
+		}
+		p.next()
+		change.Values = []string{tok.text}
+	case p.acceptKeyword("VALUES"):
+		if err := p.expectSymbol("("); err != nil {
+			return statement.AlterTableChange{}, err
+		}
+		if change.Values, err = p.parseStringList(); err != nil {
+			return statement.AlterTableChange{}, err
+		}
+	default:
+		return statement.AlterTableChange{}, p.unexpected("VALUE or VALUES")
+	}
+	return change, nil
+}
@@ -1,27 +1,18 @@
 package parser
 
-import (
-	"strings"
+import "github.com/onnasoft/ZenithSQL/io/statement"
 
-	"github.com/asaskevich/govalidator"
-	"github.com/onnasoft/ZenithSQL/io/statement"
-)
-
-func (p *Parser) parseCreateDatabase(sql string) (*statement.CreateDatabaseStatement, error) {
-	sql = cleanCreateDatabaseSQL(sql)
-	databaseName := strings.TrimSpace(sql)
-
-	statement := &statement.CreateDatabaseStatement{DatabaseName: databaseName}
-	if _, err := govalidator.ValidateStruct(statement); err != nil {
+// parseCreateDatabase reads CREATE DATABASE name.
+func (p *Parser) parseCreateDatabase() (statement.Statement, error) {
+	start := p.tokens[p.pos-2]
+	name, err := p.expectIdent("a database name")
+	if err != nil {
 		return nil, err
 	}
 
-	return statement, nil
-}
-
-func cleanCreateDatabaseSQL(sql string) string {
-	sql = strings.TrimPrefix(strings.ToUpper(sql), "CREATE DATABASE")
-	sql = strings.TrimSpace(sql)
-	sql = strings.TrimSuffix(sql, ";")
-	return sql
+	stmt, err := statement.NewCreateDatabaseStatement(name)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
 }
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// parseCreateIndex reads
+//
+//	CREATE [UNIQUE] INDEX name ON table (cols) [USING kind] [WITH (option = value, ...)]
+//
+// with CREATE, and UNIQUE if given, already consumed.
+func (p *Parser) parseCreateIndex(unique bool) (statement.Statement, error) {
+	start := p.tokens[p.pos-2]
+	if unique {
+		start = p.tokens[p.pos-3]
+	}
+	name, err := p.expectIdent("an index name")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("ON"); err != nil {
+		return nil, err
+	}
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+	columns, err := p.parseIdentList("a column name")
+	if err != nil {
+		return nil, err
+	}
+
+	var using string
+	if p.acceptKeyword("USING") {
+		kind, err := p.expectIdent("an index kind")
+		if err != nil {
+			return nil, err
+		}
+		using = strings.ToLower(kind)
+	}
+
+	var options map[string]interface{}
+	if p.acceptKeyword("WITH") {
+		if options, err = p.parseOptions(); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := statement.NewCreateIndexStatement(ref.Database, ref.Schema, name, ref.Table, columns, using, unique, options)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
+
+// parseOptions reads "(name = value, ...)".
+func (p *Parser) parseOptions() (map[string]interface{}, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	options := make(map[string]interface{})
+	for {
+		keyTok := p.peek()
+		key, err := p.expectIdent("an option name")
+		if err != nil {
+			return nil, err
+		}
+		key = strings.ToLower(key)
+		if _, ok := options[key]; ok {
+			return nil, newSyntaxError(keyTok, "option %s given more than once", key)
+		}
+		if err := p.expectSymbol("="); err != nil {
+			return nil, err
+		}
+		if options[key], err = p.parseValue(); err != nil {
+			return nil, err
+		}
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return options, p.expectSymbol(")")
+}
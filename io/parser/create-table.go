@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// columnTypes maps SQL type names, including common aliases, to field types.
+var columnTypes = map[string]fields.Types{
+	"INT8":        fields.Int8,
+	"TINYINT":     fields.Int8,
+	"INT16":       fields.Int16,
+	"SMALLINT":    fields.Int16,
+	"INT32":       fields.Int32,
+	"INT":         fields.Int32,
+	"INTEGER":     fields.Int32,
+	"INT64":       fields.Int64,
+	"BIGINT":      fields.Int64,
+	"UINT8":       fields.Uint8,
+	"UINT16":      fields.Uint16,
+	"UINT32":      fields.Uint32,
+	"UINT64":      fields.Uint64,
+	"FLOAT32":     fields.Float32,
+	"REAL":        fields.Float32,
+	"FLOAT":       fields.Float32,
+	"FLOAT64":     fields.Float64,
+	"DOUBLE":      fields.Float64,
+	"STRING":      fields.String,
+	"VARCHAR":     fields.String,
+	"CHAR":        fields.String,
+	"TEXT":        fields.String,
+	"BOOL":        fields.Bool,
+	"BOOLEAN":     fields.Bool,
+	"TIMESTAMP":   fields.Timestamp,
+	"TIMESTAMPTZ": fields.TimestampTZ,
+	"DATE":        fields.Date,
+	"TIME":        fields.Time,
+	"INTERVAL":    fields.Interval,
+	"GEOPOINT":    fields.GeoPoint,
+	"POINT":       fields.GeoPoint,
+	"VECTOR":      fields.Vector,
+	"ENUM":        fields.Enum,
+	"ARRAY":       fields.Array,
+}
+
+// parseCreateTable reads CREATE TABLE table (col type [NOT NULL], ...).
+func (p *Parser) parseCreateTable() (statement.Statement, error) {
+	start := p.tokens[p.pos-2]
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	var columns []fields.FieldMeta
+	for {
+		column, err := p.parseColumnDefinition()
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	stmt, err := statement.NewCreateTableStatement(ref.Database, ref.Schema, ref.Table, columns, "")
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseColumnDefinition() (fields.FieldMeta, error) {
+	name, err := p.expectIdent("a column name")
+	if err != nil {
+		return fields.FieldMeta{}, err
+	}
+	column, err := p.parseColumnType()
+	if err != nil {
+		return fields.FieldMeta{}, err
+	}
+	column.Name = name
+
+	if p.acceptKeyword("NOT") {
+		if err := p.expectKeyword("NULL"); err != nil {
+			return fields.FieldMeta{}, err
+		}
+		column.Required = true
+	} else {
+		p.acceptKeyword("NULL")
+	}
+	return column, nil
+}
+
+// parseColumnType reads a type such as VARCHAR(64), VECTOR(3),
+// ENUM('a', 'b'), ARRAY<INT32>, INT32[] or TIMESTAMP WITH TIME ZONE.
+func (p *Parser) parseColumnType() (fields.FieldMeta, error) {
+	tok := p.peek()
+	typ, ok := columnTypes[strings.ToUpper(tok.text)]
+	if tok.kind != tokenIdent || !ok {
+		return fields.FieldMeta{}, p.unexpected("a column type")
+	}
+	p.next()
+
+	column := fields.FieldMeta{Type: typ}
+	switch typ {
+	case fields.Float64:
+		p.acceptKeyword("PRECISION")
+	case fields.Timestamp:
+		if p.acceptKeyword("WITH") {
+			for _, keyword := range []string{"TIME", "ZONE"} {
+				if err := p.expectKeyword(keyword); err != nil {
+					return fields.FieldMeta{}, err
+				}
+			}
+			column.Type = fields.TimestampTZ
+		}
+	case fields.Enum:
+		if err := p.expectSymbol("("); err != nil {
+			return fields.FieldMeta{}, err
+		}
+		values, err := p.parseStringList()
+		if err != nil {
+			return fields.FieldMeta{}, err
+		}
+		column.Values = values
+		return column, nil
+	case fields.Array:
+		if err := p.expectSymbol("<"); err != nil {
+			return fields.FieldMeta{}, err
+		}
+		element, err := p.parseColumnType()
+		if err != nil {
+			return fields.FieldMeta{}, err
+		}
+		if err := p.expectSymbol(">"); err != nil {
+			return fields.FieldMeta{}, err
+		}
+		column.ElementType = element.Type
+		return column, nil
+	}
+
+	if p.acceptSymbol("(") {
+		lengthTok := p.peek()
+		length, err := strconv.Atoi(lengthTok.text)
+		if lengthTok.kind != tokenNumber || err != nil || length <= 0 {
+			return fields.FieldMeta{}, p.unexpected("a positive length")
+		}
+		p.next()
+		if err := p.expectSymbol(")"); err != nil {
+			return fields.FieldMeta{}, err
+		}
+		column.Length = length
+	}
+
+	if p.acceptSymbol("[") {
+		if err := p.expectSymbol("]"); err != nil {
+			return fields.FieldMeta{}, err
+		}
+		return fields.FieldMeta{Type: fields.Array, ElementType: column.Type}, nil
+	}
+
+	return column, nil
+}
+
+// parseStringList reads quoted strings up to a closing parenthesis, which
+// has to be opened by the caller.
+func (p *Parser) parseStringList() ([]string, error) {
+	var values []string
+	for {
+		tok := p.peek()
+		if tok.kind != tokenString {
+			return nil, p.unexpected("a quoted value")
+		}
+		p.next()
+		values = append(values, tok.text)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return values, p.expectSymbol(")")
+}
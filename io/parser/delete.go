@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// parseDelete reads DELETE FROM table [WHERE cond], or PURGE FROM table
+// [WHERE cond] which removes the deleted rows for good.
+func (p *Parser) parseDelete() (statement.Statement, error) {
+	start := p.next()
+	purge := p.isKeyword(start, "PURGE")
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+
+	var where *filters.Filter
+	if p.acceptKeyword("WHERE") {
+		if where, err = p.parseCondition(); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := statement.NewDeleteStatement(ref.Database, ref.Schema, ref.Table, where, purge)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
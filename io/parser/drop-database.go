@@ -1,31 +1,25 @@
 package parser
 
 import (
-	"strings"
-
 	"github.com/asaskevich/govalidator"
 	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
-func (p *Parser) parseDropDatabase(sql string) (*statement.DropDatabaseStatement, error) {
-	sql = cleanDropDatabaseSQL(sql)
-
-	databaseName := strings.TrimSpace(sql)
-	if !govalidator.Matches(databaseName, `^[a-zA-Z_][a-zA-Z0-9_]*$`) {
-		return nil, statement.NewInvalidDatabaseNameError(databaseName)
-	}
-
-	statement := &statement.DropDatabaseStatement{DatabaseName: databaseName}
-	if _, err := govalidator.ValidateStruct(statement); err != nil {
+// parseDropDatabase reads DROP DATABASE name.
+func (p *Parser) parseDropDatabase() (statement.Statement, error) {
+	start := p.tokens[p.pos-2]
+	nameTok := p.peek()
+	name, err := p.expectIdent("a database name")
+	if err != nil {
 		return nil, err
 	}
+	if !govalidator.Matches(name, `^[a-zA-Z_][a-zA-Z0-9_]*$`) {
+		return nil, p.invalid(nameTok, statement.NewInvalidDatabaseNameError(name))
+	}
 
-	return statement, nil
-}
-
-func cleanDropDatabaseSQL(sql string) string {
-	sql = strings.TrimPrefix(strings.ToUpper(sql), "DROP DATABASE")
-	sql = strings.TrimSpace(sql)
-	sql = strings.TrimSuffix(sql, ";")
-	return sql
+	stmt, err := statement.NewDropDatabaseStatement(name)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
 }
@@ -0,0 +1,25 @@
+package parser
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// parseDropIndex reads DROP INDEX name ON table.
+func (p *Parser) parseDropIndex() (statement.Statement, error) {
+	start := p.tokens[p.pos-2]
+	name, err := p.expectIdent("an index name")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("ON"); err != nil {
+		return nil, err
+	}
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := statement.NewDropIndexStatement(ref.Database, ref.Schema, name, ref.Table)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
@@ -0,0 +1,18 @@
+package parser
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// parseDropTable reads DROP TABLE table.
+func (p *Parser) parseDropTable() (statement.Statement, error) {
+	start := p.tokens[p.pos-2]
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := statement.NewDropTableStatement(ref.Database, ref.Schema, ref.Table)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
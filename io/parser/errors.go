@@ -0,0 +1,23 @@
+package parser
+
+import "fmt"
+
+// SyntaxError reports a problem in the SQL text together with the 1-based
+// line and column where it was detected.
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+func newSyntaxError(tok token, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		Line:    tok.line,
+		Column:  tok.column,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
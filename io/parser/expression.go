@@ -0,0 +1,368 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// parserState is a position of the parser it can go back to when a
+// construct turns out to be something else, with the parameters counted
+// up to there.
+type parserState struct {
+	pos        int
+	params     int
+	paramStyle string
+}
+
+func (p *Parser) save() parserState {
+	return parserState{pos: p.pos, params: p.params, paramStyle: p.paramStyle}
+}
+
+func (p *Parser) restore(s parserState) {
+	p.pos, p.params, p.paramStyle = s.pos, s.params, s.paramStyle
+}
+
+var expressionComparisons = map[string]expression.Operator{
+	"=":  expression.Equal,
+	"!=": expression.NotEqual,
+	"<>": expression.NotEqual,
+	"<":  expression.LessThan,
+	"<=": expression.LessThanOrEqual,
+	">":  expression.GreaterThan,
+	">=": expression.GreaterThanOrEqual,
+}
+
+// parseExpression reads a scalar expression:
+//
+//	expr    = and {OR and}
+//	and     = not {AND not}
+//	not     = NOT not | compare
+//	compare = sum [(= | != | <> | < | <= | > | >=) sum | IS [NOT] NULL]
+//	sum     = product {(+ | -) product}
+//	product = unary {(* | / | %) unary}
+//	unary   = (- | +) unary | primary
+//	primary = literal | column | (expr) | CASE ... END | CAST(expr AS type)
+//	        | function(expr, ...) | aggregate
+//
+// Aggregate calls are only allowed where aggregates are, and are read as
+// the column the grouped rows name them by.
+func (p *Parser) parseExpression() (*expression.Expression, error) {
+	return p.parseLogical(expression.Or, p.parseAndExpression)
+}
+
+func (p *Parser) parseAndExpression() (*expression.Expression, error) {
+	return p.parseLogical(expression.And, p.parseNotExpression)
+}
+
+func (p *Parser) parseLogical(op expression.Operator, operand func() (*expression.Expression, error)) (*expression.Expression, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword(string(op)) {
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = expression.NewBinary(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *Parser) parseNotExpression() (*expression.Expression, error) {
+	if !p.acceptKeyword("NOT") {
+		return p.parseComparison()
+	}
+	inner, err := p.parseNotExpression()
+	if err != nil {
+		return nil, err
+	}
+	return expression.NewUnary(expression.Not, inner), nil
+}
+
+func (p *Parser) parseComparison() (*expression.Expression, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if op, ok := expressionComparisons[tok.text]; ok && tok.kind == tokenSymbol {
+		p.next()
+		right, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewBinary(op, left, right), nil
+	}
+	if p.acceptKeyword("IS") {
+		op := expression.IsNull
+		if p.acceptKeyword("NOT") {
+			op = expression.IsNotNull
+		}
+		return expression.NewUnary(op, left), p.expectKeyword("NULL")
+	}
+	return left, nil
+}
+
+func (p *Parser) parseSum() (*expression.Expression, error) {
+	return p.parseArithmetic(p.parseProduct, "+", "-")
+}
+
+func (p *Parser) parseProduct() (*expression.Expression, error) {
+	return p.parseArithmetic(p.parseUnaryExpression, "*", "/", "%")
+}
+
+func (p *Parser) parseArithmetic(operand func() (*expression.Expression, error), symbols ...string) (*expression.Expression, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokenSymbol || !containsString(symbols, tok.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = expression.NewBinary(expression.Operator(tok.text), left, right)
+	}
+}
+
+func (p *Parser) parseUnaryExpression() (*expression.Expression, error) {
+	// A sign in front of a number is part of the literal.
+	if (p.peekSymbol("-") || p.peekSymbol("+")) && p.tokens[p.pos+1].kind == tokenNumber {
+		return p.parsePrimaryExpression()
+	}
+	if p.acceptSymbol("+") {
+		return p.parseUnaryExpression()
+	}
+	if !p.acceptSymbol("-") {
+		return p.parsePrimaryExpression()
+	}
+	operand, err := p.parseUnaryExpression()
+	if err != nil {
+		return nil, err
+	}
+	return expression.NewUnary(expression.Negate, operand), nil
+}
+
+func (p *Parser) parsePrimaryExpression() (*expression.Expression, error) {
+	tok := p.peek()
+	next := p.tokens[min(p.pos+1, len(p.tokens)-1)]
+	isCall := tok.kind == tokenIdent && next.kind == tokenSymbol && next.text == "("
+
+	switch {
+	case p.peekSubquery(0):
+		sub, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewLiteral(sub), nil
+	case p.acceptSymbol("("):
+		inner, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expectSymbol(")")
+	case p.isKeyword(tok, "CASE"):
+		return p.parseCaseExpression()
+	case isCall && p.isKeyword(tok, "CAST"):
+		return p.parseCastExpression()
+	case p.peekAggregate():
+		if !p.aggregates {
+			return nil, newSyntaxError(tok, "aggregate %s is not allowed here", strings.ToUpper(tok.text))
+		}
+		agg, err := p.parseAggregate()
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewColumn(agg.Name()), nil
+	case isCall && expression.IsFunction(strings.ToUpper(tok.text)):
+		return p.parseFunctionCall()
+	case isCall && !p.isKeyword(tok, "POINT"):
+		return nil, newSyntaxError(tok, "unknown function %s", tok.text)
+	case tok.kind == tokenIdent && p.peekLiteralKeyword():
+		value, err := p.parseKeywordValue(tok)
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewLiteral(value), nil
+	case tok.kind == tokenQuotedIdent || (tok.kind == tokenIdent && !reserved[strings.ToUpper(tok.text)]):
+		name, err := p.parseColumnRef()
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewColumn(name), nil
+	case tok.kind == tokenIdent:
+		return nil, p.unexpected("an expression")
+	}
+
+	value, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	return expression.NewLiteral(value), nil
+}
+
+// peekLiteralKeyword reports whether the next tokens are a literal starting
+// with a keyword, such as TRUE or DATE '2024-01-31', rather than a column.
+func (p *Parser) peekLiteralKeyword() bool {
+	tok := p.peek()
+	next := p.tokens[min(p.pos+1, len(p.tokens)-1)]
+	switch strings.ToUpper(tok.text) {
+	case "NULL", "TRUE", "FALSE", "CURRENT_TIMESTAMP":
+		return true
+	case "NOW", "POINT":
+		return next.kind == tokenSymbol && next.text == "("
+	case "ARRAY":
+		return next.kind == tokenSymbol && next.text == "["
+	case "DATE", "TIMESTAMP", "TIMESTAMPTZ", "TIME", "INTERVAL":
+		return next.kind == tokenString
+	}
+	return false
+}
+
+// parseCaseExpression reads
+//
+//	CASE [operand] WHEN value THEN result ... [ELSE result] END
+//
+// where WHEN takes conditions instead of values without an operand.
+func (p *Parser) parseCaseExpression() (*expression.Expression, error) {
+	p.next()
+	var operand *expression.Expression
+	if !p.peekKeyword("WHEN") {
+		var err error
+		if operand, err = p.parseExpression(); err != nil {
+			return nil, err
+		}
+	}
+
+	var whens, thens []*expression.Expression
+	for p.acceptKeyword("WHEN") {
+		when, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if operand != nil {
+			when = expression.NewBinary(expression.Equal, operand, when)
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		then, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		whens, thens = append(whens, when), append(thens, then)
+	}
+	if len(whens) == 0 {
+		return nil, p.unexpected("WHEN")
+	}
+
+	var otherwise *expression.Expression
+	if p.acceptKeyword("ELSE") {
+		var err error
+		if otherwise, err = p.parseExpression(); err != nil {
+			return nil, err
+		}
+	}
+	return expression.NewCase(whens, thens, otherwise), p.expectKeyword("END")
+}
+
+// parseCastExpression reads CAST(expr AS type).
+func (p *Parser) parseCastExpression() (*expression.Expression, error) {
+	p.next()
+	p.next()
+	operand, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	typ, ok := columnTypes[strings.ToUpper(tok.text)]
+	if tok.kind != tokenIdent || !ok {
+		return nil, p.unexpected("a type")
+	}
+	p.next()
+	switch typ {
+	case fields.Float64:
+		p.acceptKeyword("PRECISION")
+	case fields.Array, fields.Vector, fields.Enum, fields.GeoPoint:
+		return nil, newSyntaxError(tok, "cannot cast to %s", strings.ToUpper(tok.text))
+	}
+	return expression.NewCast(operand, typ), p.expectSymbol(")")
+}
+
+// parseFunctionCall reads name(expr, ...) for a scalar function, or
+// EXTRACT(field FROM expr), which passes the field as a string.
+func (p *Parser) parseFunctionCall() (*expression.Expression, error) {
+	name := p.next().text
+	p.next()
+	var args []*expression.Expression
+	if strings.EqualFold(name, "EXTRACT") && p.peek().kind == tokenIdent && p.isKeyword(p.tokens[p.pos+1], "FROM") {
+		field := p.next()
+		p.next()
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		args = []*expression.Expression{expression.NewLiteral(strings.ToLower(field.text)), arg}
+		return expression.NewFunction(name, args...), p.expectSymbol(")")
+	}
+	if !p.acceptSymbol(")") {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	return expression.NewFunction(name, args...), nil
+}
+
+// parseExpressionCondition reads a predicate that does not compare a
+// column with a value, such as a comparison of two columns, as an
+// expression condition.
+func (p *Parser) parseExpressionCondition() (*filters.Filter, error) {
+	e, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	return filters.NewExpressionCondition(e), nil
+}
+
+// peekOperator reports whether the next token continues an expression
+// with an operator.
+func (p *Parser) peekOperator() bool {
+	tok := p.peek()
+	if tok.kind == tokenSymbol {
+		_, ok := expressionComparisons[tok.text]
+		return ok || containsString([]string{"+", "-", "*", "/", "%"}, tok.text)
+	}
+	return p.isKeyword(tok, "IS")
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// parseInsert reads
+//
+//	INSERT INTO table (cols) VALUES (values), ...
+//	[ON CONFLICT (key) DO NOTHING | DO UPDATE [SET col = value, ...]]
+//
+// where an ON CONFLICT clause makes it an upsert.
+func (p *Parser) parseInsert() (statement.Statement, error) {
+	start := p.next()
+	if err := p.expectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peekSymbol("(") {
+		return nil, p.unexpected("a column list")
+	}
+	columns, err := p.parseIdentList("a column name")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]interface{}
+	for {
+		rowTok := p.peek()
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		values, err := p.parseValueList(")")
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(columns) {
+			return nil, newSyntaxError(rowTok, "expected %d values, found %d", len(columns), len(values))
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+		rows = append(rows, row)
+
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if p.acceptKeyword("ON") {
+		clause, err := p.parseConflictClause(ref.Table)
+		if err != nil {
+			return nil, err
+		}
+		stmt, err := statement.NewUpsertStatement(ref.Database, ref.Schema, ref.Table, rows, clause)
+		if err != nil {
+			return nil, p.invalid(start, err)
+		}
+		return stmt, nil
+	}
+
+	stmt, err := statement.NewInsertStatement(ref.Database, ref.Schema, ref.Table, rows)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
+
+// parseConflictClause reads what follows the ON of an ON CONFLICT clause.
+// The values set are read as in an UPDATE, where EXCLUDED.col stands for a
+// column of the row upserted and table.col for a column of the row of table
+// it conflicts with.
+func (p *Parser) parseConflictClause(table string) (statement.ConflictClause, error) {
+	var clause statement.ConflictClause
+	if err := p.expectKeyword("CONFLICT"); err != nil {
+		return clause, err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return clause, err
+	}
+	key, err := p.expectIdent("a unique key")
+	if err != nil {
+		return clause, err
+	}
+	clause.UniqueKey = key
+	if err := p.expectSymbol(")"); err != nil {
+		return clause, err
+	}
+	if err := p.expectKeyword("DO"); err != nil {
+		return clause, err
+	}
+
+	switch {
+	case p.acceptKeyword("NOTHING"):
+		clause.Action = statement.DoNothing
+		return clause, nil
+	case p.acceptKeyword("UPDATE"):
+		clause.Action = statement.DoUpdate
+	default:
+		return clause, p.unexpected("NOTHING or UPDATE")
+	}
+	if !p.acceptKeyword("SET") {
+		return clause, nil
+	}
+
+	updates, expressions, err := p.parseAssignments()
+	if err != nil {
+		return clause, err
+	}
+	clause.Updates = updates
+	clause.Expressions = make(map[string]*expression.Expression, len(expressions))
+	for column, expr := range expressions {
+		rename := func(name string) (string, error) {
+			name = strings.TrimPrefix(name, table+".")
+			return excludedColumn(name)
+		}
+		if clause.Expressions[column], err = expr.Rename(rename); err != nil {
+			return clause, err
+		}
+	}
+	return clause, nil
+}
+
+// excludedColumn spells the EXCLUDED of a column of the row upserted the
+// way the executor looks it up, whatever the case it was written in.
+func excludedColumn(name string) (string, error) {
+	prefix := statement.ExcludedPrefix
+	if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+		return prefix + name[len(prefix):], nil
+	}
+	return name, nil
+}
@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+	tokenParam
+)
+
+// token is a lexical unit of the input. Text holds the identifier, number or
+// symbol as written, and the unescaped contents of string literals. Pos and
+// End are byte offsets used to slice raw clauses out of the source.
+type token struct {
+	kind   tokenKind
+	text   string
+	pos    int
+	end    int
+	line   int
+	column int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenString:
+		return "'" + t.text + "'"
+	case tokenQuotedIdent:
+		return `"` + t.text + `"`
+	}
+	return `"` + t.text + `"`
+}
+
+var twoCharSymbols = []string{"<=", ">=", "<>", "!="}
+
+const oneCharSymbols = "=<>(),;.*+-[]/%"
+
+type lexer struct {
+	src    string
+	pos    int
+	line   int
+	column int
+}
+
+// lex splits sql into tokens, skipping whitespace and comments. The last
+// token is always tokenEOF.
+func lex(sql string) ([]token, error) {
+	l := &lexer{src: sql, line: 1, column: 1}
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return token{}, err
+	}
+
+	tok := token{pos: l.pos, line: l.line, column: l.column}
+	if l.pos >= len(l.src) {
+		tok.kind = tokenEOF
+		tok.end = l.pos
+		return tok, nil
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	switch {
+	case r == '_' || unicode.IsLetter(r):
+		tok.kind = tokenIdent
+		l.advanceWhile(func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) })
+		tok.text = l.src[tok.pos:l.pos]
+	case isDigit(r) || (r == '.' && l.pos+1 < len(l.src) && isDigit(rune(l.src[l.pos+1]))):
+		tok.kind = tokenNumber
+		l.lexNumber()
+		tok.text = l.src[tok.pos:l.pos]
+	case r == '\'':
+		text, err := l.lexQuoted('\'', tok)
+		if err != nil {
+			return token{}, err
+		}
+		tok.kind = tokenString
+		tok.text = text
+	case r == '"' || r == '`':
+		text, err := l.lexQuoted(r, tok)
+		if err != nil {
+			return token{}, err
+		}
+		if text == "" {
+			return token{}, newSyntaxError(tok, "empty quoted identifier")
+		}
+		tok.kind = tokenQuotedIdent
+		tok.text = text
+	case r == '?':
+		tok.kind = tokenParam
+		l.advance()
+		tok.text = "?"
+	case r == '$' && l.pos+1 < len(l.src) && isDigit(rune(l.src[l.pos+1])):
+		tok.kind = tokenParam
+		l.advance()
+		l.advanceWhile(isDigit)
+		tok.text = l.src[tok.pos:l.pos]
+	default:
+		tok.kind = tokenSymbol
+		for _, sym := range twoCharSymbols {
+			if strings.HasPrefix(l.src[l.pos:], sym) {
+				tok.text = sym
+			}
+		}
+		if tok.text == "" {
+			if !strings.ContainsRune(oneCharSymbols, r) {
+				return token{}, newSyntaxError(tok, "unexpected character %q", r)
+			}
+			tok.text = string(r)
+		}
+		for range tok.text {
+			l.advance()
+		}
+	}
+
+	tok.end = l.pos
+	return tok, nil
+}
+
+func (l *lexer) skipSpaceAndComments() error {
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], "--"):
+			l.advanceWhile(func(r rune) bool { return r != '\n' })
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			start := token{line: l.line, column: l.column}
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return newSyntaxError(start, "unterminated comment")
+			}
+			for target := l.pos + 2 + end + 2; l.pos < target; {
+				l.advance()
+			}
+		default:
+			r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+			if !unicode.IsSpace(r) {
+				return nil
+			}
+			l.advance()
+		}
+	}
+	return nil
+}
+
+func (l *lexer) lexNumber() {
+	l.advanceWhile(isDigit)
+	if l.peek() == '.' {
+		l.advance()
+		l.advanceWhile(isDigit)
+	}
+	if p := l.peek(); p == 'e' || p == 'E' {
+		rest := l.src[l.pos+1:]
+		rest = strings.TrimLeft(rest, "+-")
+		if rest != "" && isDigit(rune(rest[0])) {
+			l.advance()
+			if p := l.peek(); p == '+' || p == '-' {
+				l.advance()
+			}
+			l.advanceWhile(isDigit)
+		}
+	}
+}
+
+// lexQuoted reads a literal delimited by quote. Inside the literal a doubled
+// quote stands for the quote character itself.
+func (l *lexer) lexQuoted(quote rune, start token) (string, error) {
+	l.advance()
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		r := l.advance()
+		if r != quote {
+			sb.WriteRune(r)
+			continue
+		}
+		if l.peek() == quote {
+			l.advance()
+			sb.WriteRune(quote)
+			continue
+		}
+		return sb.String(), nil
+	}
+	if quote == '\'' {
+		return "", newSyntaxError(start, "unterminated string literal")
+	}
+	return "", newSyntaxError(start, "unterminated quoted identifier")
+}
+
+func (l *lexer) peek() rune {
+	if l.pos >= len(l.src) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return r
+}
+
+func (l *lexer) advance() rune {
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	l.pos += size
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+func (l *lexer) advanceWhile(match func(rune) bool) {
+	for l.pos < len(l.src) && match(l.peek()) {
+		l.advance()
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
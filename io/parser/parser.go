@@ -7,20 +7,313 @@ import (
 	"github.com/onnasoft/ZenithSQL/io/statement"
 )
 
-type Parser struct{}
+// ParserConfig holds the defaults applied to unqualified table names.
+type ParserConfig struct {
+	Database string
+	Schema   string
+}
+
+// Parser turns SQL text into statements. It remembers the transaction opened
+// by the last BEGIN so that a later COMMIT or ROLLBACK without a name refers
+// to it, which makes a Parser unsafe for concurrent use.
+type Parser struct {
+	config        ParserConfig
+	transactionID string
+
+	src    string
+	tokens []token
+	pos    int
+
+	// params counts the positional parameters of the parsed text and
+	// paramStyle remembers whether they are written as ? or $n.
+	params     int
+	paramStyle string
+
+	// aggregates allows conditions on aggregate calls, as in HAVING.
+	aggregates bool
+}
 
 func NewParser() *Parser {
 	return &Parser{}
 }
 
+func NewParserWithConfig(config ParserConfig) *Parser {
+	return &Parser{config: config}
+}
+
+// Parse parses a single statement, optionally terminated by a semicolon.
 func (p *Parser) Parse(sql string) (statement.Statement, error) {
-	sql = strings.TrimSpace(sql)
-	if strings.HasPrefix(strings.ToUpper(sql), "DROP DATABASE") {
-		return p.parseDropDatabase(sql)
+	stmts, err := p.ParseAll(sql)
+	if err != nil {
+		return nil, err
+	}
+	switch len(stmts) {
+	case 0:
+		return nil, errors.New("empty SQL statement")
+	case 1:
+		return stmts[0], nil
+	}
+	return nil, errors.New("expected a single SQL statement, use ParseAll for scripts")
+}
+
+// ParseAll parses a semicolon separated list of statements.
+func (p *Parser) ParseAll(sql string) ([]statement.Statement, error) {
+	tokens, err := lex(sql)
+	if err != nil {
+		return nil, err
+	}
+	p.src, p.tokens, p.pos = sql, tokens, 0
+	p.params, p.paramStyle = 0, ""
+
+	var stmts []statement.Statement
+	for {
+		for p.acceptSymbol(";") {
+		}
+		if p.peek().kind == tokenEOF {
+			return stmts, nil
+		}
+
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+
+		if !p.acceptSymbol(";") && p.peek().kind != tokenEOF {
+			return nil, p.unexpected("; or end of input")
+		}
+	}
+}
+
+// NumParams returns the number of positional parameters used by the text
+// given to the last Parse or ParseAll call. With $n parameters it is the
+// highest n referenced.
+func (p *Parser) NumParams() int {
+	return p.params
+}
+
+func (p *Parser) parseStatement() (statement.Statement, error) {
+	tok := p.peek()
+	switch {
+	case p.isKeyword(tok, "SELECT"), p.isKeyword(tok, "WITH"):
+		return p.parseSelect()
+	case p.isKeyword(tok, "EXPLAIN"):
+		return p.parseExplain()
+	case p.isKeyword(tok, "INSERT"):
+		return p.parseInsert()
+	case p.isKeyword(tok, "UPDATE"):
+		return p.parseUpdate()
+	case p.isKeyword(tok, "DELETE"), p.isKeyword(tok, "PURGE"):
+		return p.parseDelete()
+	case p.isKeyword(tok, "CREATE"):
+		return p.parseCreate()
+	case p.isKeyword(tok, "DROP"):
+		return p.parseDrop()
+	case p.isKeyword(tok, "ALTER"):
+		return p.parseAlterTable()
+	case p.isKeyword(tok, "BEGIN"), p.isKeyword(tok, "START"):
+		return p.parseBegin()
+	case p.isKeyword(tok, "COMMIT"):
+		return p.parseCommit()
+	case p.isKeyword(tok, "ROLLBACK"):
+		return p.parseRollback()
+	case p.isKeyword(tok, "SAVEPOINT"):
+		return p.parseSavepoint()
+	case p.isKeyword(tok, "RELEASE"):
+		return p.parseReleaseSavepoint()
+	}
+	return nil, p.unexpected("a statement")
+}
+
+func (p *Parser) parseCreate() (statement.Statement, error) {
+	p.next()
+	switch {
+	case p.acceptKeyword("DATABASE"):
+		return p.parseCreateDatabase()
+	case p.acceptKeyword("TABLE"):
+		return p.parseCreateTable()
+	case p.acceptKeyword("INDEX"):
+		return p.parseCreateIndex(false)
+	case p.acceptKeyword("UNIQUE"):
+		if err := p.expectKeyword("INDEX"); err != nil {
+			return nil, err
+		}
+		return p.parseCreateIndex(true)
+	}
+	return nil, p.unexpected("DATABASE, TABLE, INDEX or UNIQUE INDEX")
+}
+
+func (p *Parser) parseDrop() (statement.Statement, error) {
+	p.next()
+	switch {
+	case p.acceptKeyword("DATABASE"):
+		return p.parseDropDatabase()
+	case p.acceptKeyword("TABLE"):
+		return p.parseDropTable()
+	case p.acceptKeyword("INDEX"):
+		return p.parseDropIndex()
+	}
+	return nil, p.unexpected("DATABASE, TABLE or INDEX")
+}
+
+// tableRef is a possibly qualified table name: table, schema.table or
+// database.schema.table.
+type tableRef struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+func (p *Parser) parseTableRef() (tableRef, error) {
+	var parts []string
+	for {
+		name, err := p.expectIdent("a table name")
+		if err != nil {
+			return tableRef{}, err
+		}
+		parts = append(parts, name)
+		if len(parts) == 3 || !p.acceptSymbol(".") {
+			break
+		}
+	}
+
+	ref := tableRef{Database: p.config.Database, Schema: p.config.Schema}
+	switch len(parts) {
+	case 1:
+		ref.Table = parts[0]
+	case 2:
+		ref.Schema, ref.Table = parts[0], parts[1]
+	case 3:
+		ref.Database, ref.Schema, ref.Table = parts[0], parts[1], parts[2]
+	}
+	return ref, nil
+}
+
+// parseIdentList reads "(a, b, c)".
+func (p *Parser) parseIdentList(what string) ([]string, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
 	}
-	if strings.HasPrefix(strings.ToUpper(sql), "CREATE DATABASE") {
-		return p.parseCreateDatabase(sql)
+	var names []string
+	for {
+		name, err := p.expectIdent(what)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.acceptSymbol(",") {
+			break
+		}
 	}
+	return names, p.expectSymbol(")")
+}
+
+// reserved words cannot be used as unquoted identifiers.
+var reserved = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "BY": true,
+	"HAVING": true, "ORDER": true, "LIMIT": true, "OFFSET": true, "AND": true,
+	"OR": true, "NOT": true, "IN": true, "IS": true, "NULL": true, "LIKE": true,
+	"BETWEEN": true, "AS": true, "ON": true, "SET": true, "VALUES": true,
+	"INTO": true, "ASC": true, "DESC": true, "TRUE": true, "FALSE": true,
+	"INSERT": true, "UPDATE": true, "DELETE": true, "CREATE": true,
+	"DROP": true, "ALTER": true, "TABLE": true, "INDEX": true,
+	"CONTAINS": true, "ANY": true, "ALL": true, "WITHIN": true,
+	"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true,
+	"OUTER": true, "UNION": true, "INTERSECT": true, "EXCEPT": true,
+	"WITH": true, "DISTINCT": true,
+}
+
+func (p *Parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *Parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// lastEnd is the byte offset right after the last consumed token.
+func (p *Parser) lastEnd() int {
+	if p.pos == 0 {
+		return 0
+	}
+	return p.tokens[p.pos-1].end
+}
+
+// source returns the raw text from tok up to the last consumed token.
+func (p *Parser) source(from token) string {
+	return strings.TrimSpace(p.src[from.pos:p.lastEnd()])
+}
+
+func (p *Parser) isKeyword(tok token, keyword string) bool {
+	return tok.kind == tokenIdent && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *Parser) peekKeyword(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if p.pos+i >= len(p.tokens) || !p.isKeyword(p.tokens[p.pos+i], keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Parser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(p.peek(), keyword) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *Parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.unexpected(keyword)
+	}
+	return nil
+}
+
+func (p *Parser) peekSymbol(symbol string) bool {
+	tok := p.peek()
+	return tok.kind == tokenSymbol && tok.text == symbol
+}
+
+func (p *Parser) acceptSymbol(symbol string) bool {
+	if p.peekSymbol(symbol) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *Parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.unexpected(symbol)
+	}
+	return nil
+}
+
+func (p *Parser) expectIdent(what string) (string, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenQuotedIdent:
+	case tok.kind == tokenIdent && !reserved[strings.ToUpper(tok.text)]:
+	default:
+		return "", p.unexpected(what)
+	}
+	p.next()
+	return tok.text, nil
+}
+
+func (p *Parser) unexpected(expected string) error {
+	tok := p.peek()
+	return newSyntaxError(tok, "expected %s, found %s", expected, tok)
+}
 
-	return nil, errors.New("unsupported SQL statement")
+// invalid wraps a validation error of the statement starting at tok.
+func (p *Parser) invalid(tok token, err error) error {
+	return newSyntaxError(tok, "%v", err)
 }
@@ -0,0 +1,268 @@
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/io/parser"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+func newParser() *parser.Parser {
+	return parser.NewParserWithConfig(parser.ParserConfig{Database: "db", Schema: "public"})
+}
+
+func TestParseStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"select star", "SELECT * FROM t", "SELECT * FROM db.public.t"},
+		{"select qualified table", "select a from s.t;", "SELECT a FROM db.s.t"},
+		{"select fully qualified table", "SELECT a FROM d.s.t", "SELECT a FROM d.s.t"},
+		{"select where order limit offset",
+			"SELECT a, b AS bee FROM t WHERE a >= 1 OR NOT b LIKE 'x%' ORDER BY a, b DESC LIMIT 3 OFFSET 2",
+			"SELECT a, b AS bee FROM db.public.t WHERE (a >= ?) OR (b NOT LIKE ?) ORDER BY a, b DESC LIMIT 3 OFFSET 2"},
+		{"select limit zero", "SELECT a FROM t LIMIT 0", "SELECT a FROM db.public.t LIMIT 0"},
+		{"select is null and between",
+			"SELECT a FROM t WHERE b IS NULL AND c BETWEEN 1 AND 5",
+			"SELECT a FROM db.public.t WHERE (b IS NULL) AND (c BETWEEN ? AND ?)"},
+		{"select group by having",
+			"SELECT a, COUNT(*), SUM(b) FROM t GROUP BY a HAVING COUNT(*) > 2",
+			"SELECT a, COUNT(*), SUM(b) FROM db.public.t GROUP BY a HAVING COUNT(*) > ?"},
+		{"select distinct", "SELECT DISTINCT a FROM t", "SELECT DISTINCT a FROM db.public.t"},
+		{"select with deleted", "SELECT a FROM t WITH DELETED", "SELECT a FROM db.public.t WITH DELETED"},
+		{"select contains", "SELECT a FROM t WHERE tags CONTAINS 'x'", "SELECT a FROM db.public.t WHERE tags CONTAINS ?"},
+		{"select expression", "SELECT a * 2 + 1 AS x FROM t", "SELECT a * 2 + 1 AS x FROM db.public.t"},
+		{"inner join",
+			"SELECT t.a, u.b FROM t JOIN u ON t.id = u.tid",
+			"SELECT t.a, u.b FROM db.public.t INNER JOIN db.public.u ON t.id = u.tid"},
+		{"left join",
+			"SELECT t.a FROM t LEFT OUTER JOIN u ON t.id = u.tid WHERE u.b = 1",
+			"SELECT t.a FROM db.public.t LEFT JOIN db.public.u ON t.id = u.tid WHERE u.b = ?"},
+		{"in subquery",
+			"SELECT a FROM t WHERE a IN (SELECT b FROM u)",
+			"SELECT a FROM db.public.t WHERE a IN (SELECT b FROM db.public.u)"},
+		{"exists subquery",
+			"SELECT a FROM t WHERE EXISTS (SELECT b FROM u WHERE u.b = t.a)",
+			"SELECT a FROM db.public.t WHERE EXISTS (SELECT b FROM db.public.u WHERE u.b = t.a)"},
+		{"union all",
+			"SELECT a FROM t UNION ALL SELECT b FROM u",
+			"SELECT a FROM db.public.t UNION ALL SELECT b FROM db.public.u"},
+		{"recursive cte",
+			"WITH RECURSIVE r AS (SELECT a FROM t UNION ALL SELECT a FROM r WHERE a < 10) SELECT a FROM r",
+			"WITH RECURSIVE r AS (SELECT a FROM db.public.t UNION ALL SELECT a FROM db.public.r WHERE a < ?) SELECT a FROM db.public.r"},
+		{"window function",
+			"SELECT a, ROW_NUMBER() OVER (PARTITION BY b ORDER BY a) AS rn FROM t",
+			"SELECT a, ROW_NUMBER() OVER (PARTITION BY b ORDER BY a) AS rn FROM db.public.t"},
+		{"explain analyze", "EXPLAIN ANALYZE SELECT a FROM t", "EXPLAIN ANALYZE SELECT a FROM db.public.t"},
+		{"insert",
+			"INSERT INTO t (a, b) VALUES (1, 'x'), (2, NULL)",
+			"InsertStatement{TableName: t, Values: [map[a:1 b:x] map[a:2 b:<nil>]]}"},
+		{"scalar subquery", "SELECT a, (SELECT MAX(b) FROM t2) AS m FROM t",
+			"SELECT a, (SELECT MAX(b) FROM db.public.t2) AS m FROM db.public.t"},
+		{"select without from", "SELECT 1 + 2 AS n WHERE 1 = 1", "SELECT 1 + 2 AS n WHERE 1 = 1"},
+		{"upsert",
+			"INSERT INTO t (a, b) VALUES (1, 2) ON CONFLICT (a) DO UPDATE SET b = b + excluded.b, c = 'x'",
+			"UpsertStatement{TableName: t, Values: [map[a:1 b:2]], OnConflict: ON CONFLICT (a) DO UPDATE SET c = x, b = b + EXCLUDED.b}"},
+		{"upsert qualified",
+			"INSERT INTO public.t (a, b) VALUES (1, 2) ON CONFLICT (a) DO UPDATE SET b = t.b + excluded.b",
+			"UpsertStatement{TableName: t, Values: [map[a:1 b:2]], OnConflict: ON CONFLICT (a) DO UPDATE SET b = b + EXCLUDED.b}"},
+		{"update values",
+			"UPDATE t SET a = 1",
+			"UpdateStatement{TableName: t, Updates: map[a:1], Expressions: map[], Where: <nil>}"},
+		{"update expression",
+			"UPDATE t SET a = a + 1 WHERE b = 'x'",
+			"UpdateStatement{TableName: t, Updates: map[], Expressions: map[a:a + 1], Where: b = 'x'}"},
+		{"delete", "DELETE FROM t", "DeleteStatement{TableName: t, Where: <nil>, Purge: false}"},
+		{"delete where", "DELETE FROM t WHERE a IN (1, 2)", "DeleteStatement{TableName: t, Where: a IN (1, 2), Purge: false}"},
+		{"purge", "PURGE FROM t WHERE a = 1", "DeleteStatement{TableName: t, Where: a = 1, Purge: true}"},
+		{"create table",
+			"CREATE TABLE t (a INT32, s STRING(20) NOT NULL)",
+			"CreateTableStatement{TableName: t, FieldsMeta: [{a int32  [] 0 false []} {s string  [] 20 true []}], Storage: }"},
+		{"drop table", "DROP TABLE t", "DropTableStatement{TableName: t}"},
+		{"alter table values",
+			"ALTER TABLE t ALTER COLUMN e ADD VALUES ('z', 'w')",
+			"AlterTableStatement{TableName: t, Changes: [ADD_ENUM_VALUES e (z, w)]}"},
+		{"alter table value",
+			"ALTER TABLE t ALTER e ADD VALUE 'z'",
+			"AlterTableStatement{TableName: t, Changes: [ADD_ENUM_VALUES e (z)]}"},
+		{"create index",
+			"CREATE INDEX i ON t (v) USING hnsw WITH (metric = 'cosine', m = 16)",
+			"CreateIndexStatement{IndexName: i, TableName: t, Columns: [v], Using: hnsw, Unique: false, Options: map[m:16 metric:cosine]}"},
+		{"create unique index",
+			"CREATE UNIQUE INDEX i ON t (a)",
+			"CreateIndexStatement{IndexName: i, TableName: t, Columns: [a], Using: , Unique: true, Options: map[]}"},
+		{"drop index", "DROP INDEX i ON s.t", "DropIndexStatement{IndexName: i, TableName: t}"},
+		{"create database", "CREATE DATABASE d", "CreateDatabaseStatement{DatabaseName: d}"},
+		{"drop database", "DROP DATABASE d", "DropDatabaseStatement{DatabaseName: d}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := newParser().Parse(tt.sql)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.sql, err)
+			}
+			if got := fmt.Sprint(stmt); got != tt.want {
+				t.Errorf("Parse(%q)\n got %s\nwant %s", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	stmt, err := newParser().Parse("SELECT a FROM t WHERE a = -3 AND b = 'it''s' AND c = 1.5 AND d = TRUE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, values, err := stmt.(*statement.SelectStatement).Where.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{int64(-3), "it's", 1.5, true}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %#v, want %#v", values, want)
+	}
+}
+
+func TestParseColumnTypes(t *testing.T) {
+	stmt, err := newParser().Parse("CREATE TABLE t (a BIGINT, b VARCHAR(10), c TIMESTAMP NOT NULL, e ENUM('x', 'y'), v VECTOR(3))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metas := stmt.(*statement.CreateTableStatement).FieldsMeta
+	want := []fields.Types{fields.Int64, fields.String, fields.Timestamp, fields.Enum, fields.Vector}
+	if len(metas) != len(want) {
+		t.Fatalf("got %d columns, want %d", len(metas), len(want))
+	}
+	for i, meta := range metas {
+		if meta.Type != want[i] {
+			t.Errorf("column %s type = %v, want %v", meta.Name, meta.Type, want[i])
+		}
+	}
+	if !metas[2].Required {
+		t.Error("NOT NULL column is not required")
+	}
+	if !reflect.DeepEqual(metas[3].Values, []string{"x", "y"}) {
+		t.Errorf("enum values = %v", metas[3].Values)
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	p := newParser()
+	stmts, err := p.ParseAll("INSERT INTO t (a) VALUES (1);; SELECT a FROM t;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("got %d statements, want 2", len(stmts))
+	}
+	if _, err := p.Parse("SELECT a FROM t; SELECT b FROM t"); err == nil {
+		t.Error("Parse accepted two statements")
+	}
+	if _, err := p.Parse("  ;  "); err == nil {
+		t.Error("Parse accepted an empty statement")
+	}
+}
+
+func TestParseTransactions(t *testing.T) {
+	stmts, err := newParser().ParseAll(`BEGIN t1; SAVEPOINT s; ROLLBACK TO SAVEPOINT s;
+		RELEASE s; COMMIT; START TRANSACTION t2; ROLLBACK WORK`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"BeginTransactionStatement{TransactionID: t1}",
+		"SavepointStatement{TransactionID: t1, SavepointName: s}",
+		"RollbackStatement{TransactionID: t1, SavepointName: s}",
+		"ReleaseSavepointStatement{TransactionID: t1, SavepointName: s}",
+		"CommitStatement{TransactionID: t1}",
+		"BeginTransactionStatement{TransactionID: t2}",
+		"RollbackStatement{TransactionID: t2, SavepointName: }",
+	}
+	if len(stmts) != len(want) {
+		t.Fatalf("got %d statements, want %d", len(stmts), len(want))
+	}
+	for i, stmt := range stmts {
+		if got := fmt.Sprint(stmt); got != want[i] {
+			t.Errorf("statement %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	stmt, err := newParser().Parse("BEGIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := stmt.(*statement.BeginTransactionStatement).TransactionID; id == "" {
+		t.Error("BEGIN without a name has no transaction id")
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		sql     string
+		want    int
+		wantErr bool
+	}{
+		{"SELECT a FROM t WHERE a = ? AND b = ?", 2, false},
+		{"SELECT a FROM t WHERE a = $2 AND b = $1", 2, false},
+		{"INSERT INTO t (a, b) VALUES ($1, $3)", 3, false},
+		{"SELECT a FROM t WHERE a = ? AND b = $1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			p := newParser()
+			_, err := p.Parse(tt.sql)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && p.NumParams() != tt.want {
+				t.Errorf("NumParams() = %d, want %d", p.NumParams(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		line   int
+		column int
+	}{
+		{"unknown statement", "FETCH a", 1, 1},
+		{"missing table", "SELECT a FROM", 1, 14},
+		{"bad condition", "SELECT a\nFROM t\nWHERE a = = 1", 3, 11},
+		{"unterminated string", "SELECT a FROM t WHERE b = 'x", 1, 27},
+		{"value count", "INSERT INTO t (a, b)\n  VALUES (1, 2), (3)", 2, 18},
+		{"select item without from", "SELECT 1 n", 1, 10},
+		{"conflict assignment", "INSERT INTO t (a) VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1, a = 2", 1, 67},
+		{"reserved identifier", "CREATE TABLE select (a INT)", 1, 14},
+		{"unknown type", "CREATE TABLE t (a NUMBERS)", 1, 19},
+		{"trailing tokens", "DROP TABLE t u", 1, 14},
+		{"drop index without table", "DROP INDEX i", 1, 13},
+		{"unique without index", "CREATE UNIQUE TABLE t (a INT)", 1, 15},
+		{"commit without transaction", "SELECT a FROM t;\nCOMMIT", 2, 1},
+		{"savepoint without transaction", "SAVEPOINT s", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newParser().ParseAll(tt.sql)
+			var syntaxErr *parser.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("ParseAll(%q) error = %v, want a SyntaxError", tt.sql, err)
+			}
+			if syntaxErr.Line != tt.line || syntaxErr.Column != tt.column {
+				t.Errorf("ParseAll(%q) error at %d:%d, want %d:%d: %v",
+					tt.sql, syntaxErr.Line, syntaxErr.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,590 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/aggregate"
+)
+
+var aggregateFunctions = map[string]aggregate.AggregateType{
+	"SUM":          aggregate.SUM,
+	"AVG":          aggregate.AVG,
+	"COUNT":        aggregate.COUNT,
+	"MAX":          aggregate.MAX,
+	"MIN":          aggregate.MIN,
+	"GROUP_CONCAT": aggregate.GROUP_CONCAT,
+
+	"MEDIAN":                aggregate.MEDIAN,
+	"MODE":                  aggregate.MODE,
+	"STDDEV":                aggregate.STDDEV,
+	"VARIANCE":              aggregate.VARIANCE,
+	"PERCENTILE":            aggregate.PERCENTILE,
+	"APPROX_PERCENTILE":     aggregate.APPROX_PERCENTILE,
+	"COUNT_DISTINCT":        aggregate.COUNT_DISTINCT,
+	"APPROX_COUNT_DISTINCT": aggregate.APPROX_COUNT_DISTINCT,
+}
+
+// parseSelect reads
+//
+//	[WITH [RECURSIVE] name [(cols)] AS (select), ...]
+//	select-core [{UNION | INTERSECT | EXCEPT} [ALL | DISTINCT] select-core ...]
+//	[ORDER BY col [ASC|DESC], ...] [LIMIT n] [OFFSET n]
+func (p *Parser) parseSelect() (statement.Statement, error) {
+	start := p.peek()
+	cfg := statement.SelectStatementConfig{}
+
+	if p.acceptKeyword("WITH") {
+		cfg.Recursive = p.acceptKeyword("RECURSIVE")
+		for {
+			cte, err := p.parseCommonTableExpression()
+			if err != nil {
+				return nil, err
+			}
+			cfg.With = append(cfg.With, cte)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if err := p.parseSelectCore(&cfg); err != nil {
+		return nil, err
+	}
+	for {
+		op, ok, err := p.parseSetOperation()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		cfg.SetOperations = append(cfg.SetOperations, op)
+	}
+
+	var err error
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if cfg.OrderBy, err = p.parseOrderBy(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		limit, err := p.parseCount()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Limit = &limit
+	}
+	if p.acceptKeyword("OFFSET") {
+		if cfg.Offset, err = p.parseCount(); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := statement.NewSelectStatement(cfg)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
+
+// parseSelectCore reads
+//
+//	SELECT [DISTINCT] items [FROM table [[AS] alias] [WITH DELETED] [join ...]]
+//	       [WHERE cond] [GROUP BY cols] [HAVING cond]
+//
+// where a select without FROM computes its items over a single row.
+func (p *Parser) parseSelectCore(cfg *statement.SelectStatementConfig) error {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return err
+	}
+	cfg.Distinct = p.acceptKeyword("DISTINCT")
+
+	for {
+		if err := p.parseSelectItem(cfg); err != nil {
+			return err
+		}
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	switch {
+	case p.acceptKeyword("FROM"):
+		if err := p.parseFrom(cfg); err != nil {
+			return err
+		}
+	case p.peekSelectEnd():
+		cfg.Database, cfg.Schema = p.config.Database, p.config.Schema
+	default:
+		return p.unexpected("FROM")
+	}
+
+	var err error
+
+	if p.acceptKeyword("WHERE") {
+		if cfg.Where, err = p.parseCondition(); err != nil {
+			return err
+		}
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return err
+		}
+		if cfg.GroupBy, err = p.parseGroupBy(cfg.Projections); err != nil {
+			return err
+		}
+	}
+
+	if p.acceptKeyword("HAVING") {
+		if cfg.Having, err = p.parseHaving(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseFrom reads the table a select reads and the tables it joins.
+func (p *Parser) parseFrom(cfg *statement.SelectStatementConfig) error {
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return err
+	}
+	cfg.Database, cfg.Schema, cfg.TableName = ref.Database, ref.Schema, ref.Table
+	if cfg.Alias, err = p.parseTableAlias(); err != nil {
+		return err
+	}
+	if cfg.IncludeDeleted, err = p.parseWithDeleted(); err != nil {
+		return err
+	}
+	for {
+		join, ok, err := p.parseJoin()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		cfg.Joins = append(cfg.Joins, join)
+	}
+}
+
+// parseTableAlias reads the optional alias following a table name.
+func (p *Parser) parseTableAlias() (string, error) {
+	if p.acceptKeyword("AS") {
+		return p.expectIdent("an alias")
+	}
+	tok := p.peek()
+	if tok.kind == tokenIdent && (p.peekKeyword("HASH", "JOIN") || p.peekKeyword("MERGE", "JOIN")) {
+		return "", nil
+	}
+	if tok.kind == tokenQuotedIdent || (tok.kind == tokenIdent && !reserved[strings.ToUpper(tok.text)]) {
+		p.next()
+		return tok.text, nil
+	}
+	return "", nil
+}
+
+// parseWithDeleted reads the optional WITH DELETED following a table, which
+// also reads its deleted rows.
+func (p *Parser) parseWithDeleted() (bool, error) {
+	if !p.acceptKeyword("WITH") {
+		return false, nil
+	}
+	return true, p.expectKeyword("DELETED")
+}
+
+// parseJoin reads
+//
+//	[INNER | LEFT [OUTER] | RIGHT [OUTER] | FULL [OUTER]] [HASH | MERGE]
+//	JOIN table [[AS] alias] [WITH DELETED] ON col = col [AND col = col ...]
+//
+// and returns false when the next tokens do not start a join.
+func (p *Parser) parseJoin() (statement.Join, bool, error) {
+	join := statement.Join{Type: statement.InnerJoin}
+	switch {
+	case p.acceptKeyword("INNER"):
+	case p.acceptKeyword("LEFT"):
+		join.Type = statement.LeftJoin
+		p.acceptKeyword("OUTER")
+	case p.acceptKeyword("RIGHT"):
+		join.Type = statement.RightJoin
+		p.acceptKeyword("OUTER")
+	case p.acceptKeyword("FULL"):
+		join.Type = statement.FullJoin
+		p.acceptKeyword("OUTER")
+	case !p.peekKeyword("JOIN") && !p.peekKeyword("HASH", "JOIN") && !p.peekKeyword("MERGE", "JOIN"):
+		return join, false, nil
+	}
+
+	switch {
+	case p.acceptKeyword("HASH"):
+		join.Strategy = statement.HashJoin
+	case p.acceptKeyword("MERGE"):
+		join.Strategy = statement.MergeJoin
+	}
+	if err := p.expectKeyword("JOIN"); err != nil {
+		return join, false, err
+	}
+
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return join, false, err
+	}
+	join.Database, join.Schema, join.TableName = ref.Database, ref.Schema, ref.Table
+	if join.Alias, err = p.parseTableAlias(); err != nil {
+		return join, false, err
+	}
+	if join.IncludeDeleted, err = p.parseWithDeleted(); err != nil {
+		return join, false, err
+	}
+
+	if err := p.expectKeyword("ON"); err != nil {
+		return join, false, err
+	}
+	for {
+		var cond statement.JoinCondition
+		if cond.Left, err = p.parseColumnRef(); err != nil {
+			return join, false, err
+		}
+		if err := p.expectSymbol("="); err != nil {
+			return join, false, err
+		}
+		if cond.Right, err = p.parseColumnRef(); err != nil {
+			return join, false, err
+		}
+		join.On = append(join.On, cond)
+		if !p.acceptKeyword("AND") {
+			return join, true, nil
+		}
+	}
+}
+
+// parseExplain reads EXPLAIN [ANALYZE] select.
+func (p *Parser) parseExplain() (statement.Statement, error) {
+	p.next()
+	analyze := p.acceptKeyword("ANALYZE")
+	if !p.peekKeyword("SELECT") && !p.peekKeyword("WITH") {
+		return nil, p.unexpected("SELECT")
+	}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	sel := stmt.(*statement.SelectStatement)
+	sel.Explain, sel.Analyze = true, analyze
+	return sel, nil
+}
+
+// parseHaving reads the condition of HAVING, which may compare aggregate
+// calls as well as columns.
+func (p *Parser) parseHaving() (*filters.Filter, error) {
+	p.aggregates = true
+	defer func() { p.aggregates = false }()
+	return p.parseCondition()
+}
+
+// parseSelectItem reads a column, *, table.*, UNNEST(col) [AS col], an
+// aggregate such as COUNT(*), COUNT(DISTINCT col) or PERCENTILE(col, 0.9)
+// [AS alias], a window function such as RANK() OVER (ORDER BY col) or
+// SUM(col) OVER (...) [AS alias], or an expression [AS alias] over columns
+// and aggregates.
+func (p *Parser) parseSelectItem(cfg *statement.SelectStatementConfig) error {
+	if p.acceptSymbol("*") {
+		cfg.Columns = append(cfg.Columns, "*")
+		return nil
+	}
+
+	tok := p.peek()
+	isCall := tok.kind == tokenIdent && p.tokens[p.pos+1].kind == tokenSymbol && p.tokens[p.pos+1].text == "("
+	if p.tokens[p.pos+1].kind == tokenSymbol && p.tokens[p.pos+1].text == "." &&
+		p.tokens[p.pos+2].kind == tokenSymbol && p.tokens[p.pos+2].text == "*" {
+		table, err := p.expectIdent("a table name")
+		if err != nil {
+			return err
+		}
+		p.next()
+		p.next()
+		cfg.Columns = append(cfg.Columns, table+".*")
+		return nil
+	}
+
+	if isCall && strings.EqualFold(tok.text, "UNNEST") {
+		p.next()
+		p.next()
+		column, err := p.parseColumnRef()
+		if err != nil {
+			return err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return err
+		}
+		aliasTok := p.peek()
+		alias, err := p.parseAlias()
+		if err != nil {
+			return err
+		}
+		if alias != "" && alias != column {
+			return newSyntaxError(aliasTok, "UNNEST(%s) can only be aliased as %s", column, column)
+		}
+		cfg.Columns = append(cfg.Columns, column)
+		cfg.Unnest = append(cfg.Unnest, column)
+		return nil
+	}
+
+	p.aggregates = true
+	defer func() { p.aggregates = false }()
+
+	state := p.save()
+	if p.peekWindowFunction() {
+		w, err := p.parseWindowFunction()
+		if err != nil {
+			return err
+		}
+		return p.addWindow(cfg, w)
+	}
+	if p.peekAggregate() {
+		agg, err := p.parseAggregate()
+		if err != nil {
+			return err
+		}
+		if p.peekKeyword("OVER") {
+			w, err := p.parseAggregateWindow(tok, agg)
+			if err != nil {
+				return err
+			}
+			return p.addWindow(cfg, w)
+		}
+		if p.peekSelectItemEnd() || p.peekKeyword("AS") {
+			if agg.Alias, err = p.parseAlias(); err != nil {
+				return err
+			}
+			cfg.Aggregations = append(cfg.Aggregations, agg)
+			return nil
+		}
+	} else if tok.kind == tokenQuotedIdent || !p.peekLiteralKeyword() && !p.peekKeyword("CASE") && !isCall {
+		column, err := p.parseColumnRef()
+		if err == nil && p.peekSelectItemEnd() {
+			cfg.Columns = append(cfg.Columns, column)
+			return nil
+		}
+	}
+
+	p.restore(state)
+	expr, err := p.parseExpression()
+	if err != nil {
+		return err
+	}
+	alias, err := p.parseAlias()
+	if err != nil {
+		return err
+	}
+	cfg.Projections = append(cfg.Projections, statement.Projection{Expression: expr, Alias: alias})
+	return nil
+}
+
+func (p *Parser) addWindow(cfg *statement.SelectStatementConfig, w statement.WindowFunction) error {
+	var err error
+	if w.Alias, err = p.parseAlias(); err != nil {
+		return err
+	}
+	cfg.Windows = append(cfg.Windows, w)
+	return nil
+}
+
+// peekSelectItemEnd reports whether the next token ends an item of the
+// select list.
+func (p *Parser) peekSelectItemEnd() bool {
+	return p.peekSymbol(",") || p.peekKeyword("FROM") || p.peekSelectEnd()
+}
+
+// peekSelectEnd reports whether the next token ends the select list of a
+// select without FROM.
+func (p *Parser) peekSelectEnd() bool {
+	if tok := p.peek(); tok.kind == tokenEOF || p.peekSymbol(";") || p.peekSymbol(")") {
+		return true
+	}
+	for _, keyword := range []string{"WHERE", "GROUP", "HAVING", "UNION", "INTERSECT", "EXCEPT", "ORDER", "LIMIT", "OFFSET"} {
+		if p.peekKeyword(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseColumnRef reads a column name, qualified as table.col when the
+// statement joins tables.
+func (p *Parser) parseColumnRef() (string, error) {
+	name, err := p.expectIdent("a column name")
+	if err != nil || !p.acceptSymbol(".") {
+		return name, err
+	}
+	column, err := p.expectIdent("a column name")
+	if err != nil {
+		return "", err
+	}
+	return name + "." + column, nil
+}
+
+func (p *Parser) parseAlias() (string, error) {
+	if p.acceptKeyword("AS") {
+		return p.expectIdent("an alias")
+	}
+	return "", nil
+}
+
+func (p *Parser) parseColumnList() ([]string, error) {
+	var columns []string
+	for {
+		column, err := p.parseColumnRef()
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+		if !p.acceptSymbol(",") {
+			return columns, nil
+		}
+	}
+}
+
+// parseGroupBy reads the GROUP BY list. Besides columns, rows can be
+// grouped by a computed column of the select list, named by its alias or
+// repeated as written, which is kept as the name of the column.
+func (p *Parser) parseGroupBy(projections []statement.Projection) ([]string, error) {
+	var names []string
+	for {
+		tok := p.peek()
+		state := p.save()
+		name, err := p.parseColumnRef()
+		if err != nil || p.peekOperator() || p.peekSymbol("(") {
+			p.restore(state)
+			expr, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			name = ""
+			for _, projection := range projections {
+				if projection.Expression.String() == expr.String() {
+					name = projection.Name()
+					break
+				}
+			}
+			if name == "" {
+				return nil, newSyntaxError(tok, "GROUP BY expression %s must appear in the select list", expr)
+			}
+		}
+		names = append(names, name)
+		if !p.acceptSymbol(",") {
+			return names, nil
+		}
+	}
+}
+
+// parseOrderBy returns the sort keys as "col [DESC] [NULLS FIRST|LAST]".
+func (p *Parser) parseOrderBy() ([]string, error) {
+	return p.parseSortKeys(p.parseColumnRef)
+}
+
+// parseSortKeys reads a list of sort keys whose columns are read by
+// column, in the form parseOrderBy returns them.
+func (p *Parser) parseSortKeys(column func() (string, error)) ([]string, error) {
+	var keys []string
+	for {
+		key, err := column()
+		if err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("DESC") {
+			key += " DESC"
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		if p.acceptKeyword("NULLS") {
+			switch {
+			case p.acceptKeyword("FIRST"):
+				key += " NULLS FIRST"
+			case p.acceptKeyword("LAST"):
+				key += " NULLS LAST"
+			default:
+				return nil, p.unexpected("FIRST or LAST")
+			}
+		}
+		keys = append(keys, key)
+		if !p.acceptSymbol(",") {
+			return keys, nil
+		}
+	}
+}
+
+func (p *Parser) parseCount() (uint64, error) {
+	tok := p.peek()
+	if tok.kind != tokenNumber {
+		return 0, p.unexpected("a non-negative integer")
+	}
+	n, err := strconv.ParseUint(tok.text, 10, 64)
+	if err != nil {
+		return 0, p.unexpected("a non-negative integer")
+	}
+	p.next()
+	return n, nil
+}
+
+// peekAggregate reports whether the next tokens start an aggregate call.
+func (p *Parser) peekAggregate() bool {
+	tok := p.peek()
+	_, ok := aggregateFunctions[strings.ToUpper(tok.text)]
+	next := p.tokens[min(p.pos+1, len(p.tokens)-1)]
+	return ok && tok.kind == tokenIdent && next.kind == tokenSymbol && next.text == "("
+}
+
+// parseAggregate reads an aggregate call such as COUNT(*),
+// COUNT(DISTINCT col) or PERCENTILE(col, 0.9), without its alias.
+func (p *Parser) parseAggregate() (statement.Aggregation, error) {
+	fn := aggregateFunctions[strings.ToUpper(p.next().text)]
+	p.next()
+	if fn == aggregate.COUNT && p.acceptKeyword("DISTINCT") {
+		fn = aggregate.COUNT_DISTINCT
+	}
+
+	agg := statement.Aggregation{Function: fn, Column: "*"}
+	if !p.acceptSymbol("*") {
+		name, err := p.parseColumnRef()
+		if err != nil {
+			return agg, err
+		}
+		agg.Column = name
+	}
+	if fn == aggregate.PERCENTILE || fn == aggregate.APPROX_PERCENTILE {
+		fraction, err := p.parseFraction()
+		if err != nil {
+			return agg, err
+		}
+		agg.Fraction = fraction
+	}
+	return agg, p.expectSymbol(")")
+}
+
+// parseFraction reads the ", fraction" argument of a percentile.
+func (p *Parser) parseFraction() (float64, error) {
+	if err := p.expectSymbol(","); err != nil {
+		return 0, err
+	}
+	tok := p.peek()
+	if tok.kind != tokenNumber {
+		return 0, p.unexpected("a fraction between 0 and 1")
+	}
+	f, err := strconv.ParseFloat(tok.text, 64)
+	if err != nil || f < 0 || f > 1 {
+		return 0, p.unexpected("a fraction between 0 and 1")
+	}
+	p.next()
+	return f, nil
+}
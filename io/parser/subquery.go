@@ -0,0 +1,30 @@
+package parser
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// peekSubquery reports whether the tokens from offset on, counted from the
+// next one, open a parenthesized select.
+func (p *Parser) peekSubquery(offset int) bool {
+	if p.pos+offset+1 >= len(p.tokens) {
+		return false
+	}
+	open, first := p.tokens[p.pos+offset], p.tokens[p.pos+offset+1]
+	return open.kind == tokenSymbol && open.text == "(" && (p.isKeyword(first, "SELECT") || p.isKeyword(first, "WITH"))
+}
+
+// parseSubquery reads a select nested in a condition or an expression,
+// between parentheses. Its conditions are read as in WHERE, even within
+// HAVING or the select list.
+func (p *Parser) parseSubquery() (statement.Subquery, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return statement.Subquery{}, err
+	}
+	aggregates := p.aggregates
+	p.aggregates = false
+	stmt, err := p.parseSelect()
+	p.aggregates = aggregates
+	if err != nil {
+		return statement.Subquery{}, err
+	}
+	return statement.Subquery{Select: stmt.(*statement.SelectStatement)}, p.expectSymbol(")")
+}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// parseBegin reads BEGIN [TRANSACTION | WORK] [name] or START TRANSACTION
+// [name]. Unnamed transactions get a generated id, which starts with a
+// letter as names have to.
+func (p *Parser) parseBegin() (statement.Statement, error) {
+	start := p.next()
+	if p.isKeyword(start, "START") {
+		if err := p.expectKeyword("TRANSACTION"); err != nil {
+			return nil, err
+		}
+	} else if !p.acceptKeyword("TRANSACTION") {
+		p.acceptKeyword("WORK")
+	}
+
+	id, err := p.parseOptionalName()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		id = "tx_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	}
+
+	stmt, err := statement.NewBeginTransactionStatement(id)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	p.transactionID = id
+	return stmt, nil
+}
+
+// parseCommit reads COMMIT [TRANSACTION | WORK] [name].
+func (p *Parser) parseCommit() (statement.Statement, error) {
+	start := p.next()
+	id, err := p.parseTransactionName(start)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := statement.NewCommitStatement(id)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	p.endTransaction(id)
+	return stmt, nil
+}
+
+// parseRollback reads ROLLBACK [TRANSACTION | WORK] [name] [TO [SAVEPOINT]
+// savepoint].
+func (p *Parser) parseRollback() (statement.Statement, error) {
+	start := p.next()
+	id, err := p.parseTransactionName(start)
+	if err != nil {
+		return nil, err
+	}
+
+	var savepoint string
+	if p.acceptKeyword("TO") {
+		p.acceptKeyword("SAVEPOINT")
+		if savepoint, err = p.expectIdent("a savepoint name"); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := statement.NewRollbackStatement(id)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	stmt.SavepointName = savepoint
+	if savepoint == "" {
+		p.endTransaction(id)
+	}
+	return stmt, nil
+}
+
+// parseSavepoint reads SAVEPOINT name.
+func (p *Parser) parseSavepoint() (statement.Statement, error) {
+	start := p.next()
+	name, err := p.expectIdent("a savepoint name")
+	if err != nil {
+		return nil, err
+	}
+	if p.transactionID == "" {
+		return nil, newSyntaxError(start, "SAVEPOINT requires a transaction in progress")
+	}
+
+	stmt, err := statement.NewSavepointStatement(p.transactionID, name)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
+
+// parseReleaseSavepoint reads RELEASE [SAVEPOINT] name.
+func (p *Parser) parseReleaseSavepoint() (statement.Statement, error) {
+	start := p.next()
+	p.acceptKeyword("SAVEPOINT")
+	name, err := p.expectIdent("a savepoint name")
+	if err != nil {
+		return nil, err
+	}
+	if p.transactionID == "" {
+		return nil, newSyntaxError(start, "RELEASE SAVEPOINT requires a transaction in progress")
+	}
+
+	stmt, err := statement.NewReleaseSavepointStatement(p.transactionID, name)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
+
+// parseTransactionName reads the optional TRANSACTION or WORK keyword and
+// transaction name of COMMIT and ROLLBACK, defaulting to the transaction
+// opened by the last BEGIN.
+func (p *Parser) parseTransactionName(start token) (string, error) {
+	if !p.acceptKeyword("TRANSACTION") {
+		p.acceptKeyword("WORK")
+	}
+	id, err := p.parseOptionalName()
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		id = p.transactionID
+	}
+	if id == "" {
+		return "", newSyntaxError(start, "%s requires a transaction in progress", strings.ToUpper(start.text))
+	}
+	return id, nil
+}
+
+func (p *Parser) parseOptionalName() (string, error) {
+	tok := p.peek()
+	if tok.kind == tokenQuotedIdent || (tok.kind == tokenIdent && !p.isKeyword(tok, "TO")) {
+		return p.expectIdent("a transaction name")
+	}
+	return "", nil
+}
+
+func (p *Parser) endTransaction(id string) {
+	if id == p.transactionID {
+		p.transactionID = ""
+	}
+}
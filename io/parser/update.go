@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+)
+
+// parseUpdate reads UPDATE table SET col = value, ... [WHERE cond], where
+// values that are not literals, such as price * 1.1, are kept as
+// expressions.
+func (p *Parser) parseUpdate() (statement.Statement, error) {
+	start := p.next()
+	ref, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("SET"); err != nil {
+		return nil, err
+	}
+
+	updates, expressions, err := p.parseAssignments()
+	if err != nil {
+		return nil, err
+	}
+
+	var where *filters.Filter
+	if p.acceptKeyword("WHERE") {
+		if where, err = p.parseCondition(); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := statement.NewUpdateStatement(ref.Database, ref.Schema, ref.Table, updates, expressions, where)
+	if err != nil {
+		return nil, p.invalid(start, err)
+	}
+	return stmt, nil
+}
+
+// parseAssignments reads the col = value, ... list of a SET, keeping the
+// values that are literals apart from the expressions.
+func (p *Parser) parseAssignments() (map[string]interface{}, map[string]*expression.Expression, error) {
+	updates := make(map[string]interface{})
+	expressions := make(map[string]*expression.Expression)
+	for {
+		colTok := p.peek()
+		column, err := p.expectIdent("a column name")
+		if err != nil {
+			return nil, nil, err
+		}
+		_, assigned := expressions[column]
+		if _, ok := updates[column]; ok || assigned {
+			return nil, nil, newSyntaxError(colTok, "column %s assigned more than once", column)
+		}
+		if err := p.expectSymbol("="); err != nil {
+			return nil, nil, err
+		}
+		state := p.save()
+		value, err := p.parseValue()
+		if err == nil && !p.peekOperator() && !p.peekSymbol("(") {
+			updates[column] = value
+		} else {
+			p.restore(state)
+			expr, err := p.parseExpression()
+			if err != nil {
+				return nil, nil, err
+			}
+			expressions[column] = expr
+		}
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return updates, expressions, nil
+}
@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// parseValue reads a literal, optionally followed by interval arithmetic on
+// timestamps such as now() - interval '7 days'. Integers become int64 and
+// decimals float64; filters and writers narrow them to the column type.
+func (p *Parser) parseValue() (interface{}, error) {
+	start := p.peek()
+	value, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekSymbol("+") || p.peekSymbol("-") {
+		op := p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		interval, ok := right.(fields.IntervalValue)
+		if !ok {
+			return nil, newSyntaxError(op, "only intervals can be added to or subtracted from a timestamp")
+		}
+
+		var expr filters.TimeExpr
+		switch v := value.(type) {
+		case filters.TimeExpr:
+			expr = v
+		case time.Time:
+			expr = filters.At(v)
+		default:
+			return nil, newSyntaxError(start, "only intervals can be added to or subtracted from a timestamp")
+		}
+		if op.text == "+" {
+			value = expr.Add(interval)
+		} else {
+			value = expr.Sub(interval)
+		}
+	}
+
+	return value, nil
+}
+
+func (p *Parser) parseTerm() (interface{}, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenString:
+		p.next()
+		return tok.text, nil
+	case tokenNumber:
+		p.next()
+		return parseNumber(tok, false)
+	case tokenSymbol:
+		switch tok.text {
+		case "-", "+":
+			p.next()
+			num := p.peek()
+			if num.kind != tokenNumber {
+				return nil, p.unexpected("a number")
+			}
+			p.next()
+			return parseNumber(num, tok.text == "-")
+		case "[":
+			p.next()
+			return p.parseValueList("]")
+		}
+	case tokenIdent:
+		return p.parseKeywordValue(tok)
+	case tokenParam:
+		p.next()
+		return p.parseParam(tok)
+	}
+	return nil, p.unexpected("a value")
+}
+
+// parseParam numbers ? parameters in order of appearance and takes $n
+// literally. The two styles cannot be mixed in one text.
+func (p *Parser) parseParam(tok token) (interface{}, error) {
+	style := tok.text[:1]
+	if p.paramStyle != "" && p.paramStyle != style {
+		return nil, newSyntaxError(tok, "cannot mix ? and $n parameters")
+	}
+	p.paramStyle = style
+
+	if style == "?" {
+		p.params++
+		return statement.Param{Index: p.params - 1}, nil
+	}
+
+	n, err := strconv.Atoi(tok.text[1:])
+	if err != nil || n < 1 {
+		return nil, newSyntaxError(tok, "invalid parameter %s", tok.text)
+	}
+	p.params = max(p.params, n)
+	return statement.Param{Index: n - 1}, nil
+}
+
+func (p *Parser) parseKeywordValue(tok token) (interface{}, error) {
+	keyword := strings.ToUpper(tok.text)
+	switch keyword {
+	case "NULL":
+		p.next()
+		return nil, nil
+	case "TRUE", "FALSE":
+		p.next()
+		return keyword == "TRUE", nil
+	case "ARRAY":
+		p.next()
+		if err := p.expectSymbol("["); err != nil {
+			return nil, err
+		}
+		return p.parseValueList("]")
+	case "NOW", "CURRENT_TIMESTAMP":
+		p.next()
+		if keyword == "NOW" || p.peekSymbol("(") {
+			if err := p.expectSymbol("("); err != nil {
+				return nil, err
+			}
+			if err := p.expectSymbol(")"); err != nil {
+				return nil, err
+			}
+		}
+		return filters.Now(), nil
+	case "POINT":
+		p.next()
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		coords, err := p.parseValueList(")")
+		if err != nil {
+			return nil, err
+		}
+		point, err := fields.ToLatLng(coords)
+		if err != nil {
+			return nil, newSyntaxError(tok, "%v", err)
+		}
+		if err := point.Validate(); err != nil {
+			return nil, newSyntaxError(tok, "%v", err)
+		}
+		return point, nil
+	case "DATE", "TIMESTAMP", "TIMESTAMPTZ", "TIME", "INTERVAL":
+		p.next()
+		lit := p.peek()
+		if lit.kind != tokenString {
+			return nil, p.unexpected("a quoted " + strings.ToLower(keyword))
+		}
+		p.next()
+		value, err := parseTypedLiteral(keyword, lit.text)
+		if err != nil {
+			return nil, newSyntaxError(lit, "%v", err)
+		}
+		return value, nil
+	}
+	return nil, p.unexpected("a value")
+}
+
+func parseTypedLiteral(keyword, text string) (interface{}, error) {
+	switch keyword {
+	case "DATE":
+		t, err := fields.ParseTime(text)
+		if err != nil {
+			return nil, err
+		}
+		return fields.TruncateToDate(t), nil
+	case "TIME":
+		return fields.ParseTimeOfDay(text)
+	case "INTERVAL":
+		return fields.ParseInterval(text)
+	}
+	return fields.ParseTime(text)
+}
+
+// parseValueList reads comma separated values up to the closing symbol,
+// which has to be consumed by the caller beforehand for the opening one.
+func (p *Parser) parseValueList(closing string) ([]interface{}, error) {
+	values := []interface{}{}
+	if p.acceptSymbol(closing) {
+		return values, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return values, p.expectSymbol(closing)
+}
+
+func parseNumber(tok token, negative bool) (interface{}, error) {
+	text := tok.text
+	if negative {
+		text = "-" + text
+	}
+	if !strings.ContainsAny(text, ".eE") {
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, newSyntaxError(tok, "invalid number %s", tok.text)
+	}
+	return f, nil
+}
@@ -0,0 +1,284 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+)
+
+// parseCondition reads a boolean expression into a filter tree. AND binds
+// tighter than OR and NOT tighter than both; chains of the same connective
+// share a single group.
+func (p *Parser) parseCondition() (*filters.Filter, error) {
+	return p.parseJoined(filters.Or, p.parseAnd)
+}
+
+func (p *Parser) parseAnd() (*filters.Filter, error) {
+	return p.parseJoined(filters.And, p.parseNot)
+}
+
+func (p *Parser) parseJoined(joinWith string, operand func() (*filters.Filter, error)) (*filters.Filter, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peekKeyword(joinWith) {
+		return first, nil
+	}
+
+	group := filters.NewGroup(joinWith).Add(first)
+	for p.acceptKeyword(joinWith) {
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		group.Add(next)
+	}
+	return group, nil
+}
+
+func (p *Parser) parseNot() (*filters.Filter, error) {
+	if !p.acceptKeyword("NOT") {
+		return p.parsePrimary()
+	}
+	inner, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return negate(inner), nil
+}
+
+// negatedOperators maps operators to their explicit negated form.
+var negatedOperators = map[filters.Operator]filters.Operator{
+	filters.Equal:      filters.NotEqual,
+	filters.NotEqual:   filters.Equal,
+	filters.Like:       filters.NotLike,
+	filters.NotLike:    filters.Like,
+	filters.In:         filters.NotIn,
+	filters.NotIn:      filters.In,
+	filters.IsNull:     filters.IsNotNull,
+	filters.IsNotNull:  filters.IsNull,
+	filters.Between:    filters.NotBetween,
+	filters.NotBetween: filters.Between,
+	filters.Exists:     filters.NotExists,
+	filters.NotExists:  filters.Exists,
+}
+
+func negate(f *filters.Filter) *filters.Filter {
+	// Expressions negate with NOT of their own, which keeps NULL unknown.
+	if f.Expression != nil {
+		return filters.NewExpressionCondition(expression.NewUnary(expression.Not, f.Expression))
+	}
+	if len(f.Children) == 0 {
+		if op, ok := negatedOperators[f.Operator]; ok {
+			f.Operator = op
+			return f
+		}
+	}
+	return filters.NewGroup(filters.Not).Add(f)
+}
+
+// parsePrimary reads a parenthesized condition or a predicate. When the
+// parentheses turn out to group an operand, as in (a + b) * 2 > c, the
+// whole comparison is read again as an expression.
+func (p *Parser) parsePrimary() (*filters.Filter, error) {
+	state := p.save()
+	if p.acceptSymbol("(") {
+		inner, err := p.parseCondition()
+		if err == nil {
+			err = p.expectSymbol(")")
+		}
+		if err == nil && !p.peekOperator() {
+			return inner, nil
+		}
+		p.restore(state)
+		cond, exprErr := p.parseExpressionCondition()
+		if exprErr != nil && err != nil {
+			return nil, err
+		}
+		return cond, exprErr
+	}
+	return p.parsePredicate()
+}
+
+var comparisonOperators = map[string]filters.Operator{
+	"=":  filters.Equal,
+	"!=": filters.NotEqual,
+	"<>": filters.NotEqual,
+	"<":  filters.LessThan,
+	"<=": filters.LessThanOrEqual,
+	">":  filters.GreaterThan,
+	">=": filters.GreaterThanOrEqual,
+}
+
+// parsePredicate reads a test of a column against values, or against the
+// results of a subquery as in col IN (SELECT ...), col > (SELECT ...) and
+// EXISTS (SELECT ...). Anything else, such as a comparison of two columns
+// or of computed values, is read as an expression condition.
+func (p *Parser) parsePredicate() (*filters.Filter, error) {
+	if p.isKeyword(p.peek(), "EXISTS") && p.peekSubquery(1) {
+		p.next()
+		sub, err := p.parseSubquery()
+		return filters.NewCondition("", filters.Exists, sub), err
+	}
+
+	state := p.save()
+	if p.peekKeyword("CASE") || p.peekLiteralKeyword() {
+		return p.parseExpressionCondition()
+	}
+	field, err := p.parseOperand()
+	if err != nil || !p.peekPredicateOperator() {
+		if err == nil && !p.peekOperator() && !p.peekSymbol("(") && !p.peekConditionEnd() {
+			return nil, p.unexpected("an operator")
+		}
+		p.restore(state)
+		cond, exprErr := p.parseExpressionCondition()
+		// Nothing that starts an expression either: report the column.
+		if exprErr != nil && err != nil && p.pos == state.pos {
+			return nil, err
+		}
+		return cond, exprErr
+	}
+
+	tok := p.peek()
+	if op, ok := comparisonOperators[tok.text]; ok && tok.kind == tokenSymbol {
+		p.next()
+		if p.peekSubquery(0) {
+			sub, err := p.parseSubquery()
+			return filters.NewCondition(field, op, sub), err
+		}
+		value, err := p.parseValue()
+		if err != nil || p.peekOperator() || p.peekSymbol("(") {
+			p.restore(state)
+			return p.parseExpressionCondition()
+		}
+		return filters.NewCondition(field, op, value), nil
+	}
+
+	switch {
+	case p.acceptKeyword("IS"):
+		if p.acceptKeyword("NOT") {
+			return filters.NewCondition(field, filters.IsNotNull, nil), p.expectKeyword("NULL")
+		}
+		return filters.NewCondition(field, filters.IsNull, nil), p.expectKeyword("NULL")
+	case p.acceptKeyword("CONTAINS"):
+		if p.acceptSymbol("(") {
+			values, err := p.parseValueList(")")
+			return filters.NewCondition(field, filters.Contains, values), err
+		}
+		value, err := p.parseValue()
+		return filters.NewCondition(field, filters.Contains, value), err
+	case p.acceptKeyword("ANY"):
+		return p.parseListCondition(field, filters.Any)
+	case p.acceptKeyword("ALL"):
+		return p.parseListCondition(field, filters.All)
+	case p.acceptKeyword("WITHIN"):
+		switch {
+		case p.acceptKeyword("BOX"):
+			return p.parseListCondition(field, filters.WithinBox)
+		case p.acceptKeyword("DISTANCE"):
+			return p.parseListCondition(field, filters.WithinDistance)
+		}
+		return nil, p.unexpected("BOX or DISTANCE")
+	}
+
+	negated := p.acceptKeyword("NOT")
+	var cond *filters.Filter
+	switch {
+	case p.acceptKeyword("LIKE"):
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		cond = filters.NewCondition(field, filters.Like, value)
+	case p.peekKeyword("IN") && p.peekSubquery(1):
+		p.next()
+		sub, err := p.parseSubquery()
+		if err != nil {
+			return nil, err
+		}
+		cond = filters.NewCondition(field, filters.In, sub)
+	case p.acceptKeyword("IN"):
+		cond, err = p.parseListCondition(field, filters.In)
+		if err != nil {
+			return nil, err
+		}
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		cond = filters.NewCondition(field, filters.Between, []interface{}{low, high})
+	default:
+		if negated {
+			return nil, p.unexpected("LIKE, IN or BETWEEN")
+		}
+		return nil, p.unexpected("an operator")
+	}
+
+	if negated {
+		cond = negate(cond)
+	}
+	return cond, nil
+}
+
+// peekPredicateOperator reports whether the next token is an operator of a
+// column predicate.
+func (p *Parser) peekPredicateOperator() bool {
+	tok := p.peek()
+	if _, ok := comparisonOperators[tok.text]; ok && tok.kind == tokenSymbol {
+		return true
+	}
+	for _, keyword := range []string{"IS", "CONTAINS", "ANY", "ALL", "WITHIN", "NOT", "LIKE", "IN", "BETWEEN"} {
+		if p.isKeyword(tok, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// peekConditionEnd reports whether the next token ends a condition, so that
+// a lone column before it is a boolean test.
+func (p *Parser) peekConditionEnd() bool {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenEOF, tokenSymbol:
+		return true
+	case tokenIdent:
+		return reserved[strings.ToUpper(tok.text)]
+	}
+	return false
+}
+
+// parseOperand reads the column a predicate tests or, when aggregates are
+// allowed, an aggregate call, which is named as the grouped rows name it.
+func (p *Parser) parseOperand() (string, error) {
+	if p.aggregates && p.peekAggregate() {
+		agg, err := p.parseAggregate()
+		if err != nil {
+			return "", err
+		}
+		return agg.Name(), nil
+	}
+	return p.parseColumnRef()
+}
+
+// parseListCondition reads a parenthesized value list as the operand of op.
+func (p *Parser) parseListCondition(field string, op filters.Operator) (*filters.Filter, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	values, err := p.parseValueList(")")
+	if err != nil {
+		return nil, err
+	}
+	return filters.NewCondition(field, op, values), nil
+}
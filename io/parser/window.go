@@ -0,0 +1,245 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/io/statement"
+	"github.com/onnasoft/ZenithSQL/model/aggregate"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+var windowFunctions = map[string]statement.WindowFunctionType{
+	"ROW_NUMBER":   statement.RowNumber,
+	"RANK":         statement.Rank,
+	"DENSE_RANK":   statement.DenseRank,
+	"PERCENT_RANK": statement.PercentRank,
+	"CUME_DIST":    statement.CumeDist,
+	"NTILE":        statement.Ntile,
+	"LAG":          statement.Lag,
+	"LEAD":         statement.Lead,
+	"FIRST_VALUE":  statement.FirstValue,
+	"LAST_VALUE":   statement.LastValue,
+	"NTH_VALUE":    statement.NthValue,
+}
+
+// peekWindowFunction reports whether the next tokens call a function that
+// is only allowed with OVER.
+func (p *Parser) peekWindowFunction() bool {
+	tok := p.peek()
+	_, ok := windowFunctions[strings.ToUpper(tok.text)]
+	next := p.tokens[min(p.pos+1, len(p.tokens)-1)]
+	return ok && tok.kind == tokenIdent && next.kind == tokenSymbol && next.text == "("
+}
+
+// parseWindowFunction reads
+//
+//	ROW_NUMBER() | RANK() | DENSE_RANK() | PERCENT_RANK() | CUME_DIST()
+//	| NTILE(n) | LAG(col [, offset [, default]]) | LEAD(col [, offset [, default]])
+//	| FIRST_VALUE(col) | LAST_VALUE(col) | NTH_VALUE(col, n)
+//
+// followed by OVER and its window, without its alias.
+func (p *Parser) parseWindowFunction() (statement.WindowFunction, error) {
+	w := statement.WindowFunction{Function: windowFunctions[strings.ToUpper(p.next().text)]}
+	p.next()
+
+	var err error
+	switch w.Function {
+	case statement.Ntile:
+		w.Offset, err = p.parseWindowCount()
+	case statement.Lag, statement.Lead:
+		w.Offset = 1
+		if w.Column, err = p.parseColumnRef(); err != nil {
+			return w, err
+		}
+		if p.acceptSymbol(",") {
+			if w.Offset, err = p.parseWindowCount(); err != nil {
+				return w, err
+			}
+			if p.acceptSymbol(",") {
+				tok := p.peek()
+				if w.Default, err = p.parseTerm(); err != nil {
+					return w, err
+				}
+				if _, ok := w.Default.(statement.Param); ok {
+					return w, newSyntaxError(tok, "parameters are not allowed in %s", w.Function)
+				}
+			}
+		}
+	case statement.FirstValue, statement.LastValue:
+		w.Column, err = p.parseColumnRef()
+	case statement.NthValue:
+		if w.Column, err = p.parseColumnRef(); err != nil {
+			return w, err
+		}
+		if err := p.expectSymbol(","); err != nil {
+			return w, err
+		}
+		w.Offset, err = p.parseWindowCount()
+	}
+	if err != nil {
+		return w, err
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return w, err
+	}
+
+	if err := p.expectKeyword("OVER"); err != nil {
+		return w, err
+	}
+	w.Over, err = p.parseWindow()
+	return w, err
+}
+
+// parseAggregateWindow reads the window of an aggregate call followed by
+// OVER, computed over the frame of every row.
+func (p *Parser) parseAggregateWindow(tok token, agg statement.Aggregation) (statement.WindowFunction, error) {
+	w := statement.WindowFunction{Function: statement.WindowFunctionType(agg.Function), Column: agg.Column}
+	if agg.Function == aggregate.PERCENTILE || agg.Function == aggregate.APPROX_PERCENTILE {
+		return w, newSyntaxError(tok, "%s cannot be used as a window function", agg.Function)
+	}
+	if err := p.expectKeyword("OVER"); err != nil {
+		return w, err
+	}
+	var err error
+	w.Over, err = p.parseWindow()
+	return w, err
+}
+
+func (p *Parser) parseWindowCount() (int64, error) {
+	n, err := p.parseCount()
+	if err != nil {
+		return 0, err
+	}
+	return int64(n), nil
+}
+
+// parseWindow reads
+//
+//	( [PARTITION BY col, ...] [ORDER BY key, ...] [frame] )
+//
+// where columns and keys may be aggregate calls in a grouped select.
+func (p *Parser) parseWindow() (statement.Window, error) {
+	var w statement.Window
+	if err := p.expectSymbol("("); err != nil {
+		return w, err
+	}
+
+	if p.acceptKeyword("PARTITION") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return w, err
+		}
+		for {
+			column, err := p.parseWindowColumn()
+			if err != nil {
+				return w, err
+			}
+			w.PartitionBy = append(w.PartitionBy, column)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return w, err
+		}
+		var err error
+		if w.OrderBy, err = p.parseSortKeys(p.parseWindowColumn); err != nil {
+			return w, err
+		}
+	}
+
+	if p.peekKeyword("ROWS") || p.peekKeyword("RANGE") {
+		frame, err := p.parseFrame()
+		if err != nil {
+			return w, err
+		}
+		w.Frame = &frame
+	}
+	return w, p.expectSymbol(")")
+}
+
+// parseWindowColumn reads a column, or an aggregate call named as the
+// grouped rows name it.
+func (p *Parser) parseWindowColumn() (string, error) {
+	if p.aggregates && p.peekAggregate() {
+		agg, err := p.parseAggregate()
+		if err != nil {
+			return "", err
+		}
+		return agg.Name(), nil
+	}
+	return p.parseColumnRef()
+}
+
+// parseFrame reads
+//
+//	{ROWS | RANGE} {BETWEEN bound AND bound | bound}
+//
+// where a single bound starts a frame ending at the current row.
+func (p *Parser) parseFrame() (statement.WindowFrame, error) {
+	frame := statement.WindowFrame{Unit: statement.FrameUnit(strings.ToUpper(p.next().text))}
+	end := statement.FrameBound{Type: statement.CurrentRow}
+
+	between := p.acceptKeyword("BETWEEN")
+	start, err := p.parseFrameBound()
+	if err != nil {
+		return frame, err
+	}
+	if between {
+		if err := p.expectKeyword("AND"); err != nil {
+			return frame, err
+		}
+		if end, err = p.parseFrameBound(); err != nil {
+			return frame, err
+		}
+	}
+	frame.Start, frame.End = start, end
+	return frame, nil
+}
+
+// parseFrameBound reads UNBOUNDED {PRECEDING | FOLLOWING}, CURRENT ROW, or
+// an offset followed by PRECEDING or FOLLOWING: a number, or an interval
+// such as INTERVAL '1 day' for RANGE frames.
+func (p *Parser) parseFrameBound() (statement.FrameBound, error) {
+	var bound statement.FrameBound
+	switch {
+	case p.acceptKeyword("UNBOUNDED"):
+		switch {
+		case p.acceptKeyword("PRECEDING"):
+			bound.Type = statement.UnboundedPreceding
+		case p.acceptKeyword("FOLLOWING"):
+			bound.Type = statement.UnboundedFollowing
+		default:
+			return bound, p.unexpected("PRECEDING or FOLLOWING")
+		}
+		return bound, nil
+	case p.acceptKeyword("CURRENT"):
+		bound.Type = statement.CurrentRow
+		return bound, p.expectKeyword("ROW")
+	case p.peek().kind == tokenNumber:
+		bound.Offset = p.next().text
+	case p.acceptKeyword("INTERVAL"):
+		tok := p.peek()
+		if tok.kind != tokenString {
+			return bound, p.unexpected("an interval")
+		}
+		if _, err := fields.ParseInterval(tok.text); err != nil {
+			return bound, newSyntaxError(tok, "%v", err)
+		}
+		bound.Offset = p.next().text
+	default:
+		return bound, p.unexpected("a frame bound")
+	}
+
+	switch {
+	case p.acceptKeyword("PRECEDING"):
+		bound.Type = statement.Preceding
+	case p.acceptKeyword("FOLLOWING"):
+		bound.Type = statement.Following
+	default:
+		return bound, p.unexpected("PRECEDING or FOLLOWING")
+	}
+	return bound, nil
+}
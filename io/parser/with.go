@@ -0,0 +1,65 @@
+package parser
+
+import "github.com/onnasoft/ZenithSQL/io/statement"
+
+// parseCommonTableExpression reads name [(col, ...)] AS (select) of a WITH
+// clause.
+func (p *Parser) parseCommonTableExpression() (statement.CommonTableExpression, error) {
+	var cte statement.CommonTableExpression
+	var err error
+	if cte.Name, err = p.expectIdent("a common table expression name"); err != nil {
+		return cte, err
+	}
+	if p.peekSymbol("(") {
+		if cte.Columns, err = p.parseIdentList("a column name"); err != nil {
+			return cte, err
+		}
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return cte, err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return cte, err
+	}
+	if !p.peekKeyword("SELECT") && !p.peekKeyword("WITH") {
+		return cte, p.unexpected("SELECT")
+	}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return cte, err
+	}
+	cte.Select = stmt.(*statement.SelectStatement)
+	return cte, p.expectSymbol(")")
+}
+
+// parseSetOperation reads {UNION | INTERSECT | EXCEPT} [ALL | DISTINCT]
+// followed by a select without ORDER BY, LIMIT and OFFSET, and returns
+// false when the next tokens do not start one.
+func (p *Parser) parseSetOperation() (statement.SetOperation, bool, error) {
+	var op statement.SetOperation
+	switch {
+	case p.acceptKeyword("UNION"):
+		op.Operator = statement.Union
+	case p.acceptKeyword("INTERSECT"):
+		op.Operator = statement.Intersect
+	case p.acceptKeyword("EXCEPT"):
+		op.Operator = statement.Except
+	default:
+		return op, false, nil
+	}
+	if !p.acceptKeyword("DISTINCT") {
+		op.All = p.acceptKeyword("ALL")
+	}
+
+	start := p.peek()
+	cfg := statement.SelectStatementConfig{}
+	if err := p.parseSelectCore(&cfg); err != nil {
+		return op, false, err
+	}
+	stmt, err := statement.NewSelectStatement(cfg)
+	if err != nil {
+		return op, false, p.invalid(start, err)
+	}
+	op.Select = stmt
+	return op, true, nil
+}
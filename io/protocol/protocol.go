@@ -95,6 +95,11 @@ const (
 	GetConfig    MessageType = 131
 	ReloadConfig MessageType = 132
 
+	// Prepared Statements
+	Prepare    MessageType = 140
+	Execute    MessageType = 141
+	Deallocate MessageType = 142
+
 	// Custom Commands
 	CustomCommand MessageType = 200
 )
@@ -207,6 +212,11 @@ var messageTypeNames = map[MessageType]string{
 	GetConfig:    "GetConfig",
 	ReloadConfig: "ReloadConfig",
 
+	// Prepared Statements
+	Prepare:    "Prepare",
+	Execute:    "Execute",
+	Deallocate: "Deallocate",
+
 	// Custom Commands
 	CustomCommand: "CustomCommand",
 }
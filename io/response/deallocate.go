@@ -0,0 +1,44 @@
+package response
+
+import (
+	"fmt"
+
+	"github.com/onnasoft/ZenithSQL/io/protocol"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type DeallocateResponse struct {
+	Success bool   `msgpack:"success"`
+	Message string `msgpack:"message"`
+}
+
+func NewDeallocateResponse(success bool, message string) *DeallocateResponse {
+	return &DeallocateResponse{
+		Success: success,
+		Message: message,
+	}
+}
+
+func (r *DeallocateResponse) IsSuccess() bool {
+	return r.Success
+}
+
+func (r *DeallocateResponse) GetMessage() string {
+	return r.Message
+}
+
+func (r *DeallocateResponse) Protocol() protocol.MessageType {
+	return protocol.Deallocate
+}
+
+func (r *DeallocateResponse) FromBytes(data []byte) error {
+	return msgpack.Unmarshal(data, r)
+}
+
+func (r *DeallocateResponse) ToBytes() ([]byte, error) {
+	return msgpack.Marshal(r)
+}
+
+func (r *DeallocateResponse) String() string {
+	return fmt.Sprintf("DeallocateResponse{Success: %t, Message: %s}", r.Success, r.Message)
+}
@@ -8,14 +8,18 @@ import (
 )
 
 type DeleteResponse struct {
-	Success bool   `msgpack:"success"`
-	Message string `msgpack:"message"`
+	Success      bool   `msgpack:"success"`
+	Message      string `msgpack:"message"`
+	RowsAffected int64  `msgpack:"rows_affected"`
+	DurationMs   int64  `msgpack:"duration_ms"`
 }
 
-func NewDeleteResponse(success bool, message string) *DeleteResponse {
+func NewDeleteResponse(success bool, message string, rowsAffected int64, durationMs int64) *DeleteResponse {
 	return &DeleteResponse{
-		Success: success,
-		Message: message,
+		Success:      success,
+		Message:      message,
+		RowsAffected: rowsAffected,
+		DurationMs:   durationMs,
 	}
 }
 
@@ -27,6 +31,14 @@ func (r *DeleteResponse) GetMessage() string {
 	return r.Message
 }
 
+func (r *DeleteResponse) GetRowsAffected() int64 {
+	return r.RowsAffected
+}
+
+func (r *DeleteResponse) GetDurationMs() int64 {
+	return r.DurationMs
+}
+
 func (r *DeleteResponse) Protocol() protocol.MessageType {
 	return protocol.Delete
 }
@@ -40,5 +52,5 @@ func (r *DeleteResponse) ToBytes() ([]byte, error) {
 }
 
 func (r *DeleteResponse) String() string {
-	return fmt.Sprintf("DeleteResponse{Success: %t, Message: %s}", r.Success, r.Message)
+	return fmt.Sprintf("DeleteResponse{Success: %t, Rows: %d, Duration: %dms, Message: %s}", r.Success, r.RowsAffected, r.DurationMs, r.Message)
 }
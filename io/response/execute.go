@@ -0,0 +1,81 @@
+package response
+
+import (
+	"fmt"
+
+	"github.com/onnasoft/ZenithSQL/io/protocol"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ExecuteResponse reports the execution of a prepared statement. Result is
+// the response of the prepared statement itself, such as a SelectResponse,
+// and is nil when the statement could not be run.
+type ExecuteResponse struct {
+	Success bool     `msgpack:"success"`
+	Message string   `msgpack:"message"`
+	Result  Response `msgpack:"-"`
+}
+
+type executeResponseWire struct {
+	Success bool         `msgpack:"success"`
+	Message string       `msgpack:"message"`
+	Result  *queryResult `msgpack:"result"`
+}
+
+func NewExecuteResponse(success bool, message string, result Response) *ExecuteResponse {
+	return &ExecuteResponse{
+		Success: success,
+		Message: message,
+		Result:  result,
+	}
+}
+
+func (r *ExecuteResponse) IsSuccess() bool {
+	return r.Success
+}
+
+func (r *ExecuteResponse) GetMessage() string {
+	return r.Message
+}
+
+func (r *ExecuteResponse) Protocol() protocol.MessageType {
+	return protocol.Execute
+}
+
+func (r *ExecuteResponse) FromBytes(data []byte) error {
+	var wire executeResponseWire
+	if err := msgpack.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	var result Response
+	if wire.Result != nil {
+		resp, err := Deserialize(wire.Result.Type, wire.Result.Payload)
+		if err != nil {
+			return fmt.Errorf("result: %w", err)
+		}
+		result = resp
+	}
+
+	r.Success, r.Message, r.Result = wire.Success, wire.Message, result
+	return nil
+}
+
+func (r *ExecuteResponse) ToBytes() ([]byte, error) {
+	wire := executeResponseWire{
+		Success: r.Success,
+		Message: r.Message,
+	}
+	if r.Result != nil {
+		payload, err := r.Result.ToBytes()
+		if err != nil {
+			return nil, fmt.Errorf("result: %w", err)
+		}
+		wire.Result = &queryResult{Type: r.Result.Protocol(), Payload: payload}
+	}
+	return msgpack.Marshal(wire)
+}
+
+func (r *ExecuteResponse) String() string {
+	return fmt.Sprintf("ExecuteResponse{Success: %t, Message: %s, Result: %v}", r.Success, r.Message, r.Result)
+}
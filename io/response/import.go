@@ -12,7 +12,10 @@ type ImportResponse struct {
 	Success      bool   `msgpack:"success"`
 	Message      string `msgpack:"message"`
 	RowsImported int64  `msgpack:"rows_imported"`
-	DurationMs   int64  `msgpack:"duration_ms"`
+	// RowsUpdated cuenta las filas que actualizó una importación con
+	// upsert, que no están en RowsImported
+	RowsUpdated int64 `msgpack:"rows_updated,omitempty"`
+	DurationMs  int64 `msgpack:"duration_ms"`
 }
 
 // NewImportResponse crea una nueva respuesta de importación
@@ -63,9 +66,10 @@ func (r *ImportResponse) ToBytes() ([]byte, error) {
 // String representa la respuesta como string
 func (r *ImportResponse) String() string {
 	return fmt.Sprintf(
-		"ImportResponse{Success: %t, Rows: %d, Duration: %dms, Message: %s}",
+		"ImportResponse{Success: %t, Rows: %d, Updated: %d, Duration: %dms, Message: %s}",
 		r.Success,
 		r.RowsImported,
+		r.RowsUpdated,
 		r.DurationMs,
 		r.Message,
 	)
@@ -102,6 +102,11 @@ var responseMap = map[protocol.MessageType]responseConstructor{
 	protocol.GetConfig:    func() Response { return &GetConfigResponse{} },
 	protocol.ReloadConfig: func() Response { return &ReloadConfigResponse{} },
 
+	// Prepared Statements
+	protocol.Prepare:    func() Response { return &PrepareResponse{} },
+	protocol.Execute:    func() Response { return &ExecuteResponse{} },
+	protocol.Deallocate: func() Response { return &DeallocateResponse{} },
+
 	// Custom Commands
 	protocol.CustomCommand: func() Response { return &CustomCommandResponse{} },
 }
@@ -0,0 +1,50 @@
+package response
+
+import (
+	"fmt"
+
+	"github.com/onnasoft/ZenithSQL/io/protocol"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// PrepareResponse reports a prepared statement and the number of positional
+// parameters every execution has to bind.
+type PrepareResponse struct {
+	Success    bool   `msgpack:"success"`
+	Message    string `msgpack:"message"`
+	Name       string `msgpack:"name"`
+	ParamCount int    `msgpack:"param_count"`
+}
+
+func NewPrepareResponse(success bool, message, name string, paramCount int) *PrepareResponse {
+	return &PrepareResponse{
+		Success:    success,
+		Message:    message,
+		Name:       name,
+		ParamCount: paramCount,
+	}
+}
+
+func (r *PrepareResponse) IsSuccess() bool {
+	return r.Success
+}
+
+func (r *PrepareResponse) GetMessage() string {
+	return r.Message
+}
+
+func (r *PrepareResponse) Protocol() protocol.MessageType {
+	return protocol.Prepare
+}
+
+func (r *PrepareResponse) FromBytes(data []byte) error {
+	return msgpack.Unmarshal(data, r)
+}
+
+func (r *PrepareResponse) ToBytes() ([]byte, error) {
+	return msgpack.Marshal(r)
+}
+
+func (r *PrepareResponse) String() string {
+	return fmt.Sprintf("PrepareResponse{Success: %t, Message: %s, Name: %s, ParamCount: %d}", r.Success, r.Message, r.Name, r.ParamCount)
+}
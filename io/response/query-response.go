@@ -7,10 +7,27 @@ import (
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// QueryResponse reports the outcome of a QueryStatement. Results holds the
+// response of every executed statement, in order.
 type QueryResponse struct {
 	Success bool        `msgpack:"success"`
 	Message string      `msgpack:"message"`
 	Data    interface{} `msgpack:"data"`
+	Results []Response  `msgpack:"-"`
+}
+
+// queryResult is the wire form of an entry of QueryResponse.Results; the
+// message type tells how to decode the payload.
+type queryResult struct {
+	Type    protocol.MessageType `msgpack:"type"`
+	Payload []byte               `msgpack:"payload"`
+}
+
+type queryResponseWire struct {
+	Success bool          `msgpack:"success"`
+	Message string        `msgpack:"message"`
+	Data    interface{}   `msgpack:"data"`
+	Results []queryResult `msgpack:"results"`
 }
 
 func NewQueryResponse(success bool, message string, data interface{}) *QueryResponse {
@@ -34,13 +51,41 @@ func (r *QueryResponse) Protocol() protocol.MessageType {
 }
 
 func (r *QueryResponse) FromBytes(data []byte) error {
-	return msgpack.Unmarshal(data, r)
+	var wire queryResponseWire
+	if err := msgpack.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	results := make([]Response, len(wire.Results))
+	for i, result := range wire.Results {
+		resp, err := Deserialize(result.Type, result.Payload)
+		if err != nil {
+			return fmt.Errorf("result %d: %w", i+1, err)
+		}
+		results[i] = resp
+	}
+
+	r.Success, r.Message, r.Data, r.Results = wire.Success, wire.Message, wire.Data, results
+	return nil
 }
 
 func (r *QueryResponse) ToBytes() ([]byte, error) {
-	return msgpack.Marshal(r)
+	wire := queryResponseWire{
+		Success: r.Success,
+		Message: r.Message,
+		Data:    r.Data,
+		Results: make([]queryResult, len(r.Results)),
+	}
+	for i, resp := range r.Results {
+		payload, err := resp.ToBytes()
+		if err != nil {
+			return nil, fmt.Errorf("result %d: %w", i+1, err)
+		}
+		wire.Results[i] = queryResult{Type: resp.Protocol(), Payload: payload}
+	}
+	return msgpack.Marshal(wire)
 }
 
 func (r *QueryResponse) String() string {
-	return fmt.Sprintf("QueryResponse{Success: %t, Message: %s, Data: %v}", r.Success, r.Message, r.Data)
+	return fmt.Sprintf("QueryResponse{Success: %t, Message: %s, Data: %v, Results: %v}", r.Success, r.Message, r.Data, r.Results)
 }
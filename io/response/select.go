@@ -3,6 +3,7 @@ package response
 import (
 	"fmt"
 
+	"github.com/onnasoft/ZenithSQL/core/storage"
 	"github.com/onnasoft/ZenithSQL/io/protocol"
 	"github.com/vmihailenco/msgpack/v5"
 )
@@ -11,6 +12,10 @@ type SelectResponse struct {
 	Success bool                     `msgpack:"success"`
 	Message string                   `msgpack:"message"`
 	Rows    []map[string]interface{} `msgpack:"rows"`
+	// Columns names the columns of the rows in the order selected.
+	Columns []string `msgpack:"columns,omitempty"`
+	// Plan is set instead of Rows when the statement asked for EXPLAIN.
+	Plan *storage.PlanNode `msgpack:"plan,omitempty"`
 }
 
 func NewSelectResponse(success bool, message string, rows []map[string]interface{}) *SelectResponse {
@@ -21,6 +26,16 @@ func NewSelectResponse(success bool, message string, rows []map[string]interface
 	}
 }
 
+// NewExplainResponse returns the plan of an EXPLAIN statement.
+func NewExplainResponse(plan *storage.PlanNode) *SelectResponse {
+	return &SelectResponse{
+		Success: true,
+		Message: "Select explained successfully",
+		Rows:    []map[string]interface{}{},
+		Plan:    plan,
+	}
+}
+
 func (r *SelectResponse) IsSuccess() bool {
 	return r.Success
 }
@@ -42,5 +57,8 @@ func (r *SelectResponse) ToBytes() ([]byte, error) {
 }
 
 func (r *SelectResponse) String() string {
+	if r.Plan != nil {
+		return fmt.Sprintf("SelectResponse{Success: %t, Message: %s, Plan:\n%s}", r.Success, r.Message, r.Plan)
+	}
 	return fmt.Sprintf("SelectResponse{Success: %t, Message: %s, Rows: %v}", r.Success, r.Message, r.Rows)
 }
@@ -8,14 +8,18 @@ import (
 )
 
 type UpdateResponse struct {
-	Success bool   `msgpack:"success"`
-	Message string `msgpack:"message"`
+	Success      bool   `msgpack:"success"`
+	Message      string `msgpack:"message"`
+	RowsAffected int64  `msgpack:"rows_affected"`
+	DurationMs   int64  `msgpack:"duration_ms"`
 }
 
-func NewUpdateResponse(success bool, message string) *UpdateResponse {
+func NewUpdateResponse(success bool, message string, rowsAffected int64, durationMs int64) *UpdateResponse {
 	return &UpdateResponse{
-		Success: success,
-		Message: message,
+		Success:      success,
+		Message:      message,
+		RowsAffected: rowsAffected,
+		DurationMs:   durationMs,
 	}
 }
 
@@ -27,6 +31,14 @@ func (r *UpdateResponse) GetMessage() string {
 	return r.Message
 }
 
+func (r *UpdateResponse) GetRowsAffected() int64 {
+	return r.RowsAffected
+}
+
+func (r *UpdateResponse) GetDurationMs() int64 {
+	return r.DurationMs
+}
+
 func (r *UpdateResponse) Protocol() protocol.MessageType {
 	return protocol.Update
 }
@@ -40,5 +52,5 @@ func (r *UpdateResponse) ToBytes() ([]byte, error) {
 }
 
 func (r *UpdateResponse) String() string {
-	return fmt.Sprintf("UpdateResponse{Success: %t, Message: %s}", r.Success, r.Message)
+	return fmt.Sprintf("UpdateResponse{Success: %t, Rows: %d, Duration: %dms, Message: %s}", r.Success, r.RowsAffected, r.DurationMs, r.Message)
 }
@@ -7,15 +7,46 @@ import (
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// UpsertAction is what an upsert did with a row.
+type UpsertAction string
+
+const (
+	Inserted  UpsertAction = "inserted"
+	Updated   UpsertAction = "updated"
+	Unchanged UpsertAction = "unchanged"
+)
+
+// UpsertResult is what an upsert did with a row, in the order of the rows
+// of the statement: the id of the row inserted, or of the row of the table
+// it conflicted with.
+type UpsertResult struct {
+	ID     int64        `msgpack:"id"`
+	Action UpsertAction `msgpack:"action"`
+}
+
 type UpsertResponse struct {
-	Success bool   `msgpack:"success"`
-	Message string `msgpack:"message"`
+	Success      bool           `msgpack:"success"`
+	Message      string         `msgpack:"message"`
+	Results      []UpsertResult `msgpack:"results"`
+	RowsAffected int64          `msgpack:"rows_affected"`
+	DurationMs   int64          `msgpack:"duration_ms"`
 }
 
-func NewUpsertResponse(success bool, message string) *UpsertResponse {
+// NewUpsertResponse counts as affected the rows of results inserted or
+// updated.
+func NewUpsertResponse(success bool, message string, results []UpsertResult, durationMs int64) *UpsertResponse {
+	var affected int64
+	for _, result := range results {
+		if result.Action != Unchanged {
+			affected++
+		}
+	}
 	return &UpsertResponse{
-		Success: success,
-		Message: message,
+		Success:      success,
+		Message:      message,
+		Results:      results,
+		RowsAffected: affected,
+		DurationMs:   durationMs,
 	}
 }
 
@@ -27,6 +58,18 @@ func (r *UpsertResponse) GetMessage() string {
 	return r.Message
 }
 
+func (r *UpsertResponse) GetResults() []UpsertResult {
+	return r.Results
+}
+
+func (r *UpsertResponse) GetRowsAffected() int64 {
+	return r.RowsAffected
+}
+
+func (r *UpsertResponse) GetDurationMs() int64 {
+	return r.DurationMs
+}
+
 func (r *UpsertResponse) Protocol() protocol.MessageType {
 	return protocol.Upsert
 }
@@ -40,5 +83,5 @@ func (r *UpsertResponse) ToBytes() ([]byte, error) {
 }
 
 func (r *UpsertResponse) String() string {
-	return fmt.Sprintf("UpsertResponse{Success: %t, Message: %s}", r.Success, r.Message)
+	return fmt.Sprintf("UpsertResponse{Success: %t, Rows: %d, Duration: %dms, Results: %v, Message: %s}", r.Success, r.RowsAffected, r.DurationMs, r.Results, r.Message)
 }
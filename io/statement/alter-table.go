@@ -1,28 +1,70 @@
 package statement
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/onnasoft/ZenithSQL/io/protocol"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+type AlterAction string
+
+const (
+	AddEnumValues AlterAction = "ADD_ENUM_VALUES"
+)
+
+type AlterTableChange struct {
+	Action AlterAction `msgpack:"action" valid:"required,matches(^(ADD_ENUM_VALUES)$)"`
+	Column string      `msgpack:"column" valid:"required,alphanumunderscore"`
+	Values []string    `msgpack:"values"`
+}
+
 type AlterTableStatement struct {
-	TableName string `msgpack:"table_name" valid:"required,alphanumunderscore"`
-	Changes   string `msgpack:"changes" valid:"required"`
+	Database  string             `msgpack:"database" valid:"required,alphanumunderscore"`
+	Schema    string             `msgpack:"schema" valid:"required,alphanumunderscore"`
+	TableName string             `msgpack:"table_name" valid:"required,alphanumunderscore"`
+	Changes   []AlterTableChange `msgpack:"changes"`
 }
 
-func NewAlterTableStatement(tableName, changes string) (*AlterTableStatement, error) {
-	stmt := &AlterTableStatement{TableName: tableName, Changes: changes}
+func NewAlterTableStatement(database, schema, tableName string, changes ...AlterTableChange) (*AlterTableStatement, error) {
+	stmt := &AlterTableStatement{
+		Database:  database,
+		Schema:    schema,
+		TableName: tableName,
+		Changes:   changes,
+	}
 
-	if _, err := govalidator.ValidateStruct(stmt); err != nil {
+	if err := stmt.validate(); err != nil {
 		return nil, err
 	}
 
 	return stmt, nil
 }
 
+func (a *AlterTableStatement) validate() error {
+	if _, err := govalidator.ValidateStruct(a); err != nil {
+		return err
+	}
+
+	if len(a.Changes) == 0 {
+		return errors.New("at least one change is required")
+	}
+
+	for _, change := range a.Changes {
+		if _, err := govalidator.ValidateStruct(change); err != nil {
+			return err
+		}
+		if change.Action == AddEnumValues && len(change.Values) == 0 {
+			return fmt.Errorf("%s requires at least one value", change.Action)
+		}
+	}
+
+	return nil
+}
+
 func (a AlterTableStatement) Protocol() protocol.MessageType {
 	return protocol.AlterTable
 }
@@ -32,9 +74,16 @@ func (a AlterTableStatement) ToBytes() ([]byte, error) {
 }
 
 func (a *AlterTableStatement) FromBytes(data []byte) error {
-	return msgpack.Unmarshal(data, a)
+	if err := msgpack.Unmarshal(data, a); err != nil {
+		return err
+	}
+	return a.validate()
 }
 
 func (a AlterTableStatement) String() string {
-	return fmt.Sprintf("AlterTableStatement{TableName: %s, Changes: %s}", a.TableName, a.Changes)
+	changes := make([]string, len(a.Changes))
+	for i, change := range a.Changes {
+		changes[i] = fmt.Sprintf("%s %s (%s)", change.Action, change.Column, strings.Join(change.Values, ", "))
+	}
+	return fmt.Sprintf("AlterTableStatement{TableName: %s, Changes: [%s]}", a.TableName, strings.Join(changes, ", "))
 }
@@ -8,14 +8,23 @@ import (
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// CreateIndexStatement builds an index on a column. Using selects the kind
+// of index (hash, zonemap, hnsw or grid) and defaults to the best fit for
+// the column type; Options tune it, such as the metric of an HNSW index.
+// A Unique index rejects rows holding a value another row holds.
 type CreateIndexStatement struct {
-	IndexName string   `msgpack:"index_name" valid:"required,alphanumunderscore"`
-	TableName string   `msgpack:"table_name" valid:"required,alphanumunderscore"`
-	Columns   []string `msgpack:"columns" valid:"required"`
+	Database  string                 `msgpack:"database" valid:"required,alphanumunderscore"`
+	Schema    string                 `msgpack:"schema" valid:"required,alphanumunderscore"`
+	IndexName string                 `msgpack:"index_name" valid:"required,alphanumunderscore"`
+	TableName string                 `msgpack:"table_name" valid:"required,alphanumunderscore"`
+	Columns   []string               `msgpack:"columns" valid:"required"`
+	Using     string                 `msgpack:"using" valid:"in(hash|zonemap|hnsw|grid)"`
+	Unique    bool                   `msgpack:"unique"`
+	Options   map[string]interface{} `msgpack:"options"`
 }
 
-func NewCreateIndexStatement(indexName, tableName string, columns []string) (*CreateIndexStatement, error) {
-	stmt := &CreateIndexStatement{IndexName: indexName, TableName: tableName, Columns: columns}
+func NewCreateIndexStatement(database, schema, indexName, tableName string, columns []string, using string, unique bool, options map[string]interface{}) (*CreateIndexStatement, error) {
+	stmt := &CreateIndexStatement{Database: database, Schema: schema, IndexName: indexName, TableName: tableName, Columns: columns, Using: using, Unique: unique, Options: options}
 
 	if _, err := govalidator.ValidateStruct(stmt); err != nil {
 		return nil, err
@@ -37,5 +46,5 @@ func (c *CreateIndexStatement) FromBytes(data []byte) error {
 }
 
 func (c CreateIndexStatement) String() string {
-	return fmt.Sprintf("CreateIndexStatement{IndexName: %s, TableName: %s, Columns: %v}", c.IndexName, c.TableName, c.Columns)
+	return fmt.Sprintf("CreateIndexStatement{IndexName: %s, TableName: %s, Columns: %v, Using: %s, Unique: %t, Options: %v}", c.IndexName, c.TableName, c.Columns, c.Using, c.Unique, c.Options)
 }
@@ -20,6 +20,8 @@ type CreateTableStatement struct {
 
 func NewCreateTableStatement(database, schema, tableName string, columns []fields.FieldMeta, storage string) (*CreateTableStatement, error) {
 	stmt := &CreateTableStatement{
+		Database:   database,
+		Schema:     schema,
 		TableName:  tableName,
 		FieldsMeta: columns,
 		Storage:    storage,
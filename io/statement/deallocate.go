@@ -0,0 +1,42 @@
+package statement
+
+import (
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/io/protocol"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// DeallocateStatement discards the prepared statement Name.
+type DeallocateStatement struct {
+	Name string `msgpack:"name" valid:"required,alphanumunderscore"`
+}
+
+func NewDeallocateStatement(name string) (*DeallocateStatement, error) {
+	stmt := &DeallocateStatement{
+		Name: name,
+	}
+
+	if _, err := govalidator.ValidateStruct(stmt); err != nil {
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+func (d DeallocateStatement) Protocol() protocol.MessageType {
+	return protocol.Deallocate
+}
+
+func (d DeallocateStatement) ToBytes() ([]byte, error) {
+	return msgpack.Marshal(d)
+}
+
+func (d *DeallocateStatement) FromBytes(data []byte) error {
+	return msgpack.Unmarshal(data, d)
+}
+
+func (d DeallocateStatement) String() string {
+	return fmt.Sprintf("DeallocateStatement{Name: %s}", d.Name)
+}
@@ -4,19 +4,33 @@ import (
 	"fmt"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/io/filters"
 	"github.com/onnasoft/ZenithSQL/io/protocol"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// DeleteStatement marks the rows matching Where, or every row without it,
+// as deleted by setting their deleted_at, which hides them from selects.
+// With Purge it instead removes from the table files the values of the
+// deleted rows matching Where, reclaiming the space of the values stored
+// outside the rows, which are those of arrays. Every id is kept, and none
+// is given again, so the rows purged still take the fixed width of their
+// columns.
 type DeleteStatement struct {
-	TableName string `msgpack:"table_name" valid:"required,alphanumunderscore"`
-	Where     string `msgpack:"where"`
+	Database  string          `msgpack:"database"   valid:"required,alphanumunderscore"`
+	Schema    string          `msgpack:"schema"     valid:"required,alphanumunderscore"`
+	TableName string          `msgpack:"table_name" valid:"required,alphanumunderscore"`
+	Where     *filters.Filter `msgpack:"where"`
+	Purge     bool            `msgpack:"purge"`
 }
 
-func NewDeleteStatement(tableName, where string) (*DeleteStatement, error) {
+func NewDeleteStatement(database, schema, tableName string, where *filters.Filter, purge bool) (*DeleteStatement, error) {
 	stmt := &DeleteStatement{
+		Database:  database,
+		Schema:    schema,
 		TableName: tableName,
 		Where:     where,
+		Purge:     purge,
 	}
 
 	if _, err := govalidator.ValidateStruct(stmt); err != nil {
@@ -39,5 +53,5 @@ func (d *DeleteStatement) FromBytes(data []byte) error {
 }
 
 func (d DeleteStatement) String() string {
-	return fmt.Sprintf("DeleteStatement{TableName: %s, Where: %s}", d.TableName, d.Where)
+	return fmt.Sprintf("DeleteStatement{TableName: %s, Where: %s, Purge: %t}", d.TableName, d.Where, d.Purge)
 }
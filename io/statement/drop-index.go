@@ -9,12 +9,14 @@ import (
 )
 
 type DropIndexStatement struct {
+	Database  string `msgpack:"database" valid:"required,alphanumunderscore"`
+	Schema    string `msgpack:"schema" valid:"required,alphanumunderscore"`
 	IndexName string `msgpack:"index_name" valid:"required,alphanumunderscore"`
 	TableName string `msgpack:"table_name" valid:"required,alphanumunderscore"`
 }
 
-func NewDropIndexStatement(indexName, tableName string) (*DropIndexStatement, error) {
-	stmt := &DropIndexStatement{IndexName: indexName, TableName: tableName}
+func NewDropIndexStatement(database, schema, indexName, tableName string) (*DropIndexStatement, error) {
+	stmt := &DropIndexStatement{Database: database, Schema: schema, IndexName: indexName, TableName: tableName}
 
 	if _, err := govalidator.ValidateStruct(stmt); err != nil {
 		return nil, err
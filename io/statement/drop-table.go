@@ -14,8 +14,8 @@ type DropTableStatement struct {
 	TableName string `msgpack:"table_name" valid:"required,alphanumunderscore"`
 }
 
-func NewDropTableStatement(tableName string) (*DropTableStatement, error) {
-	stmt := &DropTableStatement{TableName: tableName}
+func NewDropTableStatement(database, schema, tableName string) (*DropTableStatement, error) {
+	stmt := &DropTableStatement{Database: database, Schema: schema, TableName: tableName}
 
 	if _, err := govalidator.ValidateStruct(stmt); err != nil {
 		return nil, err
@@ -0,0 +1,45 @@
+package statement
+
+import (
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/io/protocol"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ExecuteStatement runs the prepared statement Name, binding Params to its
+// positional parameters in order.
+type ExecuteStatement struct {
+	Name   string        `msgpack:"name" valid:"required,alphanumunderscore"`
+	Params []interface{} `msgpack:"params"`
+}
+
+func NewExecuteStatement(name string, params ...interface{}) (*ExecuteStatement, error) {
+	stmt := &ExecuteStatement{
+		Name:   name,
+		Params: params,
+	}
+
+	if _, err := govalidator.ValidateStruct(stmt); err != nil {
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+func (e ExecuteStatement) Protocol() protocol.MessageType {
+	return protocol.Execute
+}
+
+func (e ExecuteStatement) ToBytes() ([]byte, error) {
+	return msgpack.Marshal(e)
+}
+
+func (e *ExecuteStatement) FromBytes(data []byte) error {
+	return msgpack.Unmarshal(data, e)
+}
+
+func (e ExecuteStatement) String() string {
+	return fmt.Sprintf("ExecuteStatement{Name: %s, Params: %v}", e.Name, e.Params)
+}
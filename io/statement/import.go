@@ -13,6 +13,8 @@ type ImportStatement struct {
 	Schema    string                   `valid:"required,alphanumunderscore" msgpack:"schema"`
 	TableName string                   `valid:"required,alphanumunderscore" msgpack:"table_name"`
 	Values    []map[string]interface{} `msgpack:"values"`
+	// OnConflict upserts the rows instead of inserting them.
+	OnConflict *ConflictClause `msgpack:"on_conflict,omitempty"`
 }
 
 func NewImportStatement(database, schema, tableName string, values []map[string]interface{}) (*ImportStatement, error) {
@@ -30,6 +32,21 @@ func NewImportStatement(database, schema, tableName string, values []map[string]
 	return stmt, nil
 }
 
+// NewImportUpsertStatement imports values as an upsert: the rows
+// conflicting with rows of the table are updated or skipped by onConflict.
+func NewImportUpsertStatement(database, schema, tableName string, values []map[string]interface{}, onConflict ConflictClause) (*ImportStatement, error) {
+	stmt, err := NewImportStatement(database, schema, tableName, values)
+	if err != nil {
+		return nil, err
+	}
+	if err := onConflict.validate(values); err != nil {
+		return nil, err
+	}
+	stmt.OnConflict = &onConflict
+
+	return stmt, nil
+}
+
 func (i ImportStatement) Protocol() protocol.MessageType {
 	return protocol.Insert
 }
@@ -0,0 +1,14 @@
+package statement
+
+import "fmt"
+
+// Param is a positional parameter of a prepared statement. The parser puts
+// it wherever a value is expected and the executor replaces it with the
+// bound value before running the statement. Index is zero based.
+type Param struct {
+	Index int
+}
+
+func (p Param) String() string {
+	return fmt.Sprintf("$%d", p.Index+1)
+}
@@ -98,6 +98,11 @@ var statementMap = map[protocol.MessageType]statementConstructor{
 	protocol.SetConfig:    func() Statement { return &SetConfigStatement{} },
 	protocol.GetConfig:    func() Statement { return &GetConfigStatement{} },
 	protocol.ReloadConfig: func() Statement { return &ReloadConfigStatement{} },
+
+	// Prepared Statements
+	protocol.Prepare:    func() Statement { return &PrepareStatement{} },
+	protocol.Execute:    func() Statement { return &ExecuteStatement{} },
+	protocol.Deallocate: func() Statement { return &DeallocateStatement{} },
 }
 
 func Deserialize(messageType protocol.MessageType, data []byte) (Statement, error) {
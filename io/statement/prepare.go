@@ -0,0 +1,51 @@
+package statement
+
+import (
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/io/protocol"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// PrepareStatement asks the server to parse Query once and keep it under
+// Name for the rest of the session. Query may hold positional parameters,
+// written as ? or $1, $2, ..., whose values are supplied by each
+// ExecuteStatement.
+type PrepareStatement struct {
+	Name     string `msgpack:"name" valid:"required,alphanumunderscore"`
+	Database string `msgpack:"database" valid:"alphanumunderscore"`
+	Schema   string `msgpack:"schema" valid:"alphanumunderscore"`
+	Query    string `msgpack:"query" valid:"required"`
+}
+
+func NewPrepareStatement(name, database, schema, query string) (*PrepareStatement, error) {
+	stmt := &PrepareStatement{
+		Name:     name,
+		Database: database,
+		Schema:   schema,
+		Query:    query,
+	}
+
+	if _, err := govalidator.ValidateStruct(stmt); err != nil {
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+func (p PrepareStatement) Protocol() protocol.MessageType {
+	return protocol.Prepare
+}
+
+func (p PrepareStatement) ToBytes() ([]byte, error) {
+	return msgpack.Marshal(p)
+}
+
+func (p *PrepareStatement) FromBytes(data []byte) error {
+	return msgpack.Unmarshal(data, p)
+}
+
+func (p PrepareStatement) String() string {
+	return fmt.Sprintf("PrepareStatement{Name: %s, Database: %s, Schema: %s, Query: %s}", p.Name, p.Database, p.Schema, p.Query)
+}
@@ -8,13 +8,20 @@ import (
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// QueryStatement carries SQL text, possibly holding several statements
+// separated by semicolons. Database and Schema qualify unqualified table
+// names used in the query.
 type QueryStatement struct {
-	Query string `msgpack:"query" valid:"required"`
+	Database string `msgpack:"database" valid:"alphanumunderscore"`
+	Schema   string `msgpack:"schema" valid:"alphanumunderscore"`
+	Query    string `msgpack:"query" valid:"required"`
 }
 
-func NewQueryStatement(query string) (*QueryStatement, error) {
+func NewQueryStatement(database, schema, query string) (*QueryStatement, error) {
 	stmt := &QueryStatement{
-		Query: query,
+		Database: database,
+		Schema:   schema,
+		Query:    query,
 	}
 
 	if _, err := govalidator.ValidateStruct(stmt); err != nil {
@@ -37,5 +44,5 @@ func (q *QueryStatement) FromBytes(data []byte) error {
 }
 
 func (q QueryStatement) String() string {
-	return fmt.Sprintf("QueryStatement{Query: %s}", q.Query)
+	return fmt.Sprintf("QueryStatement{Database: %s, Schema: %s, Query: %s}", q.Database, q.Schema, q.Query)
 }
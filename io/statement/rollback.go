@@ -10,6 +10,9 @@ import (
 
 type RollbackStatement struct {
 	TransactionID string `msgpack:"transaction_id" valid:"required,alphanumunderscore"`
+	// SavepointName, when set, rolls back to the savepoint instead of
+	// aborting the whole transaction.
+	SavepointName string `msgpack:"savepoint_name" valid:"alphanumunderscore"`
 }
 
 func NewRollbackStatement(transactionID string) (*RollbackStatement, error) {
@@ -37,5 +40,5 @@ func (r *RollbackStatement) FromBytes(data []byte) error {
 }
 
 func (r RollbackStatement) String() string {
-	return fmt.Sprintf("RollbackStatement{TransactionID: %s}", r.TransactionID)
+	return fmt.Sprintf("RollbackStatement{TransactionID: %s, SavepointName: %s}", r.TransactionID, r.SavepointName)
 }
@@ -2,62 +2,315 @@ package statement
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/io/expression"
 	"github.com/onnasoft/ZenithSQL/io/filters"
 	"github.com/onnasoft/ZenithSQL/io/protocol"
 	"github.com/onnasoft/ZenithSQL/model/aggregate"
+	"github.com/onnasoft/ZenithSQL/model/fields"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
 type Aggregation struct {
-	Function aggregate.AggregateType `msgpack:"function" valid:"required,matches(^(SUM|AVG|COUNT|MAX|MIN|GROUP_CONCAT)$)"`
+	Function aggregate.AggregateType `msgpack:"function" valid:"required,matches(^(SUM|AVG|COUNT|MAX|MIN|GROUP_CONCAT|MEDIAN|MODE|STDDEV|VARIANCE|PERCENTILE|APPROX_PERCENTILE|COUNT_DISTINCT|APPROX_COUNT_DISTINCT)$)"`
 	Column   string                  `msgpack:"column" valid:"required"`
 	Alias    string                  `msgpack:"alias"`
+	// Fraction is the percentile of PERCENTILE and APPROX_PERCENTILE, from 0
+	// to 1.
+	Fraction float64 `msgpack:"fraction,omitempty"`
 }
 
+// Name is the column the aggregation is returned as: its alias, or the call
+// as written, such as COUNT(*).
+func (a Aggregation) Name() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+	return a.call()
+}
+
+func (a Aggregation) call() string {
+	if a.hasFraction() {
+		return fmt.Sprintf("%s(%s, %s)", a.Function, a.Column, strconv.FormatFloat(a.Fraction, 'g', -1, 64))
+	}
+	return fmt.Sprintf("%s(%s)", a.Function, a.Column)
+}
+
+// aggregationCall matches aggregate calls as written by Aggregation.Name.
+var aggregationCall = regexp.MustCompile(`^(\w+)\((\*|\w+(?:\.\w+)?)(?:,\s*([0-9.eE+-]+))?\)$`)
+
+// ParseAggregation reads an aggregate call such as AVG(temp) or
+// PERCENTILE(temp, 0.9), the way HAVING refers to aggregates that are not
+// selected. It reports false when name is not a valid call.
+func ParseAggregation(name string) (Aggregation, bool) {
+	m := aggregationCall.FindStringSubmatch(name)
+	if m == nil {
+		return Aggregation{}, false
+	}
+
+	agg := Aggregation{Function: aggregate.AggregateType(strings.ToUpper(m[1])), Column: m[2]}
+	if (m[3] != "") != agg.hasFraction() {
+		return Aggregation{}, false
+	}
+	if m[3] != "" {
+		f, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return Aggregation{}, false
+		}
+		agg.Fraction = f
+	}
+	if agg.validate() != nil {
+		return Aggregation{}, false
+	}
+	return agg, true
+}
+
+func (a Aggregation) validate() error {
+	if _, err := govalidator.ValidateStruct(a); err != nil {
+		return fmt.Errorf("invalid aggregation: %w", err)
+	}
+	if a.Column == "*" && a.Function != aggregate.COUNT {
+		return fmt.Errorf("%s(*) is not allowed, only COUNT(*)", a.Function)
+	}
+	if a.hasFraction() && (a.Fraction < 0 || a.Fraction > 1) {
+		return fmt.Errorf("%s fraction must be between 0 and 1", a.Function)
+	}
+	return nil
+}
+
+func (a Aggregation) hasFraction() bool {
+	return a.Function == aggregate.PERCENTILE || a.Function == aggregate.APPROX_PERCENTILE
+}
+
+// Projection is a computed select item, such as price * qty AS total. The
+// expression may use aggregate calls, named as written by Aggregation.Name
+// such as SUM(amount), which makes the select grouped.
+type Projection struct {
+	Expression *expression.Expression `msgpack:"expression"`
+	Alias      string                 `msgpack:"alias" valid:"alphanumunderscore"`
+}
+
+// Name is the column the projection is returned as: its alias, or the
+// expression as written.
+func (p Projection) Name() string {
+	if p.Alias != "" {
+		return p.Alias
+	}
+	return p.Expression.String()
+}
+
+// Aggregates reports whether the expression uses aggregate calls.
+func (p Projection) Aggregates() bool {
+	for _, column := range p.Expression.Columns() {
+		if _, ok := ParseAggregation(column); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NearestNeighbors orders the result by similarity to Vector and keeps the
+// K closest rows. Approximate allows answering from a vector index.
+type NearestNeighbors struct {
+	Column      string              `msgpack:"column" valid:"required"`
+	Vector      []float32           `msgpack:"vector"`
+	K           uint64              `msgpack:"k"`
+	Metric      fields.VectorMetric `msgpack:"metric" valid:"matches(^(cosine|l2|dot)$)"`
+	Alias       string              `msgpack:"alias"`
+	Approximate bool                `msgpack:"approximate"`
+}
+
+type JoinType string
+
+const (
+	InnerJoin JoinType = "INNER"
+	LeftJoin  JoinType = "LEFT"
+	RightJoin JoinType = "RIGHT"
+	FullJoin  JoinType = "FULL"
+	// SemiJoin and AntiJoin keep the rows of the left input that have a
+	// match, or that have none, without the columns of the right input.
+	// They run correlated EXISTS and NOT EXISTS subqueries and cannot be
+	// written as joins of a statement.
+	SemiJoin JoinType = "SEMI"
+	AntiJoin JoinType = "ANTI"
+)
+
+// JoinStrategy picks the join algorithm. AutoJoin uses a merge join when
+// both inputs are already ordered by the join columns and a hash join
+// otherwise. MergeJoin sorts the inputs that are not.
+type JoinStrategy string
+
+const (
+	AutoJoin  JoinStrategy = ""
+	HashJoin  JoinStrategy = "HASH"
+	MergeJoin JoinStrategy = "MERGE"
+)
+
+// JoinCondition requires Left and Right to be equal. Both are column names,
+// qualified by their table alias when they would be ambiguous.
+type JoinCondition struct {
+	Left  string `msgpack:"left" valid:"required"`
+	Right string `msgpack:"right" valid:"required"`
+}
+
+// Join joins another table to the rows of the FROM table and the tables
+// joined before it. Database and Schema default to those of the statement,
+// and the columns of the table are named Alias.column, or table.column
+// without an alias. IncludeDeleted also joins the deleted rows of the table.
+type Join struct {
+	Type           JoinType        `msgpack:"type" valid:"required,matches(^(INNER|LEFT|RIGHT|FULL)$)"`
+	Database       string          `msgpack:"database" valid:"alphanumunderscore"`
+	Schema         string          `msgpack:"schema" valid:"alphanumunderscore"`
+	TableName      string          `msgpack:"table_name" valid:"required,alphanumunderscore"`
+	Alias          string          `msgpack:"alias" valid:"alphanumunderscore"`
+	IncludeDeleted bool            `msgpack:"include_deleted"`
+	On             []JoinCondition `msgpack:"on"`
+	Strategy       JoinStrategy    `msgpack:"strategy" valid:"matches(^(HASH|MERGE)$)"`
+}
+
+// Name is the name the columns of the joined table are qualified with.
+func (j Join) Name() string {
+	if j.Alias != "" {
+		return j.Alias
+	}
+	return j.TableName
+}
+
+func (j Join) String() string {
+	var sb strings.Builder
+	sb.WriteString(" " + string(j.Type))
+	if j.Strategy != AutoJoin {
+		sb.WriteString(" " + string(j.Strategy))
+	}
+	sb.WriteString(fmt.Sprintf(" JOIN %s.%s.%s", j.Database, j.Schema, j.TableName))
+	if j.Alias != "" {
+		sb.WriteString(" AS " + j.Alias)
+	}
+	if j.IncludeDeleted {
+		sb.WriteString(" WITH DELETED")
+	}
+	for i, cond := range j.On {
+		if i == 0 {
+			sb.WriteString(" ON ")
+		} else {
+			sb.WriteString(" AND ")
+		}
+		sb.WriteString(cond.Left + " = " + cond.Right)
+	}
+	return sb.String()
+}
+
+// orderKeyPattern matches the ORDER BY keys kept in SelectStatement.OrderBy.
+var orderKeyPattern = regexp.MustCompile(`(?i)^\w+(\.\w+)?( (ASC|DESC))?( NULLS (FIRST|LAST))?$`)
+
 type SelectStatement struct {
-	Database     string          `msgpack:"database" valid:"required,alphanumunderscore"`
-	Schema       string          `msgpack:"schema" valid:"required,alphanumunderscore"`
-	TableName    string          `msgpack:"table_name" valid:"required,alphanumunderscore"`
-	Columns      []string        `msgpack:"columns"`
-	Aggregations []Aggregation   `msgpack:"aggregations"`
-	Where        *filters.Filter `msgpack:"where"`
-	GroupBy      []string        `msgpack:"group_by"`
-	Having       string          `msgpack:"having"`
-	Limit        uint64          `msgpack:"limit"`
-	Offset       uint64          `msgpack:"offset"`
-	OrderBy      []string        `msgpack:"order_by"`
+	// With names the selects the statement, its subqueries and the selects
+	// combined with it read like tables. Recursive lets them read
+	// themselves.
+	With      []CommonTableExpression `msgpack:"with"`
+	Recursive bool                    `msgpack:"recursive"`
+	Database  string                  `msgpack:"database" valid:"required,alphanumunderscore"`
+	Schema    string                  `msgpack:"schema" valid:"required,alphanumunderscore"`
+	// TableName is the FROM table, empty for a select without FROM, which
+	// computes its select list over a single row.
+	TableName string `msgpack:"table_name" valid:"alphanumunderscore"`
+	// Alias names the FROM table in the columns of a join.
+	Alias string `msgpack:"alias" valid:"alphanumunderscore"`
+	// IncludeDeleted also reads the rows of the FROM table whose
+	// deleted_at is set, which are hidden otherwise.
+	IncludeDeleted bool          `msgpack:"include_deleted"`
+	Joins          []Join        `msgpack:"joins"`
+	Columns        []string      `msgpack:"columns"`
+	Aggregations   []Aggregation `msgpack:"aggregations"`
+	Projections    []Projection  `msgpack:"projections"`
+	// Distinct drops the rows equal in every selected column to one
+	// returned before. ORDER BY can then only sort by selected columns.
+	Distinct bool `msgpack:"distinct"`
+	// Windows are computed after grouping and the computed columns, so
+	// their windows may use both, and ORDER BY may use their results.
+	Windows []WindowFunction `msgpack:"windows"`
+	Where   *filters.Filter  `msgpack:"where"`
+	// GroupBy names the columns rows are grouped by, which may be computed
+	// columns that use no aggregate.
+	GroupBy []string `msgpack:"group_by"`
+	// Having filters the groups. Its fields are group by columns or
+	// aggregates, named by their alias or their call such as AVG(temp).
+	Having *filters.Filter `msgpack:"having"`
+	// Limit is the number of rows kept, nil keeping all of them.
+	Limit   *uint64           `msgpack:"limit"`
+	Offset  uint64            `msgpack:"offset"`
+	OrderBy []string          `msgpack:"order_by"`
+	Unnest  []string          `msgpack:"unnest"`
+	Nearest *NearestNeighbors `msgpack:"nearest"`
+	// SetOperations combine the rows of the statement with those of other
+	// selects in order, INTERSECT binding tighter than UNION and EXCEPT.
+	// OrderBy, Limit and Offset then apply to the combined rows, named as
+	// the columns of the statement.
+	SetOperations []SetOperation `msgpack:"set_operations"`
+	// Explain returns the plan of the query instead of its rows. Analyze
+	// also runs the query and measures every step of the plan.
+	Explain bool `msgpack:"explain"`
+	Analyze bool `msgpack:"analyze"`
 }
 
 type SelectStatementConfig struct {
-	Database     string
-	Schema       string
-	TableName    string
-	Columns      []string
-	Aggregations []Aggregation
-	Where        *filters.Filter
-	GroupBy      []string
-	Having       string
-	Limit        uint64
-	Offset       uint64
-	OrderBy      []string
+	With           []CommonTableExpression
+	Recursive      bool
+	Database       string
+	Schema         string
+	TableName      string
+	Alias          string
+	IncludeDeleted bool
+	Joins          []Join
+	Columns        []string
+	Aggregations   []Aggregation
+	Projections    []Projection
+	Distinct       bool
+	Windows        []WindowFunction
+	Where          *filters.Filter
+	GroupBy        []string
+	Having         *filters.Filter
+	Limit          *uint64
+	Offset         uint64
+	OrderBy        []string
+	Unnest         []string
+	Nearest        *NearestNeighbors
+	SetOperations  []SetOperation
+	Explain        bool
+	Analyze        bool
 }
 
 func NewSelectStatement(cfg SelectStatementConfig) (*SelectStatement, error) {
 	stmt := &SelectStatement{
-		Database:     strings.TrimSpace(cfg.Database),
-		Schema:       strings.TrimSpace(cfg.Schema),
-		TableName:    strings.TrimSpace(cfg.TableName),
-		Columns:      cleanStrings(cfg.Columns),
-		Aggregations: cfg.Aggregations,
-		Where:        cfg.Where,
-		GroupBy:      cleanStrings(cfg.GroupBy),
-		Having:       strings.TrimSpace(cfg.Having),
-		Limit:        cfg.Limit,
-		Offset:       cfg.Offset,
-		OrderBy:      cleanStrings(cfg.OrderBy),
+		With:           cfg.With,
+		Recursive:      cfg.Recursive,
+		Database:       strings.TrimSpace(cfg.Database),
+		Schema:         strings.TrimSpace(cfg.Schema),
+		TableName:      strings.TrimSpace(cfg.TableName),
+		Alias:          strings.TrimSpace(cfg.Alias),
+		IncludeDeleted: cfg.IncludeDeleted,
+		Joins:          cfg.Joins,
+		Columns:        cleanStrings(cfg.Columns),
+		Aggregations:   cfg.Aggregations,
+		Projections:    cfg.Projections,
+		Distinct:       cfg.Distinct,
+		Windows:        cfg.Windows,
+		Where:          cfg.Where,
+		GroupBy:        cleanStrings(cfg.GroupBy),
+		Having:         cfg.Having,
+		Limit:          cfg.Limit,
+		Offset:         cfg.Offset,
+		OrderBy:        cleanStrings(cfg.OrderBy),
+		Unnest:         cleanStrings(cfg.Unnest),
+		Nearest:        cfg.Nearest,
+		SetOperations:  cfg.SetOperations,
+		Explain:        cfg.Explain || cfg.Analyze,
+		Analyze:        cfg.Analyze,
 	}
 
 	if err := stmt.validate(); err != nil {
@@ -72,19 +325,169 @@ func (s *SelectStatement) validate() error {
 		return fmt.Errorf("invalid statement: %w", err)
 	}
 
-	if len(s.Columns) == 0 && len(s.Aggregations) == 0 {
+	if len(s.Columns) == 0 && len(s.Aggregations) == 0 && len(s.Projections) == 0 && len(s.Windows) == 0 {
 		return fmt.Errorf("must specify columns or aggregations")
 	}
 
 	for _, agg := range s.Aggregations {
-		if _, err := govalidator.ValidateStruct(agg); err != nil {
-			return fmt.Errorf("invalid aggregation: %w", err)
+		if err := agg.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range s.Projections {
+		if p.Expression == nil {
+			return fmt.Errorf("projection requires an expression")
+		}
+		if _, err := govalidator.ValidateStruct(p); err != nil {
+			return fmt.Errorf("invalid projection: %w", err)
+		}
+	}
+
+	for _, w := range s.Windows {
+		if err := w.validate(); err != nil {
+			return err
 		}
 	}
 
+	if s.Having != nil && !s.Grouped() {
+		return fmt.Errorf("HAVING requires GROUP BY or an aggregate")
+	}
+
+	if err := s.validateSubqueries(); err != nil {
+		return err
+	}
+
+	if err := s.validateWith(); err != nil {
+		return err
+	}
+
+	if err := s.validateSetOperations(); err != nil {
+		return err
+	}
+
+	for _, key := range s.OrderBy {
+		if !orderKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid order by key %q", key)
+		}
+	}
+
+	if s.Analyze && !s.Explain {
+		return fmt.Errorf("analyze requires explain")
+	}
+
+	if err := s.validateJoins(); err != nil {
+		return err
+	}
+
+	if err := s.validateFrom(); err != nil {
+		return err
+	}
+
+	if s.Nearest != nil {
+		if _, err := govalidator.ValidateStruct(s.Nearest); err != nil {
+			return fmt.Errorf("invalid nearest neighbors clause: %w", err)
+		}
+		if len(s.Nearest.Vector) == 0 {
+			return fmt.Errorf("nearest neighbors clause requires a vector")
+		}
+		if s.Nearest.K == 0 {
+			return fmt.Errorf("nearest neighbors clause requires k greater than zero")
+		}
+		if len(s.Windows) > 0 {
+			return fmt.Errorf("nearest neighbors cannot be combined with window functions")
+		}
+		if s.Distinct {
+			return fmt.Errorf("nearest neighbors cannot be combined with DISTINCT")
+		}
+	}
+
+	return nil
+}
+
+// Grouped reports whether the select aggregates its rows: it groups them,
+// or it selects aggregates on their own, within expressions or in the
+// windows of window functions.
+func (s *SelectStatement) Grouped() bool {
+	if len(s.GroupBy) > 0 || len(s.Aggregations) > 0 {
+		return true
+	}
+	for _, p := range s.Projections {
+		if p.Aggregates() {
+			return true
+		}
+	}
+	for _, w := range s.Windows {
+		if w.Aggregates() {
+			return true
+		}
+	}
+	return false
+}
+
+// validateJoins fills in the database and schema of the joined tables and
+// checks that every table has its own name.
+func (s *SelectStatement) validateJoins() error {
+	if len(s.Joins) == 0 {
+		return nil
+	}
+	if s.Nearest != nil {
+		return fmt.Errorf("nearest neighbors cannot be combined with joins")
+	}
+
+	names := map[string]bool{s.TableAlias(): true}
+	for i := range s.Joins {
+		join := &s.Joins[i]
+		if join.Database == "" {
+			join.Database = s.Database
+		}
+		if join.Schema == "" {
+			join.Schema = s.Schema
+		}
+		if _, err := govalidator.ValidateStruct(join); err != nil {
+			return fmt.Errorf("invalid join: %w", err)
+		}
+		if len(join.On) == 0 {
+			return fmt.Errorf("join of %s requires an ON condition", join.TableName)
+		}
+		for _, cond := range join.On {
+			if _, err := govalidator.ValidateStruct(cond); err != nil {
+				return fmt.Errorf("invalid join condition: %w", err)
+			}
+		}
+		if names[join.Name()] {
+			return fmt.Errorf("table name %s specified more than once", join.Name())
+		}
+		names[join.Name()] = true
+	}
+	return nil
+}
+
+// validateFrom checks that a select without FROM reads no column.
+func (s *SelectStatement) validateFrom() error {
+	if s.TableName != "" {
+		return nil
+	}
+	switch {
+	case s.Alias != "" || s.IncludeDeleted || len(s.Joins) > 0:
+		return fmt.Errorf("a select without FROM cannot have an alias, WITH DELETED or joins")
+	case len(s.Columns) > 0:
+		return fmt.Errorf("column %s requires FROM", s.Columns[0])
+	case s.Nearest != nil:
+		return fmt.Errorf("nearest neighbors require FROM")
+	}
 	return nil
 }
 
+// TableAlias is the name the columns of the FROM table are qualified with
+// in a join.
+func (s *SelectStatement) TableAlias() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.TableName
+}
+
 func cleanStrings(items []string) []string {
 	cleaned := make([]string, 0, len(items))
 	for _, item := range items {
@@ -113,10 +516,38 @@ func (s *SelectStatement) FromBytes(data []byte) error {
 
 func (s SelectStatement) String() string {
 	var sb strings.Builder
+	if s.Analyze {
+		sb.WriteString("EXPLAIN ANALYZE ")
+	} else if s.Explain {
+		sb.WriteString("EXPLAIN ")
+	}
+	if len(s.With) > 0 {
+		sb.WriteString("WITH ")
+		if s.Recursive {
+			sb.WriteString("RECURSIVE ")
+		}
+		for i, cte := range s.With {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(cte.String())
+		}
+		sb.WriteString(" ")
+	}
 	sb.WriteString("SELECT ")
+	if s.Distinct {
+		sb.WriteString("DISTINCT ")
+	}
 
 	if len(s.Columns) > 0 {
-		sb.WriteString(strings.Join(s.Columns, ", "))
+		columns := make([]string, len(s.Columns))
+		for i, column := range s.Columns {
+			columns[i] = column
+			if slices.Contains(s.Unnest, column) {
+				columns[i] = fmt.Sprintf("UNNEST(%s) AS %s", column, column)
+			}
+		}
+		sb.WriteString(strings.Join(columns, ", "))
 	}
 
 	if len(s.Aggregations) > 0 {
@@ -127,14 +558,42 @@ func (s SelectStatement) String() string {
 			if i > 0 {
 				sb.WriteString(", ")
 			}
-			sb.WriteString(fmt.Sprintf("%s(%s)", agg.Function, agg.Column))
+			sb.WriteString(agg.call())
 			if agg.Alias != "" {
 				sb.WriteString(" AS " + agg.Alias)
 			}
 		}
 	}
 
-	sb.WriteString(fmt.Sprintf(" FROM %s.%s.%s", s.Database, s.Schema, s.TableName))
+	for i, p := range s.Projections {
+		if i > 0 || len(s.Columns) > 0 || len(s.Aggregations) > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(p.Expression.String())
+		if p.Alias != "" {
+			sb.WriteString(" AS " + p.Alias)
+		}
+	}
+
+	for i, w := range s.Windows {
+		if i > 0 || len(s.Columns) > 0 || len(s.Aggregations) > 0 || len(s.Projections) > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(w.String())
+	}
+
+	if s.TableName != "" {
+		sb.WriteString(fmt.Sprintf(" FROM %s.%s.%s", s.Database, s.Schema, s.TableName))
+	}
+	if s.Alias != "" {
+		sb.WriteString(" AS " + s.Alias)
+	}
+	if s.IncludeDeleted {
+		sb.WriteString(" WITH DELETED")
+	}
+	for _, join := range s.Joins {
+		sb.WriteString(join.String())
+	}
 
 	if s.Where != nil {
 		sql, _, err := s.Where.Build()
@@ -145,17 +604,32 @@ func (s SelectStatement) String() string {
 
 	if len(s.GroupBy) > 0 {
 		sb.WriteString(" GROUP BY " + strings.Join(s.GroupBy, ", "))
-		if s.Having != "" {
-			sb.WriteString(" HAVING " + s.Having)
+	}
+
+	if s.Having != nil {
+		sql, _, err := s.Having.Build()
+		if err == nil && sql != "" {
+			sb.WriteString(" HAVING " + sql)
 		}
 	}
 
+	for _, op := range s.SetOperations {
+		sb.WriteString(" " + op.String())
+	}
+
 	if len(s.OrderBy) > 0 {
 		sb.WriteString(" ORDER BY " + strings.Join(s.OrderBy, ", "))
 	}
 
-	if s.Limit > 0 {
-		sb.WriteString(fmt.Sprintf(" LIMIT %d", s.Limit))
+	if s.Nearest != nil {
+		sb.WriteString(fmt.Sprintf(" NEAREST %d TO %s USING %s", s.Nearest.K, s.Nearest.Column, s.Nearest.Metric))
+		if s.Nearest.Approximate {
+			sb.WriteString(" APPROXIMATE")
+		}
+	}
+
+	if s.Limit != nil {
+		sb.WriteString(fmt.Sprintf(" LIMIT %d", *s.Limit))
 	}
 
 	if s.Offset > 0 {
@@ -0,0 +1,74 @@
+package statement
+
+import (
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+)
+
+// SetOperator combines the rows of two selects.
+type SetOperator string
+
+const (
+	// Union returns the rows of both selects.
+	Union SetOperator = "UNION"
+	// Intersect returns the rows found in both selects.
+	Intersect SetOperator = "INTERSECT"
+	// Except returns the rows of the first select missing from the second.
+	Except SetOperator = "EXCEPT"
+)
+
+// SetOperation combines the rows produced so far with those of Select,
+// whose columns are matched to the others by position. Without All, every
+// row is returned once; with All, UNION keeps all the rows, INTERSECT a row
+// as many times as both sides have it, and EXCEPT as many times as the
+// first side has it more than the second.
+type SetOperation struct {
+	Operator SetOperator      `msgpack:"operator" valid:"required,matches(^(UNION|INTERSECT|EXCEPT)$)"`
+	All      bool             `msgpack:"all"`
+	Select   *SelectStatement `msgpack:"select"`
+}
+
+func (o SetOperation) String() string {
+	s := string(o.Operator)
+	if o.All {
+		s += " ALL"
+	}
+	return s + " " + o.Select.String()
+}
+
+// validateSetOperations checks the selects combined with the statement,
+// which only return rows: ORDER BY, LIMIT and OFFSET belong to the
+// statement and apply to the combined rows.
+func (s *SelectStatement) validateSetOperations() error {
+	if len(s.SetOperations) == 0 {
+		return nil
+	}
+	if s.Nearest != nil {
+		return fmt.Errorf("nearest neighbors cannot be combined with %s", s.SetOperations[0].Operator)
+	}
+	for _, op := range s.SetOperations {
+		if _, err := govalidator.ValidateStruct(op); err != nil {
+			return fmt.Errorf("invalid set operation: %w", err)
+		}
+		sel := op.Select
+		switch {
+		case sel == nil:
+			return fmt.Errorf("%s requires a select statement", op.Operator)
+		case sel.Explain:
+			return fmt.Errorf("EXPLAIN is not allowed after %s", op.Operator)
+		case len(sel.With) > 0:
+			return fmt.Errorf("WITH is not allowed after %s", op.Operator)
+		case len(sel.SetOperations) > 0:
+			return fmt.Errorf("set operations cannot be nested")
+		case len(sel.OrderBy) > 0 || sel.Limit != nil || sel.Offset > 0:
+			return fmt.Errorf("ORDER BY, LIMIT and OFFSET apply to the rows of the whole %s and must follow its last select", op.Operator)
+		case sel.Nearest != nil:
+			return fmt.Errorf("nearest neighbors cannot be combined with %s", op.Operator)
+		}
+		if err := sel.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package statement
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/filters"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// SubqueryExtID is the msgpack extension id used to ship Subquery values
+// inside Filter.Value.
+const SubqueryExtID int8 = 11
+
+// Subquery is a select nested in a condition of another, as the Value of
+// a filters.Filter: the list of IN and NOT IN, the value a comparison is
+// made with, which the select must return at most one of, or the rows
+// EXISTS and NOT EXISTS test for. As the Value of a literal of an
+// expression, it is a scalar subquery, standing for the only value it
+// returns, or null without rows. Subqueries are run once: only the
+// conditions of an EXISTS may refer to the columns of the outer select, by
+// equality with a column of the subquery.
+type Subquery struct {
+	Select *SelectStatement
+}
+
+func (s Subquery) SQL() string {
+	return s.Select.String()
+}
+
+func (s Subquery) String() string {
+	return "(" + s.SQL() + ")"
+}
+
+// ScalarSubqueries returns the subqueries expr holds as literals.
+func ScalarSubqueries(expr *expression.Expression) []Subquery {
+	var subs []Subquery
+	expr.Walk(func(node *expression.Expression) {
+		if sub, ok := node.Value.(Subquery); ok && node.Kind == expression.Literal {
+			subs = append(subs, sub)
+		}
+	})
+	return subs
+}
+
+// subqueries returns the selects nested in the statement: in its
+// conditions and in the expressions of its conditions and select list.
+func (s *SelectStatement) subqueries() []Subquery {
+	var subs []Subquery
+	for _, f := range []*filters.Filter{s.Where, s.Having} {
+		if f == nil {
+			continue
+		}
+		f.Walk(func(f *filters.Filter) {
+			if sub, ok := f.Value.(Subquery); ok {
+				subs = append(subs, sub)
+			}
+			if f.Expression != nil {
+				subs = append(subs, ScalarSubqueries(f.Expression)...)
+			}
+		})
+	}
+	for _, p := range s.Projections {
+		if p.Expression != nil {
+			subs = append(subs, ScalarSubqueries(p.Expression)...)
+		}
+	}
+	return subs
+}
+
+// validateSubqueries checks the selects nested in the statement, which
+// only return rows.
+func (s *SelectStatement) validateSubqueries() error {
+	for _, f := range []*filters.Filter{s.Where, s.Having} {
+		if f == nil {
+			continue
+		}
+		var err error
+		f.Walk(func(f *filters.Filter) {
+			_, ok := f.Value.(Subquery)
+			exists := f.Operator == filters.Exists || f.Operator == filters.NotExists
+			switch {
+			case err != nil || len(f.Children) > 0 || f.Expression != nil:
+			case exists && !ok:
+				err = fmt.Errorf("%s requires a subquery", f.Operator)
+			case exists && f.Field != "":
+				err = fmt.Errorf("%s takes no column", f.Operator)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range s.subqueries() {
+		switch {
+		case sub.Select == nil:
+			return fmt.Errorf("subquery requires a select statement")
+		case sub.Select.Explain:
+			return fmt.Errorf("EXPLAIN is not allowed in a subquery")
+		case sub.Select.Nearest != nil:
+			return fmt.Errorf("nearest neighbors cannot be used in a subquery")
+		}
+	}
+	return nil
+}
+
+func init() {
+	msgpack.RegisterExtEncoder(SubqueryExtID, Subquery{}, func(e *msgpack.Encoder, v reflect.Value) ([]byte, error) {
+		return msgpack.Marshal(v.Interface().(Subquery).Select)
+	})
+	msgpack.RegisterExtDecoder(SubqueryExtID, Subquery{}, func(d *msgpack.Decoder, v reflect.Value, extLen int) error {
+		b := make([]byte, extLen)
+		if err := d.ReadFull(b); err != nil {
+			return err
+		}
+		stmt := &SelectStatement{}
+		if err := stmt.FromBytes(b); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(Subquery{Select: stmt}))
+		return nil
+	})
+}
@@ -1,32 +1,60 @@
 package statement
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/io/filters"
 	"github.com/onnasoft/ZenithSQL/io/protocol"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// UpdateStatement sets columns to the values in Updates, or to the values
+// of the expressions in Expressions computed from the row being updated, in
+// the rows matching Where, or in every row without it.
 type UpdateStatement struct {
-	Database  string                 `msgpack:"database"   valid:"required,alphanumunderscore"`
-	Schema    string                 `msgpack:"schema"     valid:"required,alphanumunderscore"`
-	TableName string                 `msgpack:"table_name" valid:"required,alphanumunderscore"`
-	Updates   map[string]interface{} `msgpack:"updates"    valid:"required"`
-	Where     string                 `msgpack:"where"`
+	Database    string                            `msgpack:"database"   valid:"required,alphanumunderscore"`
+	Schema      string                            `msgpack:"schema"     valid:"required,alphanumunderscore"`
+	TableName   string                            `msgpack:"table_name" valid:"required,alphanumunderscore"`
+	Updates     map[string]interface{}            `msgpack:"updates"`
+	Expressions map[string]*expression.Expression `msgpack:"expressions"`
+	Where       *filters.Filter                   `msgpack:"where"`
 }
 
-func NewUpdateStatement(tableName string, updates map[string]interface{}, where string) (*UpdateStatement, error) {
+// systemColumns are kept by the storage itself and cannot be assigned.
+var systemColumns = []string{"id", "created_at", "updated_at", "deleted_at"}
+
+func NewUpdateStatement(database, schema, tableName string, updates map[string]interface{}, expressions map[string]*expression.Expression, where *filters.Filter) (*UpdateStatement, error) {
 	stmt := &UpdateStatement{
-		TableName: tableName,
-		Updates:   updates,
-		Where:     where,
+		Database:    database,
+		Schema:      schema,
+		TableName:   tableName,
+		Updates:     updates,
+		Expressions: expressions,
+		Where:       where,
 	}
 
 	if _, err := govalidator.ValidateStruct(stmt); err != nil {
 		return nil, err
 	}
 
+	if len(updates) == 0 && len(expressions) == 0 {
+		return nil, errors.New("at least one column must be updated")
+	}
+	for column := range expressions {
+		if _, ok := updates[column]; ok {
+			return nil, fmt.Errorf("column %s assigned more than once", column)
+		}
+	}
+	for _, column := range systemColumns {
+		_, assigned := expressions[column]
+		if _, ok := updates[column]; ok || assigned {
+			return nil, fmt.Errorf("column %s cannot be updated", column)
+		}
+	}
+
 	return stmt, nil
 }
 
@@ -43,5 +71,5 @@ func (u *UpdateStatement) FromBytes(data []byte) error {
 }
 
 func (u UpdateStatement) String() string {
-	return fmt.Sprintf("UpdateStatement{TableName: %s, Updates: %v, Where: %s}", u.TableName, u.Updates, u.Where)
+	return fmt.Sprintf("UpdateStatement{TableName: %s, Updates: %v, Expressions: %v, Where: %s}", u.TableName, u.Updates, u.Expressions, u.Where)
 }
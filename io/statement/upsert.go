@@ -1,29 +1,168 @@
 package statement
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/io/expression"
 	"github.com/onnasoft/ZenithSQL/io/protocol"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// ConflictAction is what an upsert does with a row whose key is already
+// held by a row of the table.
+type ConflictAction string
+
+const (
+	// DoNothing leaves the row of the table as it is.
+	DoNothing ConflictAction = "NOTHING"
+	// DoUpdate sets columns of the row of the table, by default to the
+	// values of the row upserted.
+	DoUpdate ConflictAction = "UPDATE"
+)
+
+// ConflictClause decides which rows of an upsert conflict with the rows of
+// the table, and what happens to them. UniqueKey is a column, or an index
+// on the column, whose value identifies a row; rows without a value never
+// conflict. DoUpdate sets the UpdateColumns of the rows of the table to
+// their values in the row upserted, the columns of Updates to their values
+// and those of Expressions to the values of the expressions computed from
+// the row of the table, whose columns they read as in an UPDATE, and the
+// row upserted, whose columns they read as EXCLUDED.col. Without any of
+// them it sets every column given but the key.
+type ConflictClause struct {
+	UniqueKey     string                            `msgpack:"unique_key" valid:"required,alphanumunderscore"`
+	Action        ConflictAction                    `msgpack:"action" valid:"required,matches(^(NOTHING|UPDATE)$)"`
+	UpdateColumns []string                          `msgpack:"update_columns"`
+	Updates       map[string]interface{}            `msgpack:"updates"`
+	Expressions   map[string]*expression.Expression `msgpack:"expressions"`
+}
+
+// ExcludedPrefix qualifies the columns of the row upserted in the
+// expressions of a conflict clause.
+const ExcludedPrefix = "EXCLUDED."
+
+func (c ConflictClause) validate(values []map[string]interface{}) error {
+	if _, err := govalidator.ValidateStruct(c); err != nil {
+		return fmt.Errorf("invalid conflict clause: %w", err)
+	}
+	if c.Action == DoNothing && c.sets() {
+		return errors.New("DO NOTHING cannot update columns")
+	}
+	assigned := make(map[string]bool)
+	assign := func(column string) error {
+		if slices.Contains(systemColumns, column) {
+			return fmt.Errorf("column %s cannot be updated", column)
+		}
+		if assigned[column] {
+			return fmt.Errorf("column %s assigned more than once", column)
+		}
+		assigned[column] = true
+		return nil
+	}
+	for _, column := range c.UpdateColumns {
+		if err := assign(column); err != nil {
+			return err
+		}
+		for _, row := range values {
+			if _, ok := row[column]; !ok {
+				return fmt.Errorf("column %s is updated but not inserted", column)
+			}
+		}
+	}
+	for column := range c.Updates {
+		if err := assign(column); err != nil {
+			return err
+		}
+	}
+	for column, expr := range c.Expressions {
+		if err := assign(column); err != nil {
+			return err
+		}
+		if expr == nil {
+			return fmt.Errorf("column %s has no expression", column)
+		}
+		if len(ScalarSubqueries(expr)) > 0 {
+			return fmt.Errorf("column %s cannot be set to a subquery on conflict", column)
+		}
+	}
+	return nil
+}
+
+// sets reports whether the clause names the columns it sets.
+func (c ConflictClause) sets() bool {
+	return len(c.UpdateColumns) > 0 || len(c.Updates) > 0 || len(c.Expressions) > 0
+}
+
+// Columns returns the columns the clause sets to their values in a
+// conflicting row upserted as row. Updates and Expressions set the others.
+func (c ConflictClause) Columns(row map[string]interface{}, key string) []string {
+	if c.Action == DoNothing {
+		return nil
+	}
+	if c.sets() {
+		return c.UpdateColumns
+	}
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		if column != key && !slices.Contains(systemColumns, column) {
+			columns = append(columns, column)
+		}
+	}
+	slices.Sort(columns)
+	return columns
+}
+
+func (c ConflictClause) String() string {
+	var assignments []string
+	for _, column := range c.UpdateColumns {
+		assignments = append(assignments, column+" = "+ExcludedPrefix+column)
+	}
+	for _, column := range slices.Sorted(maps.Keys(c.Updates)) {
+		assignments = append(assignments, fmt.Sprintf("%s = %v", column, c.Updates[column]))
+	}
+	for _, column := range slices.Sorted(maps.Keys(c.Expressions)) {
+		assignments = append(assignments, fmt.Sprintf("%s = %s", column, c.Expressions[column]))
+	}
+	s := fmt.Sprintf("ON CONFLICT (%s) DO %s", c.UniqueKey, c.Action)
+	if len(assignments) > 0 {
+		s += " SET " + strings.Join(assignments, ", ")
+	}
+	return s
+}
+
+// UpsertStatement inserts the rows in Values, but those conflicting with
+// a row of the table, which OnConflict updates or skips.
 type UpsertStatement struct {
-	TableName string                 `msgpack:"table_name" valid:"required,alphanumunderscore"`
-	Values    map[string]interface{} `msgpack:"values" valid:"required"`
-	UniqueKey string                 `msgpack:"unique_key"`
+	Database   string                   `msgpack:"database" valid:"required,alphanumunderscore"`
+	Schema     string                   `msgpack:"schema" valid:"required,alphanumunderscore"`
+	TableName  string                   `msgpack:"table_name" valid:"required,alphanumunderscore"`
+	Values     []map[string]interface{} `msgpack:"values"`
+	OnConflict ConflictClause           `msgpack:"on_conflict"`
 }
 
-func NewUpsertStatement(tableName string, values map[string]interface{}, uniqueKey string) (*UpsertStatement, error) {
+func NewUpsertStatement(database, schema, tableName string, values []map[string]interface{}, onConflict ConflictClause) (*UpsertStatement, error) {
 	stmt := &UpsertStatement{
-		TableName: tableName,
-		Values:    values,
-		UniqueKey: uniqueKey,
+		Database:   database,
+		Schema:     schema,
+		TableName:  tableName,
+		Values:     values,
+		OnConflict: onConflict,
 	}
 
 	if _, err := govalidator.ValidateStruct(stmt); err != nil {
 		return nil, err
 	}
+	if len(values) == 0 {
+		return nil, errors.New("upsert requires at least one row")
+	}
+	if err := onConflict.validate(values); err != nil {
+		return nil, err
+	}
 
 	return stmt, nil
 }
@@ -41,5 +180,5 @@ func (u *UpsertStatement) FromBytes(data []byte) error {
 }
 
 func (u UpsertStatement) String() string {
-	return fmt.Sprintf("UpsertStatement{TableName: %s, Values: %v, UniqueKey: %s}", u.TableName, u.Values, u.UniqueKey)
+	return fmt.Sprintf("UpsertStatement{TableName: %s, Values: %v, OnConflict: %s}", u.TableName, u.Values, u.OnConflict)
 }
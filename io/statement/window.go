@@ -0,0 +1,317 @@
+package statement
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/io/expression"
+	"github.com/onnasoft/ZenithSQL/model/aggregate"
+)
+
+// WindowFunctionType is a ranking or offset function computed over a
+// window, or an aggregate function computed over the frame of every row.
+type WindowFunctionType string
+
+const (
+	RowNumber   WindowFunctionType = "ROW_NUMBER"
+	Rank        WindowFunctionType = "RANK"
+	DenseRank   WindowFunctionType = "DENSE_RANK"
+	PercentRank WindowFunctionType = "PERCENT_RANK"
+	CumeDist    WindowFunctionType = "CUME_DIST"
+	Ntile       WindowFunctionType = "NTILE"
+	Lag         WindowFunctionType = "LAG"
+	Lead        WindowFunctionType = "LEAD"
+	FirstValue  WindowFunctionType = "FIRST_VALUE"
+	LastValue   WindowFunctionType = "LAST_VALUE"
+	NthValue    WindowFunctionType = "NTH_VALUE"
+)
+
+// Ranking reports whether the function numbers the rows of the window,
+// taking no column.
+func (f WindowFunctionType) Ranking() bool {
+	switch f {
+	case RowNumber, Rank, DenseRank, PercentRank, CumeDist, Ntile:
+		return true
+	}
+	return false
+}
+
+// Aggregate reports whether the function aggregates the frame of every row.
+func (f WindowFunctionType) Aggregate() bool {
+	switch aggregate.AggregateType(f) {
+	case aggregate.SUM, aggregate.AVG, aggregate.COUNT, aggregate.MAX, aggregate.MIN,
+		aggregate.GROUP_CONCAT, aggregate.MEDIAN, aggregate.MODE, aggregate.STDDEV,
+		aggregate.VARIANCE, aggregate.COUNT_DISTINCT, aggregate.APPROX_COUNT_DISTINCT:
+		return true
+	}
+	return false
+}
+
+// Framed reports whether the function reads the frame of the row rather
+// than the whole window.
+func (f WindowFunctionType) Framed() bool {
+	switch f {
+	case FirstValue, LastValue, NthValue:
+		return true
+	}
+	return f.Aggregate()
+}
+
+type FrameUnit string
+
+const (
+	// RowsFrame bounds the frame by a number of rows.
+	RowsFrame FrameUnit = "ROWS"
+	// RangeFrame bounds the frame by the value of the ORDER BY key, rows
+	// with equal keys being peers that are always in the frame together.
+	RangeFrame FrameUnit = "RANGE"
+)
+
+type FrameBoundType string
+
+const (
+	UnboundedPreceding FrameBoundType = "UNBOUNDED PRECEDING"
+	Preceding          FrameBoundType = "PRECEDING"
+	CurrentRow         FrameBoundType = "CURRENT ROW"
+	Following          FrameBoundType = "FOLLOWING"
+	UnboundedFollowing FrameBoundType = "UNBOUNDED FOLLOWING"
+)
+
+// order ranks the bound types from the start of the window to its end.
+func (t FrameBoundType) order() int {
+	switch t {
+	case UnboundedPreceding:
+		return 0
+	case Preceding:
+		return 1
+	case CurrentRow:
+		return 2
+	case Following:
+		return 3
+	}
+	return 4
+}
+
+// FrameBound is an end of a frame. Offset is set for PRECEDING and
+// FOLLOWING: a number of rows for ROWS frames, and for RANGE frames a
+// number, or an interval such as "1 day" when the window is ordered by a
+// date or a timestamp.
+type FrameBound struct {
+	Type   FrameBoundType `msgpack:"type" valid:"required,matches(^(UNBOUNDED PRECEDING|PRECEDING|CURRENT ROW|FOLLOWING|UNBOUNDED FOLLOWING)$)"`
+	Offset string         `msgpack:"offset"`
+}
+
+func (b FrameBound) String() string {
+	if b.Type != Preceding && b.Type != Following {
+		return string(b.Type)
+	}
+	if _, err := strconv.ParseFloat(b.Offset, 64); err != nil {
+		return fmt.Sprintf("INTERVAL '%s' %s", b.Offset, b.Type)
+	}
+	return b.Offset + " " + string(b.Type)
+}
+
+// WindowFrame is the set of rows of the window the framed functions of a
+// row read, from Start to End.
+type WindowFrame struct {
+	Unit  FrameUnit  `msgpack:"unit" valid:"required,matches(^(ROWS|RANGE)$)"`
+	Start FrameBound `msgpack:"start"`
+	End   FrameBound `msgpack:"end"`
+}
+
+func (f WindowFrame) String() string {
+	return fmt.Sprintf("%s BETWEEN %s AND %s", f.Unit, f.Start, f.End)
+}
+
+func (f WindowFrame) validate(orderBy []string) error {
+	if _, err := govalidator.ValidateStruct(f); err != nil {
+		return fmt.Errorf("invalid window frame: %w", err)
+	}
+	if f.Start.Type == UnboundedFollowing || f.End.Type == UnboundedPreceding {
+		return fmt.Errorf("invalid window frame %s", f)
+	}
+	if f.Start.Type.order() > f.End.Type.order() {
+		return fmt.Errorf("window frame %s starts after it ends", f)
+	}
+	for _, bound := range []FrameBound{f.Start, f.End} {
+		if bound.Type != Preceding && bound.Type != Following {
+			if bound.Offset != "" {
+				return fmt.Errorf("%s takes no offset", bound.Type)
+			}
+			continue
+		}
+		if f.Unit == RowsFrame {
+			if _, err := strconv.ParseUint(bound.Offset, 10, 63); err != nil {
+				return fmt.Errorf("ROWS %s offset must be a non-negative integer, got %q", bound.Type, bound.Offset)
+			}
+			continue
+		}
+		if len(orderBy) != 1 {
+			return fmt.Errorf("RANGE with an offset requires exactly one ORDER BY key")
+		}
+		if bound.Offset == "" || strings.HasPrefix(bound.Offset, "-") {
+			return fmt.Errorf("RANGE %s offset must not be negative", bound.Type)
+		}
+	}
+	return nil
+}
+
+// Window partitions the rows by PartitionBy and orders every partition by
+// OrderBy, whose keys are written as in SelectStatement.OrderBy. Without a
+// frame, framed functions read the whole partition, or with OrderBy the
+// rows up to the current one and its peers.
+type Window struct {
+	PartitionBy []string     `msgpack:"partition_by"`
+	OrderBy     []string     `msgpack:"order_by"`
+	Frame       *WindowFrame `msgpack:"frame"`
+}
+
+func (w Window) String() string {
+	var parts []string
+	if len(w.PartitionBy) > 0 {
+		parts = append(parts, "PARTITION BY "+strings.Join(w.PartitionBy, ", "))
+	}
+	if len(w.OrderBy) > 0 {
+		parts = append(parts, "ORDER BY "+strings.Join(w.OrderBy, ", "))
+	}
+	if w.Frame != nil {
+		parts = append(parts, w.Frame.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// windowKeyPattern matches the PARTITION BY columns and ORDER BY keys of a
+// window, which may name aggregates by their call in a grouped select.
+var windowKeyPattern = regexp.MustCompile(`(?i)^(\w+(\.\w+)?|\w+\((\*|\w+(\.\w+)?)\))( (ASC|DESC))?( NULLS (FIRST|LAST))?$`)
+
+// WindowFunction is a select item computed over the window of every row,
+// such as ROW_NUMBER() OVER (PARTITION BY id ORDER BY ts), returned next
+// to the columns of the row. Column is the argument of the function, * for
+// COUNT(*). Offset is how many rows LAG and LEAD look back or ahead, the
+// number of buckets of NTILE and the position read by NTH_VALUE. Default
+// is what LAG and LEAD return past the ends of the partition.
+type WindowFunction struct {
+	Function WindowFunctionType `msgpack:"function" valid:"required"`
+	Column   string             `msgpack:"column"`
+	Offset   int64              `msgpack:"offset"`
+	Default  interface{}        `msgpack:"default"`
+	Over     Window             `msgpack:"over"`
+	Alias    string             `msgpack:"alias" valid:"alphanumunderscore"`
+}
+
+// Name is the column the function is returned as: its alias, or the call
+// as written.
+func (w WindowFunction) Name() string {
+	if w.Alias != "" {
+		return w.Alias
+	}
+	return w.call() + " OVER " + w.Over.String()
+}
+
+func (w WindowFunction) call() string {
+	args := []string{w.Column}
+	switch w.Function {
+	case Ntile:
+		args = []string{strconv.FormatInt(w.Offset, 10)}
+	case Lag, Lead:
+		args = append(args, strconv.FormatInt(w.Offset, 10))
+		if w.Default != nil {
+			args = append(args, expression.NewLiteral(w.Default).String())
+		}
+	case NthValue:
+		args = append(args, strconv.FormatInt(w.Offset, 10))
+	default:
+		if w.Function.Ranking() {
+			args = nil
+		}
+	}
+	if w.Function == WindowFunctionType(aggregate.COUNT_DISTINCT) {
+		return fmt.Sprintf("COUNT(DISTINCT %s)", w.Column)
+	}
+	return fmt.Sprintf("%s(%s)", w.Function, strings.Join(args, ", "))
+}
+
+func (w WindowFunction) String() string {
+	s := w.call() + " OVER " + w.Over.String()
+	if w.Alias != "" {
+		s += " AS " + w.Alias
+	}
+	return s
+}
+
+// Columns returns the columns the function reads: its argument and those
+// of its window.
+func (w WindowFunction) Columns() []string {
+	var columns []string
+	if w.Column != "" && w.Column != "*" {
+		columns = append(columns, w.Column)
+	}
+	columns = append(columns, w.Over.PartitionBy...)
+	for _, key := range w.Over.OrderBy {
+		column, _, _ := strings.Cut(key, " ")
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// Aggregates reports whether the function or its window use aggregate
+// calls, such as RANK() OVER (ORDER BY SUM(amount) DESC).
+func (w WindowFunction) Aggregates() bool {
+	for _, column := range w.Columns() {
+		if _, ok := ParseAggregation(column); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (w WindowFunction) validate() error {
+	if _, err := govalidator.ValidateStruct(w); err != nil {
+		return fmt.Errorf("invalid window function: %w", err)
+	}
+
+	switch {
+	case w.Function.Ranking():
+		if w.Column != "" {
+			return fmt.Errorf("%s takes no column", w.Function)
+		}
+		if w.Function == Ntile && w.Offset <= 0 {
+			return fmt.Errorf("NTILE requires a positive number of buckets")
+		}
+	case w.Function == Lag || w.Function == Lead:
+		if w.Offset < 0 {
+			return fmt.Errorf("%s offset must not be negative", w.Function)
+		}
+	case w.Function == NthValue:
+		if w.Offset <= 0 {
+			return fmt.Errorf("NTH_VALUE requires a positive position")
+		}
+	case w.Function.Framed():
+	default:
+		return fmt.Errorf("unsupported window function %s", w.Function)
+	}
+	if !w.Function.Ranking() && w.Column == "" {
+		return fmt.Errorf("%s requires a column", w.Function)
+	}
+	if w.Column == "*" && w.Function != WindowFunctionType(aggregate.COUNT) {
+		return fmt.Errorf("%s(*) is not allowed, only COUNT(*)", w.Function)
+	}
+
+	for _, column := range w.Over.PartitionBy {
+		if strings.Contains(column, " ") || !windowKeyPattern.MatchString(column) {
+			return fmt.Errorf("invalid partition by column %q", column)
+		}
+	}
+	for _, key := range w.Over.OrderBy {
+		if !windowKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid window order by key %q", key)
+		}
+	}
+	if w.Over.Frame != nil {
+		return w.Over.Frame.validate(w.Over.OrderBy)
+	}
+	return nil
+}
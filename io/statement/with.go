@@ -0,0 +1,139 @@
+package statement
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+)
+
+// CommonTableExpression is a select named by a WITH clause, which the
+// statement, its subqueries and the expressions after it in the clause read
+// like a table. Columns renames the columns of the select, in order.
+//
+// In a WITH RECURSIVE clause, an expression reading its own name is
+// recursive: its select is an anchor of one or more selects, followed by a
+// single select reading the expression joined by UNION or UNION ALL. The
+// anchor is run first, then the last select is run on the rows the
+// previous run produced until it produces none. With UNION, rows already
+// produced are dropped, which ends the recursion over cycles.
+type CommonTableExpression struct {
+	Name    string           `msgpack:"name" valid:"required,alphanumunderscore"`
+	Columns []string         `msgpack:"columns"`
+	Select  *SelectStatement `msgpack:"select"`
+}
+
+func (c CommonTableExpression) String() string {
+	s := c.Name
+	if len(c.Columns) > 0 {
+		s += " (" + strings.Join(c.Columns, ", ") + ")"
+	}
+	return s + " AS (" + c.Select.String() + ")"
+}
+
+// validateWith checks the expressions of the WITH clause.
+func (s *SelectStatement) validateWith() error {
+	if len(s.With) == 0 {
+		if s.Recursive {
+			return fmt.Errorf("RECURSIVE requires a WITH clause")
+		}
+		return nil
+	}
+	if s.Nearest != nil {
+		return fmt.Errorf("nearest neighbors cannot be used with a WITH clause")
+	}
+
+	names := make(map[string]bool, len(s.With))
+	for _, cte := range s.With {
+		if _, err := govalidator.ValidateStruct(cte); err != nil {
+			return fmt.Errorf("invalid common table expression: %w", err)
+		}
+		if names[cte.Name] {
+			return fmt.Errorf("common table expression %s specified more than once", cte.Name)
+		}
+		names[cte.Name] = true
+
+		columns := make(map[string]bool, len(cte.Columns))
+		for _, column := range cte.Columns {
+			if !govalidator.Matches(column, `^\w+$`) || columns[column] {
+				return fmt.Errorf("invalid column %q of common table expression %s", column, cte.Name)
+			}
+			columns[column] = true
+		}
+
+		sel := cte.Select
+		switch {
+		case sel == nil:
+			return fmt.Errorf("common table expression %s requires a select statement", cte.Name)
+		case sel.Explain:
+			return fmt.Errorf("EXPLAIN is not allowed in common table expression %s", cte.Name)
+		case sel.Nearest != nil:
+			return fmt.Errorf("nearest neighbors cannot be used in common table expression %s", cte.Name)
+		}
+		if err := sel.validate(); err != nil {
+			return err
+		}
+		if s.Recursive && sel.ReadsTable(cte.Name) {
+			if err := cte.validateRecursion(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c CommonTableExpression) validateRecursion() error {
+	sel := c.Select
+	last := len(sel.SetOperations) - 1
+	if last < 0 || sel.SetOperations[last].Operator != Union {
+		return fmt.Errorf("recursive common table expression %s must end with UNION or UNION ALL and a select reading it", c.Name)
+	}
+	anchor := *sel
+	anchor.SetOperations = sel.SetOperations[:last]
+	if anchor.ReadsTable(c.Name) {
+		return fmt.Errorf("only the last select of recursive common table expression %s may read it", c.Name)
+	}
+	if len(sel.OrderBy) > 0 || sel.Limit != nil || sel.Offset > 0 {
+		return fmt.Errorf("recursive common table expression %s cannot use ORDER BY, LIMIT or OFFSET", c.Name)
+	}
+	step := sel.SetOperations[last].Select
+	if step.Grouped() || len(step.Windows) > 0 {
+		return fmt.Errorf("the recursive select of %s cannot use aggregates or window functions", c.Name)
+	}
+	return nil
+}
+
+// ReadsTable reports whether the statement reads a table or a common table
+// expression called name: in FROM, in a join, in a combined select or in a
+// subquery, unless a WITH clause redefines the name first.
+func (s *SelectStatement) ReadsTable(name string) bool {
+	for _, cte := range s.With {
+		if cte.Select.ReadsTable(name) {
+			return true
+		}
+		if cte.Name == name {
+			return false
+		}
+	}
+
+	if s.TableName == name {
+		return true
+	}
+	for _, join := range s.Joins {
+		if join.TableName == name {
+			return true
+		}
+	}
+	for _, op := range s.SetOperations {
+		if op.Select.ReadsTable(name) {
+			return true
+		}
+	}
+
+	for _, sub := range s.subqueries() {
+		if sub.Select.ReadsTable(name) {
+			return true
+		}
+	}
+	return false
+}
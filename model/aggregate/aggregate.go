@@ -1,7 +1,10 @@
 package aggregate
 
 import (
+	"cmp"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/onnasoft/ZenithSQL/core/buffer"
 	"github.com/onnasoft/ZenithSQL/model/fields"
@@ -16,19 +19,108 @@ const (
 	MAX          AggregateType = "MAX"
 	MIN          AggregateType = "MIN"
 	GROUP_CONCAT AggregateType = "GROUP_CONCAT"
+
+	MEDIAN                AggregateType = "MEDIAN"
+	MODE                  AggregateType = "MODE"
+	STDDEV                AggregateType = "STDDEV"
+	VARIANCE              AggregateType = "VARIANCE"
+	PERCENTILE            AggregateType = "PERCENTILE"
+	APPROX_PERCENTILE     AggregateType = "APPROX_PERCENTILE"
+	COUNT_DISTINCT        AggregateType = "COUNT_DISTINCT"
+	APPROX_COUNT_DISTINCT AggregateType = "APPROX_COUNT_DISTINCT"
 )
 
+// groupConcatSeparator joins the values of GROUP_CONCAT.
+const groupConcatSeparator = ","
+
 type Aggregate interface {
+	// ResultType is the type of the values returned by Result.
+	ResultType() fields.DataType
 	Result() (interface{}, error)
 	Execute() error
 	Reset() error
 }
 
+// New returns an aggregate reading the column of scanner. Numbers support
+// every function but GROUP_CONCAT, strings COUNT, MIN, MAX and
+// GROUP_CONCAT, and other ordered types COUNT, MIN and MAX. Any column can
+// be counted. Statistics, medians included, take numbers, and MODE and the
+// distinct counts any column whose values can be compared for equality.
+// Percentiles are built by NewPercentile.
 func New(dataType fields.DataType, fn AggregateType, scanner *buffer.Scanner) (Aggregate, error) {
-	switch dataType.String() {
-	case "int8":
-		return NewInt8Aggregate(fn, scanner)
+	switch fn {
+	case MEDIAN:
+		return NewPercentile(dataType, fn, 0.5, scanner)
+	case PERCENTILE, APPROX_PERCENTILE:
+		return nil, fmt.Errorf("%s needs a fraction", fn)
+	case STDDEV, VARIANCE:
+		return newStatistic(dataType, fn, 0, scanner)
+	case MODE, COUNT_DISTINCT, APPROX_COUNT_DISTINCT:
+		return newDistinct(dataType, fn, scanner)
+	}
+
+	switch dt := dataType.(type) {
+	case fields.Int8Type:
+		return NewNumericAggregate[int8](fn, dt, scanner)
+	case fields.Int16Type:
+		return NewNumericAggregate[int16](fn, dt, scanner)
+	case fields.Int32Type:
+		return NewNumericAggregate[int32](fn, dt, scanner)
+	case fields.Int64Type:
+		return NewNumericAggregate[int64](fn, dt, scanner)
+	case fields.Uint8Type:
+		return NewNumericAggregate[uint8](fn, dt, scanner)
+	case fields.Uint16Type:
+		return NewNumericAggregate[uint16](fn, dt, scanner)
+	case fields.Uint32Type:
+		return NewNumericAggregate[uint32](fn, dt, scanner)
+	case fields.Uint64Type:
+		return NewNumericAggregate[uint64](fn, dt, scanner)
+	case fields.Float32Type:
+		return NewNumericAggregate[float32](fn, dt, scanner)
+	case fields.Float64Type:
+		return NewNumericAggregate[float64](fn, dt, scanner)
+	case fields.StringType, fields.EnumType:
+		return NewValueAggregate(fn, dt, scanner, strings.Compare)
+	case fields.TimestampType, fields.TimestampTZType, fields.DateType:
+		return NewValueAggregate(fn, dt, scanner, time.Time.Compare)
+	case fields.IntervalType:
+		return NewValueAggregate(fn, dt, scanner, fields.IntervalValue.Compare)
+	case fields.TimeOfDayType:
+		return NewValueAggregate(fn, dt, scanner, cmp.Compare[fields.TimeOfDay])
+	case fields.BoolType:
+		return NewValueAggregate(fn, dt, scanner, compareBools)
+	case fields.ArrayType:
+		return NewValueAggregate[[]interface{}](fn, dt, scanner, nil)
+	case fields.VectorType:
+		return NewValueAggregate[[]float32](fn, dt, scanner, nil)
+	case fields.GeoPointType:
+		return NewValueAggregate[fields.LatLng](fn, dt, scanner, nil)
 	}
 
 	return nil, fmt.Errorf("unsupported data type: %s", dataType.String())
 }
+
+// NewPercentile returns the aggregate of the fraction percentile of a
+// number column, a value between 0 for the minimum and 1 for the maximum.
+func NewPercentile(dataType fields.DataType, fn AggregateType, fraction float64, scanner *buffer.Scanner) (Aggregate, error) {
+	switch fn {
+	case MEDIAN, PERCENTILE, APPROX_PERCENTILE:
+	default:
+		return nil, fmt.Errorf("%s is not a percentile", fn)
+	}
+	if fraction < 0 || fraction > 1 {
+		return nil, fmt.Errorf("percentile fraction %v is not between 0 and 1", fraction)
+	}
+	return newStatistic(dataType, fn, fraction, scanner)
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
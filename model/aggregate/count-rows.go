@@ -0,0 +1,33 @@
+package aggregate
+
+import (
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// CountRowsAggregate implements COUNT(*): it counts rows, nulls included,
+// without reading any column.
+type CountRowsAggregate struct {
+	counted int64
+}
+
+func NewCountRowsAggregate() *CountRowsAggregate {
+	return &CountRowsAggregate{}
+}
+
+func (agg *CountRowsAggregate) ResultType() fields.DataType {
+	return fields.Int64Type{}
+}
+
+func (agg *CountRowsAggregate) Result() (interface{}, error) {
+	return agg.counted, nil
+}
+
+func (agg *CountRowsAggregate) Execute() error {
+	agg.counted++
+	return nil
+}
+
+func (agg *CountRowsAggregate) Reset() error {
+	agg.counted = 0
+	return nil
+}
@@ -0,0 +1,199 @@
+package aggregate
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// DistinctAggregate aggregates the distinct values of a column. MODE and
+// COUNT_DISTINCT count every value in a map, and MODE breaks ties with the
+// smallest value, or the first one to reach the count when values cannot
+// be ordered. APPROX_COUNT_DISTINCT only keeps a HyperLogLog sketch of the
+// hashes of the values.
+type DistinctAggregate[T comparable] struct {
+	fn       AggregateType
+	dataType fields.DataType
+	compare  func(a, b T) int
+	hash     func(T) uint64
+
+	counts    map[T]int64
+	mode      T
+	modeCount int64
+	sketch    *HyperLogLog
+
+	*buffer.Scanner
+}
+
+func NewDistinctAggregate[T comparable](fn AggregateType, dataType fields.DataType, scanner *buffer.Scanner, compare func(a, b T) int, hash func(T) uint64) (*DistinctAggregate[T], error) {
+	if scanner == nil {
+		return nil, errors.New("scanner cannot be nil")
+	}
+
+	agg := &DistinctAggregate[T]{
+		fn:       fn,
+		dataType: dataType,
+		compare:  compare,
+		hash:     hash,
+		Scanner:  scanner,
+	}
+	switch fn {
+	case MODE, COUNT_DISTINCT:
+		agg.counts = make(map[T]int64)
+	case APPROX_COUNT_DISTINCT:
+		agg.sketch = NewHyperLogLog()
+	default:
+		return nil, errors.New("unsupported aggregate function")
+	}
+	return agg, nil
+}
+
+func newDistinct(dataType fields.DataType, fn AggregateType, scanner *buffer.Scanner) (Aggregate, error) {
+	switch dt := dataType.(type) {
+	case fields.Int8Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[int8], hashNumber[int8])
+	case fields.Int16Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[int16], hashNumber[int16])
+	case fields.Int32Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[int32], hashNumber[int32])
+	case fields.Int64Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[int64], hashNumber[int64])
+	case fields.Uint8Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[uint8], hashNumber[uint8])
+	case fields.Uint16Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[uint16], hashNumber[uint16])
+	case fields.Uint32Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[uint32], hashNumber[uint32])
+	case fields.Uint64Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[uint64], hashNumber[uint64])
+	case fields.Float32Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[float32], hashNumber[float32])
+	case fields.Float64Type:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[float64], hashNumber[float64])
+	case fields.StringType, fields.EnumType:
+		return NewDistinctAggregate(fn, dt, scanner, strings.Compare, hashString)
+	case fields.TimestampType, fields.TimestampTZType, fields.DateType:
+		return NewDistinctAggregate(fn, dt, scanner, time.Time.Compare, hashTime)
+	case fields.IntervalType:
+		return NewDistinctAggregate(fn, dt, scanner, fields.IntervalValue.Compare, hashInterval)
+	case fields.TimeOfDayType:
+		return NewDistinctAggregate(fn, dt, scanner, cmp.Compare[fields.TimeOfDay], hashNumber[fields.TimeOfDay])
+	case fields.BoolType:
+		return NewDistinctAggregate(fn, dt, scanner, compareBools, hashBool)
+	case fields.GeoPointType:
+		return NewDistinctAggregate(fn, dt, scanner, nil, hashLatLng)
+	}
+	return nil, fmt.Errorf("%s is not supported for %s", fn, dataType)
+}
+
+func (agg *DistinctAggregate[T]) Execute() error {
+	var value T
+	ok, err := agg.Scan(&value)
+	if err != nil || !ok {
+		return err
+	}
+
+	if agg.sketch != nil {
+		agg.sketch.Add(agg.hash(value))
+		return nil
+	}
+
+	agg.counts[value]++
+	if agg.fn != MODE {
+		return nil
+	}
+	n := agg.counts[value]
+	if n > agg.modeCount || (n == agg.modeCount && agg.compare != nil && agg.compare(value, agg.mode) < 0) {
+		agg.mode, agg.modeCount = value, n
+	}
+	return nil
+}
+
+func (agg *DistinctAggregate[T]) ResultType() fields.DataType {
+	if agg.fn == MODE {
+		return agg.dataType
+	}
+	return fields.Int64Type{}
+}
+
+// Result returns the count of distinct values, or the most frequent value
+// and nil when there were none.
+func (agg *DistinctAggregate[T]) Result() (interface{}, error) {
+	switch agg.fn {
+	case COUNT_DISTINCT:
+		return int64(len(agg.counts)), nil
+	case APPROX_COUNT_DISTINCT:
+		return int64(agg.sketch.Estimate()), nil
+	}
+	if agg.modeCount == 0 {
+		return nil, nil
+	}
+	return agg.mode, nil
+}
+
+func (agg *DistinctAggregate[T]) Reset() error {
+	if agg.sketch != nil {
+		agg.sketch = NewHyperLogLog()
+		return nil
+	}
+	clear(agg.counts)
+	var zero T
+	agg.mode, agg.modeCount = zero, 0
+	return nil
+}
+
+// mix64 is the finalizer of SplitMix64, spreading every input bit over the
+// whole hash.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+func hashNumber[T Number](value T) uint64 {
+	switch kindOf[T]() {
+	case floatNumber:
+		f := float64(value)
+		if f == 0 {
+			f = 0 // -0 and 0 are the same value
+		}
+		return mix64(math.Float64bits(f))
+	case unsignedNumber:
+		return mix64(uint64(value))
+	}
+	return mix64(uint64(int64(value)))
+}
+
+func hashString(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	return mix64(h.Sum64())
+}
+
+func hashTime(value time.Time) uint64 {
+	return mix64(uint64(value.UnixNano()))
+}
+
+func hashInterval(value fields.IntervalValue) uint64 {
+	return mix64(mix64(uint64(uint32(value.Months))<<32|uint64(uint32(value.Days))) ^ uint64(value.Nanos))
+}
+
+func hashBool(value bool) uint64 {
+	if value {
+		return mix64(1)
+	}
+	return mix64(0)
+}
+
+func hashLatLng(value fields.LatLng) uint64 {
+	return mix64(hashNumber(value.Lat) ^ hashNumber(value.Lng))
+}
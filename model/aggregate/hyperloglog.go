@@ -0,0 +1,48 @@
+package aggregate
+
+import (
+	"math"
+	"math/bits"
+)
+
+// hyperLogLogPrecision is the number of hash bits selecting a register.
+// 2^12 registers take 4 KiB and give a standard error of about 1.6%.
+const hyperLogLogPrecision = 12
+
+// HyperLogLog estimates the number of distinct 64-bit hashes it was given
+// from the longest run of leading zeros seen in each of its registers.
+// Small counts are estimated by linear counting of the empty registers.
+type HyperLogLog struct {
+	registers []uint8
+}
+
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{registers: make([]uint8, 1<<hyperLogLogPrecision)}
+}
+
+// Add records a hash. Hashes must be uniformly distributed.
+func (h *HyperLogLog) Add(hash uint64) {
+	index := hash >> (64 - hyperLogLogPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<hyperLogLogPrecision|1<<(hyperLogLogPrecision-1))) + 1
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+func (h *HyperLogLog) Estimate() uint64 {
+	m := float64(len(h.registers))
+	sum, empty := 0.0, 0
+	for _, rank := range h.registers {
+		sum += 1 / float64(uint64(1)<<rank)
+		if rank == 0 {
+			empty++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && empty > 0 {
+		estimate = m * math.Log(m/float64(empty))
+	}
+	return uint64(math.Round(estimate))
+}
@@ -0,0 +1,30 @@
+package aggregate_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/model/aggregate"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, distinct := range []int{0, 1, 100, 10000, 1000000} {
+		sketch := aggregate.NewHyperLogLog()
+		rng := rand.New(rand.NewSource(int64(distinct)))
+		hashes := make([]uint64, distinct)
+		for i := range hashes {
+			hashes[i] = rng.Uint64()
+			sketch.Add(hashes[i])
+		}
+		// Adding a hash again changes nothing.
+		for _, hash := range hashes[:distinct/2] {
+			sketch.Add(hash)
+		}
+
+		got := float64(sketch.Estimate())
+		if relative := math.Abs(got-float64(distinct)) / max(float64(distinct), 1); relative > 0.05 {
+			t.Errorf("estimate of %d distinct hashes = %v, off by %.1f%%", distinct, got, relative*100)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package aggregate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+type Number interface {
+	~int8 | ~int16 | ~int32 | ~int64 |
+		~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+type numberKind int
+
+const (
+	signedNumber numberKind = iota
+	unsignedNumber
+	floatNumber
+)
+
+// NumericAggregate aggregates a numeric column. SUM keeps the widest type
+// of the column's kind, int64, uint64 or float64, and fails rather than
+// wrap around on overflow. MIN and MAX keep the column type, COUNT is an
+// int64 and AVG a float64.
+type NumericAggregate[T Number] struct {
+	fn       AggregateType
+	dataType fields.DataType
+	kind     numberKind
+
+	counted  int64
+	sumInt   int64
+	sumUint  uint64
+	sumFloat float64
+	floating bool
+	min      T
+	max      T
+
+	aggregateFunc func(value T) error
+	*buffer.Scanner
+}
+
+func NewNumericAggregate[T Number](fn AggregateType, dataType fields.DataType, scanner *buffer.Scanner) (*NumericAggregate[T], error) {
+	if scanner == nil {
+		return nil, errors.New("scanner cannot be nil")
+	}
+
+	agg := &NumericAggregate[T]{
+		fn:       fn,
+		dataType: dataType,
+		kind:     kindOf[T](),
+		Scanner:  scanner,
+	}
+
+	switch fn {
+	case SUM, AVG:
+		agg.aggregateFunc = agg.sumFunc
+	case COUNT:
+		agg.aggregateFunc = agg.countFunc
+	case MAX:
+		agg.aggregateFunc = agg.maxFunc
+	case MIN:
+		agg.aggregateFunc = agg.minFunc
+	case GROUP_CONCAT:
+		return nil, fmt.Errorf("GROUP_CONCAT is not supported for %s", dataType)
+	default:
+		return nil, errors.New("unsupported aggregate function")
+	}
+
+	return agg, nil
+}
+
+func kindOf[T Number]() numberKind {
+	var zero T
+	switch any(zero).(type) {
+	case float32, float64:
+		return floatNumber
+	}
+	if zero-1 > zero {
+		return unsignedNumber
+	}
+	return signedNumber
+}
+
+func (agg *NumericAggregate[T]) sumFunc(value T) error {
+	agg.counted++
+	if agg.floating {
+		agg.sumFloat += float64(value)
+		return nil
+	}
+
+	overflow := ""
+	switch agg.kind {
+	case signedNumber:
+		v := int64(value)
+		sum := agg.sumInt + v
+		if (v > 0 && sum < agg.sumInt) || (v < 0 && sum > agg.sumInt) {
+			overflow = "int64"
+			break
+		}
+		agg.sumInt = sum
+	case unsignedNumber:
+		v := uint64(value)
+		if agg.sumUint > math.MaxUint64-v {
+			overflow = "uint64"
+			break
+		}
+		agg.sumUint += v
+	case floatNumber:
+		agg.sumFloat += float64(value)
+	}
+
+	if overflow == "" {
+		return nil
+	}
+	if agg.fn == SUM {
+		return fmt.Errorf("SUM overflows %s", overflow)
+	}
+	// An average only needs the magnitude, so it carries on in floating point.
+	agg.sumFloat = float64(agg.sumInt) + float64(agg.sumUint) + float64(value)
+	agg.floating = true
+	return nil
+}
+
+func (agg *NumericAggregate[T]) countFunc(value T) error {
+	agg.counted++
+	return nil
+}
+
+func (agg *NumericAggregate[T]) maxFunc(value T) error {
+	if agg.counted == 0 || value > agg.max {
+		agg.max = value
+	}
+	agg.counted++
+	return nil
+}
+
+func (agg *NumericAggregate[T]) minFunc(value T) error {
+	if agg.counted == 0 || value < agg.min {
+		agg.min = value
+	}
+	agg.counted++
+	return nil
+}
+
+func (agg *NumericAggregate[T]) ResultType() fields.DataType {
+	switch agg.fn {
+	case COUNT:
+		return fields.Int64Type{}
+	case AVG:
+		return fields.Float64Type{}
+	case SUM:
+		switch agg.kind {
+		case signedNumber:
+			return fields.Int64Type{}
+		case unsignedNumber:
+			return fields.Uint64Type{}
+		}
+		return fields.Float64Type{}
+	}
+	return agg.dataType
+}
+
+// Result returns the aggregate, or nil when no value other than null was
+// aggregated, except for COUNT which is then zero.
+func (agg *NumericAggregate[T]) Result() (interface{}, error) {
+	if agg.fn == COUNT {
+		return agg.counted, nil
+	}
+	if agg.counted == 0 {
+		return nil, nil
+	}
+
+	switch agg.fn {
+	case SUM:
+		switch agg.kind {
+		case signedNumber:
+			return agg.sumInt, nil
+		case unsignedNumber:
+			return agg.sumUint, nil
+		}
+		return agg.sumFloat, nil
+	case AVG:
+		if agg.floating {
+			return agg.sumFloat / float64(agg.counted), nil
+		}
+		switch agg.kind {
+		case signedNumber:
+			return float64(agg.sumInt) / float64(agg.counted), nil
+		case unsignedNumber:
+			return float64(agg.sumUint) / float64(agg.counted), nil
+		}
+		return agg.sumFloat / float64(agg.counted), nil
+	case MAX:
+		return agg.max, nil
+	case MIN:
+		return agg.min, nil
+	}
+	return nil, errors.New("unsupported aggregate function")
+}
+
+func (agg *NumericAggregate[T]) Execute() error {
+	var value T
+	ok, err := agg.Scan(&value)
+	if err != nil || !ok {
+		return err
+	}
+	return agg.aggregateFunc(value)
+}
+
+func (agg *NumericAggregate[T]) Reset() error {
+	agg.counted = 0
+	agg.sumInt, agg.sumUint, agg.sumFloat = 0, 0, 0
+	agg.floating = false
+	var zero T
+	agg.min, agg.max = zero, zero
+	return nil
+}
@@ -0,0 +1,230 @@
+package aggregate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// StatisticAggregate computes a float64 statistic of a number column in a
+// single pass. VARIANCE and STDDEV are the sample ones, updated with
+// Welford's method. MEDIAN and PERCENTILE keep every value and interpolate
+// between the two closest ones, while APPROX_PERCENTILE estimates it in
+// constant memory.
+type StatisticAggregate[T Number] struct {
+	fn       AggregateType
+	fraction float64
+
+	counted int64
+	mean    float64
+	m2      float64
+
+	values   []float64
+	estimate *quantileEstimator
+
+	*buffer.Scanner
+}
+
+func NewStatisticAggregate[T Number](fn AggregateType, fraction float64, scanner *buffer.Scanner) (*StatisticAggregate[T], error) {
+	if scanner == nil {
+		return nil, errors.New("scanner cannot be nil")
+	}
+
+	agg := &StatisticAggregate[T]{fn: fn, fraction: fraction, Scanner: scanner}
+	switch fn {
+	case VARIANCE, STDDEV, MEDIAN, PERCENTILE:
+	case APPROX_PERCENTILE:
+		agg.estimate = newQuantileEstimator(fraction)
+	default:
+		return nil, errors.New("unsupported aggregate function")
+	}
+	return agg, nil
+}
+
+func newStatistic(dataType fields.DataType, fn AggregateType, fraction float64, scanner *buffer.Scanner) (Aggregate, error) {
+	switch dataType.(type) {
+	case fields.Int8Type:
+		return NewStatisticAggregate[int8](fn, fraction, scanner)
+	case fields.Int16Type:
+		return NewStatisticAggregate[int16](fn, fraction, scanner)
+	case fields.Int32Type:
+		return NewStatisticAggregate[int32](fn, fraction, scanner)
+	case fields.Int64Type:
+		return NewStatisticAggregate[int64](fn, fraction, scanner)
+	case fields.Uint8Type:
+		return NewStatisticAggregate[uint8](fn, fraction, scanner)
+	case fields.Uint16Type:
+		return NewStatisticAggregate[uint16](fn, fraction, scanner)
+	case fields.Uint32Type:
+		return NewStatisticAggregate[uint32](fn, fraction, scanner)
+	case fields.Uint64Type:
+		return NewStatisticAggregate[uint64](fn, fraction, scanner)
+	case fields.Float32Type:
+		return NewStatisticAggregate[float32](fn, fraction, scanner)
+	case fields.Float64Type:
+		return NewStatisticAggregate[float64](fn, fraction, scanner)
+	}
+	return nil, fmt.Errorf("%s is not supported for %s", fn, dataType)
+}
+
+func (agg *StatisticAggregate[T]) Execute() error {
+	var value T
+	ok, err := agg.Scan(&value)
+	if err != nil || !ok {
+		return err
+	}
+
+	x := float64(value)
+	agg.counted++
+	switch agg.fn {
+	case VARIANCE, STDDEV:
+		delta := x - agg.mean
+		agg.mean += delta / float64(agg.counted)
+		agg.m2 += delta * (x - agg.mean)
+	case MEDIAN, PERCENTILE:
+		agg.values = append(agg.values, x)
+	case APPROX_PERCENTILE:
+		agg.estimate.add(x)
+	}
+	return nil
+}
+
+func (agg *StatisticAggregate[T]) ResultType() fields.DataType {
+	return fields.Float64Type{}
+}
+
+// Result returns the statistic, or nil when there are too few values for
+// it: none for percentiles and less than two for the sample variance.
+func (agg *StatisticAggregate[T]) Result() (interface{}, error) {
+	switch agg.fn {
+	case VARIANCE, STDDEV:
+		if agg.counted < 2 {
+			return nil, nil
+		}
+		variance := agg.m2 / float64(agg.counted-1)
+		if agg.fn == STDDEV {
+			return math.Sqrt(variance), nil
+		}
+		return variance, nil
+	case MEDIAN, PERCENTILE:
+		if len(agg.values) == 0 {
+			return nil, nil
+		}
+		slices.Sort(agg.values)
+		return interpolate(agg.values, agg.fraction), nil
+	case APPROX_PERCENTILE:
+		if agg.counted == 0 {
+			return nil, nil
+		}
+		return agg.estimate.result(), nil
+	}
+	return nil, errors.New("unsupported aggregate function")
+}
+
+func (agg *StatisticAggregate[T]) Reset() error {
+	agg.counted = 0
+	agg.mean, agg.m2 = 0, 0
+	agg.values = agg.values[:0]
+	if agg.estimate != nil {
+		agg.estimate = newQuantileEstimator(agg.fraction)
+	}
+	return nil
+}
+
+// interpolate returns the fraction percentile of sorted values, linearly
+// interpolated between the two closest ranks.
+func interpolate(sorted []float64, fraction float64) float64 {
+	pos := fraction * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (sorted[lo+1]-sorted[lo])*(pos-float64(lo))
+}
+
+// quantileEstimator is the P² algorithm of Jain and Chlamtac: five markers
+// track the minimum, the maximum, the quantile and two points halfway to
+// it, and are moved along a parabola fitted to their neighbours as values
+// arrive. It is exact up to five values.
+type quantileEstimator struct {
+	p       float64
+	count   int
+	heights [5]float64
+	pos     [5]float64
+	desired [5]float64
+	step    [5]float64
+}
+
+func newQuantileEstimator(p float64) *quantileEstimator {
+	return &quantileEstimator{
+		p:       p,
+		pos:     [5]float64{0, 1, 2, 3, 4},
+		desired: [5]float64{0, 2 * p, 4 * p, 2 + 2*p, 4},
+		step:    [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+func (e *quantileEstimator) add(x float64) {
+	if e.count < 5 {
+		e.heights[e.count] = x
+		e.count++
+		if e.count == 5 {
+			slices.Sort(e.heights[:])
+		}
+		return
+	}
+	e.count++
+
+	var k int
+	switch {
+	case x < e.heights[0]:
+		e.heights[0] = x
+	case x >= e.heights[4]:
+		e.heights[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3 && x >= e.heights[k+1]; k++ {
+		}
+	}
+
+	for i := k + 1; i < 5; i++ {
+		e.pos[i]++
+	}
+	for i := range e.desired {
+		e.desired[i] += e.step[i]
+	}
+
+	for i := 1; i < 4; i++ {
+		d := e.desired[i] - e.pos[i]
+		if (d >= 1 && e.pos[i+1]-e.pos[i] > 1) || (d <= -1 && e.pos[i-1]-e.pos[i] < -1) {
+			sign := math.Copysign(1, d)
+			h := e.parabolic(i, sign)
+			if h <= e.heights[i-1] || h >= e.heights[i+1] {
+				j := i + int(sign)
+				h = e.heights[i] + sign*(e.heights[j]-e.heights[i])/(e.pos[j]-e.pos[i])
+			}
+			e.heights[i] = h
+			e.pos[i] += sign
+		}
+	}
+}
+
+func (e *quantileEstimator) parabolic(i int, d float64) float64 {
+	q, n := e.heights, e.pos
+	return q[i] + d/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+d)*(q[i+1]-q[i])/(n[i+1]-n[i])+
+			(n[i+1]-n[i]-d)*(q[i]-q[i-1])/(n[i]-n[i-1]))
+}
+
+func (e *quantileEstimator) result() float64 {
+	if e.count < 5 {
+		values := slices.Clone(e.heights[:e.count])
+		slices.Sort(values)
+		return interpolate(values, e.p)
+	}
+	return e.heights[2]
+}
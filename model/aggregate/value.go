@@ -0,0 +1,113 @@
+package aggregate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/buffer"
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+// ValueAggregate aggregates a column that is not summed. MIN and MAX need
+// compare and keep the column type, GROUP_CONCAT joins string values with
+// commas in the order they are read and COUNT is an int64.
+type ValueAggregate[T any] struct {
+	fn       AggregateType
+	dataType fields.DataType
+	compare  func(a, b T) int
+
+	counted int64
+	best    T
+	concat  strings.Builder
+
+	*buffer.Scanner
+}
+
+func NewValueAggregate[T any](fn AggregateType, dataType fields.DataType, scanner *buffer.Scanner, compare func(a, b T) int) (*ValueAggregate[T], error) {
+	if scanner == nil {
+		return nil, errors.New("scanner cannot be nil")
+	}
+
+	switch fn {
+	case COUNT:
+	case MIN, MAX:
+		if compare == nil {
+			return nil, fmt.Errorf("%s is not supported for %s", fn, dataType)
+		}
+	case GROUP_CONCAT:
+		var zero T
+		if _, ok := any(zero).(string); !ok {
+			return nil, fmt.Errorf("GROUP_CONCAT is not supported for %s", dataType)
+		}
+	case SUM, AVG:
+		return nil, fmt.Errorf("%s is not supported for %s", fn, dataType)
+	default:
+		return nil, errors.New("unsupported aggregate function")
+	}
+
+	return &ValueAggregate[T]{
+		fn:       fn,
+		dataType: dataType,
+		compare:  compare,
+		Scanner:  scanner,
+	}, nil
+}
+
+func (agg *ValueAggregate[T]) Execute() error {
+	var value T
+	ok, err := agg.Scan(&value)
+	if err != nil || !ok {
+		return err
+	}
+
+	switch agg.fn {
+	case MIN:
+		if agg.counted == 0 || agg.compare(value, agg.best) < 0 {
+			agg.best = value
+		}
+	case MAX:
+		if agg.counted == 0 || agg.compare(value, agg.best) > 0 {
+			agg.best = value
+		}
+	case GROUP_CONCAT:
+		if agg.counted > 0 {
+			agg.concat.WriteString(groupConcatSeparator)
+		}
+		agg.concat.WriteString(any(value).(string))
+	}
+	agg.counted++
+	return nil
+}
+
+func (agg *ValueAggregate[T]) ResultType() fields.DataType {
+	switch agg.fn {
+	case COUNT:
+		return fields.Int64Type{}
+	case GROUP_CONCAT:
+		return fields.StringType{}
+	}
+	return agg.dataType
+}
+
+// Result returns the aggregate, or nil when no value other than null was
+// aggregated, except for COUNT which is then zero.
+func (agg *ValueAggregate[T]) Result() (interface{}, error) {
+	switch {
+	case agg.fn == COUNT:
+		return agg.counted, nil
+	case agg.counted == 0:
+		return nil, nil
+	case agg.fn == GROUP_CONCAT:
+		return agg.concat.String(), nil
+	}
+	return agg.best, nil
+}
+
+func (agg *ValueAggregate[T]) Reset() error {
+	agg.counted = 0
+	var zero T
+	agg.best = zero
+	agg.concat.Reset()
+	return nil
+}
@@ -76,6 +76,26 @@ func (c *Catalog) CreateDatabase(name string) (*Database, error) {
 	return db, nil
 }
 
+// DropDatabase closes the tables of the database and removes its files.
+func (c *Catalog) DropDatabase(name string) error {
+	db, err := c.GetDatabase(name)
+	if err != nil {
+		return err
+	}
+	for _, schema := range db.Schemas {
+		if err := schema.Close(); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(db.Path); err != nil {
+		return fmt.Errorf("error while removing database directory %v: %v", db.Path, err)
+	}
+	delete(c.Databases, name)
+
+	return nil
+}
+
 func (c *Catalog) Close() error {
 	for _, db := range c.Databases {
 		if err := db.Close(); err != nil {
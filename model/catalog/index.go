@@ -0,0 +1,296 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/onnasoft/ZenithSQL/core/index"
+	"github.com/onnasoft/ZenithSQL/core/storage"
+)
+
+// TableIndex binds a secondary index to the column it indexes. No two rows
+// hold the same value of the column of a Unique index, which is a
+// storage.LookupIndex.
+type TableIndex struct {
+	Name   string
+	Column string
+	Unique bool
+	storage.Index
+}
+
+// CreateIndex builds the index config describes, fills it with the rows
+// already in the table and records it in the table config, so that opening
+// the table builds it again. An empty kind is the default kind for the
+// column type.
+func (t *Table) CreateIndex(config storage.IndexConfig) error {
+	meta, err := t.GetFieldMeta(config.Column)
+	if err != nil {
+		return err
+	}
+	config.Kind = strings.ToLower(config.Kind)
+	if config.Kind == "" {
+		config.Kind = index.DefaultKind(meta.DataType())
+	}
+
+	t.indexMu.Lock()
+	defer t.indexMu.Unlock()
+
+	if err := t.addIndex(config); err != nil {
+		return err
+	}
+	t.StorageConfig.Indexes = append(t.StorageConfig.Indexes, config)
+	if err := t.saveConfig(); err != nil {
+		delete(t.indexes, config.Name)
+		t.StorageConfig.Indexes = t.StorageConfig.Indexes[:len(t.StorageConfig.Indexes)-1]
+		return err
+	}
+	return nil
+}
+
+// DropIndex removes the index called name from the table and its config.
+func (t *Table) DropIndex(name string) error {
+	t.indexMu.Lock()
+	defer t.indexMu.Unlock()
+
+	if _, exists := t.indexes[name]; !exists {
+		return fmt.Errorf("index %s not found", name)
+	}
+	configs := t.StorageConfig.Indexes
+	t.StorageConfig.Indexes = slices.DeleteFunc(slices.Clone(configs), func(c storage.IndexConfig) bool {
+		return c.Name == name
+	})
+	if err := t.saveConfig(); err != nil {
+		t.StorageConfig.Indexes = configs
+		return err
+	}
+	delete(t.indexes, name)
+	return nil
+}
+
+// addIndex builds the index config describes and fills it with the rows
+// already in the table. The caller holds indexMu.
+func (t *Table) addIndex(config storage.IndexConfig) error {
+	if _, exists := t.indexes[config.Name]; exists {
+		return fmt.Errorf("index %s already exists", config.Name)
+	}
+	meta, err := t.GetFieldMeta(config.Column)
+	if err != nil {
+		return err
+	}
+	idx, err := index.New(config.Kind, meta.DataType(), config.Options)
+	if err != nil {
+		return err
+	}
+	lookup, ok := idx.(storage.LookupIndex)
+	if config.Unique && !ok {
+		return fmt.Errorf("a unique index cannot be a %s index", config.Kind)
+	}
+
+	cursor, err := t.Cursor()
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	for cursor.Next() {
+		value, err := cursor.ScanField(config.Column)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			continue
+		}
+		if config.Unique {
+			holders, err := lookup.Lookup(value)
+			if err != nil {
+				return err
+			}
+			if len(holders) > 0 {
+				return fmt.Errorf("could not create unique index %s: several rows hold %v in column %s", config.Name, value, config.Column)
+			}
+		}
+		if err := idx.Add(value, cursor.Reader().CurrentID()); err != nil {
+			return fmt.Errorf("failed to build index %s: %w", config.Name, err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if t.indexes == nil {
+		t.indexes = make(map[string]*TableIndex)
+	}
+	t.indexes[config.Name] = &TableIndex{Name: config.Name, Column: config.Column, Unique: config.Unique, Index: idx}
+	return nil
+}
+
+// saveConfig writes the table config to config.json.
+func (t *Table) saveConfig() error {
+	return storage.NewConfigManager(t.Path).SaveTableConfig(t.Name, t.StorageConfig)
+}
+
+func (t *Table) Index(name string) (*TableIndex, bool) {
+	t.indexMu.RLock()
+	defer t.indexMu.RUnlock()
+
+	idx, ok := t.indexes[name]
+	return idx, ok
+}
+
+// Indexes returns the table indexes sorted by name, optionally restricted to
+// the ones on column.
+func (t *Table) Indexes(column string) []*TableIndex {
+	t.indexMu.RLock()
+	defer t.indexMu.RUnlock()
+
+	result := make([]*TableIndex, 0, len(t.indexes))
+	for _, idx := range t.indexes {
+		if column == "" || idx.Column == column {
+			result = append(result, idx)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// CheckUnique reports an error when writing rows would leave two rows
+// holding the same value of the column of a unique index: a row of rows and
+// a row of the table with another id, or two rows of rows. Rows are keyed
+// by their id; the ones not holding a column keep their value.
+func (t *Table) CheckUnique(rows []map[string]interface{}) error {
+	for _, idx := range t.Indexes("") {
+		if !idx.Unique {
+			continue
+		}
+		lookup := idx.Index.(storage.LookupIndex)
+
+		// The rows of the table rows write the column of are compared by
+		// their new value.
+		written := make(map[int64]bool)
+		for _, row := range rows {
+			if _, ok := row[idx.Column]; ok {
+				written[row["id"].(int64)] = true
+			}
+		}
+
+		batch := index.NewHashIndex()
+		for _, row := range rows {
+			value := row[idx.Column]
+			if value == nil {
+				continue
+			}
+			id := row["id"].(int64)
+			holders, err := lookup.Lookup(value)
+			if err != nil {
+				return err
+			}
+			others, err := batch.Lookup(value)
+			if err != nil {
+				return err
+			}
+			for _, holder := range holders {
+				if holder != id && !written[holder] {
+					return duplicateError(idx, value)
+				}
+			}
+			for _, holder := range others {
+				if holder != id {
+					return duplicateError(idx, value)
+				}
+			}
+			if err := batch.Add(value, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func duplicateError(idx *TableIndex, value interface{}) error {
+	return fmt.Errorf("duplicate value %v in column %s violates unique index %s", value, idx.Column, idx.Name)
+}
+
+// IndexRow adds a freshly written row to every index of the table.
+func (t *Table) IndexRow(id int64, values map[string]interface{}) error {
+	for _, idx := range t.Indexes("") {
+		value, ok := values[idx.Column]
+		if !ok || value == nil {
+			continue
+		}
+		if err := idx.Add(value, id); err != nil {
+			return fmt.Errorf("failed to update index %s: %w", idx.Name, err)
+		}
+	}
+	return nil
+}
+
+// Purge removes the rows ids, sorted ascending, from the table files like
+// Storage.Purge. The other rows keep their ids, so the indexes only lose
+// the rows purged, which already left them when deleted.
+func (t *Table) Purge(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := t.unindexRows(ids); err != nil {
+		return err
+	}
+	return t.Storage.Purge(ctx, ids)
+}
+
+// Compact purges every row whose deleted_at is set.
+func (t *Table) Compact(ctx context.Context) error {
+	cursor, err := t.CursorWithDeleted()
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	var ids []int64
+	for cursor.Next() {
+		deletedAt, err := cursor.ScanField("deleted_at")
+		if err != nil {
+			return err
+		}
+		if deletedAt != nil {
+			ids = append(ids, cursor.Reader().CurrentID())
+		}
+	}
+	return t.Purge(ctx, ids)
+}
+
+// unindexRows removes the rows ids, sorted ascending, that are not deleted
+// from the indexes.
+func (t *Table) unindexRows(ids []int64) error {
+	indexes := t.Indexes("")
+	if len(indexes) == 0 {
+		return nil
+	}
+	cursor, err := t.Cursor()
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	for cursor.Next() {
+		position := cursor.Reader().CurrentID()
+		if _, found := slices.BinarySearch(ids, position); !found {
+			continue
+		}
+		for _, idx := range indexes {
+			value, err := cursor.ScanField(idx.Column)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				continue
+			}
+			if err := idx.Remove(value, position); err != nil {
+				return fmt.Errorf("failed to update index %s: %w", idx.Name, err)
+			}
+		}
+	}
+	return cursor.Err()
+}
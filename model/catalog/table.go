@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sync"
 
 	"github.com/onnasoft/ZenithSQL/core/providers/columnstorage"
 	"github.com/onnasoft/ZenithSQL/core/storage"
@@ -16,6 +17,9 @@ type Table struct {
 	Logger        *logrus.Logger
 	StorageConfig *storage.TableConfig
 	storage.Storage
+
+	indexes map[string]*TableIndex
+	indexMu sync.RWMutex
 }
 
 type TableConfig struct {
@@ -49,5 +53,13 @@ func OpenTable(config *TableConfig) (*Table, error) {
 		return nil, fmt.Errorf("storage config is nil")
 	}
 
+	// Indexes live in memory: the ones recorded in the config are built
+	// again from the rows.
+	for _, idx := range config.StorageConfig.Indexes {
+		if err := table.addIndex(idx); err != nil {
+			return nil, fmt.Errorf("failed to build index %s: %w", idx.Name, err)
+		}
+	}
+
 	return table, nil
 }
@@ -0,0 +1,218 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// arrayRefLength is the size of the reference kept in the column record:
+// heap offset (int64) followed by payload length (uint32).
+const arrayRefLength = 12
+
+var arrayElementTypes = map[Types]reflect.Type{
+	Int8:        reflect.TypeOf(int8(0)),
+	Int16:       reflect.TypeOf(int16(0)),
+	Int32:       reflect.TypeOf(int32(0)),
+	Int64:       reflect.TypeOf(int64(0)),
+	Uint8:       reflect.TypeOf(uint8(0)),
+	Uint16:      reflect.TypeOf(uint16(0)),
+	Uint32:      reflect.TypeOf(uint32(0)),
+	Uint64:      reflect.TypeOf(uint64(0)),
+	Float32:     reflect.TypeOf(float32(0)),
+	Float64:     reflect.TypeOf(float64(0)),
+	String:      reflect.TypeOf(""),
+	Bool:        reflect.TypeOf(false),
+	Timestamp:   reflect.TypeOf(time.Time{}),
+	TimestampTZ: reflect.TypeOf(time.Time{}),
+	Date:        reflect.TypeOf(time.Time{}),
+}
+
+// ArrayType stores a list of primitive values. The list is msgpack encoded
+// into the column heap and the record only holds a reference to it.
+type ArrayType struct {
+	ElementType Types
+	heap        Heap
+}
+
+func NewArrayType(element Types) ArrayType {
+	return ArrayType{ElementType: element}
+}
+
+func (dt ArrayType) WithHeap(heap Heap) DataType {
+	dt.heap = heap
+	return dt
+}
+
+// Element returns the data type of the items in the array.
+func (dt ArrayType) Element() DataType {
+	return NewDataType(dt.ElementType)
+}
+
+func (dt ArrayType) ResolveLength(length int) (int, error) {
+	if _, ok := arrayElementTypes[dt.ElementType]; !ok {
+		return 0, fmt.Errorf("unsupported array element type %q", dt.ElementType)
+	}
+	return arrayRefLength, nil
+}
+
+func (dt ArrayType) Read(data []byte, out interface{}) error {
+	payload, err := dt.payload(data)
+	if err != nil {
+		return err
+	}
+
+	ptr, ok := out.(*[]interface{})
+	if !ok {
+		if len(payload) == 0 {
+			return nil
+		}
+		return msgpack.Unmarshal(payload, out)
+	}
+
+	values, err := dt.decode(payload)
+	if err != nil {
+		return err
+	}
+	*ptr = values
+	return nil
+}
+
+func (dt ArrayType) Write(buffer []byte, value interface{}) error {
+	if dt.heap == nil {
+		return errors.New("array column has no heap")
+	}
+
+	var offset int64
+	var length int
+	if value != nil {
+		values, err := dt.Elements(value)
+		if err != nil {
+			return err
+		}
+		payload, err := msgpack.Marshal(values)
+		if err != nil {
+			return fmt.Errorf("failed to encode array: %w", err)
+		}
+		if offset, err = dt.heap.Append(payload); err != nil {
+			return err
+		}
+		length = len(payload)
+	}
+
+	*(*int64)(unsafe.Pointer(&buffer[0])) = offset
+	*(*uint32)(unsafe.Pointer(&buffer[8])) = uint32(length)
+	return nil
+}
+
+func (dt ArrayType) Valid(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	_, err := dt.Elements(value)
+	return err
+}
+
+func (dt ArrayType) Parse(data []byte) interface{} {
+	payload, err := dt.payload(data)
+	if err != nil {
+		return nil
+	}
+	slice := reflect.New(reflect.SliceOf(arrayElementTypes[dt.ElementType]))
+	if len(payload) > 0 {
+		if err := msgpack.Unmarshal(payload, slice.Interface()); err != nil {
+			return nil
+		}
+	}
+	if slice.Elem().IsNil() {
+		slice.Elem().Set(reflect.MakeSlice(slice.Elem().Type(), 0, 0))
+	}
+	return slice.Elem().Interface()
+}
+
+func (dt ArrayType) String() string {
+	return "array"
+}
+
+// Elements flattens any slice into a list of values of the element type.
+// Integer and float values are converted when they fit the element type, so
+// lists decoded from msgpack as []interface{} are accepted as they are.
+func (dt ArrayType) Elements(value interface{}) ([]interface{}, error) {
+	elemType, ok := arrayElementTypes[dt.ElementType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported array element type %q", dt.ElementType)
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("value %v is not an array", value)
+	}
+
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		v, err := ConvertElement(elemType, rv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("invalid array element %d: %w", i, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// ConvertElement converts v to the given Go type when it is a lossless
+// numeric conversion or v already has that type.
+func ConvertElement(to reflect.Type, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, errors.New("null elements are not supported")
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type() == to {
+		return v, nil
+	}
+	if isNumericKind(rv.Kind()) && isNumericKind(to.Kind()) {
+		converted := rv.Convert(to)
+		if converted.Convert(rv.Type()).Interface() == v {
+			return converted.Interface(), nil
+		}
+		return nil, fmt.Errorf("value %v overflows %s", v, to)
+	}
+	return nil, fmt.Errorf("value %v is not %s", v, to)
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64 && k != reflect.Uintptr
+}
+
+func (dt ArrayType) payload(data []byte) ([]byte, error) {
+	if len(data) < arrayRefLength {
+		return nil, errors.New("insufficient data for Array (need 12 bytes)")
+	}
+	offset := *(*int64)(unsafe.Pointer(&data[0]))
+	length := int(*(*uint32)(unsafe.Pointer(&data[8])))
+	if length == 0 {
+		return nil, nil
+	}
+	if dt.heap == nil {
+		return nil, errors.New("array column has no heap")
+	}
+	return dt.heap.Read(offset, length)
+}
+
+func (dt ArrayType) decode(payload []byte) ([]interface{}, error) {
+	if len(payload) == 0 {
+		return []interface{}{}, nil
+	}
+	slice := reflect.New(reflect.SliceOf(arrayElementTypes[dt.ElementType]))
+	if err := msgpack.Unmarshal(payload, slice.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to decode array: %w", err)
+	}
+	values := make([]interface{}, slice.Elem().Len())
+	for i := range values {
+		values[i] = slice.Elem().Index(i).Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,40 @@
+package fields
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Coerce converts numeric literals, such as the int64 and float64 values
+// produced by the SQL parser or decoded from msgpack, into the Go type
+// stored by dt. Lists are coerced element by element. Values that are not
+// numeric, or that would not fit the column type, are returned unchanged so
+// the caller reports the type mismatch.
+func Coerce(dt DataType, value interface{}) interface{} {
+	to, ok := arrayElementTypes[Types(strings.ToLower(dt.String()))]
+	if !ok || !isNumericKind(to.Kind()) {
+		return value
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		coerced := make([]interface{}, len(v))
+		for i, elem := range v {
+			coerced[i] = Coerce(dt, elem)
+		}
+		return coerced
+	case float64:
+		// Decimal literals rarely round trip through float32, so narrowing
+		// is allowed to lose precision.
+		if to.Kind() == reflect.Float32 {
+			return float32(v)
+		}
+	}
+
+	if converted, err := ConvertElement(to, value); err == nil {
+		return converted
+	}
+	return value
+}
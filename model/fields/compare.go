@@ -0,0 +1,114 @@
+package fields
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Compare orders two non-null column values. Numbers of different Go types
+// are compared by value, exactly when both are signed or both are unsigned
+// integers. Values with a Compare method, such as IntervalValue, use it,
+// false sorts before true and lists compare element by element.
+func Compare(a, b interface{}) (int, error) {
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return compareBools(x, y), nil
+		}
+	case IntervalValue:
+		if y, ok := b.(IntervalValue); ok {
+			return x.Compare(y), nil
+		}
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case isSigned(va) && isSigned(vb):
+		return compareOrdered(va.Int(), vb.Int()), nil
+	case isUnsigned(va) && isUnsigned(vb):
+		return compareOrdered(va.Uint(), vb.Uint()), nil
+	case isNumber(va) && isNumber(vb):
+		return compareOrdered(numberAsFloat(va), numberAsFloat(vb)), nil
+	case va.Kind() == reflect.Slice && vb.Kind() == reflect.Slice:
+		return compareLists(va, vb)
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
+
+func compareLists(a, b reflect.Value) (int, error) {
+	for i := 0; i < a.Len() && i < b.Len(); i++ {
+		x, y := a.Index(i).Interface(), b.Index(i).Interface()
+		switch {
+		case x == nil && y == nil:
+			continue
+		case x == nil:
+			return -1, nil
+		case y == nil:
+			return 1, nil
+		}
+		if c, err := Compare(x, y); err != nil || c != 0 {
+			return c, err
+		}
+	}
+	return compareOrdered(int64(a.Len()), int64(b.Len())), nil
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isSigned(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUnsigned(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	return isSigned(v) || isUnsigned(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func numberAsFloat(v reflect.Value) float64 {
+	switch {
+	case isSigned(v):
+		return float64(v.Int())
+	case isUnsigned(v):
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
@@ -26,6 +26,17 @@ const (
 	Bool      Types = "bool"
 	Timestamp Types = "timestamp"
 	Unknown   Types = "unknown"
+
+	TimestampTZ Types = "timestamptz"
+	Date        Types = "date"
+	Time        Types = "time"
+	Interval    Types = "interval"
+
+	Array  Types = "array"
+	Vector Types = "vector"
+	Enum   Types = "enum"
+
+	GeoPoint Types = "geopoint"
 )
 
 var mapTypes = map[Types]DataType{
@@ -43,6 +54,13 @@ var mapTypes = map[Types]DataType{
 	"bool":      BoolType{},
 	"timestamp": TimestampType{},
 	"unknown":   UnknownType{},
+
+	"timestamptz": TimestampTZType{},
+	"date":        DateType{},
+	"time":        TimeOfDayType{},
+	"interval":    IntervalType{},
+
+	"geopoint": GeoPointType{},
 }
 
 func NewDataType(dt Types) DataType {
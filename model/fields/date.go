@@ -0,0 +1,85 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// DateType stores a calendar date as the number of days since the Unix epoch.
+type DateType struct{}
+
+func (DateType) ResolveLength(length int) (int, error) {
+	return 4, nil
+}
+
+func (DateType) Read(data []byte, out interface{}) error {
+	if len(data) < 4 {
+		return errors.New("insufficient data for Date (need 4 bytes)")
+	}
+	ptr, ok := out.(*time.Time)
+	if !ok {
+		return errors.New("output must be *time.Time")
+	}
+	*ptr = DateFromDays(*(*int32)(unsafe.Pointer(&data[0])))
+	return nil
+}
+
+func (DateType) Write(buffer []byte, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*(*int32)(unsafe.Pointer(&buffer[0])) = 0
+	case time.Time:
+		*(*int32)(unsafe.Pointer(&buffer[0])) = DaysFromDate(v)
+	default:
+		return fmt.Errorf("type assertion failed for Date")
+	}
+	return nil
+}
+
+func (DateType) Valid(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if _, ok := value.(time.Time); ok {
+		return nil
+	}
+	return fmt.Errorf("value %v is not time.Time type", value)
+}
+
+func (DateType) Parse(data []byte) interface{} {
+	if len(data) < 4 {
+		return nil
+	}
+	return DateFromDays(*(*int32)(unsafe.Pointer(&data[0])))
+}
+
+func (DateType) String() string {
+	return "date"
+}
+
+// TruncateToDate drops the clock part of t, keeping the calendar date it
+// falls on in its own location.
+func TruncateToDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// DaysFromDate returns the number of days between the Unix epoch and the
+// calendar date of t.
+func DaysFromDate(t time.Time) int32 {
+	secs := TruncateToDate(t).Unix()
+	days := secs / secondsPerDay
+	if secs%secondsPerDay < 0 {
+		days--
+	}
+	return int32(days)
+}
+
+// DateFromDays is the inverse of DaysFromDate.
+func DateFromDays(days int32) time.Time {
+	return time.Unix(int64(days)*secondsPerDay, 0).UTC()
+}
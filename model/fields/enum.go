@@ -0,0 +1,186 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+// EnumSet is the ordered list of values allowed in an enum column. A value's
+// code is its position, so values can be appended without touching rows
+// already written.
+type EnumSet struct {
+	mu     sync.RWMutex
+	values []string
+	codes  map[string]uint16
+}
+
+func NewEnumSet(values []string) *EnumSet {
+	s := &EnumSet{codes: make(map[string]uint16, len(values))}
+	s.add(values...)
+	return s
+}
+
+func (s *EnumSet) Code(value string) (uint16, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	code, ok := s.codes[value]
+	return code, ok
+}
+
+func (s *EnumSet) Value(code uint16) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if int(code) >= len(s.values) {
+		return "", false
+	}
+	return s.values[code], true
+}
+
+func (s *EnumSet) Values() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.values)
+}
+
+func (s *EnumSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.values)
+}
+
+func (s *EnumSet) add(values ...string) {
+	for _, v := range values {
+		if _, exists := s.codes[v]; exists {
+			continue
+		}
+		s.codes[v] = uint16(len(s.values))
+		s.values = append(s.values, v)
+	}
+}
+
+// EnumType stores a string restricted to an EnumSet as a one or two byte
+// code. FieldMeta.Length selects the width: 2 for uint16 codes, anything
+// else for uint8.
+type EnumType struct {
+	Set   *EnumSet
+	Width int
+}
+
+func NewEnumType(values []string, length int) EnumType {
+	width := 1
+	if length == 2 {
+		width = 2
+	}
+	return EnumType{Set: NewEnumSet(values), Width: width}
+}
+
+// Capacity is the number of distinct values the code width can hold.
+func (dt EnumType) Capacity() int {
+	return 1 << (8 * dt.Width)
+}
+
+// AddValues appends values to the set. Existing values are ignored.
+func (dt EnumType) AddValues(values ...string) error {
+	dt.Set.mu.Lock()
+	defer dt.Set.mu.Unlock()
+
+	added := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		if v == "" {
+			return errors.New("enum values cannot be empty")
+		}
+		if _, exists := dt.Set.codes[v]; !exists {
+			added[v] = struct{}{}
+		}
+	}
+	if len(dt.Set.values)+len(added) > dt.Capacity() {
+		return fmt.Errorf("enum can hold at most %d values with a %d byte code", dt.Capacity(), dt.Width)
+	}
+
+	dt.Set.add(values...)
+	return nil
+}
+
+func (dt EnumType) ResolveLength(length int) (int, error) {
+	if dt.Set == nil || dt.Set.Len() == 0 {
+		return 0, errors.New("enum requires at least one value")
+	}
+	if dt.Set.Len() > dt.Capacity() {
+		return 0, fmt.Errorf("enum has %d values, a %d byte code holds at most %d", dt.Set.Len(), dt.Width, dt.Capacity())
+	}
+	return dt.Width, nil
+}
+
+func (dt EnumType) Read(data []byte, out interface{}) error {
+	if len(data) < dt.Width {
+		return fmt.Errorf("insufficient data for Enum (need %d bytes)", dt.Width)
+	}
+	ptr, ok := out.(*string)
+	if !ok {
+		return errors.New("output must be *string")
+	}
+	value, ok := dt.Set.Value(dt.code(data))
+	if !ok {
+		return fmt.Errorf("unknown enum code %d", dt.code(data))
+	}
+	*ptr = value
+	return nil
+}
+
+func (dt EnumType) Write(buffer []byte, value interface{}) error {
+	var code uint16
+	if value != nil {
+		v, ok := value.(string)
+		if !ok {
+			return errors.New("type assertion failed for Enum")
+		}
+		if code, ok = dt.Set.Code(v); !ok {
+			return fmt.Errorf("value %q is not a member of the enum", v)
+		}
+	}
+	if dt.Width == 2 {
+		*(*uint16)(unsafe.Pointer(&buffer[0])) = code
+	} else {
+		buffer[0] = uint8(code)
+	}
+	return nil
+}
+
+func (dt EnumType) Valid(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v, ok := value.(string)
+	if !ok {
+		return errors.New("value is not of type string")
+	}
+	if _, ok := dt.Set.Code(v); !ok {
+		return fmt.Errorf("value %q is not a member of the enum", v)
+	}
+	return nil
+}
+
+func (dt EnumType) Parse(data []byte) interface{} {
+	if len(data) < dt.Width {
+		return nil
+	}
+	value, ok := dt.Set.Value(dt.code(data))
+	if !ok {
+		return nil
+	}
+	return value
+}
+
+func (dt EnumType) String() string {
+	return "enum"
+}
+
+func (dt EnumType) code(data []byte) uint16 {
+	if dt.Width == 2 {
+		return *(*uint16)(unsafe.Pointer(&data[0]))
+	}
+	return uint16(data[0])
+}
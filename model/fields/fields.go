@@ -12,11 +12,27 @@ type ValidatorInfo struct {
 }
 
 type FieldMeta struct {
-	Name       string          `json:"name"`
-	Type       Types           `json:"type"`
-	Length     int             `json:"length"`
-	Required   bool            `json:"required,omitempty"`
-	Validators []ValidatorInfo `json:"validators,omitempty"`
+	Name        string          `json:"name"`
+	Type        Types           `json:"type"`
+	ElementType Types           `json:"element_type,omitempty"`
+	Values      []string        `json:"values,omitempty"`
+	Length      int             `json:"length"`
+	Required    bool            `json:"required,omitempty"`
+	Validators  []ValidatorInfo `json:"validators,omitempty"`
+}
+
+// DataType resolves the storage type of the field, taking parameterized
+// types such as arrays into account.
+func (f FieldMeta) DataType() DataType {
+	switch f.Type {
+	case Array:
+		return NewArrayType(f.ElementType)
+	case Vector:
+		return NewVectorType(f.Length)
+	case Enum:
+		return NewEnumType(f.Values, f.Length)
+	}
+	return NewDataType(f.Type)
 }
 
 type FieldsMeta []FieldMeta
@@ -24,7 +40,11 @@ type FieldsMeta []FieldMeta
 func (f FieldsMeta) String() string {
 	var results []string
 	for _, field := range f {
-		result := fmt.Sprintf("Name: %s, Type: %s, Length: %d, Required: %t", field.Name, field.Type, field.Length, field.Required)
+		fieldType := string(field.Type)
+		if field.Type == Array {
+			fieldType = fmt.Sprintf("%s<%s>", field.Type, field.ElementType)
+		}
+		result := fmt.Sprintf("Name: %s, Type: %s, Length: %d, Required: %t", field.Name, fieldType, field.Length, field.Required)
 		results = append(results, result)
 	}
 
@@ -0,0 +1,153 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// EarthRadiusMeters is the mean Earth radius used for haversine distances.
+const EarthRadiusMeters = 6371008.8
+
+// LatLng is a WGS84 coordinate in degrees.
+type LatLng struct {
+	Lat float64
+	Lng float64
+}
+
+func (p LatLng) Validate() error {
+	if math.IsNaN(p.Lat) || p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("latitude %v is out of range (-90 to 90)", p.Lat)
+	}
+	if math.IsNaN(p.Lng) || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("longitude %v is out of range (-180 to 180)", p.Lng)
+	}
+	return nil
+}
+
+// DistanceTo returns the great-circle distance to q in meters.
+func (p LatLng) DistanceTo(q LatLng) float64 {
+	lat1, lat2 := p.Lat*math.Pi/180, q.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (q.Lng - p.Lng) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// InBox reports whether p lies in the box spanned by the south-west and
+// north-east corners. Boxes crossing the antimeridian have sw.Lng > ne.Lng.
+func (p LatLng) InBox(sw, ne LatLng) bool {
+	if p.Lat < sw.Lat || p.Lat > ne.Lat {
+		return false
+	}
+	if sw.Lng <= ne.Lng {
+		return p.Lng >= sw.Lng && p.Lng <= ne.Lng
+	}
+	return p.Lng >= sw.Lng || p.Lng <= ne.Lng
+}
+
+func (p LatLng) String() string {
+	return fmt.Sprintf("(%g, %g)", p.Lat, p.Lng)
+}
+
+// ToLatLng accepts a LatLng, a [lat, lng] pair or a map with lat and lng
+// keys, the shapes a point takes after a msgpack round trip.
+func ToLatLng(value interface{}) (LatLng, error) {
+	switch v := value.(type) {
+	case LatLng:
+		return v, nil
+	case *LatLng:
+		if v != nil {
+			return *v, nil
+		}
+	case map[string]interface{}:
+		lat, err1 := toFloat64(v["lat"])
+		lng, err2 := toFloat64(v["lng"])
+		if err1 == nil && err2 == nil {
+			return LatLng{Lat: lat, Lng: lng}, nil
+		}
+	default:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice && rv.Len() == 2 {
+			lat, err1 := toFloat64(rv.Index(0).Interface())
+			lng, err2 := toFloat64(rv.Index(1).Interface())
+			if err1 == nil && err2 == nil {
+				return LatLng{Lat: lat, Lng: lng}, nil
+			}
+		}
+	}
+	return LatLng{}, fmt.Errorf("value %v is not a geo point", value)
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	f, err := ConvertElement(reflect.TypeOf(float64(0)), value)
+	if err != nil {
+		return 0, err
+	}
+	return f.(float64), nil
+}
+
+// GeoPointType stores a LatLng as two float64 values.
+type GeoPointType struct{}
+
+func (GeoPointType) ResolveLength(length int) (int, error) {
+	return 16, nil
+}
+
+func (GeoPointType) Read(data []byte, out interface{}) error {
+	if len(data) < 16 {
+		return errors.New("insufficient data for GeoPoint (need 16 bytes)")
+	}
+	ptr, ok := out.(*LatLng)
+	if !ok {
+		return errors.New("output must be *fields.LatLng")
+	}
+	*ptr = readLatLng(data)
+	return nil
+}
+
+func (GeoPointType) Write(buffer []byte, value interface{}) error {
+	var p LatLng
+	if value != nil {
+		var err error
+		if p, err = ToLatLng(value); err != nil {
+			return errors.New("type assertion failed for GeoPoint")
+		}
+	}
+	*(*float64)(unsafe.Pointer(&buffer[0])) = p.Lat
+	*(*float64)(unsafe.Pointer(&buffer[8])) = p.Lng
+	return nil
+}
+
+func (GeoPointType) Valid(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	p, err := ToLatLng(value)
+	if err != nil {
+		return err
+	}
+	return p.Validate()
+}
+
+func (GeoPointType) Parse(data []byte) interface{} {
+	if len(data) < 16 {
+		return nil
+	}
+	return readLatLng(data)
+}
+
+func (GeoPointType) String() string {
+	return "geopoint"
+}
+
+func readLatLng(data []byte) LatLng {
+	return LatLng{
+		Lat: *(*float64)(unsafe.Pointer(&data[0])),
+		Lng: *(*float64)(unsafe.Pointer(&data[8])),
+	}
+}
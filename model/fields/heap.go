@@ -0,0 +1,17 @@
+package fields
+
+// Heap holds the payload of variable-length values. Columns using a
+// VariableLengthType keep only a fixed-size reference in their records and
+// the bytes themselves in a Heap provided by the storage engine.
+type Heap interface {
+	Append(data []byte) (int64, error)
+	Read(offset int64, length int) ([]byte, error)
+}
+
+// VariableLengthType is implemented by data types that need a Heap. The
+// storage engine calls WithHeap once per column and uses the returned type
+// for every read and write.
+type VariableLengthType interface {
+	DataType
+	WithHeap(heap Heap) DataType
+}
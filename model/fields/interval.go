@@ -0,0 +1,215 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// IntervalValue is a calendar aware span of time. Months and days are kept
+// apart from the clock part so that adding "1 month" to a timestamp honours
+// the length of the month and adding "1 day" honours daylight saving changes.
+type IntervalValue struct {
+	Months int32
+	Days   int32
+	Nanos  int64
+}
+
+func NewInterval(months, days int32, d time.Duration) IntervalValue {
+	return IntervalValue{Months: months, Days: days, Nanos: int64(d)}
+}
+
+var intervalUnits = map[string]IntervalValue{
+	"year":        {Months: 12},
+	"month":       {Months: 1},
+	"week":        {Days: 7},
+	"day":         {Days: 1},
+	"hour":        {Nanos: int64(time.Hour)},
+	"minute":      {Nanos: int64(time.Minute)},
+	"second":      {Nanos: int64(time.Second)},
+	"millisecond": {Nanos: int64(time.Millisecond)},
+	"microsecond": {Nanos: int64(time.Microsecond)},
+}
+
+// ParseInterval accepts SQL style intervals such as "1 day", "2 hours 30
+// minutes", "-1 year 2 months", "01:30:00" and Go durations like "90m".
+// A fraction of a month is kept as days of a 30 day month, and one of a day
+// as time of a 24 hour day, to the microsecond: "1.5 months" is 1 month and
+// 15 days.
+func ParseInterval(s string) (IntervalValue, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" {
+		return IntervalValue{}, errors.New("empty interval")
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return IntervalValue{Nanos: int64(d)}, nil
+	}
+
+	var result IntervalValue
+	parts := strings.Fields(s)
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+
+		if strings.Contains(part, ":") {
+			neg := strings.HasPrefix(part, "-")
+			tod, err := ParseTimeOfDay(strings.TrimPrefix(part, "-"))
+			if err != nil {
+				return IntervalValue{}, fmt.Errorf("invalid interval %q: %w", s, err)
+			}
+			if neg {
+				tod = -tod
+			}
+			result.Nanos += int64(tod)
+			continue
+		}
+
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || i+1 >= len(parts) {
+			return IntervalValue{}, fmt.Errorf("invalid interval %q", s)
+		}
+		i++
+		unit, ok := intervalUnits[strings.TrimSuffix(parts[i], "s")]
+		if !ok {
+			return IntervalValue{}, fmt.Errorf("invalid interval unit %q", parts[i])
+		}
+		months := n * float64(unit.Months)
+		days := (months-math.Trunc(months))*30 + n*float64(unit.Days)
+		spilled := (days - math.Trunc(days)) * float64(24*time.Hour)
+		result.Months += int32(months)
+		result.Days += int32(days)
+		result.Nanos += int64(n*float64(unit.Nanos)) + int64(math.Round(spilled/1e3))*1e3
+	}
+
+	return result, nil
+}
+
+// AddTo returns t shifted forward by the interval.
+func (i IntervalValue) AddTo(t time.Time) time.Time {
+	return t.AddDate(0, int(i.Months), int(i.Days)).Add(time.Duration(i.Nanos))
+}
+
+// SubFrom returns t shifted backwards by the interval.
+func (i IntervalValue) SubFrom(t time.Time) time.Time {
+	return i.Negate().AddTo(t)
+}
+
+func (i IntervalValue) Negate() IntervalValue {
+	return IntervalValue{Months: -i.Months, Days: -i.Days, Nanos: -i.Nanos}
+}
+
+func (i IntervalValue) Add(other IntervalValue) IntervalValue {
+	return IntervalValue{
+		Months: i.Months + other.Months,
+		Days:   i.Days + other.Days,
+		Nanos:  i.Nanos + other.Nanos,
+	}
+}
+
+// Duration approximates the interval as a fixed duration using 30 day months
+// and 24 hour days. It is meant for ordering, not for arithmetic on dates.
+func (i IntervalValue) Duration() time.Duration {
+	return time.Duration(i.Months)*30*24*time.Hour +
+		time.Duration(i.Days)*24*time.Hour +
+		time.Duration(i.Nanos)
+}
+
+func (i IntervalValue) Compare(other IntervalValue) int {
+	a, b := i.Duration(), other.Duration()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (i IntervalValue) String() string {
+	var parts []string
+	if years := i.Months / 12; years != 0 {
+		parts = append(parts, fmt.Sprintf("%d years", years))
+	}
+	if months := i.Months % 12; months != 0 {
+		parts = append(parts, fmt.Sprintf("%d months", months))
+	}
+	if i.Days != 0 {
+		parts = append(parts, fmt.Sprintf("%d days", i.Days))
+	}
+	if i.Nanos != 0 || len(parts) == 0 {
+		if i.Nanos < 0 {
+			parts = append(parts, "-"+TimeOfDay(-i.Nanos).String())
+		} else {
+			parts = append(parts, TimeOfDay(i.Nanos).String())
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// IntervalType stores an IntervalValue as months (int32), days (int32) and
+// nanoseconds (int64).
+type IntervalType struct{}
+
+func (IntervalType) ResolveLength(length int) (int, error) {
+	return 16, nil
+}
+
+func (IntervalType) Read(data []byte, out interface{}) error {
+	if len(data) < 16 {
+		return errors.New("insufficient data for Interval (need 16 bytes)")
+	}
+	ptr, ok := out.(*IntervalValue)
+	if !ok {
+		return errors.New("output must be *fields.IntervalValue")
+	}
+	*ptr = readInterval(data)
+	return nil
+}
+
+func (IntervalType) Write(buffer []byte, value interface{}) error {
+	var v IntervalValue
+	switch val := value.(type) {
+	case nil:
+	case IntervalValue:
+		v = val
+	case time.Duration:
+		v = IntervalValue{Nanos: int64(val)}
+	default:
+		return errors.New("type assertion failed for Interval")
+	}
+	*(*int32)(unsafe.Pointer(&buffer[0])) = v.Months
+	*(*int32)(unsafe.Pointer(&buffer[4])) = v.Days
+	*(*int64)(unsafe.Pointer(&buffer[8])) = v.Nanos
+	return nil
+}
+
+func (IntervalType) Valid(value interface{}) error {
+	switch value.(type) {
+	case nil, IntervalValue, time.Duration:
+		return nil
+	}
+	return fmt.Errorf("value %v is neither fields.IntervalValue nor time.Duration type", value)
+}
+
+func (IntervalType) Parse(data []byte) interface{} {
+	if len(data) < 16 {
+		return nil
+	}
+	return readInterval(data)
+}
+
+func (IntervalType) String() string {
+	return "interval"
+}
+
+func readInterval(data []byte) IntervalValue {
+	return IntervalValue{
+		Months: *(*int32)(unsafe.Pointer(&data[0])),
+		Days:   *(*int32)(unsafe.Pointer(&data[4])),
+		Nanos:  *(*int64)(unsafe.Pointer(&data[8])),
+	}
+}
@@ -0,0 +1,86 @@
+package fields
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpack extension ids used by value types defined in this package. They let
+// values travelling inside interface{} (filter values, insert rows) decode
+// back into their Go type instead of a generic map.
+const (
+	IntervalExtID  int8 = 1
+	TimeOfDayExtID int8 = 2
+	LatLngExtID    int8 = 3
+)
+
+func init() {
+	msgpack.RegisterExtEncoder(IntervalExtID, IntervalValue{}, func(e *msgpack.Encoder, v reflect.Value) ([]byte, error) {
+		i := v.Interface().(IntervalValue)
+		b := make([]byte, 16)
+		binary.LittleEndian.PutUint32(b[0:], uint32(i.Months))
+		binary.LittleEndian.PutUint32(b[4:], uint32(i.Days))
+		binary.LittleEndian.PutUint64(b[8:], uint64(i.Nanos))
+		return b, nil
+	})
+	msgpack.RegisterExtDecoder(IntervalExtID, IntervalValue{}, func(d *msgpack.Decoder, v reflect.Value, extLen int) error {
+		b, err := readExt(d, extLen, 16)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(IntervalValue{
+			Months: int32(binary.LittleEndian.Uint32(b[0:])),
+			Days:   int32(binary.LittleEndian.Uint32(b[4:])),
+			Nanos:  int64(binary.LittleEndian.Uint64(b[8:])),
+		}))
+		return nil
+	})
+
+	msgpack.RegisterExtEncoder(TimeOfDayExtID, TimeOfDay(0), func(e *msgpack.Encoder, v reflect.Value) ([]byte, error) {
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, uint64(v.Interface().(TimeOfDay)))
+		return b, nil
+	})
+	msgpack.RegisterExtDecoder(TimeOfDayExtID, TimeOfDay(0), func(d *msgpack.Decoder, v reflect.Value, extLen int) error {
+		b, err := readExt(d, extLen, 8)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(TimeOfDay(binary.LittleEndian.Uint64(b))))
+		return nil
+	})
+
+	msgpack.RegisterExtEncoder(LatLngExtID, LatLng{}, func(e *msgpack.Encoder, v reflect.Value) ([]byte, error) {
+		p := v.Interface().(LatLng)
+		b := make([]byte, 16)
+		binary.LittleEndian.PutUint64(b[0:], math.Float64bits(p.Lat))
+		binary.LittleEndian.PutUint64(b[8:], math.Float64bits(p.Lng))
+		return b, nil
+	})
+	msgpack.RegisterExtDecoder(LatLngExtID, LatLng{}, func(d *msgpack.Decoder, v reflect.Value, extLen int) error {
+		b, err := readExt(d, extLen, 16)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(LatLng{
+			Lat: math.Float64frombits(binary.LittleEndian.Uint64(b[0:])),
+			Lng: math.Float64frombits(binary.LittleEndian.Uint64(b[8:])),
+		}))
+		return nil
+	})
+}
+
+func readExt(d *msgpack.Decoder, extLen, want int) ([]byte, error) {
+	if extLen != want {
+		return nil, errors.New("msgpack: invalid extension length")
+	}
+	b := make([]byte, extLen)
+	if err := d.ReadFull(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,85 @@
+package fields_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+func TestTemporalRoundTrip(t *testing.T) {
+	tests := []struct {
+		dataType fields.DataType
+		value    interface{}
+		want     interface{}
+	}{
+		{fields.DateType{}, time.Date(2024, 2, 29, 23, 59, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{fields.DateType{}, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{fields.DateType{}, time.Date(1900, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{fields.TimeOfDayType{}, fields.NewTimeOfDay(23, 59, 59, 999999999), fields.NewTimeOfDay(23, 59, 59, 999999999)},
+		{fields.TimeOfDayType{}, time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC), fields.NewTimeOfDay(8, 30, 0, 0)},
+		{fields.IntervalType{}, fields.NewInterval(-14, 3, -90*time.Minute), fields.NewInterval(-14, 3, -90*time.Minute)},
+		{fields.IntervalType{}, 36 * time.Hour, fields.NewInterval(0, 0, 36*time.Hour)},
+	}
+
+	for _, tt := range tests {
+		length, err := tt.dataType.ResolveLength(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tt.dataType.Valid(tt.value); err != nil {
+			t.Errorf("%s.Valid(%v) = %v", tt.dataType, tt.value, err)
+		}
+		buffer := make([]byte, length)
+		if err := tt.dataType.Write(buffer, tt.value); err != nil {
+			t.Fatalf("%s.Write(%v) = %v", tt.dataType, tt.value, err)
+		}
+		got := tt.dataType.Parse(buffer)
+		if want, ok := tt.want.(time.Time); ok {
+			if got, ok := got.(time.Time); !ok || !got.Equal(want) {
+				t.Errorf("%s.Parse = %v, want %v", tt.dataType, got, want)
+			}
+		} else if got != tt.want {
+			t.Errorf("%s.Parse = %v, want %v", tt.dataType, got, tt.want)
+		}
+	}
+
+	if err := (fields.TimeOfDayType{}).Valid(fields.TimeOfDay(24 * time.Hour)); err == nil {
+		t.Error("TimeOfDayType accepted 24:00:00")
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		s    string
+		want fields.IntervalValue
+	}{
+		{"1 day", fields.NewInterval(0, 1, 0)},
+		{"2 hours 30 minutes", fields.NewInterval(0, 0, 150*time.Minute)},
+		{"-1 year 2 months", fields.NewInterval(-10, 0, 0)},
+		{"1 week 01:30:00", fields.NewInterval(0, 7, 90*time.Minute)},
+		{"-01:30", fields.NewInterval(0, 0, -90*time.Minute)},
+		{"90m", fields.NewInterval(0, 0, 90*time.Minute)},
+		{"1.5 months", fields.NewInterval(1, 15, 0)},
+		{"-1.5 months", fields.NewInterval(-1, -15, 0)},
+		{"1.5 days", fields.NewInterval(0, 1, 12*time.Hour)},
+		{"1.1 years", fields.NewInterval(13, 6, 0)},
+		{"0.25 weeks", fields.NewInterval(0, 1, 18*time.Hour)},
+		{"1.5 seconds", fields.NewInterval(0, 0, 1500*time.Millisecond)},
+		{"3 Months", fields.NewInterval(3, 0, 0)},
+	}
+	for _, tt := range tests {
+		got, err := fields.ParseInterval(tt.s)
+		if err != nil {
+			t.Errorf("ParseInterval(%q) = %v", tt.s, err)
+		} else if got != tt.want {
+			t.Errorf("ParseInterval(%q) = %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "1", "1 fortnight", "day", "25:00"} {
+		if got, err := fields.ParseInterval(s); err == nil {
+			t.Errorf("ParseInterval(%q) = %v, want an error", s, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// TimeOfDay is a wall clock time without date or zone, expressed as the
+// elapsed time since midnight.
+type TimeOfDay time.Duration
+
+const maxTimeOfDay = TimeOfDay(24 * time.Hour)
+
+func NewTimeOfDay(hour, min, sec, nsec int) TimeOfDay {
+	return TimeOfDay(time.Duration(hour)*time.Hour +
+		time.Duration(min)*time.Minute +
+		time.Duration(sec)*time.Second +
+		time.Duration(nsec))
+}
+
+// TimeOfDayFromTime extracts the clock part of t in its own location.
+func TimeOfDayFromTime(t time.Time) TimeOfDay {
+	return NewTimeOfDay(t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+}
+
+// ParseTimeOfDay accepts "15:04", "15:04:05" and "15:04:05.999999999".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"15:04:05.999999999", "15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return TimeOfDayFromTime(t), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time of day: %q", s)
+}
+
+func (t TimeOfDay) Valid() bool {
+	return t >= 0 && t < maxTimeOfDay
+}
+
+func (t TimeOfDay) String() string {
+	d := time.Duration(t)
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+	ns := d - s*time.Second
+	if ns == 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	}
+	return strings.TrimRight(fmt.Sprintf("%02d:%02d:%02d.%09d", h, m, s, ns), "0")
+}
+
+// TimeOfDayType stores a TimeOfDay as nanoseconds since midnight.
+type TimeOfDayType struct{}
+
+func (TimeOfDayType) ResolveLength(length int) (int, error) {
+	return 8, nil
+}
+
+func (TimeOfDayType) Read(data []byte, out interface{}) error {
+	if len(data) < 8 {
+		return errors.New("insufficient data for Time (need 8 bytes)")
+	}
+	ptr, ok := out.(*TimeOfDay)
+	if !ok {
+		return errors.New("output must be *fields.TimeOfDay")
+	}
+	*ptr = TimeOfDay(*(*int64)(unsafe.Pointer(&data[0])))
+	return nil
+}
+
+func (TimeOfDayType) Write(buffer []byte, value interface{}) error {
+	var v TimeOfDay
+	switch val := value.(type) {
+	case nil:
+	case TimeOfDay:
+		v = val
+	case time.Time:
+		v = TimeOfDayFromTime(val)
+	default:
+		return errors.New("type assertion failed for Time")
+	}
+	*(*int64)(unsafe.Pointer(&buffer[0])) = int64(v)
+	return nil
+}
+
+func (TimeOfDayType) Valid(value interface{}) error {
+	switch v := value.(type) {
+	case nil, time.Time:
+		return nil
+	case TimeOfDay:
+		if !v.Valid() {
+			return fmt.Errorf("time of day %s is out of range", time.Duration(v))
+		}
+		return nil
+	}
+	return fmt.Errorf("value %v is neither fields.TimeOfDay nor time.Time type", value)
+}
+
+func (TimeOfDayType) Parse(data []byte) interface{} {
+	if len(data) < 8 {
+		return nil
+	}
+	return TimeOfDay(*(*int64)(unsafe.Pointer(&data[0])))
+}
+
+func (TimeOfDayType) String() string {
+	return "time"
+}
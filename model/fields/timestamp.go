@@ -3,6 +3,7 @@ package fields
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 	"unsafe"
 )
@@ -26,11 +27,16 @@ func (TimestampType) Read(data []byte, out interface{}) error {
 }
 
 func (TimestampType) Write(buffer []byte, value interface{}) error {
-	v, ok := value.(time.Time)
-	if !ok && value != nil {
+	switch v := value.(type) {
+	case nil:
+		*(*int64)(unsafe.Pointer(&buffer[0])) = 0
+	case time.Time:
+		*(*int64)(unsafe.Pointer(&buffer[0])) = v.UnixNano()
+	case int64:
+		*(*int64)(unsafe.Pointer(&buffer[0])) = v
+	default:
 		return fmt.Errorf("type assertion failed for Timestamp")
 	}
-	*(*int64)(unsafe.Pointer(&buffer[0])) = v.UnixNano()
 	return nil
 }
 
@@ -57,3 +63,23 @@ func (TimestampType) Parse(data []byte) interface{} {
 func (TimestampType) String() string {
 	return "timestamp"
 }
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseTime parses the textual timestamp formats accepted in SQL literals
+// and filter values.
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q, expected RFC3339", s)
+}
@@ -0,0 +1,70 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// TimestampTZType stores an instant (UnixNano) together with the UTC offset
+// it was written with, so values read back keep their original offset.
+type TimestampTZType struct{}
+
+func (TimestampTZType) ResolveLength(length int) (int, error) {
+	return 12, nil
+}
+
+func (TimestampTZType) Read(data []byte, out interface{}) error {
+	if len(data) < 12 {
+		return errors.New("insufficient data for TimestampTZ (need 12 bytes)")
+	}
+	ptr, ok := out.(*time.Time)
+	if !ok {
+		return errors.New("output must be *time.Time")
+	}
+	*ptr = readTimestampTZ(data)
+	return nil
+}
+
+func (TimestampTZType) Write(buffer []byte, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*(*int64)(unsafe.Pointer(&buffer[0])) = 0
+		*(*int32)(unsafe.Pointer(&buffer[8])) = 0
+	case time.Time:
+		_, offset := v.Zone()
+		*(*int64)(unsafe.Pointer(&buffer[0])) = v.UnixNano()
+		*(*int32)(unsafe.Pointer(&buffer[8])) = int32(offset)
+	default:
+		return fmt.Errorf("type assertion failed for TimestampTZ")
+	}
+	return nil
+}
+
+func (TimestampTZType) Valid(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if _, ok := value.(time.Time); ok {
+		return nil
+	}
+	return fmt.Errorf("value %v is not time.Time type", value)
+}
+
+func (TimestampTZType) Parse(data []byte) interface{} {
+	if len(data) < 12 {
+		return nil
+	}
+	return readTimestampTZ(data)
+}
+
+func (TimestampTZType) String() string {
+	return "timestamptz"
+}
+
+func readTimestampTZ(data []byte) time.Time {
+	nanos := *(*int64)(unsafe.Pointer(&data[0]))
+	offset := *(*int32)(unsafe.Pointer(&data[8]))
+	return time.Unix(0, nanos).In(time.FixedZone("", int(offset)))
+}
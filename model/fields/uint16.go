@@ -33,7 +33,7 @@ func (Uint16Type) Write(buffer []byte, value interface{}) error {
 }
 
 func (Uint16Type) Parse(data []byte) interface{} {
-	return *(*int16)(unsafe.Pointer(&data[0]))
+	return *(*uint16)(unsafe.Pointer(&data[0]))
 }
 
 func (Uint16Type) Valid(value interface{}) error {
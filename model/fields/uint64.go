@@ -33,7 +33,7 @@ func (Uint64Type) Write(buffer []byte, value interface{}) error {
 }
 
 func (Uint64Type) Parse(data []byte) interface{} {
-	return *(*int64)(unsafe.Pointer(&data[0]))
+	return *(*uint64)(unsafe.Pointer(&data[0]))
 }
 
 func (Uint64Type) Valid(value interface{}) error {
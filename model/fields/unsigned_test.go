@@ -0,0 +1,35 @@
+package fields_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+func TestUnsignedRoundTrip(t *testing.T) {
+	tests := []struct {
+		dataType fields.DataType
+		value    interface{}
+	}{
+		{fields.Uint8Type{}, uint8(math.MaxUint8)},
+		{fields.Uint16Type{}, uint16(math.MaxUint16)},
+		{fields.Uint32Type{}, uint32(math.MaxUint32)},
+		{fields.Uint64Type{}, uint64(math.MaxUint64)},
+		{fields.Uint64Type{}, uint64(math.MaxInt64 + 1)},
+	}
+
+	for _, tt := range tests {
+		buffer := make([]byte, 8)
+		if err := tt.dataType.Write(buffer, tt.value); err != nil {
+			t.Fatalf("%s.Write(%v) = %v", tt.dataType, tt.value, err)
+		}
+		if got := tt.dataType.Parse(buffer); got != tt.value {
+			t.Errorf("%s.Parse = %v (%T), want %v (%T)", tt.dataType, got, got, tt.value, tt.value)
+		}
+	}
+
+	if c, err := fields.Compare(uint64(math.MaxUint64), uint64(1)); err != nil || c != 1 {
+		t.Errorf("Compare(MaxUint64, 1) = %d, %v, want 1", c, err)
+	}
+}
@@ -0,0 +1,183 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// VectorType stores a fixed-dimension embedding as consecutive float32
+// values. The dimension comes from FieldMeta.Length.
+type VectorType struct {
+	Dimensions int
+}
+
+func NewVectorType(dimensions int) VectorType {
+	return VectorType{Dimensions: dimensions}
+}
+
+func (dt VectorType) ResolveLength(length int) (int, error) {
+	if dt.Dimensions <= 0 {
+		return 0, errors.New("vector dimensions must be greater than zero")
+	}
+	return dt.Dimensions * 4, nil
+}
+
+// Read points out at the column data without copying, so the slice is only
+// valid until the underlying view is released. Use Parse for a copy.
+func (dt VectorType) Read(data []byte, out interface{}) error {
+	if len(data) < dt.Dimensions*4 {
+		return fmt.Errorf("insufficient data for Vector (need %d bytes)", dt.Dimensions*4)
+	}
+	ptr, ok := out.(*[]float32)
+	if !ok {
+		return errors.New("output must be *[]float32")
+	}
+	*ptr = unsafe.Slice((*float32)(unsafe.Pointer(&data[0])), dt.Dimensions)
+	return nil
+}
+
+func (dt VectorType) Write(buffer []byte, value interface{}) error {
+	if value == nil {
+		clear(buffer[:dt.Dimensions*4])
+		return nil
+	}
+	vector, err := dt.Vector(value)
+	if err != nil {
+		return err
+	}
+	copy(unsafe.Slice((*float32)(unsafe.Pointer(&buffer[0])), dt.Dimensions), vector)
+	return nil
+}
+
+func (dt VectorType) Valid(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	_, err := dt.Vector(value)
+	return err
+}
+
+func (dt VectorType) Parse(data []byte) interface{} {
+	if len(data) < dt.Dimensions*4 {
+		return nil
+	}
+	vector := make([]float32, dt.Dimensions)
+	copy(vector, unsafe.Slice((*float32)(unsafe.Pointer(&data[0])), dt.Dimensions))
+	return vector
+}
+
+func (dt VectorType) String() string {
+	return "vector"
+}
+
+// Vector converts value into a []float32 of the column dimension. It accepts
+// []float32, []float64 and lists of numbers as decoded by msgpack.
+func (dt VectorType) Vector(value interface{}) ([]float32, error) {
+	vector, err := ToVector(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(vector) != dt.Dimensions {
+		return nil, fmt.Errorf("vector has %d dimensions, expected %d", len(vector), dt.Dimensions)
+	}
+	return vector, nil
+}
+
+// ToVector converts a list of numbers into a []float32.
+func ToVector(value interface{}) ([]float32, error) {
+	switch v := value.(type) {
+	case []float32:
+		return v, nil
+	case []float64:
+		vector := make([]float32, len(v))
+		for i, f := range v {
+			vector[i] = float32(f)
+		}
+		return vector, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("value %v is not a vector", value)
+	}
+	vector := make([]float32, rv.Len())
+	for i := range vector {
+		f, err := ConvertElement(reflect.TypeOf(float64(0)), rv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector component %d: %w", i, err)
+		}
+		vector[i] = float32(f.(float64))
+	}
+	return vector, nil
+}
+
+// VectorMetric names a distance function between vectors. Smaller distances
+// always mean more similar vectors, so dot product is returned negated.
+type VectorMetric string
+
+const (
+	Cosine VectorMetric = "cosine"
+	L2     VectorMetric = "l2"
+	Dot    VectorMetric = "dot"
+)
+
+type DistanceFunc func(a, b []float32) float32
+
+func (m VectorMetric) DistanceFunc() (DistanceFunc, error) {
+	switch m {
+	case Cosine, "":
+		return CosineDistance, nil
+	case L2:
+		return L2Distance, nil
+	case Dot:
+		return func(a, b []float32) float32 { return -DotProduct(a, b) }, nil
+	}
+	return nil, fmt.Errorf("unknown vector metric %q", m)
+}
+
+// DotProduct is unrolled by four so the compiler can keep independent
+// accumulators in registers.
+func DotProduct(a, b []float32) float32 {
+	n := min(len(a), len(b))
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < n; i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+func L2Distance(a, b []float32) float32 {
+	n := min(len(a), len(b))
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		d0, d1, d2, d3 := a[i]-b[i], a[i+1]-b[i+1], a[i+2]-b[i+2], a[i+3]-b[i+3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for ; i < n; i++ {
+		d := a[i] - b[i]
+		s0 += d * d
+	}
+	return float32(math.Sqrt(float64(s0 + s1 + s2 + s3)))
+}
+
+func CosineDistance(a, b []float32) float32 {
+	normA, normB := DotProduct(a, a), DotProduct(b, b)
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return 1 - DotProduct(a, b)/float32(math.Sqrt(float64(normA)*float64(normB)))
+}
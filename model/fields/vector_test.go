@@ -0,0 +1,64 @@
+package fields_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/onnasoft/ZenithSQL/model/fields"
+)
+
+func TestVectorRoundTrip(t *testing.T) {
+	dataType := fields.NewVectorType(3)
+	length, err := dataType.ResolveLength(0)
+	if err != nil || length != 12 {
+		t.Fatalf("ResolveLength = %d, %v, want 12", length, err)
+	}
+
+	for _, value := range []interface{}{[]float32{1, -2, 0.5}, []float64{1, -2, 0.5}, []interface{}{int8(1), -2, 0.5}} {
+		buffer := make([]byte, length)
+		if err := dataType.Write(buffer, value); err != nil {
+			t.Fatalf("Write(%v) = %v", value, err)
+		}
+		if got := fmt.Sprint(dataType.Parse(buffer)); got != "[1 -2 0.5]" {
+			t.Errorf("Parse after Write(%v) = %s, want [1 -2 0.5]", value, got)
+		}
+	}
+
+	for _, value := range []interface{}{[]float32{1, 2}, "abc", []interface{}{1, "x", 2}} {
+		if err := dataType.Valid(value); err == nil {
+			t.Errorf("Valid(%v) accepted", value)
+		}
+	}
+	if err := dataType.Valid(nil); err != nil {
+		t.Errorf("Valid(nil) = %v", err)
+	}
+}
+
+func TestVectorDistances(t *testing.T) {
+	a, b := []float32{1, 0, 0, 0, 3}, []float32{0, 2, 0, 0, 3}
+	tests := []struct {
+		metric fields.VectorMetric
+		want   float32
+	}{
+		{fields.L2, 2.236068},
+		{fields.Dot, -9},
+		{fields.Cosine, 1 - 9/(3.1622777*3.6055512)},
+	}
+
+	for _, tt := range tests {
+		distance, err := tt.metric.DistanceFunc()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.metric, err)
+		}
+		if got := distance(a, b); got-tt.want > 1e-5 || tt.want-got > 1e-5 {
+			t.Errorf("%s distance = %v, want %v", tt.metric, got, tt.want)
+		}
+	}
+
+	if got := fields.CosineDistance(a, make([]float32, 5)); got != 1 {
+		t.Errorf("cosine distance to the zero vector = %v, want 1", got)
+	}
+	if _, err := fields.VectorMetric("manhattan").DistanceFunc(); err == nil {
+		t.Error("unknown metric accepted")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/onnasoft/ZenithSQL/core/executor"
 	"github.com/onnasoft/ZenithSQL/core/utils"
 	"github.com/onnasoft/ZenithSQL/io/protocol"
 	"github.com/onnasoft/ZenithSQL/io/response"
@@ -25,6 +26,10 @@ func (s *MessageServer) handleConnection(conn net.Conn) {
 	loginStmt := stmt.(*statement.JoinClusterStatement)
 
 	handler := network.NewZenithConnection(conn, s.logger, s.timeout)
+	// The session keeps the statements the connection prepares, for the
+	// requests executed with the context of the connection.
+	session := executor.NewSession()
+	handler.SetContext(executor.WithSession(handler.Context(), session))
 
 	if s.onConnection != nil {
 		s.onConnection(handler, loginStmt)
@@ -33,6 +38,7 @@ func (s *MessageServer) handleConnection(conn net.Conn) {
 	go handler.Listen(func(m *transport.Message) {
 		s.handler(handler, m)
 	}, func(error) {
+		session.Close()
 		s.logger.Warn("Connection closed: ", conn.RemoteAddr())
 	})
 
@@ -1,10 +1,12 @@
 package network
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/onnasoft/ZenithSQL/io/transport"
@@ -17,6 +19,11 @@ type ZenithConnection struct {
 	mu          sync.Mutex
 	logger      *logrus.Logger
 	timeout     time.Duration
+	// ctx carries per-connection state, such as the executor session.
+	// Request handlers read it while Listen holds mu to dispatch them, and
+	// mu is not reentrant, so ctx is an atomic.Value read and replaced
+	// without taking a lock.
+	ctx atomic.Value
 }
 
 func NewZenithConnection(conn net.Conn, logger *logrus.Logger, timeout time.Duration) *ZenithConnection {
@@ -28,10 +35,29 @@ func NewZenithConnection(conn net.Conn, logger *logrus.Logger, timeout time.Dura
 
 		mu: sync.Mutex{},
 	}
+	connection.ctx.Store(contextHolder{context.Background()})
 
 	return connection
 }
 
+// contextHolder lets contexts of different dynamic types share ctx.
+type contextHolder struct {
+	context.Context
+}
+
+// Context returns the context of the connection, which servers use to keep
+// per-connection state across requests.
+func (c *ZenithConnection) Context() context.Context {
+	return c.ctx.Load().(contextHolder).Context
+}
+
+// SetContext replaces the context of the connection. Servers set it when
+// the connection is accepted, before any request is handled; the
+// OnConnection callback may derive a new one from it.
+func (c *ZenithConnection) SetContext(ctx context.Context) {
+	c.ctx.Store(contextHolder{ctx})
+}
+
 func (c *ZenithConnection) Send(message *transport.Message) (*transport.Message, error) {
 	messageID := message.Header.MessageIDString()
 	responseChan := make(chan *transport.Message, 1)